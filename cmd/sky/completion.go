@@ -0,0 +1,240 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// completionCommand is a completable word with a short description.
+type completionCommand struct {
+	name string
+	desc string
+}
+
+// managementCommands are the built-in commands offered by completion, in
+// addition to the core tool aliases.
+var managementCommands = []completionCommand{
+	{"verify", "run fmt --check, lint, and check together"},
+	{"plugin", "manage plugins"},
+	{"env", "print the SKY_* environment passed to plugins"},
+	{"tools", "show how each core tool resolves"},
+	{"version", "show version"},
+	{"completion", "generate shell completion scripts"},
+	{"help", "show help"},
+}
+
+// pluginSubcommands are the "sky plugin" subcommands offered by completion.
+var pluginSubcommands = []completionCommand{
+	{"init", "create a new plugin project"},
+	{"list", "list installed plugins"},
+	{"install", "install a plugin"},
+	{"inspect", "inspect plugin metadata"},
+	{"remove", "remove a plugin"},
+	{"search", "search marketplaces"},
+	{"verify", "check installed binaries against recorded sha256"},
+	{"doctor", "check that plugins run and are ready"},
+	{"export", "write installed plugins and marketplaces to a manifest"},
+	{"import", "install the plugins and marketplaces in a manifest"},
+	{"lock", "write installed plugin digests to a lockfile"},
+	{"marketplace", "manage marketplaces"},
+}
+
+// marketplaceSubcommands are the "sky plugin marketplace" subcommands.
+var marketplaceSubcommands = []string{"list", "add", "remove", "validate"}
+
+// pluginNameSubcommands take an installed plugin name as their argument.
+var pluginNameSubcommands = []string{"inspect", "remove", "verify", "doctor"}
+
+// completionShells are the shells "sky completion" can generate for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// runCompletion prints a shell completion script. Installed plugin names are
+// completed at runtime via "sky plugin list --quiet".
+func runCompletion(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("completion", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		writeln(stderr, "usage: sky completion bash|zsh|fish")
+		writeln(stderr)
+		writeln(stderr, "Examples:")
+		writeln(stderr, "  source <(sky completion bash)")
+		writeln(stderr, "  sky completion zsh > \"${fpath[1]}/_sky\"")
+		writeln(stderr, "  sky completion fish > ~/.config/fish/completions/sky.fish")
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	switch fs.Arg(0) {
+	case "bash":
+		writef(stdout, "%s", bashCompletion())
+	case "zsh":
+		writef(stdout, "%s", zshCompletion())
+	case "fish":
+		writef(stdout, "%s", fishCompletion())
+	default:
+		writef(stderr, "sky: unsupported shell %q (want bash, zsh, or fish)\n", fs.Arg(0))
+		return 2
+	}
+	return 0
+}
+
+// topLevelCommands returns core aliases followed by management commands.
+func topLevelCommands() []completionCommand {
+	names := make([]string, 0, len(coreCommands))
+	for name := range coreCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	commands := make([]completionCommand, 0, len(names)+len(managementCommands))
+	for _, name := range names {
+		commands = append(commands, completionCommand{name, coreCommandDescriptions[name]})
+	}
+	return append(commands, managementCommands...)
+}
+
+func commandNames(commands []completionCommand) string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+	}
+	return strings.Join(names, " ")
+}
+
+func bashCompletion() string {
+	return fmt.Sprintf(`# bash completion for sky
+_sky() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    COMPREPLY=()
+
+    if [[ $COMP_CWORD -eq 1 ]]; then
+        COMPREPLY=($(compgen -W "%s $(sky plugin list --quiet 2>/dev/null)" -- "$cur"))
+        return
+    fi
+
+    case "${COMP_WORDS[1]}" in
+    plugin)
+        if [[ $COMP_CWORD -eq 2 ]]; then
+            COMPREPLY=($(compgen -W "%s" -- "$cur"))
+        elif [[ $COMP_CWORD -eq 3 ]]; then
+            case "${COMP_WORDS[2]}" in
+            %s)
+                COMPREPLY=($(compgen -W "$(sky plugin list --quiet 2>/dev/null)" -- "$cur"))
+                ;;
+            marketplace)
+                COMPREPLY=($(compgen -W "%s" -- "$cur"))
+                ;;
+            esac
+        fi
+        ;;
+    completion)
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+        ;;
+    esac
+}
+complete -o default -F _sky sky
+`,
+		commandNames(topLevelCommands()),
+		commandNames(pluginSubcommands),
+		strings.Join(pluginNameSubcommands, "|"),
+		strings.Join(marketplaceSubcommands, " "),
+		strings.Join(completionShells, " "),
+	)
+}
+
+// zshDescribe renders commands as a zsh array of "name:description" words.
+func zshDescribe(commands []completionCommand) string {
+	var b strings.Builder
+	for _, c := range commands {
+		fmt.Fprintf(&b, "\n        '%s:%s'", c.name, strings.ReplaceAll(c.desc, "'", "'\\''"))
+	}
+	return b.String()
+}
+
+func zshCompletion() string {
+	return fmt.Sprintf(`#compdef sky
+# zsh completion for sky
+_sky() {
+    local -a commands plugin_commands installed
+    commands=(%s
+    )
+    plugin_commands=(%s
+    )
+    installed=(${(f)"$(sky plugin list --quiet 2>/dev/null)"})
+
+    if (( CURRENT == 2 )); then
+        _describe 'command' commands
+        (( ${#installed} )) && compadd -X 'installed plugins' -- $installed
+        return
+    fi
+
+    case $words[2] in
+    plugin)
+        if (( CURRENT == 3 )); then
+            _describe 'plugin command' plugin_commands
+        elif (( CURRENT == 4 )); then
+            case $words[3] in
+            %s) compadd -- $installed ;;
+            marketplace) compadd -- %s ;;
+            *) _files ;;
+            esac
+        else
+            _files
+        fi
+        ;;
+    completion)
+        compadd -- %s
+        ;;
+    *)
+        _files
+        ;;
+    esac
+}
+
+if [[ "${funcstack[1]}" == "_sky" ]]; then
+    _sky "$@"
+else
+    compdef _sky sky
+fi
+`,
+		zshDescribe(topLevelCommands()),
+		zshDescribe(pluginSubcommands),
+		strings.Join(pluginNameSubcommands, "|"),
+		strings.Join(marketplaceSubcommands, " "),
+		strings.Join(completionShells, " "),
+	)
+}
+
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# fish completion for sky\n")
+	for _, c := range topLevelCommands() {
+		fmt.Fprintf(&b, "complete -c sky -n __fish_use_subcommand -a %s -d %s\n", c.name, fishQuote(c.desc))
+	}
+	b.WriteString("complete -c sky -n __fish_use_subcommand -a '(sky plugin list --quiet 2>/dev/null)' -d 'installed plugin'\n")
+
+	pluginNames := commandNames(pluginSubcommands)
+	for _, c := range pluginSubcommands {
+		fmt.Fprintf(&b, "complete -c sky -n '__fish_seen_subcommand_from plugin; and not __fish_seen_subcommand_from %s' -a %s -d %s\n", pluginNames, c.name, fishQuote(c.desc))
+	}
+	fmt.Fprintf(&b, "complete -c sky -n '__fish_seen_subcommand_from plugin; and __fish_seen_subcommand_from %s' -f -a '(sky plugin list --quiet 2>/dev/null)'\n", strings.Join(pluginNameSubcommands, " "))
+	fmt.Fprintf(&b, "complete -c sky -n '__fish_seen_subcommand_from marketplace' -f -a '%s'\n", strings.Join(marketplaceSubcommands, " "))
+	fmt.Fprintf(&b, "complete -c sky -n '__fish_seen_subcommand_from completion' -f -a '%s'\n", strings.Join(completionShells, " "))
+	return b.String()
+}
+
+// fishQuote single-quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCompletion(t *testing.T) {
+	cases := []struct {
+		shell string
+		want  []string
+	}{
+		{shell: "bash", want: []string{"complete -o default -F _sky sky", "fmt", "marketplace", "sky plugin list --quiet"}},
+		{shell: "zsh", want: []string{"#compdef sky", "'lint:lint Starlark files'", "sky plugin list --quiet"}},
+		{shell: "fish", want: []string{"-a check -d 'static analysis'", "__fish_seen_subcommand_from inspect remove verify doctor", "sky plugin list --quiet"}},
+	}
+
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		if code := runCompletion([]string{tc.shell}, &stdout, &stderr); code != 0 {
+			t.Fatalf("%s: expected exit code 0, got %d (stderr %q)", tc.shell, code, stderr.String())
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(stdout.String(), want) {
+				t.Fatalf("%s: expected script to contain %q", tc.shell, want)
+			}
+		}
+	}
+}
+
+func TestRunCompletion_UnknownShell(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runCompletion([]string{"powershell"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+}
+
+func TestRunCompletion_BashSyntax(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	var stdout bytes.Buffer
+	runCompletion([]string{"bash"}, &stdout, &bytes.Buffer{})
+	script := filepath.Join(t.TempDir(), "sky.bash")
+	if err := os.WriteFile(script, stdout.Bytes(), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	if out, err := exec.Command(bash, "-n", script).CombinedOutput(); err != nil {
+		t.Fatalf("bash -n: %v\n%s", err, out)
+	}
+}
+
+func TestRunPluginList_Quiet(t *testing.T) {
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := runPluginList([]string{"--quiet"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected no output with no plugins installed, got %q", stdout.String())
+	}
+}
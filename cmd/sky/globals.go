@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+// globalOptions holds flags accepted before the command name, e.g.
+// "sky --output json lint ." or "sky --config-dir /tmp/sky plugin list".
+type globalOptions struct {
+	output    string
+	noColor   bool
+	workspace string
+	configDir string
+	debug     bool
+}
+
+// parseGlobalFlags consumes leading global flags and returns the remaining
+// arguments, starting at the command name. Parsing stops at the first
+// argument that is not a global flag so tool flags are passed through as-is.
+func parseGlobalFlags(args []string) (globalOptions, []string, error) {
+	var opts globalOptions
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			return opts, args[1:], nil
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--output", "-output":
+			if !hasValue {
+				if len(args) < 2 {
+					return opts, nil, fmt.Errorf("flag %s requires a value (json|text)", name)
+				}
+				value = args[1]
+				args = args[1:]
+			}
+			format := strings.ToLower(strings.TrimSpace(value))
+			if format != "json" && format != "text" {
+				return opts, nil, fmt.Errorf("invalid output format %q (want json or text)", value)
+			}
+			opts.output = format
+		case "--workspace", "-workspace":
+			if !hasValue {
+				if len(args) < 2 {
+					return opts, nil, fmt.Errorf("flag %s requires a directory", name)
+				}
+				value = args[1]
+				args = args[1:]
+			}
+			if value == "" {
+				return opts, nil, fmt.Errorf("flag %s requires a directory", name)
+			}
+			opts.workspace = value
+		case "--config-dir", "-config-dir":
+			if !hasValue {
+				if len(args) < 2 {
+					return opts, nil, fmt.Errorf("flag %s requires a directory", name)
+				}
+				value = args[1]
+				args = args[1:]
+			}
+			if value == "" {
+				return opts, nil, fmt.Errorf("flag %s requires a directory", name)
+			}
+			opts.configDir = value
+		case "--json", "-json":
+			opts.output = "json"
+		case "--no-color", "-no-color":
+			opts.noColor = true
+		case "--debug-plugins", "-debug-plugins":
+			opts.debug = true
+		default:
+			return opts, args, nil
+		}
+		args = args[1:]
+	}
+	return opts, args, nil
+}
+
+// apply exports the options to the process environment so that core
+// commands, embedded tools, and plugins all observe them.
+func (o globalOptions) apply() error {
+	if o.output != "" {
+		if err := os.Setenv(plugins.EnvOutputFormat, o.output); err != nil {
+			return err
+		}
+	}
+	if o.noColor {
+		if err := os.Setenv(plugins.EnvNoColor, "1"); err != nil {
+			return err
+		}
+	}
+	if o.debug {
+		if err := os.Setenv(plugins.EnvDebugPlugins, "1"); err != nil {
+			return err
+		}
+	}
+	if o.workspace != "" {
+		root, err := filepath.Abs(o.workspace)
+		if err != nil {
+			return fmt.Errorf("workspace: %w", err)
+		}
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("workspace: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("workspace %q is not a directory", o.workspace)
+		}
+		if err := os.Setenv(plugins.EnvWorkspaceRoot, root); err != nil {
+			return err
+		}
+	}
+	if o.configDir != "" {
+		// The directory is created on first write, so it need not exist yet.
+		dir, err := filepath.Abs(o.configDir)
+		if err != nil {
+			return fmt.Errorf("config dir: %w", err)
+		}
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			return fmt.Errorf("config dir %q is not a directory", o.configDir)
+		}
+		if err := os.Setenv(plugins.EnvConfigDir, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	cases := []struct {
+		args      []string
+		output    string
+		noColor   bool
+		workspace string
+		configDir string
+		debug     bool
+		rest      []string
+		ok        bool
+	}{
+		{args: []string{"lint", "--json"}, rest: []string{"lint", "--json"}, ok: true},
+		{args: []string{"--output", "json", "lint"}, output: "json", rest: []string{"lint"}, ok: true},
+		{args: []string{"--output=TEXT", "my-plugin", "a"}, output: "text", rest: []string{"my-plugin", "a"}, ok: true},
+		{args: []string{"--json", "--no-color", "fmt"}, output: "json", noColor: true, rest: []string{"fmt"}, ok: true},
+		{args: []string{"--no-color", "--", "--json"}, noColor: true, rest: []string{"--json"}, ok: true},
+		{args: []string{"--workspace", "/repo", "lint"}, workspace: "/repo", rest: []string{"lint"}, ok: true},
+		{args: []string{"--workspace=../x", "env"}, workspace: "../x", rest: []string{"env"}, ok: true},
+		{args: []string{"--config-dir", "/tmp/sky", "plugin", "list"}, configDir: "/tmp/sky", rest: []string{"plugin", "list"}, ok: true},
+		{args: []string{"--config-dir=ci", "--json", "env"}, configDir: "ci", output: "json", rest: []string{"env"}, ok: true},
+		{args: []string{"--debug-plugins", "my-plugin"}, debug: true, rest: []string{"my-plugin"}, ok: true},
+		{args: []string{"--config-dir="}, ok: false},
+		{args: []string{"--output", "yaml", "lint"}, ok: false},
+		{args: []string{"--workspace"}, ok: false},
+		{args: []string{"--output"}, ok: false},
+	}
+
+	for _, tc := range cases {
+		opts, rest, err := parseGlobalFlags(tc.args)
+		if tc.ok != (err == nil) {
+			t.Fatalf("parseGlobalFlags(%q) error = %v, want ok=%v", tc.args, err, tc.ok)
+		}
+		if !tc.ok {
+			continue
+		}
+		if opts.output != tc.output || opts.noColor != tc.noColor || opts.workspace != tc.workspace || opts.configDir != tc.configDir || opts.debug != tc.debug {
+			t.Errorf("parseGlobalFlags(%q) = %+v, want output=%q noColor=%v workspace=%q configDir=%q debug=%v", tc.args, opts, tc.output, tc.noColor, tc.workspace, tc.configDir, tc.debug)
+		}
+		if strings.Join(rest, " ") != strings.Join(tc.rest, " ") {
+			t.Errorf("parseGlobalFlags(%q) rest = %q, want %q", tc.args, rest, tc.rest)
+		}
+	}
+}
+
+func TestGlobalOptionsApply_ConfigDir(t *testing.T) {
+	t.Setenv(plugins.EnvConfigDir, "")
+	dir := filepath.Join(t.TempDir(), "sky")
+
+	if err := (globalOptions{configDir: dir}).apply(); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got := os.Getenv(plugins.EnvConfigDir); got != dir {
+		t.Fatalf("expected %s=%q, got %q", plugins.EnvConfigDir, dir, got)
+	}
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		t.Fatalf("DefaultStore: %v", err)
+	}
+	if store.Root != dir {
+		t.Fatalf("expected store root %q, got %q", dir, store.Root)
+	}
+}
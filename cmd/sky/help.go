@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+// helpCatalog is the JSON output of "sky help --json".
+type helpCatalog struct {
+	Commands []helpCommand `json:"commands"`
+	Plugins  []helpPlugin  `json:"plugins"`
+}
+
+// helpCommand describes a core or built-in command.
+type helpCommand struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // "core" or "builtin"
+	Description string `json:"description"`
+}
+
+// helpPlugin describes an installed plugin.
+type helpPlugin struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// runHelp prints the usage text, or with --json (or the global --json flag)
+// a catalog of commands and installed plugins.
+func runHelp(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("help", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { printUsage(stderr) }
+	jsonOut := fs.Bool("json", false, "print commands and installed plugins as a JSON object")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if !*jsonOut && os.Getenv(plugins.EnvOutputFormat) != "json" {
+		printUsage(stderr)
+		return 0
+	}
+
+	catalog, err := buildHelpCatalog()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	payload, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	writeln(stdout, string(payload))
+	return 0
+}
+
+// buildHelpCatalog lists the core commands, the built-in commands, and the
+// installed plugins, each sorted by name.
+func buildHelpCatalog() (helpCatalog, error) {
+	catalog := helpCatalog{Plugins: []helpPlugin{}}
+
+	for _, name := range slices.Sorted(maps.Keys(coreCommandDescriptions)) {
+		catalog.Commands = append(catalog.Commands, helpCommand{
+			Name:        name,
+			Kind:        "core",
+			Description: coreCommandDescriptions[name],
+		})
+	}
+
+	builtins := slices.Clone(managementCommands)
+	sort.Slice(builtins, func(i, j int) bool { return builtins[i].name < builtins[j].name })
+	for _, cmd := range builtins {
+		catalog.Commands = append(catalog.Commands, helpCommand{
+			Name:        cmd.name,
+			Kind:        "builtin",
+			Description: cmd.desc,
+		})
+	}
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		return helpCatalog{}, err
+	}
+	list, err := store.LoadPlugins()
+	if err != nil {
+		return helpCatalog{}, err
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	for _, plugin := range list {
+		catalog.Plugins = append(catalog.Plugins, helpPlugin{
+			Name:    plugin.Name,
+			Type:    string(plugin.EffectiveType()),
+			Version: plugin.Version,
+			Summary: plugin.Description,
+		})
+	}
+	return catalog, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+func TestRunHelp_JSON(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(plugins.EnvConfigDir, dir)
+	t.Setenv(plugins.EnvOutputFormat, "")
+
+	store := &plugins.Store{Root: dir}
+	if err := store.UpsertPlugin(plugins.Plugin{Name: "greet", Version: "0.2.0", Description: "Greets people", Path: "/bin/true"}); err != nil {
+		t.Fatalf("UpsertPlugin: %v", err)
+	}
+
+	for _, args := range [][]string{{"help", "--json"}, {"--help", "--json"}, {"--json", "help"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("run(%q): expected exit code 0, got %d (stderr %q)", args, code, stderr.String())
+		}
+
+		var catalog helpCatalog
+		if err := json.Unmarshal(stdout.Bytes(), &catalog); err != nil {
+			t.Fatalf("run(%q): invalid JSON: %v\n%s", args, err, stdout.String())
+		}
+		commands := map[string]helpCommand{}
+		for _, cmd := range catalog.Commands {
+			commands[cmd.Name] = cmd
+		}
+		if got := commands["lint"]; got.Kind != "core" || got.Description != coreCommandDescriptions["lint"] {
+			t.Errorf("run(%q): unexpected lint entry %+v", args, got)
+		}
+		if got := commands["plugin"]; got.Kind != "builtin" || got.Description == "" {
+			t.Errorf("run(%q): unexpected plugin entry %+v", args, got)
+		}
+		want := []helpPlugin{{Name: "greet", Type: "exe", Version: "0.2.0", Summary: "Greets people"}}
+		if len(catalog.Plugins) != 1 || catalog.Plugins[0] != want[0] {
+			t.Errorf("run(%q): expected plugins %+v, got %+v", args, want, catalog.Plugins)
+		}
+	}
+}
+
+func TestRunHelp_Text(t *testing.T) {
+	t.Setenv(plugins.EnvOutputFormat, "")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"help"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected empty stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "usage: sky [global flags] <command> [args]") {
+		t.Fatalf("expected usage on stderr, got %q", stderr.String())
+	}
+}
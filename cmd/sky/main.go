@@ -7,14 +7,17 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/albertocavalcante/sky/internal/editdistance"
 	"github.com/albertocavalcante/sky/internal/plugins"
 	"github.com/albertocavalcante/sky/internal/version"
 )
@@ -32,25 +35,47 @@ var coreCommands = map[string]string{
 	"ls":    "skyls",
 }
 
+// builtinCommands are handled by sky itself, before core aliases and plugins.
+var builtinCommands = []string{"verify", "version", "plugin", "env", "tools", "completion", "help"}
+
 func main() {
 	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
 
 func run(args []string, stdout, stderr io.Writer) int {
-	if len(args) == 0 || isHelp(args[0]) {
+	opts, args, err := parseGlobalFlags(args)
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 2
+	}
+	if err := opts.apply(); err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	if len(args) == 0 {
 		printUsage(stderr)
 		return 0
 	}
+	if isHelp(args[0]) {
+		return runHelp(args[1:], stdout, stderr)
+	}
 
 	switch args[0] {
+	case "verify":
+		return runVerify(args[1:], stdout, stderr)
 	case "version":
-		writef(stdout, "sky %s\n", version.String())
-		return 0
+		return runVersion(args[1:], stdout, stderr)
 	case "plugin":
 		return runPlugin(args[1:], stdout, stderr)
+	case "env":
+		return runEnv(args[1:], stdout, stderr)
+	case "tools":
+		return runTools(args[1:], stdout, stderr)
+	case "completion":
+		return runCompletion(args[1:], stdout, stderr)
 	case "help":
-		printUsage(stderr)
-		return 0
+		return runHelp(args[1:], stdout, stderr)
 	default:
 		// Check for core command aliases (fmt, lint, check, etc.)
 		if _, ok := coreCommands[args[0]]; ok {
@@ -58,13 +83,24 @@ func run(args []string, stdout, stderr io.Writer) int {
 		}
 		// Check if it's an embedded tool by full name (skylint, skyfmt, etc.)
 		if tool := getEmbeddedTool(args[0]); tool != nil {
+			exportWorkspaceRoot()
 			return tool(context.Background(), args[1:], os.Stdin, stdout, stderr)
 		}
 		return runInstalledPlugin(args, stdout, stderr)
 	}
 }
 
-// runCoreCommand runs a core command.
+// exportWorkspaceRoot sets SKY_WORKSPACE_ROOT, if it is not already set, to
+// the detected workspace root, so that embedded tools see the same
+// environment as the external binaries and plugins that sky runs.
+func exportWorkspaceRoot() {
+	if os.Getenv(plugins.EnvWorkspaceRoot) == "" {
+		_ = os.Setenv(plugins.EnvWorkspaceRoot, plugins.FindWorkspaceRoot())
+	}
+}
+
+// runCoreCommand runs a core command; "sky tools" reports which of these
+// sources each command resolves to.
 // Resolution order:
 // 1. Embedded tools (if built with -tags=sky_full)
 // 2. External binary in same directory as sky executable
@@ -73,6 +109,7 @@ func run(args []string, stdout, stderr io.Writer) int {
 func runCoreCommand(name string, args []string, stdout, stderr io.Writer) int {
 	// First, check for embedded tool
 	if tool := getEmbeddedTool(name); tool != nil {
+		exportWorkspaceRoot()
 		return tool(context.Background(), args, os.Stdin, stdout, stderr)
 	}
 
@@ -89,6 +126,7 @@ func runCoreCommand(name string, args []string, stdout, stderr io.Writer) int {
 	}
 
 	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), plugins.EnvWorkspaceRoot+"="+plugins.FindWorkspaceRoot())
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 	cmd.Stdin = os.Stdin
@@ -121,6 +159,67 @@ func findCoreBinary(name string) (string, error) {
 	return exec.LookPath(name)
 }
 
+// runVersion prints the sky build identity, as JSON with --json (or the
+// global --json flag).
+func runVersion(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOut := fs.Bool("json", false, "print build metadata as a JSON object")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		writeln(stderr, "usage: sky version [--json]")
+		return 2
+	}
+	version.Write(stdout, "sky", *jsonOut)
+	return 0
+}
+
+// runEnv prints the SKY_* environment a plugin would receive when run from
+// the current directory.
+func runEnv(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("env", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOut := fs.Bool("json", false, "print the environment as a JSON object")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		writeln(stderr, "usage: sky env [--json] [plugin]")
+		return 2
+	}
+
+	name := fs.Arg(0)
+	env := plugins.PluginEnv(name, plugins.ModeExec)
+	if name == "" {
+		// No plugin selected: omit the per-plugin name rather than printing an empty value.
+		env = slices.DeleteFunc(env, func(kv string) bool {
+			return strings.HasPrefix(kv, plugins.EnvPluginName+"=")
+		})
+	}
+
+	if *jsonOut {
+		values := make(map[string]string, len(env))
+		for _, kv := range env {
+			key, value, _ := strings.Cut(kv, "=")
+			values[key] = value
+		}
+		payload, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return 1
+		}
+		writeln(stdout, string(payload))
+		return 0
+	}
+
+	for _, kv := range env {
+		writeln(stdout, kv)
+	}
+	return 0
+}
+
 func runPlugin(args []string, stdout, stderr io.Writer) int {
 	if len(args) == 0 || isHelp(args[0]) {
 		printPluginUsage(stderr)
@@ -129,7 +228,7 @@ func runPlugin(args []string, stdout, stderr io.Writer) int {
 
 	switch args[0] {
 	case "list":
-		return runPluginList(stdout, stderr)
+		return runPluginList(args[1:], stdout, stderr)
 	case "install":
 		return runPluginInstall(args[1:], stdout, stderr)
 	case "remove":
@@ -142,6 +241,16 @@ func runPlugin(args []string, stdout, stderr io.Writer) int {
 		return runMarketplace(args[1:], stdout, stderr)
 	case "init":
 		return runPluginInit(args[1:], stdout, stderr)
+	case "verify":
+		return runPluginVerify(args[1:], stdout, stderr)
+	case "export":
+		return runPluginExport(args[1:], stdout, stderr)
+	case "import":
+		return runPluginImport(args[1:], stdout, stderr)
+	case "lock":
+		return runPluginLock(args[1:], stdout, stderr)
+	case "doctor":
+		return runPluginDoctor(args[1:], stdout, stderr)
 	default:
 		writef(stderr, "unknown plugin command %q\n", args[0])
 		printPluginUsage(stderr)
@@ -149,7 +258,19 @@ func runPlugin(args []string, stdout, stderr io.Writer) int {
 	}
 }
 
-func runPluginList(stdout, stderr io.Writer) int {
+func runPluginList(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	quiet := fs.Bool("quiet", false, "print only plugin names, one per line")
+	fs.BoolVar(quiet, "q", false, "shorthand for --quiet")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		writeln(stderr, "usage: sky plugin list [--quiet]")
+		return 2
+	}
+
 	store, err := plugins.DefaultStore()
 	if err != nil {
 		writef(stderr, "sky: %v\n", err)
@@ -162,7 +283,9 @@ func runPluginList(stdout, stderr io.Writer) int {
 		return 1
 	}
 	if len(list) == 0 {
-		writeln(stdout, "no plugins installed")
+		if !*quiet {
+			writeln(stdout, "no plugins installed")
+		}
 		return 0
 	}
 
@@ -170,10 +293,17 @@ func runPluginList(stdout, stderr io.Writer) int {
 		return list[i].Name < list[j].Name
 	})
 
+	if *quiet {
+		for _, plugin := range list {
+			writeln(stdout, plugin.Name)
+		}
+		return 0
+	}
+
 	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
-	writeln(writer, "NAME\tTYPE\tVERSION\tSOURCE\tDESCRIPTION")
+	writeln(writer, "NAME\tTYPE\tVERSION\tSOURCE\tCAPABILITIES\tDESCRIPTION")
 	for _, plugin := range list {
-		writef(writer, "%s\t%s\t%s\t%s\t%s\n", plugin.Name, plugin.EffectiveType(), plugin.Version, plugin.Source, plugin.Description)
+		writef(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", plugin.Name, plugin.EffectiveType(), plugin.Version, plugin.Source, formatCapabilities(plugin.Capabilities), plugin.Description)
 	}
 	_ = writer.Flush()
 	return 0
@@ -184,35 +314,87 @@ func runPluginInstall(args []string, stdout, stderr io.Writer) int {
 	fs.SetOutput(stderr)
 	path := fs.String("path", "", "path to local plugin binary")
 	url := fs.String("url", "", "URL to download plugin binary")
+	gitFlag := fs.String("git", "", "git repository to build the plugin from, as URL[@REF]")
 	marketplace := fs.String("marketplace", "", "marketplace name (optional)")
 	versionFlag := fs.String("version", "", "plugin version metadata")
-	sha := fs.String("sha256", "", "expected sha256 for --url downloads")
-	typeFlag := fs.String("type", "", "plugin type (exe|wasm)")
+	sha := fs.String("sha256", "", "expected sha256 for --url downloads and --git builds")
+	typeFlag := fs.String("type", "", "plugin type (exe|wasm|script)")
+	cacheTTL := fs.Duration("cache-ttl", plugins.DefaultIndexTTL, "how long cached marketplace indices stay fresh")
+	refresh := fs.Bool("refresh", false, "fetch marketplace indices even when cached")
+	retries := fs.Int("retries", plugins.DefaultRetries, "how many times to retry failed downloads and marketplace fetches (0 for none)")
+	dryRun := fs.Bool("dry-run", false, "fetch the plugin and print its metadata without installing it")
+	force := fs.Bool("force", false, "install even if a core command shadows the plugin name")
+	frozen := fs.Bool("frozen", false, "refuse to install a binary whose sha256 is not recorded in the lockfile")
+	lockfile := fs.String("lockfile", "", "lockfile for --frozen (default: "+plugins.LockfileName+" at the workspace root)")
+	fromManifest := fs.String("from-manifest", "", "install every plugin listed in a manifest file (- for stdin)")
+	failFast := fs.Bool("fail-fast", false, "with --from-manifest, stop at the first plugin that fails to install")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 
+	if *fromManifest != "" {
+		return installFromManifest(fs, *fromManifest, *failFast, *cacheTTL, *refresh, *retries, stdout, stderr)
+	}
+	if *failFast {
+		writeln(stderr, "sky: --fail-fast requires --from-manifest")
+		return 2
+	}
 	if fs.NArg() != 1 {
-		writeln(stderr, "usage: sky plugin install <name> [--path PATH | --url URL] [--marketplace NAME] [--type exe|wasm]")
+		writeln(stderr, "usage: sky plugin install <name> [--path PATH | --url URL | --git URL[@REF]] [--marketplace NAME] [--type exe|wasm|script] [--cache-ttl DURATION] [--refresh] [--retries N] [--dry-run] [--force] [--frozen [--lockfile PATH]]")
+		writeln(stderr, "       sky plugin install --from-manifest FILE [--fail-fast] [--cache-ttl DURATION] [--refresh] [--retries N]")
 		return 2
 	}
 	name := fs.Arg(0)
 
-	if *path != "" && *url != "" {
-		writeln(stderr, "sky: only one of --path or --url is allowed")
+	sources := 0
+	for _, source := range []string{*path, *url, *gitFlag} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		writeln(stderr, "sky: only one of --path, --url, or --git is allowed")
 		return 2
 	}
-	if *typeFlag != "" && *path == "" && *url == "" {
-		writeln(stderr, "sky: --type requires --path or --url")
+	if *typeFlag != "" && sources == 0 {
+		writeln(stderr, "sky: --type requires --path, --url, or --git")
+		return 2
+	}
+	var gitSource plugins.GitSource
+	if *gitFlag != "" {
+		var err error
+		if gitSource, err = plugins.ParseGitSource(*gitFlag); err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return 2
+		}
+	}
+	if *lockfile != "" && !*frozen {
+		writeln(stderr, "sky: --lockfile requires --frozen")
+		return 2
+	}
+	if *retries < 0 {
+		writef(stderr, "sky: --retries must be non-negative, got %d\n", *retries)
 		return 2
 	}
 
+	if reason := commandShadowing(name); reason != "" {
+		if !*force && !*dryRun {
+			writef(stderr, "sky: cannot install plugin %q: %s, so \"sky %s\" would never reach the plugin\n", name, reason, name)
+			writeln(stderr, "sky: choose another name, or use --force to install anyway")
+			return 1
+		}
+		writef(stderr, "sky: warning: %s; \"sky %s\" will not run this plugin\n", reason, name)
+	}
+
 	store, err := plugins.DefaultStore()
 	if err != nil {
 		writef(stderr, "sky: %v\n", err)
 		return 1
 	}
 
+	configureIndexCache(store, *cacheTTL, *refresh, stderr)
+	configureRetries(store, *retries)
+
 	pluginType, err := plugins.ParsePluginType(*typeFlag)
 	if err != nil {
 		writef(stderr, "sky: %v\n", err)
@@ -229,23 +411,127 @@ func runPluginInstall(args []string, stdout, stderr io.Writer) int {
 	}
 
 	ctx := context.Background()
+
+	if *frozen {
+		digest, err := frozenDigest(lockfilePath(*lockfile), name, *sha)
+		if err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return 1
+		}
+		*sha = digest
+	}
+
+	if *dryRun {
+		return dryRunPluginInstall(ctx, store, name, *path, *url, gitSource, *sha, *versionFlag, *marketplace, pluginType, stdout, stderr)
+	}
+
 	var plugin plugins.Plugin
-	if *path != "" {
+	if *path != "" && !*frozen {
 		plugin, err = store.InstallFromPath(name, *path, *versionFlag, pluginType)
+	} else if *path != "" {
+		// InstallFromURL reads local paths too, and checks the digest.
+		plugin, err = store.InstallFromURL(ctx, name, *path, *sha, *versionFlag, "", pluginType)
 	} else if *url != "" {
 		plugin, err = store.InstallFromURL(ctx, name, *url, *sha, *versionFlag, "", pluginType)
+	} else if *gitFlag != "" {
+		plugin, err = store.InstallFromGit(ctx, name, gitSource, *sha, *versionFlag, pluginType)
 	} else {
-		plugin, err = store.InstallFromMarketplace(ctx, name, *marketplace)
+		plugin, err = store.InstallFromMarketplacePinned(ctx, name, *marketplace, *sha)
 	}
 	if err != nil {
 		writef(stderr, "sky: %v\n", err)
 		return 1
 	}
+	recordCapabilities(ctx, store, plugin, stderr)
 
 	writef(stdout, "installed %s (%s)\n", plugin.Name, plugin.Version)
 	return 0
 }
 
+// recordCapabilities runs the metadata handshake of a newly installed plugin
+// and records the capabilities it declares, which decide how it is run (for
+// example as a daemon). A plugin whose metadata cannot be read stays
+// installed, without capabilities, and a warning is printed.
+func recordCapabilities(ctx context.Context, store *plugins.Store, plugin plugins.Plugin, stderr io.Writer) {
+	metadata, err := newPluginRunner(stderr).Metadata(ctx, plugin)
+	if err != nil {
+		writef(stderr, "sky: warning: %v; capabilities not recorded\n", err)
+		return
+	}
+	if len(metadata.Capabilities) == 0 {
+		return
+	}
+	plugin.Capabilities = metadata.Capabilities
+	if err := store.UpsertPlugin(plugin); err != nil {
+		writef(stderr, "sky: warning: %v; capabilities not recorded\n", err)
+	}
+}
+
+// dryRunPluginInstall fetches a plugin into a throwaway store, runs the
+// metadata handshake, and prints the result. The real store is only read
+// (to resolve marketplaces); nothing is recorded in plugins.json.
+func dryRunPluginInstall(ctx context.Context, store *plugins.Store, name, path, url string, gitSource plugins.GitSource, sha, version, marketplace string, pluginType plugins.PluginType, stdout, stderr io.Writer) int {
+	stagingDir, err := os.MkdirTemp("", "sky-plugin-dry-run-")
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	defer func() { _ = os.RemoveAll(stagingDir) }()
+	staging := plugins.NewStore(stagingDir)
+	staging.Retries = store.Retries
+	staging.Warn = store.Warn
+
+	var plugin plugins.Plugin
+	switch {
+	case path != "" && sha != "":
+		plugin, err = staging.InstallFromURL(ctx, name, path, sha, version, "", pluginType)
+	case path != "":
+		plugin, err = staging.InstallFromPath(name, path, version, pluginType)
+	case url != "":
+		plugin, err = staging.InstallFromURL(ctx, name, url, sha, version, "", pluginType)
+	case gitSource.URL != "":
+		plugin, err = staging.InstallFromGit(ctx, name, gitSource, sha, version, pluginType)
+	default:
+		var entry plugins.MarketplacePlugin
+		_, entry, err = store.ResolveMarketplacePlugin(ctx, name, marketplace)
+		if err == nil {
+			entryType := entry.Type
+			if entryType == "" {
+				entryType = plugins.TypeExecutable
+			}
+			if sha == "" {
+				sha = entry.SHA256
+			}
+			plugin, err = staging.InstallFromURL(ctx, name, entry.URL, sha, entry.Version, entry.Description, entryType)
+		}
+	}
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	runner := newPluginRunner(stderr)
+	metadata, err := runner.Metadata(ctx, plugin)
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	payload, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	writeln(stdout, string(payload))
+
+	if metadata.Name != "" && metadata.Name != name {
+		writef(stderr, "sky: warning: plugin declares name %q but would be installed as %q\n", metadata.Name, name)
+	}
+	warnShadowedCommands(stderr, name, metadata.Commands)
+	writef(stderr, "dry run: %s (sha256 %s) was not installed\n", name, plugin.SHA256)
+	return 0
+}
+
 func runPluginRemove(args []string, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("remove", flag.ContinueOnError)
 	fs.SetOutput(stderr)
@@ -275,11 +561,12 @@ func runPluginRemove(args []string, stdout, stderr io.Writer) int {
 func runPluginInspect(args []string, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
 	fs.SetOutput(stderr)
+	usage := fs.Bool("usage", false, "print the plugin's commands and flags as text instead of JSON")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 	if fs.NArg() != 1 {
-		writeln(stderr, "usage: sky plugin inspect <name>")
+		writeln(stderr, "usage: sky plugin inspect [--usage] <name>")
 		return 2
 	}
 
@@ -299,7 +586,7 @@ func runPluginInspect(args []string, stdout, stderr io.Writer) int {
 		return 1
 	}
 
-	runner := plugins.Runner{}
+	runner := newPluginRunner(stderr)
 	metadata, err := runner.Metadata(context.Background(), *plugin)
 	if err != nil {
 		writef(stderr, "sky: %v\n", err)
@@ -312,12 +599,19 @@ func runPluginInspect(args []string, stdout, stderr io.Writer) int {
 	if metadata.Summary != "" {
 		plugin.Description = metadata.Summary
 	}
+	plugin.Capabilities = metadata.Capabilities
 	plugin.Type = plugin.EffectiveType()
+	warnShadowedCommands(stderr, plugin.Name, metadata.Commands)
 	if err := store.UpsertPlugin(*plugin); err != nil {
 		writef(stderr, "sky: %v\n", err)
 		return 1
 	}
 
+	if *usage {
+		writePluginUsage(stdout, metadata)
+		return 0
+	}
+
 	payload, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
 		writef(stderr, "sky: %v\n", err)
@@ -327,15 +621,171 @@ func runPluginInspect(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
+// writePluginUsage prints a plugin's commands and their flags, as declared
+// in its metadata.
+func writePluginUsage(w io.Writer, metadata plugins.Metadata) {
+	header := metadata.Name
+	if metadata.Version != "" {
+		header += " " + metadata.Version
+	}
+	if metadata.Summary != "" {
+		header += " - " + metadata.Summary
+	}
+	writeln(w, header)
+
+	if len(metadata.Commands) == 0 {
+		writeln(w)
+		writeln(w, "no commands declared")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range metadata.Commands {
+		writeln(tw)
+		writef(tw, "sky %s\t%s\n", cmd.Name, cmd.Summary)
+		for _, f := range cmd.Flags {
+			name := "  --" + f.Name
+			if f.Type != "" && f.Type != "bool" {
+				name += " " + f.Type
+			}
+			desc := f.Description
+			if f.Default != "" && f.Default != "false" {
+				desc += fmt.Sprintf(" (default %q)", f.Default)
+			}
+			writef(tw, "%s\t%s\n", name, strings.TrimSpace(desc))
+		}
+	}
+	_ = tw.Flush()
+}
+
+// commandShadowing explains why "sky <name>" would not reach a plugin
+// called name, or returns "" if nothing takes precedence over it. See run
+// for the resolution order: built-in commands, then core aliases, then
+// embedded tools, then plugins.
+func commandShadowing(name string) string {
+	if slices.Contains(builtinCommands, name) {
+		return fmt.Sprintf("%q is a built-in sky command", name)
+	}
+	if binary, ok := coreCommands[name]; ok {
+		return fmt.Sprintf("%q is the core command for %s", name, binary)
+	}
+	// Full tool names are embedded in sky_full builds; treat them as
+	// reserved in every build so installs behave the same everywhere.
+	if getEmbeddedTool(name) != nil || slices.Contains(slices.Collect(maps.Values(coreCommands)), name) {
+		return fmt.Sprintf("%q is a core tool", name)
+	}
+	return ""
+}
+
+// warnShadowedCommands warns about declared plugin commands that a core
+// command would intercept. The plugin name itself is checked at install.
+func warnShadowedCommands(w io.Writer, name string, commands []plugins.CommandMetadata) {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			continue
+		}
+		if reason := commandShadowing(cmd.Name); reason != "" {
+			writef(w, "sky: warning: plugin %s declares command %q, but %s\n", name, cmd.Name, reason)
+		}
+	}
+}
+
+// formatCapabilities renders declared capabilities for table output.
+// "-" means the plugin has not been inspected or declared none.
+func formatCapabilities(capabilities []string) string {
+	if len(capabilities) == 0 {
+		return "-"
+	}
+	return strings.Join(capabilities, ",")
+}
+
+func runPluginVerify(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	all := fs.Bool("all", false, "verify all installed plugins")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if (*all && fs.NArg() != 0) || (!*all && fs.NArg() != 1) {
+		writeln(stderr, "usage: sky plugin verify <name> | --all")
+		return 2
+	}
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	var results []plugins.VerifyResult
+	if *all {
+		results, err = store.VerifyPlugins()
+	} else {
+		var plugin *plugins.Plugin
+		plugin, err = store.FindPlugin(fs.Arg(0))
+		if err == nil && plugin == nil {
+			err = fmt.Errorf("plugin %q not installed", fs.Arg(0))
+		}
+		if err == nil {
+			var result plugins.VerifyResult
+			result, err = store.VerifyPlugin(*plugin)
+			results = append(results, result)
+		}
+	}
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	if len(results) == 0 {
+		writeln(stdout, "no plugins installed")
+		return 0
+	}
+
+	failed := 0
+	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	writeln(writer, "NAME\tSTATUS\tSHA256")
+	for _, result := range results {
+		digest := result.Actual
+		switch result.Status {
+		case plugins.VerifyMismatch:
+			failed++
+			digest = fmt.Sprintf("%s (expected %s)", result.Actual, result.Expected)
+		case plugins.VerifyMissing:
+			failed++
+			digest = result.Expected
+		}
+		writef(writer, "%s\t%s\t%s\n", result.Plugin.Name, result.Status, digest)
+	}
+	_ = writer.Flush()
+
+	if failed > 0 {
+		writef(stderr, "sky: %d plugin(s) failed verification\n", failed)
+		return 1
+	}
+	return 0
+}
+
 func runPluginSearch(args []string, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("search", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	marketplace := fs.String("marketplace", "", "marketplace name (optional)")
+	cacheTTL := fs.Duration("cache-ttl", plugins.DefaultIndexTTL, "how long cached marketplace indices stay fresh")
+	refresh := fs.Bool("refresh", false, "fetch marketplace indices even when cached")
+	retries := fs.Int("retries", plugins.DefaultRetries, "how many times to retry failed downloads and marketplace fetches (0 for none)")
+	limit := fs.Int("limit", 20, "maximum number of results to show (0 for all)")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 	if fs.NArg() != 1 {
-		writeln(stderr, "usage: sky plugin search <query> [--marketplace NAME]")
+		writeln(stderr, "usage: sky plugin search <query> [--marketplace NAME] [--limit N] [--cache-ttl DURATION] [--refresh] [--retries N]")
+		return 2
+	}
+	if *limit < 0 {
+		writef(stderr, "sky: --limit must be non-negative, got %d\n", *limit)
+		return 2
+	}
+	if *retries < 0 {
+		writef(stderr, "sky: --retries must be non-negative, got %d\n", *retries)
 		return 2
 	}
 
@@ -344,6 +794,8 @@ func runPluginSearch(args []string, stdout, stderr io.Writer) int {
 		writef(stderr, "sky: %v\n", err)
 		return 1
 	}
+	configureIndexCache(store, *cacheTTL, *refresh, stderr)
+	configureRetries(store, *retries)
 
 	results, err := store.SearchMarketplaces(context.Background(), fs.Arg(0), *marketplace)
 	if err != nil {
@@ -351,15 +803,45 @@ func runPluginSearch(args []string, stdout, stderr io.Writer) int {
 		return 1
 	}
 
+	total := len(results)
+	if *limit > 0 && total > *limit {
+		results = results[:*limit]
+	}
+
 	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
 	writeln(writer, "NAME\tVERSION\tMARKETPLACE\tDESCRIPTION\tURL")
 	for _, result := range results {
 		writef(writer, "%s\t%s\t%s\t%s\t%s\n", result.Plugin.Name, result.Plugin.Version, result.Marketplace.Name, result.Plugin.Description, result.Plugin.URL)
 	}
 	_ = writer.Flush()
+	if len(results) < total {
+		writef(stderr, "showing %d of %d results (use --limit 0 to show all)\n", len(results), total)
+	}
 	return 0
 }
 
+// configureIndexCache applies marketplace index cache flags to a store.
+// A zero TTL disables caching.
+func configureIndexCache(store *plugins.Store, ttl time.Duration, refresh bool, stderr io.Writer) {
+	if ttl == 0 {
+		ttl = -1
+	}
+	store.IndexTTL = ttl
+	store.RefreshIndex = refresh
+	store.Warn = func(msg string) {
+		writef(stderr, "sky: warning: %s\n", msg)
+	}
+}
+
+// configureRetries applies the --retries flag to a store. Zero disables
+// retries.
+func configureRetries(store *plugins.Store, retries int) {
+	if retries == 0 {
+		retries = -1
+	}
+	store.Retries = retries
+}
+
 func runMarketplace(args []string, stdout, stderr io.Writer) int {
 	if len(args) == 0 || isHelp(args[0]) {
 		printMarketplaceUsage(stderr)
@@ -373,6 +855,8 @@ func runMarketplace(args []string, stdout, stderr io.Writer) int {
 		return runMarketplaceAdd(args[1:], stdout, stderr)
 	case "remove":
 		return runMarketplaceRemove(args[1:], stdout, stderr)
+	case "validate":
+		return runMarketplaceValidate(args[1:], stdout, stderr)
 	default:
 		writef(stderr, "unknown marketplace command %q\n", args[0])
 		printMarketplaceUsage(stderr)
@@ -467,6 +951,58 @@ func runMarketplaceRemove(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
+func runMarketplaceValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("marketplace validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		writeln(stderr, "usage: sky plugin marketplace validate <name|url>")
+		return 2
+	}
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	marketplace, err := store.ResolveMarketplace(fs.Arg(0))
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	configureIndexCache(store, plugins.DefaultIndexTTL, true, stderr)
+
+	index, err := store.FetchMarketplaceIndex(context.Background(), marketplace)
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	problems := plugins.ValidateMarketplaceIndex(index)
+	for _, problem := range problems {
+		writeln(stdout, problem.String())
+	}
+	if len(problems) > 0 {
+		writef(stderr, "sky: marketplace %s: %d problem(s) in %d plugin(s)\n", marketplace.Name, len(problems), len(index.Plugins))
+		return 1
+	}
+	writef(stdout, "marketplace %s is valid (%d plugins)\n", marketplace.Name, len(index.Plugins))
+	return 0
+}
+
+// newPluginRunner returns a plugin runner that traces invocations to stderr
+// when SKY_DEBUG_PLUGINS is set.
+func newPluginRunner(stderr io.Writer) plugins.Runner {
+	var runner plugins.Runner
+	if plugins.DebugEnabled() {
+		runner.Trace = stderr
+	}
+	return runner
+}
+
 func runInstalledPlugin(args []string, stdout, stderr io.Writer) int {
 	store, err := plugins.DefaultStore()
 	if err != nil {
@@ -483,7 +1019,9 @@ func runInstalledPlugin(args []string, stdout, stderr io.Writer) int {
 		printUnknownCommandHelp(stderr, args[0])
 		return 2
 	}
-	runner := plugins.Runner{}
+	// A daemon would not outlive this command, so even plugins that declare
+	// the daemon capability run one-shot.
+	runner := newPluginRunner(stderr)
 	exitCode, err := runner.Run(context.Background(), *plugin, args[1:], os.Stdin, stdout, stderr)
 	if err != nil {
 		writef(stderr, "sky: %v\n", err)
@@ -543,7 +1081,7 @@ func findSimilarCommands(input string) []commandSuggestion {
 			continue
 		}
 		// Check for Levenshtein distance <= 2
-		if levenshtein(input, cmd) <= 2 {
+		if editdistance.Levenshtein(input, cmd) <= 2 {
 			suggestions = append(suggestions, commandSuggestion{
 				name: cmd,
 				desc: coreCommandDescriptions[cmd],
@@ -559,45 +1097,6 @@ func findSimilarCommands(input string) []commandSuggestion {
 	return suggestions
 }
 
-// levenshtein computes the Levenshtein distance between two strings.
-func levenshtein(a, b string) int {
-	if len(a) == 0 {
-		return len(b)
-	}
-	if len(b) == 0 {
-		return len(a)
-	}
-
-	if len(a) > len(b) {
-		a, b = b, a
-	}
-
-	prev := make([]int, len(a)+1)
-	curr := make([]int, len(a)+1)
-
-	for i := range prev {
-		prev[i] = i
-	}
-
-	for j := 1; j <= len(b); j++ {
-		curr[0] = j
-		for i := 1; i <= len(a); i++ {
-			cost := 1
-			if a[i-1] == b[j-1] {
-				cost = 0
-			}
-			curr[i] = min(
-				prev[i]+1,      // deletion
-				curr[i-1]+1,    // insertion
-				prev[i-1]+cost, // substitution
-			)
-		}
-		prev, curr = curr, prev
-	}
-
-	return prev[len(a)]
-}
-
 func isHelp(arg string) bool {
 	return arg == "-h" || arg == "--help"
 }
@@ -617,7 +1116,15 @@ func writeln(w io.Writer, args ...any) {
 }
 
 func printUsage(w io.Writer) {
-	writeln(w, "usage: sky <command> [args]")
+	writeln(w, "usage: sky [global flags] <command> [args]")
+	writeln(w)
+	writeln(w, "global flags:")
+	writeln(w, "  --output json|text   set SKY_OUTPUT_FORMAT for tools and plugins")
+	writeln(w, "  --json               shorthand for --output json")
+	writeln(w, "  --no-color           set SKY_NO_COLOR for tools and plugins")
+	writeln(w, "  --workspace DIR      set SKY_WORKSPACE_ROOT instead of detecting it")
+	writeln(w, "  --config-dir DIR     set SKY_CONFIG_DIR (plugin store, marketplaces, caches)")
+	writeln(w, "  --debug-plugins      trace plugin invocations to stderr (or SKY_DEBUG_PLUGINS=1)")
 	writeln(w)
 	writeln(w, "starlark tools:")
 	writeln(w, "  fmt          format Starlark files")
@@ -628,10 +1135,15 @@ func printUsage(w io.Writer) {
 	writeln(w, "  doc          generate documentation")
 	writeln(w, "  repl         interactive Starlark REPL")
 	writeln(w, "  ls           language server (LSP)")
+	writeln(w, "  verify       run fmt --check, lint, and check together")
 	writeln(w)
 	writeln(w, "management:")
 	writeln(w, "  plugin       manage plugins")
-	writeln(w, "  version      show version")
+	writeln(w, "  env          print the SKY_* environment passed to plugins")
+	writeln(w, "  tools        show whether each tool is embedded, a binary, or a plugin")
+	writeln(w, "  version      show version (--json for build metadata)")
+	writeln(w, "  completion   generate shell completions (bash, zsh, fish)")
+	writeln(w, "  help         show this help (--json for a command catalog)")
 	writeln(w)
 	writeln(w, "plugin-first:")
 	writeln(w, "  unknown commands are resolved to installed plugins")
@@ -643,16 +1155,22 @@ func runPluginInit(args []string, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("init", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	wasm := fs.Bool("wasm", false, "create a WASM plugin template")
+	sdk := fs.Bool("sdk", false, "create a native plugin template using the skyplugin SDK")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 	if fs.NArg() != 1 {
-		writeln(stderr, "usage: sky plugin init <name> [--wasm]")
+		writeln(stderr, "usage: sky plugin init <name> [--wasm | --sdk]")
 		writeln(stderr)
 		writeln(stderr, "Creates a new plugin project with boilerplate code.")
 		return 2
 	}
 
+	if *wasm && *sdk {
+		writeln(stderr, "sky: --wasm and --sdk cannot be combined")
+		return 2
+	}
+
 	name := fs.Arg(0)
 
 	// Validate plugin name
@@ -675,7 +1193,7 @@ func runPluginInit(args []string, stdout, stderr io.Writer) int {
 	}
 
 	// Write files
-	if err := writePluginTemplate(name, *wasm); err != nil {
+	if err := writePluginTemplate(name, *wasm, *sdk); err != nil {
 		writef(stderr, "sky: failed to create plugin files: %v\n", err)
 		// Clean up on failure
 		_ = os.RemoveAll(name)
@@ -689,6 +1207,10 @@ func runPluginInit(args []string, stdout, stderr io.Writer) int {
 		writeln(stdout, "  GOOS=wasip1 GOARCH=wasm go build -o plugin.wasm")
 		writef(stdout, "  sky plugin install --path ./plugin.wasm %s\n", name)
 	} else {
+		if *sdk {
+			writeln(stdout, "  go mod tidy")
+			writeln(stdout, "  go test ./...")
+		}
 		writeln(stdout, "  go build -o plugin")
 		writef(stdout, "  sky plugin install --path ./plugin %s\n", name)
 	}
@@ -697,9 +1219,13 @@ func runPluginInit(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
-func writePluginTemplate(name string, wasm bool) error {
+func writePluginTemplate(name string, wasm, sdk bool) error {
 	var mainGo string
 
+	if sdk {
+		return writeSDKPluginTemplate(name)
+	}
+
 	if wasm {
 		// WASM-specific template
 		mainGo = fmt.Sprintf(`//go:build wasip1
@@ -970,16 +1496,171 @@ These are set by Sky when running your plugin:
 	return nil
 }
 
+// writeSDKPluginTemplate scaffolds a native plugin built on pkg/skyplugin:
+// Serve handles the metadata handshake and exit codes, and main_test.go
+// drives the plugin in-process with pkg/skyplugin/testing.
+func writeSDKPluginTemplate(name string) error {
+	mainGo := fmt.Sprintf(`package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+const (
+	pluginName    = %q
+	pluginVersion = "0.1.0"
+	pluginSummary = "A Sky plugin"
+)
+
+// plugin describes the plugin to Sky. skyplugin.Serve answers the metadata
+// handshake and calls Run with the command-line arguments.
+var plugin = skyplugin.Plugin{
+	Metadata: skyplugin.Metadata{
+		APIVersion: 1,
+		Name:       pluginName,
+		Version:    pluginVersion,
+		Summary:    pluginSummary,
+		Commands: []skyplugin.CommandMetadata{
+			{Name: pluginName, Summary: pluginSummary},
+		},
+	},
+	Run: run,
+}
+
+func main() {
+	skyplugin.Serve(plugin)
+}
+
+// run is the plugin entry point. Return a *skyplugin.ExitError to choose
+// the exit code; other errors exit with 1.
+func run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet(pluginName, flag.ContinueOnError)
+	showVersion := fs.Bool("version", false, "show version")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return &skyplugin.ExitError{Code: skyplugin.ExitUsage}
+	}
+
+	if *showVersion {
+		fmt.Printf("%%s %%s\n", pluginName, pluginVersion)
+		return nil
+	}
+
+	// Your plugin logic here
+	fmt.Println("Hello from", pluginName)
+
+	// Access workspace info via the SDK's environment helpers
+	fmt.Println("Workspace:", skyplugin.WorkspaceRoot())
+
+	return nil
+}
+`, name)
+
+	mainTestGo := `package main
+
+import (
+	"strings"
+	"testing"
+
+	skytesting "github.com/albertocavalcante/sky/pkg/skyplugin/testing"
+)
+
+func TestMetadata(t *testing.T) {
+	m, err := skytesting.RunMetadata(plugin)
+	if err != nil {
+		t.Fatalf("metadata: %v", err)
+	}
+	if m.Name != pluginName || m.Version != pluginVersion {
+		t.Fatalf("unexpected metadata: %+v", m)
+	}
+}
+
+func TestRun(t *testing.T) {
+	result := skytesting.RunPlugin(plugin, nil, "")
+	if result.ExitCode != 0 {
+		t.Fatalf("exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+	if !strings.Contains(result.Stdout, "Hello from "+pluginName) {
+		t.Fatalf("unexpected output: %q", result.Stdout)
+	}
+}
+
+func TestRun_BadFlag(t *testing.T) {
+	result := skytesting.RunPlugin(plugin, []string{"--no-such-flag"}, "")
+	if result.ExitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", result.ExitCode)
+	}
+}
+`
+
+	goMod := fmt.Sprintf(`module %s
+
+go 1.21
+`, name)
+
+	readme := fmt.Sprintf(`# %s
+
+A Sky plugin built with the `+"`skyplugin`"+` SDK.
+
+## Build
+
+`+"```bash\ngo mod tidy\ngo build -o plugin\n```"+`
+
+## Test
+
+`+"```bash\ngo test ./...\n```"+`
+
+`+"`main_test.go`"+` uses `+"`skyplugin/testing`"+` to run the plugin in-process,
+covering both the metadata handshake and normal execution.
+
+## Install
+
+`+"```bash\nsky plugin install --path ./plugin %s\n```"+`
+
+## Usage
+
+`+"```bash\nsky %s\n```"+`
+`, name, name, name)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"main.go", mainGo},
+		{"main_test.go", mainTestGo},
+		{"go.mod", goMod},
+		{"README.md", readme},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(name, f.name), []byte(f.content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func printPluginUsage(w io.Writer) {
 	writeln(w, "usage: sky plugin <command> [args]")
 	writeln(w)
 	writeln(w, "commands:")
 	writeln(w, "  init <name>              create a new plugin project")
-	writeln(w, "  list                     list installed plugins")
+	writeln(w, "  list [--quiet]           list installed plugins")
 	writeln(w, "  install <name>           install a plugin")
 	writeln(w, "  inspect <name>           inspect plugin metadata")
 	writeln(w, "  remove <name>            remove a plugin")
 	writeln(w, "  search <query>           search marketplaces")
+	writeln(w, "  verify <name> | --all    check installed binaries against recorded sha256")
+	writeln(w, "  doctor [name...]         check that plugins run and are ready")
+	writeln(w, "  export <file>            write installed plugins and marketplaces to a manifest")
+	writeln(w, "  import <file>            install the plugins and marketplaces in a manifest")
+	writeln(w, "  lock                     write installed plugin digests to "+plugins.LockfileName)
 	writeln(w, "  marketplace <command>    manage marketplaces")
 }
 
@@ -990,4 +1671,5 @@ func printMarketplaceUsage(w io.Writer) {
 	writeln(w, "  list                     list marketplaces")
 	writeln(w, "  add <name> <url>          add or update a marketplace")
 	writeln(w, "  remove <name>             remove a marketplace")
+	writeln(w, "  validate <name|url>       check a marketplace index for schema errors")
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+// Outcomes of "sky plugin doctor" for one plugin.
+const (
+	doctorOK        = "ok"        // runs, and reports ready if it has a health check
+	doctorUnhealthy = "unhealthy" // its health check reports it is not ready
+	doctorError     = "error"     // could not be run or answered badly
+)
+
+// doctorResult is the diagnosis of one installed plugin.
+type doctorResult struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Message string   `json:"message,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// runPluginDoctor checks that installed plugins run and, for plugins that
+// declare the health capability, that they report themselves ready.
+func runPluginDoctor(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOut := fs.Bool("json", false, "print the results as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	var list []plugins.Plugin
+	if fs.NArg() == 0 {
+		list, err = store.LoadPlugins()
+		if err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return 1
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+	}
+	for _, name := range fs.Args() {
+		plugin, err := store.FindPlugin(name)
+		if err == nil && plugin == nil {
+			err = fmt.Errorf("plugin %q not installed", name)
+		}
+		if err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return 1
+		}
+		list = append(list, *plugin)
+	}
+
+	runner := newPluginRunner(stderr)
+	results := make([]doctorResult, 0, len(list))
+	failed := 0
+	for _, plugin := range list {
+		result := diagnosePlugin(context.Background(), runner, plugin)
+		if result.Status != doctorOK {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if *jsonOut || os.Getenv(plugins.EnvOutputFormat) == "json" {
+		payload, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return 1
+		}
+		writeln(stdout, string(payload))
+	} else if len(results) == 0 {
+		writeln(stdout, "no plugins installed")
+	} else {
+		writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		writeln(writer, "NAME\tSTATUS\tDETAILS")
+		for _, result := range results {
+			details := result.Message
+			if len(result.Missing) > 0 {
+				details = "missing " + strings.Join(result.Missing, ", ")
+			}
+			if details == "" {
+				details = "-"
+			}
+			writef(writer, "%s\t%s\t%s\n", result.Name, result.Status, details)
+		}
+		_ = writer.Flush()
+	}
+
+	if failed > 0 {
+		writef(stderr, "sky: %d plugin(s) have problems\n", failed)
+		return 1
+	}
+	return 0
+}
+
+// diagnosePlugin fetches the plugin's metadata, then runs its health check
+// if the metadata declares one.
+func diagnosePlugin(ctx context.Context, runner plugins.Runner, plugin plugins.Plugin) doctorResult {
+	result := doctorResult{Name: plugin.Name, Status: doctorOK}
+
+	metadata, err := runner.Metadata(ctx, plugin)
+	if err != nil {
+		result.Status, result.Message = doctorError, err.Error()
+		return result
+	}
+	// Go by what the plugin declares now, not what was recorded at install.
+	plugin.Capabilities = metadata.Capabilities
+	if !plugins.SupportsHealth(plugin) {
+		result.Message = "no health check"
+		return result
+	}
+
+	health, err := runner.HealthCheck(ctx, plugin)
+	if err != nil {
+		result.Status, result.Message = doctorError, err.Error()
+		return result
+	}
+	if !health.Ready {
+		result.Status = doctorUnhealthy
+		result.Message, result.Missing = health.Message, health.Missing
+	}
+	return result
+}
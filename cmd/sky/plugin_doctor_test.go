@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+// writeDoctorPlugin installs a script plugin that answers metadata with
+// capabilities and health mode with health.
+func writeDoctorPlugin(t *testing.T, store *plugins.Store, name, capabilities, health string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		"case \"$SKY_PLUGIN_MODE\" in",
+		"metadata) echo '{\"api_version\":1,\"name\":\"" + name + "\",\"capabilities\":[" + capabilities + "]}' ;;",
+		"health) " + health + " ;;",
+		"*) exit 1 ;;",
+		"esac",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	if err := store.UpsertPlugin(plugins.Plugin{Name: name, Path: path}); err != nil {
+		t.Fatalf("UpsertPlugin: %v", err)
+	}
+}
+
+func TestRunPluginDoctor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	configDir := t.TempDir()
+	t.Setenv(plugins.EnvConfigDir, configDir)
+	t.Setenv(plugins.EnvOutputFormat, "")
+	store := &plugins.Store{Root: configDir}
+	writeDoctorPlugin(t, store, "basic", `"net"`, "exit 1")
+	writeDoctorPlugin(t, store, "ready", `"health"`, `echo '{"ready":true}'`)
+	writeDoctorPlugin(t, store, "needy", `"health"`, `echo '{"ready":false,"message":"missing dependencies: jq","missing":["jq"]}'; exit 1`)
+	if err := store.UpsertPlugin(plugins.Plugin{Name: "gone", Path: filepath.Join(configDir, "gone")}); err != nil {
+		t.Fatalf("UpsertPlugin: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runPluginDoctor([]string{"--json"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr %q)", code, stderr.String())
+	}
+	var results []doctorResult
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	got := map[string]doctorResult{}
+	for _, result := range results {
+		got[result.Name] = result
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %+v", results)
+	}
+	if r := got["basic"]; r.Status != doctorOK || r.Message != "no health check" {
+		t.Errorf("basic: %+v", r)
+	}
+	if r := got["ready"]; r.Status != doctorOK || r.Message != "" {
+		t.Errorf("ready: %+v", r)
+	}
+	if r := got["needy"]; r.Status != doctorUnhealthy || strings.Join(r.Missing, ",") != "jq" {
+		t.Errorf("needy: %+v", r)
+	}
+	if r := got["gone"]; r.Status != doctorError {
+		t.Errorf("gone: %+v", r)
+	}
+	if !strings.Contains(stderr.String(), "2 plugin(s) have problems") {
+		t.Errorf("unexpected stderr %q", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := runPluginDoctor([]string{"ready", "basic"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+	want := "NAME   STATUS  DETAILS\nready  ok      -\nbasic  ok      no health check\n"
+	if stdout.String() != want {
+		t.Errorf("unexpected table:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	if code := runPluginDoctor([]string{"nosuch"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for an unknown plugin, got %d", code)
+	}
+}
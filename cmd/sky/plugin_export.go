@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+// runPluginExport writes a manifest of the installed plugins and
+// marketplaces, for "sky plugin import" to recreate elsewhere.
+func runPluginExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		writeln(stderr, "usage: sky plugin export <file|->")
+		return 2
+	}
+	path := fs.Arg(0)
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	manifest, err := store.Export()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, _ = stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	writef(stdout, "exported %d plugin(s) and %d marketplace(s) to %s\n", len(manifest.Plugins), len(manifest.Marketplaces), path)
+	return 0
+}
+
+// runPluginImport adds the marketplaces in a manifest written by "sky plugin
+// export" and installs its plugins from their recorded sources.
+func runPluginImport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	retries := fs.Int("retries", plugins.DefaultRetries, "how many times to retry failed downloads and marketplace fetches (0 for none)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		writeln(stderr, "usage: sky plugin import [--retries N] <file|->")
+		return 2
+	}
+	if *retries < 0 {
+		writef(stderr, "sky: --retries must be non-negative, got %d\n", *retries)
+		return 2
+	}
+	manifest, err := readManifestFile(fs.Arg(0))
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	configureRetries(store, *retries)
+	ctx := context.Background()
+	results, err := store.Import(ctx, manifest, plugins.ImportOptions{Check: checkShadowing})
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	recordImportedCapabilities(ctx, store, results, stderr)
+	return reportImport("imported", results, stdout, stderr)
+}
+
+// manifestInstallFlags are the "sky plugin install" flags that may be
+// combined with --from-manifest.
+var manifestInstallFlags = []string{"from-manifest", "fail-fast", "cache-ttl", "refresh", "retries"}
+
+// installFromManifest installs every plugin listed in a manifest, as
+// "sky plugin install --from-manifest". It continues past plugins that fail
+// to install unless failFast is set, and prints a summary either way.
+func installFromManifest(fs *flag.FlagSet, path string, failFast bool, cacheTTL time.Duration, refresh bool, retries int, stdout, stderr io.Writer) int {
+	if fs.NArg() != 0 {
+		writeln(stderr, "sky: --from-manifest does not take a plugin name")
+		return 2
+	}
+	var conflict string
+	fs.Visit(func(f *flag.Flag) {
+		if conflict == "" && !slices.Contains(manifestInstallFlags, f.Name) {
+			conflict = f.Name
+		}
+	})
+	if conflict != "" {
+		writef(stderr, "sky: --%s cannot be used with --from-manifest\n", conflict)
+		return 2
+	}
+	if retries < 0 {
+		writef(stderr, "sky: --retries must be non-negative, got %d\n", retries)
+		return 2
+	}
+
+	manifest, err := readManifestFile(path)
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	configureIndexCache(store, cacheTTL, refresh, stderr)
+	configureRetries(store, retries)
+
+	ctx := context.Background()
+	results, err := store.Import(ctx, manifest, plugins.ImportOptions{FailFast: failFast, Check: checkShadowing})
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	recordImportedCapabilities(ctx, store, results, stderr)
+	return reportImport("processed", results, stdout, stderr)
+}
+
+// checkShadowing rejects manifest plugins whose name a core command would
+// intercept, as "sky plugin install" does for a single plugin.
+func checkShadowing(entry plugins.ManifestPlugin) error {
+	if reason := commandShadowing(entry.Name); reason != "" {
+		return fmt.Errorf("cannot install: %s, so \"sky %s\" would never reach the plugin", reason, entry.Name)
+	}
+	return nil
+}
+
+// recordImportedCapabilities records the capabilities of the plugins that
+// an import installed, as recordCapabilities does for a single install.
+func recordImportedCapabilities(ctx context.Context, store *plugins.Store, results []plugins.ImportResult, stderr io.Writer) {
+	for _, result := range results {
+		if result.Status != plugins.ImportInstalled {
+			continue
+		}
+		plugin, err := store.FindPlugin(result.Plugin.Name)
+		if err != nil || plugin == nil {
+			continue
+		}
+		recordCapabilities(ctx, store, *plugin, stderr)
+	}
+}
+
+// readManifestFile reads a plugin manifest from path, or from stdin if path
+// is "-".
+func readManifestFile(path string) (plugins.Manifest, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return plugins.Manifest{}, err
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	manifest, err := plugins.ReadManifest(in)
+	if err != nil {
+		return plugins.Manifest{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// reportImport prints the outcome of each plugin and a summary line
+// starting with verb, returning the exit code: 1 if any plugin failed.
+func reportImport(verb string, results []plugins.ImportResult, stdout, stderr io.Writer) int {
+	counts := make(map[plugins.ImportStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+		switch result.Status {
+		case plugins.ImportInstalled:
+			writef(stdout, "installed %s (%s)\n", result.Plugin.Name, result.Plugin.Version)
+		case plugins.ImportUnchanged:
+			writef(stdout, "unchanged %s (%s)\n", result.Plugin.Name, result.Plugin.Version)
+		case plugins.ImportFailed:
+			writef(stderr, "sky: %s: %v\n", result.Plugin.Name, result.Err)
+		}
+	}
+	writef(stdout, "%s %d plugin(s): %d installed, %d unchanged, %d failed",
+		verb, len(results), counts[plugins.ImportInstalled], counts[plugins.ImportUnchanged], counts[plugins.ImportFailed])
+	if skipped := counts[plugins.ImportSkipped]; skipped > 0 {
+		writef(stdout, ", %d skipped", skipped)
+	}
+	writeln(stdout)
+	if counts[plugins.ImportFailed] > 0 {
+		return 1
+	}
+	return 0
+}
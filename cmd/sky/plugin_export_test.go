@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+func TestRunPluginExportImport(t *testing.T) {
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+
+	pluginPath := filepath.Join(t.TempDir(), "demo-plugin")
+	if err := os.WriteFile(pluginPath, []byte("demo-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--path", pluginPath, "--version", "1.2.3", "demo"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("install: exit %d (stderr %q)", code, stderr.String())
+	}
+	if code := runMarketplaceAdd([]string{"team", "https://example.com/index.json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("marketplace add: exit %d (stderr %q)", code, stderr.String())
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "plugins.json")
+	stdout.Reset()
+	if code := runPluginExport([]string{manifestPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("export: exit %d (stderr %q)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "exported 1 plugin(s) and 1 marketplace(s) to "+manifestPath+"\n" {
+		t.Errorf("unexpected export output %q", got)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest plugins.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	if len(manifest.Plugins) != 1 || manifest.Plugins[0].Name != "demo" || manifest.Plugins[0].Source != pluginPath || manifest.Plugins[0].Version != "1.2.3" {
+		t.Fatalf("unexpected manifest plugins: %+v", manifest.Plugins)
+	}
+
+	// Import into a fresh config directory.
+	configDir := t.TempDir()
+	t.Setenv("SKY_CONFIG_DIR", configDir)
+	stdout.Reset()
+	if code := runPluginImport([]string{manifestPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("import: exit %d (stderr %q)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "installed demo (1.2.3)") || !strings.Contains(stdout.String(), "1 installed, 0 unchanged, 0 failed") {
+		t.Errorf("unexpected import output %q", stdout.String())
+	}
+	store := plugins.NewStore(configDir)
+	if plugin, err := store.FindPlugin("demo"); err != nil || plugin == nil {
+		t.Fatalf("expected demo to be installed, got %v, %v", plugin, err)
+	}
+	if list, err := store.LoadMarketplaces(); err != nil || len(list) != 1 || list[0].Name != "team" {
+		t.Fatalf("expected marketplace team, got %+v, %v", list, err)
+	}
+
+	// A source that has gone away fails the import.
+	if err := os.Remove(pluginPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+	stdout.Reset()
+	stderr.Reset()
+	if code := runPluginImport([]string{manifestPath}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit 1 for a missing source, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "sky: demo:") || !strings.Contains(stdout.String(), "1 failed") {
+		t.Errorf("expected a failure report, got stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPluginInit_SDK(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInit([]string{"--sdk", "demo"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+
+	mainGo, err := os.ReadFile(filepath.Join("demo", "main.go"))
+	if err != nil {
+		t.Fatalf("read main.go: %v", err)
+	}
+	for _, want := range []string{`"github.com/albertocavalcante/sky/pkg/skyplugin"`, "skyplugin.Serve(plugin)", `pluginName    = "demo"`} {
+		if !strings.Contains(string(mainGo), want) {
+			t.Fatalf("expected main.go to contain %q", want)
+		}
+	}
+
+	mainTest, err := os.ReadFile(filepath.Join("demo", "main_test.go"))
+	if err != nil {
+		t.Fatalf("read main_test.go: %v", err)
+	}
+	if !strings.Contains(string(mainTest), "skytesting.RunPlugin(plugin") {
+		t.Fatalf("expected main_test.go to use skyplugin/testing")
+	}
+	if !strings.Contains(stdout.String(), "go mod tidy") {
+		t.Fatalf("expected next steps to mention go mod tidy, got %q", stdout.String())
+	}
+}
+
+func TestRunPluginInit_SDKAndWasm(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInit([]string{"--sdk", "--wasm", "demo"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if _, err := os.Stat("demo"); !os.IsNotExist(err) {
+		t.Fatalf("expected no directory to be created")
+	}
+}
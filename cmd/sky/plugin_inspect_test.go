@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+func TestRunPluginInspect_Flags(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+
+	pluginPath := filepath.Join(t.TempDir(), "greet")
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		"if [ \"$SKY_PLUGIN_MODE\" = \"metadata\" ]; then",
+		"  echo '{\"api_version\":1,\"name\":\"greet\",\"version\":\"0.2.0\",\"summary\":\"Greets people\",\"commands\":[{\"name\":\"greet\",\"summary\":\"Say hello\",\"flags\":[{\"name\":\"name\",\"type\":\"string\",\"description\":\"who to greet\",\"default\":\"world\"},{\"name\":\"loud\",\"type\":\"bool\",\"description\":\"shout\",\"default\":\"false\"}]}]}'",
+		"  exit 0",
+		"fi",
+		"exit 1",
+	}, "\n")
+	if err := os.WriteFile(pluginPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--path", pluginPath, "greet"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("install: expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := runPluginInspect([]string{"greet"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("inspect: expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+	var metadata plugins.Metadata
+	if err := json.Unmarshal(stdout.Bytes(), &metadata); err != nil {
+		t.Fatalf("expected metadata JSON on stdout: %v (%q)", err, stdout.String())
+	}
+	if len(metadata.Commands) != 1 || len(metadata.Commands[0].Flags) != 2 {
+		t.Fatalf("expected one command with two flags, got %+v", metadata.Commands)
+	}
+	if f := metadata.Commands[0].Flags[0]; f.Name != "name" || f.Type != "string" || f.Default != "world" {
+		t.Fatalf("unexpected flag metadata: %+v", f)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := runPluginInspect([]string{"--usage", "greet"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("inspect --usage: expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+	want := strings.Join([]string{
+		"greet 0.2.0 - Greets people",
+		"",
+		"sky greet        Say hello",
+		"  --name string  who to greet (default \"world\")",
+		"  --loud         shout",
+		"",
+	}, "\n")
+	if stdout.String() != want {
+		t.Fatalf("unexpected usage:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+func TestRunPluginInspect_DebugPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+	t.Setenv(plugins.EnvDebugPlugins, "")
+
+	pluginPath := filepath.Join(t.TempDir(), "greet")
+	script := "#!/bin/sh\necho '{\"api_version\":1,\"name\":\"greet\"}'\n"
+	if err := os.WriteFile(pluginPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--path", pluginPath, "greet"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("install: expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+
+	stderr.Reset()
+	if code := run([]string{"--debug-plugins", "plugin", "inspect", "greet"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("inspect: expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+	for _, want := range []string{"sky: debug:   mode: metadata", `sky: debug: plugin "greet" exited with 0`} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("expected stderr to contain %q, got:\n%s", want, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+func TestRunPluginInstall_DryRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	configDir := t.TempDir()
+	t.Setenv("SKY_CONFIG_DIR", configDir)
+
+	pluginPath := filepath.Join(t.TempDir(), "demo-plugin")
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		"if [ \"$SKY_PLUGIN_MODE\" = \"metadata\" ]; then",
+		"  echo '{\"api_version\":1,\"name\":\"demo\",\"version\":\"1.2.3\",\"commands\":[{\"name\":\"demo\"}]}'",
+		"  exit 0",
+		"fi",
+		"exit 1",
+	}, "\n")
+	if err := os.WriteFile(pluginPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := runPluginInstall([]string{"--dry-run", "--path", pluginPath, "demo"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+
+	var metadata struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &metadata); err != nil {
+		t.Fatalf("expected metadata JSON on stdout: %v (%q)", err, stdout.String())
+	}
+	if metadata.Name != "demo" || metadata.Version != "1.2.3" {
+		t.Fatalf("unexpected metadata: %+v", metadata)
+	}
+	if !strings.Contains(stderr.String(), "was not installed") {
+		t.Fatalf("expected dry run notice, got %q", stderr.String())
+	}
+
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		t.Fatalf("read config dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected dry run to leave config dir untouched, found %d entries", len(entries))
+	}
+}
+
+func TestCommandShadowing(t *testing.T) {
+	cases := []struct {
+		name     string
+		shadowed bool
+	}{
+		{name: "fmt", shadowed: true},
+		{name: "plugin", shadowed: true},
+		{name: "version", shadowed: true},
+		{name: "skylint", shadowed: true},
+		{name: "my-tool", shadowed: false},
+		{name: "format", shadowed: false},
+	}
+
+	for _, tc := range cases {
+		if got := commandShadowing(tc.name) != ""; got != tc.shadowed {
+			t.Fatalf("expected commandShadowing(%q) shadowed=%v, got %v", tc.name, tc.shadowed, got)
+		}
+	}
+}
+
+func TestRunPluginInstall_ShadowedName(t *testing.T) {
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+
+	pluginPath := filepath.Join(t.TempDir(), "plugin")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--path", pluginPath, "lint"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "--force") {
+		t.Fatalf("expected --force hint, got %q", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := runPluginInstall([]string{"--force", "--path", pluginPath, "lint"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected --force install to succeed, got %d (stderr %q)", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "warning") {
+		t.Fatalf("expected shadowing warning, got %q", stderr.String())
+	}
+}
+
+func TestWarnShadowedCommands(t *testing.T) {
+	var stderr bytes.Buffer
+	warnShadowedCommands(&stderr, "demo", []plugins.CommandMetadata{{Name: "demo"}, {Name: "test"}, {Name: "extra"}})
+
+	if got := strings.Count(stderr.String(), "warning"); got != 1 {
+		t.Fatalf("expected 1 warning, got %d: %q", got, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), `declares command "test"`) {
+		t.Fatalf("unexpected warning: %q", stderr.String())
+	}
+}
+
+func TestRunPluginInstall_GitUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "with url", args: []string{"--git", "https://example.com/repo", "--url", "https://example.com/bin", "demo"}, wantErr: "only one of --path, --url, or --git"},
+		{name: "with path", args: []string{"--git", "https://example.com/repo", "--path", "bin", "demo"}, wantErr: "only one of --path, --url, or --git"},
+		{name: "empty ref", args: []string{"--git", "https://example.com/repo@", "demo"}, wantErr: "empty ref"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runPluginInstall(tt.args, &stdout, &stderr); code != 2 {
+				t.Fatalf("expected exit code 2, got %d", code)
+			}
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("expected stderr to contain %q, got %q", tt.wantErr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunPluginInstall_FromManifest(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good-plugin")
+	if err := os.WriteFile(good, []byte("good-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	manifestPath := filepath.Join(dir, "plugins.json")
+	manifest := `{
+  "version": 1,
+  "plugins": [
+    {"name": "first", "source": "` + filepath.ToSlash(good) + `", "version": "1.0.0"},
+    {"name": "broken", "source": "` + filepath.ToSlash(filepath.Join(dir, "missing")) + `"},
+    {"name": "last", "source": "` + filepath.ToSlash(good) + `"}
+  ]
+}`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	configDir := t.TempDir()
+	t.Setenv("SKY_CONFIG_DIR", configDir)
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--from-manifest", manifestPath}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit 1, got %d (stderr %q)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "processed 3 plugin(s): 2 installed, 0 unchanged, 1 failed\n") || !strings.Contains(stderr.String(), "sky: broken:") {
+		t.Errorf("unexpected output: stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+	store := plugins.NewStore(configDir)
+	if plugin, err := store.FindPlugin("last"); err != nil || plugin == nil {
+		t.Fatalf("expected last to be installed past the failure, got %v, %v", plugin, err)
+	}
+
+	configDir = t.TempDir()
+	t.Setenv("SKY_CONFIG_DIR", configDir)
+	stdout.Reset()
+	stderr.Reset()
+	if code := runPluginInstall([]string{"--from-manifest", manifestPath, "--fail-fast"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit 1, got %d (stderr %q)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "1 installed, 0 unchanged, 1 failed, 1 skipped") {
+		t.Errorf("unexpected fail-fast summary %q", stdout.String())
+	}
+	if plugin, _ := plugins.NewStore(configDir).FindPlugin("last"); plugin != nil {
+		t.Errorf("expected --fail-fast to skip last, got %+v", plugin)
+	}
+
+	for _, args := range [][]string{
+		{"--from-manifest", manifestPath, "demo"},
+		{"--from-manifest", manifestPath, "--url", "https://example.com/demo"},
+		{"--fail-fast", "demo"},
+	} {
+		stderr.Reset()
+		if code := runPluginInstall(args, &stdout, &stderr); code != 2 {
+			t.Errorf("%q: expected exit 2, got %d (stderr %q)", args, code, stderr.String())
+		}
+	}
+}
+
+func TestRunPluginInstall_FromManifestShadowing(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "plugin")
+	if err := os.WriteFile(bin, []byte("plugin-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	manifestPath := filepath.Join(dir, "plugins.json")
+	manifest := `{
+  "version": 1,
+  "plugins": [
+    {"name": "fmt", "source": "` + filepath.ToSlash(bin) + `"},
+    {"name": "demo", "source": "` + filepath.ToSlash(bin) + `"}
+  ]
+}`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	configDir := t.TempDir()
+	t.Setenv("SKY_CONFIG_DIR", configDir)
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--from-manifest", manifestPath}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit 1, got %d (stderr %q)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "1 installed, 0 unchanged, 1 failed") || !strings.Contains(stderr.String(), `sky: fmt: cannot install: "fmt" is the core command`) {
+		t.Errorf("unexpected output: stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+	store := plugins.NewStore(configDir)
+	if plugin, _ := store.FindPlugin("fmt"); plugin != nil {
+		t.Errorf("expected fmt not to be installed, got %+v", plugin)
+	}
+	if plugin, err := store.FindPlugin("demo"); err != nil || plugin == nil {
+		t.Fatalf("expected demo to be installed, got %v, %v", plugin, err)
+	}
+}
+
+func TestRunPluginInstall_RecordsCapabilities(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	configDir := t.TempDir()
+	t.Setenv("SKY_CONFIG_DIR", configDir)
+
+	pluginPath := filepath.Join(t.TempDir(), "demo-plugin")
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		"if [ \"$SKY_PLUGIN_MODE\" = \"metadata\" ]; then",
+		"  echo '{\"api_version\":1,\"name\":\"demo\",\"capabilities\":[\"daemon\",\"health\"]}'",
+		"  exit 0",
+		"fi",
+		"exit 1",
+	}, "\n")
+	if err := os.WriteFile(pluginPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--path", pluginPath, "demo"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("install: exit %d (stderr %q)", code, stderr.String())
+	}
+	plugin, err := plugins.NewStore(configDir).FindPlugin("demo")
+	if err != nil || plugin == nil {
+		t.Fatalf("expected demo to be installed, got %v, %v", plugin, err)
+	}
+	if !plugins.SupportsDaemon(*plugin) || strings.Join(plugin.Capabilities, ",") != "daemon,health" {
+		t.Errorf("expected capabilities from metadata to be recorded, got %q", plugin.Capabilities)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+// runPluginLock writes the digests of the installed plugins to a lockfile,
+// for "sky plugin install --frozen" to enforce.
+func runPluginLock(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lock", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	lockfile := fs.String("lockfile", "", "lockfile path (default: "+plugins.LockfileName+" at the workspace root)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		writeln(stderr, "usage: sky plugin lock [--lockfile PATH]")
+		return 2
+	}
+	path := lockfilePath(*lockfile)
+
+	store, err := plugins.DefaultStore()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	lock, err := store.Lock()
+	if err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	if err := plugins.WriteLockfile(path, lock); err != nil {
+		writef(stderr, "sky: %v\n", err)
+		return 1
+	}
+	writef(stdout, "locked %d plugin(s) in %s\n", len(lock.Plugins), path)
+	return 0
+}
+
+// lockfilePath returns path, or the default lockfile at the workspace root
+// when path is empty.
+func lockfilePath(path string) string {
+	if path != "" {
+		return path
+	}
+	return filepath.Join(plugins.FindWorkspaceRoot(), plugins.LockfileName)
+}
+
+// frozenDigest returns the digest locked for name in the lockfile at path.
+// A --sha256 given alongside --frozen must agree with the lock.
+func frozenDigest(path, name, sha string) (string, error) {
+	lock, err := plugins.ReadLockfile(path)
+	if err != nil {
+		return "", fmt.Errorf("--frozen: %w (run \"sky plugin lock\" to create it)", err)
+	}
+	digest, ok := lock.Digest(name)
+	if !ok {
+		return "", fmt.Errorf("--frozen: plugin %q is not in %s", name, path)
+	}
+	if sha != "" && !strings.EqualFold(sha, digest) {
+		return "", fmt.Errorf("--frozen: --sha256 %s does not match the locked sha256 %s", sha, digest)
+	}
+	return digest, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+func TestRunPluginLockAndFrozenInstall(t *testing.T) {
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+
+	pluginPath := filepath.Join(t.TempDir(), "demo-plugin")
+	if err := os.WriteFile(pluginPath, []byte("demo-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--path", pluginPath, "demo"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("install: exit %d (stderr %q)", code, stderr.String())
+	}
+
+	lockPath := filepath.Join(t.TempDir(), plugins.LockfileName)
+	stdout.Reset()
+	if code := runPluginLock([]string{"--lockfile", lockPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("lock: exit %d (stderr %q)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "locked 1 plugin(s) in "+lockPath+"\n" {
+		t.Errorf("unexpected lock output %q", got)
+	}
+
+	// A fresh store installs the locked binary.
+	t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+	stderr.Reset()
+	if code := runPluginInstall([]string{"--frozen", "--lockfile", lockPath, "--path", pluginPath, "demo"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("frozen install: exit %d (stderr %q)", code, stderr.String())
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		setup   func()
+		wantErr string
+	}{
+		{
+			name:    "not locked",
+			args:    []string{"--frozen", "--lockfile", lockPath, "--path", pluginPath, "other"},
+			wantErr: `plugin "other" is not in`,
+		},
+		{
+			name:    "missing lockfile",
+			args:    []string{"--frozen", "--lockfile", filepath.Join(t.TempDir(), "missing.lock"), "--path", pluginPath, "demo"},
+			wantErr: "sky plugin lock",
+		},
+		{
+			name:    "conflicting sha256",
+			args:    []string{"--frozen", "--lockfile", lockPath, "--sha256", strings.Repeat("0", 64), "--path", pluginPath, "demo"},
+			wantErr: "does not match the locked sha256",
+		},
+		{
+			name: "changed binary",
+			args: []string{"--frozen", "--lockfile", lockPath, "--path", pluginPath, "demo"},
+			setup: func() {
+				if err := os.WriteFile(pluginPath, []byte("tampered"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "checksum mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SKY_CONFIG_DIR", t.TempDir())
+			if tt.setup != nil {
+				tt.setup()
+			}
+			var stdout, stderr bytes.Buffer
+			if code := runPluginInstall(tt.args, &stdout, &stderr); code != 1 {
+				t.Fatalf("expected exit 1, got %d (stderr %q)", code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("expected stderr to contain %q, got %q", tt.wantErr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunPluginInstall_LockfileRequiresFrozen(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runPluginInstall([]string{"--lockfile", "x.lock", "demo"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "--lockfile requires --frozen") {
+		t.Errorf("unexpected stderr %q", stderr.String())
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/cmdtest"
+)
+
+// TestMain makes the test binary act as sky and as each Sky tool, so that
+// the scripts in testdata/script run sky against the real tools on PATH.
+func TestMain(m *testing.M) {
+	cmdtest.MainWith(m, map[string]func() int{
+		"sky": func() int { return run(os.Args[1:], os.Stdout, os.Stderr) },
+	})
+}
+
+func TestScripts(t *testing.T) {
+	cmdtest.Run(t, "testdata/script")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+// Sources of a core command, in the order runCoreCommand tries them.
+const (
+	toolEmbedded = "embedded" // compiled into a sky_full build
+	toolExternal = "external" // binary next to the sky executable
+	toolPath     = "path"     // binary found in PATH
+	toolPlugin   = "plugin"   // installed plugin of the same name
+	toolMissing  = "missing"  // nothing; sky reports an unknown command
+)
+
+// toolResolution describes how "sky <command>" runs a core command.
+type toolResolution struct {
+	Command string `json:"command"`
+	Binary  string `json:"binary"`
+	Source  string `json:"source"`
+	Path    string `json:"path,omitempty"`
+}
+
+// runTools lists the core commands and how each resolves, as JSON with
+// --json (or the global --json flag).
+func runTools(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tools", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOut := fs.Bool("json", false, "print the resolutions as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		writeln(stderr, "usage: sky tools [--json]")
+		return 2
+	}
+
+	// A broken plugin store only hides the plugin fallback.
+	var store *plugins.Store
+	if s, err := plugins.DefaultStore(); err == nil {
+		store = s
+	} else {
+		writef(stderr, "sky: warning: %v\n", err)
+	}
+
+	var tools []toolResolution
+	for _, name := range slices.Sorted(maps.Keys(coreCommands)) {
+		tools = append(tools, resolveCoreCommand(name, store))
+	}
+
+	if *jsonOut || os.Getenv(plugins.EnvOutputFormat) == "json" {
+		payload, err := json.MarshalIndent(tools, "", "  ")
+		if err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return 1
+		}
+		writeln(stdout, string(payload))
+		return 0
+	}
+
+	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	writeln(writer, "COMMAND\tBINARY\tSOURCE\tPATH")
+	for _, tool := range tools {
+		path := tool.Path
+		if path == "" {
+			path = "-"
+		}
+		writef(writer, "%s\t%s\t%s\t%s\n", tool.Command, tool.Binary, tool.Source, path)
+	}
+	_ = writer.Flush()
+	return 0
+}
+
+// resolveCoreCommand reports which of the sources run and runCoreCommand
+// try would run the core command name. store may be nil to skip plugins.
+func resolveCoreCommand(name string, store *plugins.Store) toolResolution {
+	tool := toolResolution{Command: name, Binary: coreCommands[name]}
+
+	if getEmbeddedTool(name) != nil {
+		tool.Source = toolEmbedded
+		return tool
+	}
+	if path, err := findCoreBinary(tool.Binary); err == nil {
+		tool.Source, tool.Path = toolPath, path
+		if exe, err := os.Executable(); err == nil && filepath.Dir(path) == filepath.Dir(exe) {
+			tool.Source = toolExternal
+		}
+		return tool
+	}
+	if store != nil {
+		if plugin, err := store.FindPlugin(name); err == nil && plugin != nil {
+			tool.Source, tool.Path = toolPlugin, plugin.Path
+			return tool
+		}
+	}
+	tool.Source = toolMissing
+	return tool
+}
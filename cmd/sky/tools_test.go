@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+)
+
+func TestRunTools(t *testing.T) {
+	if getEmbeddedTool("fmt") != nil {
+		t.Skip("core tools are embedded in this build")
+	}
+
+	configDir := t.TempDir()
+	t.Setenv(plugins.EnvConfigDir, configDir)
+	t.Setenv(plugins.EnvOutputFormat, "")
+	store := &plugins.Store{Root: configDir}
+	if err := store.UpsertPlugin(plugins.Plugin{Name: "lint", Path: "/opt/lint-plugin"}); err != nil {
+		t.Fatalf("UpsertPlugin: %v", err)
+	}
+
+	binDir := t.TempDir()
+	skyfmt := filepath.Join(binDir, "skyfmt")
+	if err := os.WriteFile(skyfmt, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"tools", "--json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+	var tools []toolResolution
+	if err := json.Unmarshal(stdout.Bytes(), &tools); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	got := map[string]toolResolution{}
+	for _, tool := range tools {
+		got[tool.Command] = tool
+	}
+
+	want := map[string]toolResolution{
+		"check": {Command: "check", Binary: "skycheck", Source: toolMissing},
+		"fmt":   {Command: "fmt", Binary: "skyfmt", Source: toolPath, Path: skyfmt},
+		"lint":  {Command: "lint", Binary: "skylint", Source: toolPlugin, Path: "/opt/lint-plugin"},
+		"query": {Command: "query", Binary: "skyquery", Source: toolMissing},
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s: got %+v, want %+v", name, got[name], w)
+		}
+	}
+	if len(tools) != len(coreCommands) {
+		t.Errorf("expected %d tools, got %d", len(coreCommands), len(tools))
+	}
+
+	stdout.Reset()
+	if code := run([]string{"tools"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if !strings.HasPrefix(stdout.String(), "COMMAND") || !strings.Contains(stdout.String(), skyfmt) {
+		t.Errorf("unexpected table:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+	"github.com/albertocavalcante/sky/internal/starlark/pathmatch"
+)
+
+// Exit codes for "sky verify".
+const (
+	verifyExitOK       = 0
+	verifyExitFindings = 1 // a stage reported findings
+	verifyExitFailed   = 2 // usage error, or a stage could not run
+)
+
+// toolRunner runs a core tool by name, as runCoreCommand does.
+type toolRunner func(name string, args []string, stdout, stderr io.Writer) int
+
+// verifyFinding is one issue reported by a "sky verify" stage. Line and
+// Column are zero for findings about a whole file.
+type verifyFinding struct {
+	Stage    string `json:"stage"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+}
+
+// verifyStage is one tool run by "sky verify". Its output is parsed into
+// findings; a parse error means the tool could not run.
+type verifyStage struct {
+	name  string
+	tool  string
+	args  []string
+	parse func(code int, stdout []byte) ([]verifyFinding, error)
+}
+
+// verifyStages are the stages of "sky verify", in the order they run.
+var verifyStages = []verifyStage{
+	{name: "fmt", tool: "skyfmt", args: []string{"--check"}, parse: parseFmtCheck},
+	{name: "lint", tool: "skylint", args: []string{"--format=json"}, parse: parseLintJSON},
+	{name: "check", tool: "skycheck", args: []string{"--json"}, parse: parseSkycheckJSON},
+}
+
+// verifyStageResult is the outcome of one stage, for JSON output.
+type verifyStageResult struct {
+	Name     string `json:"name"`
+	Findings int    `json:"findings"`
+	Error    string `json:"error,omitempty"`
+}
+
+// verifyReport is the JSON output of "sky verify".
+type verifyReport struct {
+	Stages   []verifyStageResult `json:"stages"`
+	Findings []verifyFinding     `json:"findings"`
+}
+
+// runVerify runs skyfmt --check, skylint, and skycheck over the same paths
+// and prints their findings as one report.
+func runVerify(args []string, stdout, stderr io.Writer) int {
+	return runVerifyWith(args, stdout, stderr, runCoreCommand)
+}
+
+func runVerifyWith(args []string, stdout, stderr io.Writer, run toolRunner) int {
+	var excludes pathmatch.Excludes
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	noFmt := fs.Bool("no-fmt", false, "skip the formatting check")
+	noLint := fs.Bool("no-lint", false, "skip linting")
+	noCheck := fs.Bool("no-check", false, "skip static analysis")
+	jsonOut := fs.Bool("json", false, "print the report as a JSON object")
+	fs.Var(&excludes, "exclude", "skip files and directories matching this glob when walking directories (repeatable)")
+	fs.Usage = func() {
+		writeln(stderr, "usage: sky verify [flags] [path ...]")
+		writeln(stderr)
+		writeln(stderr, "Runs skyfmt --check, skylint, and skycheck over the paths (default \".\")")
+		writeln(stderr, "and prints their findings as one report. Exits 0 when every stage is clean,")
+		writeln(stderr, "1 when any stage reports findings, and 2 when a stage cannot run.")
+		writeln(stderr)
+		writeln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return verifyExitOK
+		}
+		return verifyExitFailed
+	}
+	if os.Getenv(plugins.EnvOutputFormat) == "json" {
+		*jsonOut = true
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	skip := map[string]bool{"fmt": *noFmt, "lint": *noLint, "check": *noCheck}
+
+	report := verifyReport{Findings: []verifyFinding{}}
+	exit := verifyExitOK
+	for _, stage := range verifyStages {
+		if skip[stage.name] {
+			continue
+		}
+
+		stageArgs := slices.Clone(stage.args)
+		for _, pattern := range excludes {
+			stageArgs = append(stageArgs, "--exclude="+pattern)
+		}
+		stageArgs = append(stageArgs, paths...)
+
+		var out, errOut bytes.Buffer
+		code := run(stage.tool, stageArgs, &out, &errOut)
+		findings, err := stage.parse(code, out.Bytes())
+
+		result := verifyStageResult{Name: stage.name, Findings: len(findings)}
+		if err != nil {
+			result.Error = strings.TrimSpace(errOut.String())
+			if result.Error == "" {
+				result.Error = err.Error()
+			}
+			writef(stderr, "sky verify: %s failed (exit %d): %s\n", stage.name, code, result.Error)
+			exit = verifyExitFailed
+		} else if len(findings) > 0 && exit == verifyExitOK {
+			exit = verifyExitFindings
+		}
+		report.Stages = append(report.Stages, result)
+		report.Findings = append(report.Findings, findings...)
+	}
+
+	slices.SortStableFunc(report.Findings, func(a, b verifyFinding) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+
+	if *jsonOut {
+		payload, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			writef(stderr, "sky: %v\n", err)
+			return verifyExitFailed
+		}
+		writeln(stdout, string(payload))
+		return exit
+	}
+
+	for _, f := range report.Findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+		}
+		code := f.Stage
+		if f.Code != "" {
+			code += "/" + f.Code
+		}
+		writef(stdout, "%s: %s: %s [%s]\n", location, f.Severity, f.Message, code)
+	}
+	if len(report.Findings) > 0 {
+		writeln(stdout)
+	}
+	for _, r := range report.Stages {
+		switch {
+		case r.Error != "":
+			writef(stdout, "%-6s failed\n", r.Name)
+		case r.Findings == 0:
+			writef(stdout, "%-6s ok\n", r.Name)
+		default:
+			writef(stdout, "%-6s %d finding(s)\n", r.Name, r.Findings)
+		}
+	}
+	return exit
+}
+
+// parseFmtCheck parses "skyfmt --check" output: the files that need
+// formatting, one per line.
+func parseFmtCheck(code int, stdout []byte) ([]verifyFinding, error) {
+	if code != 0 && code != 1 {
+		return nil, fmt.Errorf("skyfmt exited with status %d", code)
+	}
+	var findings []verifyFinding
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	for scanner.Scan() {
+		if file := strings.TrimSpace(scanner.Text()); file != "" {
+			findings = append(findings, verifyFinding{
+				Stage:    "fmt",
+				File:     file,
+				Severity: "warning",
+				Message:  "file is not formatted (run sky fmt -w)",
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if code == 1 && len(findings) == 0 {
+		return nil, fmt.Errorf("skyfmt exited with status %d", code)
+	}
+	return findings, nil
+}
+
+// parseLintJSON parses "skylint --format=json" output.
+func parseLintJSON(code int, stdout []byte) ([]verifyFinding, error) {
+	// A clean run over no files prints nothing.
+	if code == 0 && len(bytes.TrimSpace(stdout)) == 0 {
+		return nil, nil
+	}
+	var out struct {
+		Files []struct {
+			Path     string `json:"path"`
+			Findings []struct {
+				Rule     string `json:"rule"`
+				Severity string `json:"severity"`
+				Message  string `json:"message"`
+				Line     int    `json:"line"`
+				Column   int    `json:"column"`
+			} `json:"findings"`
+		} `json:"files"`
+		Summary struct {
+			FileErrors []struct {
+				Path    string `json:"path"`
+				Message string `json:"message"`
+			} `json:"file_errors"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(stdout, &out); err != nil {
+		return nil, fmt.Errorf("parsing skylint output: %w", err)
+	}
+
+	var findings []verifyFinding
+	for _, file := range out.Files {
+		for _, f := range file.Findings {
+			findings = append(findings, verifyFinding{
+				Stage:    "lint",
+				File:     file.Path,
+				Line:     f.Line,
+				Column:   f.Column,
+				Severity: f.Severity,
+				Code:     f.Rule,
+				Message:  f.Message,
+			})
+		}
+	}
+	for _, fe := range out.Summary.FileErrors {
+		findings = append(findings, verifyFinding{
+			Stage:    "lint",
+			File:     fe.Path,
+			Severity: "error",
+			Message:  fe.Message,
+		})
+	}
+	return findings, nil
+}
+
+// parseSkycheckJSON parses "skycheck --json" output.
+func parseSkycheckJSON(code int, stdout []byte) ([]verifyFinding, error) {
+	// A clean run over no files prints nothing.
+	if code == 0 && len(bytes.TrimSpace(stdout)) == 0 {
+		return nil, nil
+	}
+	var out struct {
+		Diagnostics []struct {
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+			Severity string `json:"severity"`
+			Code     string `json:"code"`
+			Message  string `json:"message"`
+		} `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(stdout, &out); err != nil {
+		return nil, fmt.Errorf("parsing skycheck output: %w", err)
+	}
+
+	findings := make([]verifyFinding, 0, len(out.Diagnostics))
+	for _, d := range out.Diagnostics {
+		findings = append(findings, verifyFinding{
+			Stage:    "check",
+			File:     d.File,
+			Line:     d.Line,
+			Column:   d.Column,
+			Severity: d.Severity,
+			Code:     d.Code,
+			Message:  d.Message,
+		})
+	}
+	return findings, nil
+}
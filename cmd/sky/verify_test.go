@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeTools returns a toolRunner that replays canned output per tool and
+// records the arguments each tool was run with.
+func fakeTools(outputs map[string]string, codes map[string]int, calls map[string][]string) toolRunner {
+	return func(name string, args []string, stdout, stderr io.Writer) int {
+		calls[name] = args
+		out, ok := outputs[name]
+		if !ok {
+			writef(stderr, "sky: unknown command %q\n", name)
+			return 1
+		}
+		writef(stdout, "%s", out)
+		return codes[name]
+	}
+}
+
+const (
+	fakeLintJSON = `{"files": [{"path": "b.star", "findings": [
+		{"rule": "unused-variable", "severity": "warning", "message": "unused x", "line": 2, "column": 5}
+	]}], "summary": {}}`
+	fakeSkycheckJSON = `{"diagnostics": [
+		{"file": "a.star", "line": 3, "column": 12, "severity": "error", "code": "undefined", "message": "undefined: y"}
+	]}`
+)
+
+func TestRunVerify(t *testing.T) {
+	outputs := map[string]string{
+		"skyfmt":   "c.star\n",
+		"skylint":  fakeLintJSON,
+		"skycheck": fakeSkycheckJSON,
+	}
+	codes := map[string]int{"skyfmt": 1, "skylint": 2, "skycheck": 1}
+	calls := make(map[string][]string)
+
+	var stdout, stderr bytes.Buffer
+	code := runVerifyWith([]string{"--exclude=vendor", "src"}, &stdout, &stderr, fakeTools(outputs, codes, calls))
+	if code != verifyExitFindings {
+		t.Fatalf("expected exit code %d, got %d (stderr %q)", verifyExitFindings, code, stderr.String())
+	}
+
+	want := []string{
+		"a.star:3:12: error: undefined: y [check/undefined]",
+		"b.star:2:5: warning: unused x [lint/unused-variable]",
+		"c.star: warning: file is not formatted (run sky fmt -w) [fmt]",
+		"",
+		"fmt    1 finding(s)",
+		"lint   1 finding(s)",
+		"check  1 finding(s)",
+	}
+	if got := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n"); !slices.Equal(got, want) {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", stdout.String(), strings.Join(want, "\n"))
+	}
+
+	if got := calls["skylint"]; !slices.Equal(got, []string{"--format=json", "--exclude=vendor", "src"}) {
+		t.Errorf("unexpected skylint args: %q", got)
+	}
+}
+
+func TestRunVerify_SkipStages(t *testing.T) {
+	outputs := map[string]string{"skycheck": `{"diagnostics": []}`}
+	calls := make(map[string][]string)
+
+	var stdout, stderr bytes.Buffer
+	code := runVerifyWith([]string{"--no-fmt", "--no-lint", "--json"}, &stdout, &stderr, fakeTools(outputs, nil, calls))
+	if code != verifyExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr %q)", verifyExitOK, code, stderr.String())
+	}
+	if _, ok := calls["skyfmt"]; ok {
+		t.Error("skyfmt ran despite --no-fmt")
+	}
+	if got := calls["skycheck"]; !slices.Equal(got, []string{"--json", "."}) {
+		t.Errorf("unexpected skycheck args: %q", got)
+	}
+
+	var report verifyReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, stdout.String())
+	}
+	if len(report.Stages) != 1 || report.Stages[0].Name != "check" || len(report.Findings) != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestRunVerify_StageFailure(t *testing.T) {
+	// skyfmt is unavailable, so the fmt stage cannot run.
+	outputs := map[string]string{"skylint": fakeLintJSON, "skycheck": `{"diagnostics": []}`}
+	calls := make(map[string][]string)
+
+	var stdout, stderr bytes.Buffer
+	code := runVerifyWith(nil, &stdout, &stderr, fakeTools(outputs, map[string]int{"skylint": 2}, calls))
+	if code != verifyExitFailed {
+		t.Fatalf("expected exit code %d, got %d", verifyExitFailed, code)
+	}
+	if !strings.Contains(stderr.String(), `sky verify: fmt failed (exit 1): sky: unknown command "skyfmt"`) {
+		t.Errorf("expected fmt failure on stderr, got %q", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "fmt    failed") || !strings.Contains(stdout.String(), "lint   1 finding(s)") {
+		t.Errorf("expected remaining stages to run, got:\n%s", stdout.String())
+	}
+}
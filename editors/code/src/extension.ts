@@ -4,6 +4,14 @@ import { activateLanguageServer } from "./server/client";
 
 let ctx: ExtensionContext | undefined;
 
+/** Result of the skyls sky/fileInfo request. */
+interface FileInfo {
+  uri: string;
+  fileKind: string;
+  dialect: string;
+  configPath?: string;
+}
+
 export async function activate(context: vscode.ExtensionContext): Promise<void> {
   const outputChannel = vscode.window.createOutputChannel("Sky - Starlark", { log: true });
   outputChannel.info("Activating Sky - Starlark extension...");
@@ -21,6 +29,20 @@ export async function activate(context: vscode.ExtensionContext): Promise<void>
     await activateLanguageServer(extCtx);
   });
 
+  extCtx.registerCommand("sky-starlark.showFileInfo", async () => {
+    const editor = vscode.window.activeTextEditor;
+    if (editor === undefined || extCtx.client === undefined) {
+      return;
+    }
+    const info = await extCtx.client.sendRequest<FileInfo>("sky/fileInfo", {
+      textDocument: { uri: editor.document.uri.toString() },
+    });
+    const source = info.configPath ? ` (from ${info.configPath})` : "";
+    void vscode.window.showInformationMessage(
+      `File kind: ${info.fileKind}, dialect: ${info.dialect}${source}`
+    );
+  });
+
   outputChannel.info("Sky - Starlark extension activated");
 }
 
@@ -41,7 +41,7 @@ func Execute(cmd Command, args []string, stdout, stderr io.Writer) int {
 	}
 
 	if *showVersion {
-		Writef(stdout, "%s %s\n", cmd.Name, version.String())
+		version.Write(stdout, cmd.Name, false)
 		return ExitOK
 	}
 
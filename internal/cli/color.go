@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+// ColorMode selects when a tool colors its output. It implements flag.Value
+// so tools can accept --color directly; see ColorFlag.
+type ColorMode string
+
+const (
+	// ColorAuto colors output written to a terminal, unless
+	// skyplugin.NoColor reports that color is disabled (as "sky --no-color"
+	// does) or TERM is "dumb".
+	ColorAuto ColorMode = "auto"
+	// ColorAlways colors output even when it is piped to a file.
+	ColorAlways ColorMode = "always"
+	// ColorNever never colors output.
+	ColorNever ColorMode = "never"
+)
+
+// ColorFlag registers --color on fs, defaulting to ColorAuto.
+//
+// Example:
+//
+//	color := cli.ColorFlag(fs)
+//	...
+//	reporter.ColorOutput = color.Enabled(stdout)
+func ColorFlag(fs *flag.FlagSet) *ColorMode {
+	mode := ColorAuto
+	fs.Var(&mode, "color", "color output: auto (when writing to a terminal), always, or never")
+	return &mode
+}
+
+// String implements flag.Value.
+func (m *ColorMode) String() string {
+	if m == nil || *m == "" {
+		return string(ColorAuto)
+	}
+	return string(*m)
+}
+
+// Set implements flag.Value.
+func (m *ColorMode) Set(value string) error {
+	switch mode := ColorMode(value); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("invalid color mode %q (want auto, always, or never)", value)
+}
+
+// Enabled reports whether output written to w should be colored. The zero
+// value behaves like ColorAuto.
+func (m ColorMode) Enabled(w io.Writer) bool {
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if skyplugin.NoColor() || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// ANSI styles for Paint.
+const (
+	Red    = "31"
+	Green  = "32"
+	Yellow = "33"
+	Cyan   = "36"
+	Gray   = "90"
+	Bold   = "1"
+)
+
+// Paint wraps s in the ANSI escape codes for style when enabled, and
+// returns it unchanged otherwise.
+func Paint(enabled bool, style, s string) string {
+	if !enabled {
+		return s
+	}
+	return "\033[" + style + "m" + s + "\033[0m"
+}
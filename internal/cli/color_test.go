@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+func TestColorFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	color := ColorFlag(fs)
+	if *color != ColorAuto {
+		t.Errorf("default = %q, want auto", *color)
+	}
+	if err := fs.Parse([]string{"--color=never"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if *color != ColorNever {
+		t.Errorf("--color=never = %q", *color)
+	}
+	if err := fs.Parse([]string{"--color=sometimes"}); err == nil {
+		t.Error("Parse() accepted an invalid mode")
+	}
+}
+
+func TestColorMode_Enabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv(skyplugin.EnvNoColor, "")
+	t.Setenv("TERM", "xterm")
+
+	var buf bytes.Buffer
+	tests := []struct {
+		mode ColorMode
+		want bool
+	}{
+		{ColorAlways, true},
+		{ColorNever, false},
+		{ColorAuto, false}, // not a terminal
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.Enabled(&buf); got != tt.want {
+			t.Errorf("%q.Enabled() = %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+
+	t.Setenv(skyplugin.EnvNoColor, "1")
+	if !ColorAlways.Enabled(&buf) {
+		t.Error("SKY_NO_COLOR disabled --color=always")
+	}
+}
+
+func TestPaint(t *testing.T) {
+	if got := Paint(false, Red, "error"); got != "error" {
+		t.Errorf("Paint(false) = %q", got)
+	}
+	if got, want := Paint(true, Red, "error"), "\033[31merror\033[0m"; got != want {
+		t.Errorf("Paint(true) = %q, want %q", got, want)
+	}
+}
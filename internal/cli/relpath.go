@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+// PathDisplay rewrites file paths for display in tool output, so that
+// reports read the same no matter where the tool was run from or how its
+// paths were spelled. The zero value shows paths as given.
+type PathDisplay struct {
+	root string
+}
+
+// RelativeToWorkspace returns a PathDisplay that shows paths relative to the
+// workspace root: SKY_WORKSPACE_ROOT when set (sky sets it for the tools it
+// runs), or the current directory otherwise.
+//
+// Example:
+//
+//	display := cli.RelativeToWorkspace()
+//	cli.Writef(stdout, "%s:%d: %s\n", display.Path(file), line, msg)
+func RelativeToWorkspace() PathDisplay {
+	if root := os.Getenv(skyplugin.EnvWorkspaceRoot); root != "" {
+		return RelativeTo(root)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return PathDisplay{}
+	}
+	return RelativeTo(cwd)
+}
+
+// RelativeTo returns a PathDisplay that shows paths relative to root.
+func RelativeTo(root string) PathDisplay {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return PathDisplay{root: root}
+}
+
+// Path returns p as it should be displayed. Relative paths are taken from
+// the current directory. Paths outside the root, and pseudo-paths such as
+// "<stdin>", are returned unchanged. Results use forward slashes.
+func (d PathDisplay) Path(p string) string {
+	if d.root == "" || p == "" || p == "-" || strings.HasPrefix(p, "<") {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	rel, ok := relWithin(d.root, abs)
+	if !ok {
+		// The root or the path may be spelled through a symlink (such as
+		// /tmp on macOS); compare the resolved forms before giving up.
+		root, rootErr := filepath.EvalSymlinks(d.root)
+		resolved, pathErr := filepath.EvalSymlinks(abs)
+		if rootErr != nil || pathErr != nil {
+			return p
+		}
+		if rel, ok = relWithin(root, resolved); !ok {
+			return p
+		}
+	}
+	return filepath.ToSlash(rel)
+}
+
+// relWithin returns target relative to root if target is root or below it.
+func relWithin(root, target string) (string, bool) {
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+func TestPathDisplay(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "pkg")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	display := RelativeTo(root)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(root, "pkg", "BUILD.bazel"), "pkg/BUILD.bazel"},
+		{"defs.bzl", "pkg/defs.bzl"},
+		{filepath.Join("..", "MODULE.bazel"), "MODULE.bazel"},
+		{root, "."},
+		{filepath.Join(root, "..", "other", "x.bzl"), filepath.Join(root, "..", "other", "x.bzl")},
+		{"<stdin>", "<stdin>"},
+		{"-", "-"},
+	}
+	for _, tt := range tests {
+		if got := display.Path(tt.path); got != tt.want {
+			t.Errorf("Path(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if got := (PathDisplay{}).Path("defs.bzl"); got != "defs.bzl" {
+		t.Errorf("zero PathDisplay changed path to %q", got)
+	}
+}
+
+func TestRelativeToWorkspace(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+
+	t.Setenv(skyplugin.EnvWorkspaceRoot, filepath.Dir(root))
+	if got, want := RelativeToWorkspace().Path("BUILD"), filepath.Base(root)+"/BUILD"; got != want {
+		t.Errorf("with %s set: Path = %q, want %q", skyplugin.EnvWorkspaceRoot, got, want)
+	}
+
+	t.Setenv(skyplugin.EnvWorkspaceRoot, "")
+	if got := RelativeToWorkspace().Path(filepath.Join(root, "BUILD")); got != "BUILD" {
+		t.Errorf("without %s: Path = %q, want %q", skyplugin.EnvWorkspaceRoot, got, "BUILD")
+	}
+}
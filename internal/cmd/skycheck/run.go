@@ -12,8 +12,10 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/albertocavalcante/sky/internal/cli"
 	"github.com/albertocavalcante/sky/internal/starlark/checker"
-	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+	"github.com/albertocavalcante/sky/internal/starlark/classifier"
+	"github.com/albertocavalcante/sky/internal/starlark/pathmatch"
 	"github.com/albertocavalcante/sky/internal/version"
 )
 
@@ -36,6 +38,9 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		jsonFlag    bool
 		versionFlag bool
 		quietFlag   bool
+		relative    bool
+		excludes    pathmatch.Excludes
+		generated   pathmatch.Excludes
 	)
 
 	fs := flag.NewFlagSet("skycheck", flag.ContinueOnError)
@@ -43,6 +48,10 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	fs.BoolVar(&jsonFlag, "json", false, "output diagnostics as JSON")
 	fs.BoolVar(&versionFlag, "version", false, "print version and exit")
 	fs.BoolVar(&quietFlag, "quiet", false, "only output errors, suppress warnings")
+	fs.Var(&excludes, "exclude", "skip files and directories matching this glob when walking directories (repeatable)")
+	fs.Var(&generated, "generated-pattern", "treat files matching this glob as generated and skip them, like files marked \"@generated\" (repeatable)")
+	fs.BoolVar(&relative, "relative", false, "show paths relative to the workspace root ($SKY_WORKSPACE_ROOT, or the current directory)")
+	color := cli.ColorFlag(fs)
 
 	fs.Usage = func() {
 		writeln(stderr, "Usage: skycheck [flags] <files...>")
@@ -61,6 +70,10 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		writeln(stderr, "  skycheck file.star              # Check a single file")
 		writeln(stderr, "  skycheck *.star                 # Check multiple files")
 		writeln(stderr, "  skycheck --json file.star       # Output as JSON")
+		writeln(stderr, "  skycheck --exclude=vendor .     # Skip vendor directories")
+		writeln(stderr, "  skycheck --relative .           # Report workspace-relative paths")
+		writeln(stderr)
+		writeln(stderr, "Files whose header comments contain \"@generated\" are skipped.")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -71,7 +84,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	}
 
 	if versionFlag {
-		writef(stdout, "skycheck %s\n", version.String())
+		version.Write(stdout, "skycheck", jsonFlag)
 		return exitOK
 	}
 
@@ -96,7 +109,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		}
 		// Expand each match (handles directories)
 		for _, match := range matches {
-			expanded, err := expandPath(match)
+			expanded, err := expandPath(match, excludes)
 			if err != nil {
 				writef(stderr, "skycheck: %v\n", err)
 				return exitError
@@ -105,6 +118,13 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		}
 	}
 
+	files = slices.DeleteFunc(files, func(path string) bool {
+		return isGeneratedPath(path, generated)
+	})
+	for _, err := range classifier.InvalidOverrides(files) {
+		writef(stderr, "skycheck: warning: ignoring %v\n", err)
+	}
+
 	if len(files) == 0 {
 		writeln(stderr, "skycheck: no files to check")
 		return exitOK
@@ -139,18 +159,24 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		})
 	}
 
+	var display cli.PathDisplay
+	if relative {
+		display = cli.RelativeToWorkspace()
+	}
+
 	// Output results
 	if jsonFlag {
-		return outputJSON(stdout, result)
+		return outputJSON(stdout, result, display)
 	}
-	return outputText(stdout, result)
+	return outputText(stdout, result, display, color.Enabled(stdout))
 }
 
-func outputText(w io.Writer, result checker.Result) int {
+func outputText(w io.Writer, result checker.Result, display cli.PathDisplay, color bool) int {
 	// Group by file
 	byFile := make(map[string][]checker.Diagnostic)
 	for _, d := range result.Diagnostics {
-		byFile[d.Pos.Filename()] = append(byFile[d.Pos.Filename()], d)
+		file := display.Path(d.Pos.Filename())
+		byFile[file] = append(byFile[file], d)
 	}
 
 	for _, file := range slices.Sorted(maps.Keys(byFile)) {
@@ -158,7 +184,7 @@ func outputText(w io.Writer, result checker.Result) int {
 			severity := strings.ToLower(d.Severity.String())
 			writef(w, "%s:%d:%d: %s: %s [%s]\n",
 				file, d.Pos.Line, d.Pos.Col,
-				severity, d.Message, d.Code)
+				cli.Paint(color, severityStyle(d.Severity), severity), d.Message, d.Code)
 		}
 	}
 
@@ -185,6 +211,18 @@ func outputText(w io.Writer, result checker.Result) int {
 	return exitOK
 }
 
+// severityStyle returns the color a diagnostic's severity is shown in.
+func severityStyle(s checker.Severity) string {
+	switch s {
+	case checker.SeverityError:
+		return cli.Red
+	case checker.SeverityWarning:
+		return cli.Yellow
+	default:
+		return cli.Cyan
+	}
+}
+
 type jsonOutput struct {
 	Files       int              `json:"files"`
 	Errors      int              `json:"errors"`
@@ -201,7 +239,7 @@ type jsonDiagnostic struct {
 	Message  string `json:"message"`
 }
 
-func outputJSON(w io.Writer, result checker.Result) int {
+func outputJSON(w io.Writer, result checker.Result, display cli.PathDisplay) int {
 	out := jsonOutput{
 		Files:       result.FileCount,
 		Errors:      result.ErrorCount(),
@@ -211,7 +249,7 @@ func outputJSON(w io.Writer, result checker.Result) int {
 
 	for _, d := range result.Diagnostics {
 		out.Diagnostics = append(out.Diagnostics, jsonDiagnostic{
-			File:     d.Pos.Filename(),
+			File:     display.Path(d.Pos.Filename()),
 			Line:     int(d.Pos.Line),
 			Column:   int(d.Pos.Col),
 			Severity: strings.ToLower(d.Severity.String()),
@@ -235,9 +273,27 @@ func outputJSON(w io.Writer, result checker.Result) int {
 	return exitOK
 }
 
+// isGeneratedPath reports whether path matches one of the --generated-pattern
+// globs. Patterns match the path relative to the current directory, like
+// --exclude patterns match the path relative to the directory being walked.
+func isGeneratedPath(path string, patterns pathmatch.Excludes) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(cwd, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				path = rel
+			}
+		}
+	}
+	return patterns.Match(path)
+}
+
 // expandPath expands a path to a list of files to check.
-// If path is a directory, it recursively finds all Starlark files.
-func expandPath(path string) ([]string, error) {
+// If path is a directory, it recursively finds all Starlark files,
+// skipping those matching excludes.
+func expandPath(path string, excludes pathmatch.Excludes) ([]string, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
@@ -252,14 +308,20 @@ func expandPath(path string) ([]string, error) {
 		if err != nil {
 			return err
 		}
+		if excludes.MatchIn(path, p) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if d.IsDir() {
 			// Skip hidden directories
-			if strings.HasPrefix(d.Name(), ".") {
+			if strings.HasPrefix(d.Name(), ".") && d.Name() != "." {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-		if filekind.IsStarlarkFile(d.Name()) {
+		if classifier.IsStarlarkPath(p) {
 			files = append(files, p)
 		}
 		return nil
@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -128,6 +129,59 @@ func TestRun_CheckDirectory(t *testing.T) {
 	}
 }
 
+func TestRun_CheckCurrentDirectory(t *testing.T) {
+	dir := t.TempDir()
+	bad := "def foo():\n    return undefined_variable\n"
+	if err := os.WriteFile(filepath.Join(dir, "bad.star"), []byte(bad), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	t.Chdir(dir)
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"."}, nil, &stdout, &stderr)
+
+	// "." must not be skipped as a hidden directory
+	if code != exitError {
+		t.Errorf("RunWithIO(.) returned %d, want %d\nstderr: %s", code, exitError, stderr.String())
+	}
+}
+
+func TestRun_Exclude(t *testing.T) {
+	dir := t.TempDir()
+	vendored := filepath.Join(dir, "third_party", "lib")
+	if err := os.MkdirAll(vendored, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.star"), []byte("x = 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	bad := "def foo():\n    return undefined_variable\n"
+	if err := os.WriteFile(filepath.Join(vendored, "bad.star"), []byte(bad), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no exclude", []string{dir}, exitError},
+		{"directory name", []string{"--exclude", "third_party", dir}, exitOK},
+		{"file glob", []string{"--exclude", "bad.*", dir}, exitOK},
+		{"unrelated", []string{"--exclude", "vendor", dir}, exitError},
+		{"invalid pattern", []string{"--exclude", "[", dir}, exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := RunWithIO(context.Background(), tt.args, nil, &stdout, &stderr)
+			if code != tt.want {
+				t.Errorf("RunWithIO(%v) returned %d, want %d\nstdout: %s\nstderr: %s", tt.args, code, tt.want, stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
 func TestRun_CheckNonexistentFile(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	code := RunWithIO(context.Background(), []string{"/nonexistent/file.star"}, nil, &stdout, &stderr)
@@ -187,3 +241,57 @@ result = add("hello", 42)
 	// The test documents expected behavior
 	_ = code // Result depends on checker strictness
 }
+
+func TestRun_Relative(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "pkg", "invalid.star")
+	if err := os.WriteFile(file, []byte("x = undefined_variable\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	t.Setenv("SKY_WORKSPACE_ROOT", root)
+
+	var stdout, stderr bytes.Buffer
+	RunWithIO(context.Background(), []string{"--relative", file}, nil, &stdout, &stderr)
+	if !strings.HasPrefix(stdout.String(), "pkg/invalid.star:1:") {
+		t.Errorf("expected a workspace-relative path, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	RunWithIO(context.Background(), []string{"--relative", "--json", file}, nil, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), `"file": "pkg/invalid.star"`) {
+		t.Errorf("expected a workspace-relative path in JSON, got:\n%s", stdout.String())
+	}
+}
+
+func TestRun_Generated(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		"marked.star":      "# @generated\nx = undefined_variable\n",
+		"rules.gen.star":   "x = undefined_variable\n",
+		"handwritten.star": "x = 1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--generated-pattern=*.gen.star", "."}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("RunWithIO returned %d, want 0\nstdout: %s\nstderr: %s", code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Checked 2 file(s)") {
+		t.Errorf("expected the pattern match to be skipped, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = RunWithIO(context.Background(), []string{"."}, nil, &stdout, &stderr)
+	if code == 0 || !strings.Contains(stdout.String(), "rules.gen.star") {
+		t.Errorf("expected unmarked files to be checked without --generated-pattern, got %d: %s", code, stdout.String())
+	}
+}
@@ -90,7 +90,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	}
 
 	if versionFlag {
-		writef(stdout, "skycov %s\n", version.String())
+		version.Write(stdout, "skycov", formatFlag == "json")
 		return exitOK
 	}
 
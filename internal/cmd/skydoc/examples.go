@@ -0,0 +1,91 @@
+package skydoc
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+	"github.com/albertocavalcante/sky/internal/starlark/docgen"
+	"github.com/albertocavalcante/sky/internal/starlark/tester"
+)
+
+// checkExamples runs the docstring examples of filename and reports the
+// ones that fail. Each example runs in a fresh thread with the file's
+// globals and the predeclared values of skytest (assert, struct, json, and
+// the native rule stubs of dialect, if set), so an example can check its
+// results with assert.eq. load() statements resolve as in skytest.
+func checkExamples(filename string, opts docgen.Options, dialect string, stdout, stderr io.Writer) int {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		writef(stderr, "skydoc: %v\n", err)
+		return 1
+	}
+	doc, err := docgen.ExtractFile(filename, src, opts)
+	if err != nil {
+		writef(stderr, "skydoc: %v\n", err)
+		return 1
+	}
+
+	predeclared := starlark.StringDict{
+		"assert": tester.NewAssertModule(),
+		"struct": starlark.NewBuiltin("struct", starlarkstruct.Make),
+		"json":   json.Module,
+	}
+	if dialect != "" {
+		stubs, err := tester.DialectBuiltins(dialect)
+		if err != nil {
+			writef(stderr, "skydoc: -builtins-dialect %s: %v\n", dialect, err)
+			return 2
+		}
+		for name, v := range stubs {
+			predeclared[name] = v
+		}
+	}
+	load := tester.MakeLoad(tester.LoadRoot(os.Getenv(plugins.EnvWorkspaceRoot), filename), predeclared)
+
+	thread := &starlark.Thread{Name: filename, Load: load}
+	globals, err := starlark.ExecFile(thread, filename, src, predeclared)
+	if err != nil {
+		writef(stderr, "skydoc: %s\n", errorText(err))
+		return 1
+	}
+
+	env := make(starlark.StringDict, len(predeclared)+len(globals))
+	for _, dict := range []starlark.StringDict{predeclared, globals} {
+		for name, v := range dict {
+			env[name] = v
+		}
+	}
+
+	examples := docgen.Examples(doc)
+	failed := 0
+	for _, ex := range examples {
+		name := fmt.Sprintf("example of %s", ex.Function)
+		thread := &starlark.Thread{Name: name, Load: load}
+		if _, err := starlark.ExecFile(thread, name, ex.Code, env); err != nil {
+			failed++
+			writef(stderr, "%s:%d: %s failed:\n%s\n", filename, ex.Line, name, errorText(err))
+		}
+	}
+
+	if failed > 0 {
+		writef(stdout, "%d of %d example(s) failed\n", failed, len(examples))
+		return 1
+	}
+	writef(stdout, "%d example(s) passed\n", len(examples))
+	return 0
+}
+
+// errorText returns the backtrace of an evaluation error, or the error
+// message otherwise.
+func errorText(err error) string {
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		return evalErr.Backtrace()
+	}
+	return err.Error()
+}
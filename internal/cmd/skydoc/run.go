@@ -8,11 +8,50 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/albertocavalcante/sky/internal/starlark/docgen"
 	"github.com/albertocavalcante/sky/internal/version"
 )
 
+// frontmatterFlag collects repeated -frontmatter key=value flags.
+type frontmatterFlag []docgen.FrontmatterField
+
+func (f *frontmatterFlag) String() string {
+	pairs := make([]string, len(*f))
+	for i, field := range *f {
+		pairs[i] = field.Key + "=" + field.Value
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func (f *frontmatterFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || strings.ContainsAny(key, ": \t") {
+		return fmt.Errorf("want key=value, got %q", value)
+	}
+	*f = append(*f, docgen.FrontmatterField{Key: key, Value: val})
+	return nil
+}
+
+// symbolsFlag collects the comma-separated names of -symbols; it may also
+// be repeated.
+type symbolsFlag []string
+
+func (f *symbolsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *symbolsFlag) Set(value string) error {
+	for name := range strings.SplitSeq(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*f = append(*f, name)
+		}
+	}
+	return nil
+}
+
 // Run executes skydoc with the given arguments.
 // Returns exit code.
 func Run(args []string) int {
@@ -20,7 +59,7 @@ func Run(args []string) int {
 }
 
 // RunWithIO allows custom IO for embedding/testing.
-func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) int {
+func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) int {
 	var (
 		outputFlag  string
 		formatFlag  string
@@ -28,6 +67,11 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		titleFlag   string
 		tocFlag     bool
 		versionFlag bool
+		watchFlag   bool
+		frontmatter frontmatterFlag
+		symbols     symbolsFlag
+		checkFlag   bool
+		dialectFlag string
 	)
 
 	fs := flag.NewFlagSet("skydoc", flag.ContinueOnError)
@@ -35,8 +79,13 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	fs.StringVar(&outputFlag, "o", "", "output file (default: stdout)")
 	fs.StringVar(&formatFlag, "format", "markdown", "output format: markdown, json")
 	fs.BoolVar(&privateFlag, "private", false, "include private symbols (starting with _)")
+	fs.Var(&symbols, "symbols", "document only these comma-separated `names`, private or not; fails if one is not defined")
 	fs.StringVar(&titleFlag, "title", "", "document title (default: filename)")
 	fs.BoolVar(&tocFlag, "toc", true, "include table of contents")
+	fs.Var(&frontmatter, "frontmatter", "add a YAML frontmatter `key=value` to markdown output, with title and generated date (can be specified multiple times)")
+	fs.BoolVar(&watchFlag, "watch", false, "regenerate whenever the input file changes")
+	fs.BoolVar(&checkFlag, "check-examples", false, "run the Example: sections of docstrings instead of generating docs, and fail if one raises an error")
+	fs.StringVar(&dialectFlag, "builtins-dialect", "", "with -check-examples, predeclare stub native rules of a dialect (e.g., bazel)")
 	fs.BoolVar(&versionFlag, "version", false, "print version and exit")
 
 	fs.Usage = func() {
@@ -55,6 +104,10 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		writeln(stderr, "  skydoc -o docs/lib.md lib.star     # Write to file")
 		writeln(stderr, "  skydoc -format json lib.star       # JSON output")
 		writeln(stderr, "  skydoc -private lib.star           # Include private symbols")
+		writeln(stderr, "  skydoc -symbols foo,bar lib.star   # Document only foo and bar")
+		writeln(stderr, "  skydoc -watch -o docs/lib.md lib.star  # Regenerate on every save")
+		writeln(stderr, "  skydoc -frontmatter layout=docs lib.star  # Add YAML frontmatter")
+		writeln(stderr, "  skydoc -check-examples lib.star    # Verify docstring examples run")
 		writeln(stderr)
 		writeln(stderr, "Docstring format:")
 		writeln(stderr, "  def my_func(name, count=1):")
@@ -79,7 +132,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	}
 
 	if versionFlag {
-		writef(stdout, "skydoc %s\n", version.String())
+		version.Write(stdout, "skydoc", formatFlag == "json")
 		return 0
 	}
 
@@ -91,70 +144,106 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 
 	filename := fs.Arg(0)
 
-	// Read source file
-	src, err := os.ReadFile(filename)
-	if err != nil {
+	if dialectFlag != "" && !checkFlag {
+		writeln(stderr, "skydoc: -builtins-dialect requires -check-examples")
+		return 2
+	}
+	if checkFlag {
+		if watchFlag || outputFlag != "" {
+			writeln(stderr, "skydoc: -check-examples cannot be used with -watch or -o")
+			return 2
+		}
+		return checkExamples(filename, docgen.Options{IncludePrivate: privateFlag, Symbols: symbols}, dialectFlag, stdout, stderr)
+	}
+
+	switch formatFlag {
+	case "markdown", "md":
+	case "json":
+		if len(frontmatter) > 0 {
+			writeln(stderr, "skydoc: -frontmatter applies only to markdown output")
+			return 2
+		}
+	default:
+		writef(stderr, "skydoc: unknown format %q (use markdown or json)\n", formatFlag)
+		return 2
+	}
+
+	g := generator{
+		filename: filename,
+		output:   outputFlag,
+		format:   formatFlag,
+		opts: docgen.Options{
+			IncludePrivate: privateFlag,
+			Symbols:        symbols,
+		},
+		mdOpts: docgen.MarkdownOptions{
+			Title:                  titleFlag,
+			IncludeTableOfContents: tocFlag,
+			Frontmatter:            frontmatter,
+		},
+	}
+
+	if watchFlag {
+		return watch(ctx, g, stdout, stderr)
+	}
+
+	if err := g.generate(stdout); err != nil {
 		writef(stderr, "skydoc: %v\n", err)
 		return 1
 	}
+	return 0
+}
 
-	// Extract documentation
-	opts := docgen.Options{
-		IncludePrivate: privateFlag,
+// generator renders the documentation for one file.
+type generator struct {
+	filename string
+	output   string // output file; stdout if empty
+	format   string
+	opts     docgen.Options
+	mdOpts   docgen.MarkdownOptions
+}
+
+// generate reads and documents the file, writing to the output file or to
+// stdout.
+func (g generator) generate(stdout io.Writer) error {
+	// Read source file
+	src, err := os.ReadFile(g.filename)
+	if err != nil {
+		return err
 	}
-	doc, err := docgen.ExtractFile(filename, src, opts)
+
+	// Extract documentation
+	doc, err := docgen.ExtractFile(g.filename, src, g.opts)
 	if err != nil {
-		writef(stderr, "skydoc: %v\n", err)
-		return 1
+		return err
 	}
 
 	// Determine output writer
 	var out io.Writer = stdout
-	if outputFlag != "" {
+	if g.output != "" {
 		// Ensure output directory exists
-		dir := filepath.Dir(outputFlag)
+		dir := filepath.Dir(g.output)
 		if dir != "" && dir != "." {
 			if err := os.MkdirAll(dir, 0755); err != nil {
-				writef(stderr, "skydoc: %v\n", err)
-				return 1
+				return err
 			}
 		}
 
-		f, err := os.Create(outputFlag)
+		f, err := os.Create(g.output)
 		if err != nil {
-			writef(stderr, "skydoc: %v\n", err)
-			return 1
+			return err
 		}
 		defer func() { _ = f.Close() }()
 		out = f
 	}
 
 	// Generate output
-	switch formatFlag {
-	case "markdown", "md":
-		mdOpts := docgen.MarkdownOptions{
-			Title:                  titleFlag,
-			IncludeTableOfContents: tocFlag,
-		}
-		if err := docgen.RenderMarkdown(out, doc, mdOpts); err != nil {
-			writef(stderr, "skydoc: %v\n", err)
-			return 1
-		}
-
-	case "json":
+	if g.format == "json" {
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
-		if err := enc.Encode(doc); err != nil {
-			writef(stderr, "skydoc: %v\n", err)
-			return 1
-		}
-
-	default:
-		writef(stderr, "skydoc: unknown format %q (use markdown or json)\n", formatFlag)
-		return 2
+		return enc.Encode(doc)
 	}
-
-	return 0
+	return docgen.RenderMarkdown(out, doc, g.mdOpts)
 }
 
 // Helper functions for writing output.
@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
 )
 
 func TestRun_Version(t *testing.T) {
@@ -213,6 +216,127 @@ func TestRun_SyntaxError(t *testing.T) {
 	}
 }
 
+func TestRun_Symbols(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lib.star")
+	content := `def keep():
+    """Kept."""
+
+def drop():
+    """Dropped."""
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithIO(context.Background(), []string{"-symbols", "keep", file}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("RunWithIO(-symbols keep) returned %d\nstderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "keep") || strings.Contains(stdout.String(), "drop") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := RunWithIO(context.Background(), []string{"-symbols", "keep,nope", file}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("RunWithIO(-symbols keep,nope) returned %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), `symbol "nope" not found`) {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestRun_CheckExamples(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		return path
+	}
+
+	good := write("good.star", `def double(x):
+    """Doubles x.
+
+    Example:
+        assert.eq(double(2), 4)
+    """
+    return x * 2
+`)
+	var stdout, stderr bytes.Buffer
+	if code := RunWithIO(context.Background(), []string{"-check-examples", good}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("RunWithIO(-check-examples) returned %d\nstderr: %s", code, stderr.String())
+	}
+	if stdout.String() != "1 example(s) passed\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+
+	bad := write("bad.star", `def double(x):
+    """Doubles x.
+
+    Example:
+        assert.eq(double(2), 5)
+    """
+    return x * 2
+`)
+	stdout.Reset()
+	if code := RunWithIO(context.Background(), []string{"-check-examples", bad}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("RunWithIO(-check-examples) returned %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "bad.star:1: example of double failed") || !strings.Contains(stderr.String(), "expected 4 == 5") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+	if stdout.String() != "1 of 1 example(s) failed\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+}
+
+func TestRun_CheckExamplesLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(plugins.EnvWorkspaceRoot, "")
+	files := map[string]string{
+		"MODULE.bazel":    "",
+		"lib/strings.bzl": "def suffix(name):\n    return name + \".cc\"\n",
+		"lib/sources.bzl": "load(\":strings.bzl\", \"suffix\")\n\ndef srcs(name):\n    return [suffix(name)]\n",
+		"rules/macros.bzl": `load("//lib:sources.bzl", "srcs")
+
+def cc_lib(name):
+    """Declares a cc_library.
+
+    Example:
+        cc_lib("foo")
+        assert.eq(native.existing_rule("foo")["srcs"], ["foo.cc"])
+    """
+    native.cc_library(name = name, srcs = srcs(name))
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+	macros := filepath.Join(dir, "rules", "macros.bzl")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-check-examples", "-builtins-dialect", "bazel", macros}
+	if code := RunWithIO(context.Background(), args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("RunWithIO(%v) returned %d\nstderr: %s", args, code, stderr.String())
+	}
+	if stdout.String() != "1 example(s) passed\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := RunWithIO(context.Background(), []string{"-builtins-dialect", "bazel", macros}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("RunWithIO(-builtins-dialect without -check-examples) returned %d, want 2", code)
+	}
+}
+
 func TestRun_NonexistentFile(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	code := RunWithIO(context.Background(), []string{"/nonexistent/file.star"}, nil, &stdout, &stderr)
@@ -221,3 +345,82 @@ func TestRun_NonexistentFile(t *testing.T) {
 		t.Error("RunWithIO(nonexistent file) returned 0, want non-zero")
 	}
 }
+
+func TestRun_Watch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lib.star")
+	out := filepath.Join(dir, "lib.md")
+	if err := os.WriteFile(file, []byte("def first():\n    \"\"\"First function.\"\"\"\n    pass\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	var stdout, stderr bytes.Buffer
+	go func() {
+		done <- RunWithIO(ctx, []string{"-watch", "-o", out, file}, nil, &stdout, &stderr)
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if data, err := os.ReadFile(out); err == nil && strings.Contains(string(data), want) {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("output never contained %q", want)
+	}
+
+	waitFor("first")
+	if err := os.WriteFile(file, []byte("def second():\n    \"\"\"Second function.\"\"\"\n    pass\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	waitFor("second")
+
+	cancel()
+	select {
+	case code := <-done:
+		if code != 0 {
+			t.Errorf("RunWithIO(-watch) returned %d, want 0", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunWithIO(-watch) did not stop after the context was cancelled")
+	}
+}
+
+func TestRun_Frontmatter(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lib.star")
+	if err := os.WriteFile(file, []byte("def f():\n    \"\"\"Doc.\"\"\"\n    pass\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"-frontmatter", "layout=docs", "-frontmatter", "weight=2", file}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("RunWithIO(-frontmatter) returned %d, want 0\nstderr: %s", code, stderr.String())
+	}
+	for _, want := range []string{"---\ntitle: \"lib.star\"\ngenerated: ", "layout: \"docs\"\nweight: 2\n---\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing value", args: []string{"-frontmatter", "layout", file}},
+		{name: "json format", args: []string{"-format", "json", "-frontmatter", "a=b", file}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := RunWithIO(context.Background(), tt.args, nil, &stdout, &stderr); code != 2 {
+				t.Errorf("RunWithIO(%v) returned %d, want 2", tt.args, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package skydoc
+
+import (
+	"context"
+	"io"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long to wait after a change before regenerating, so
+// that the several events of one save trigger a single regeneration.
+const watchDebounce = 100 * time.Millisecond
+
+// watch generates the documentation, then regenerates it each time the input
+// file changes, until ctx is done or the process is interrupted. When writing
+// to stdout, the previous output is cleared first. Errors are reported and
+// watching continues.
+func watch(ctx context.Context, g generator, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	absPath, err := filepath.Abs(g.filename)
+	if err != nil {
+		writef(stderr, "skydoc: %v\n", err)
+		return 1
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		writef(stderr, "skydoc: creating watcher: %v\n", err)
+		return 1
+	}
+	defer func() { _ = watcher.Close() }()
+
+	// Watch the directory rather than the file, so that editors that save
+	// by writing a new file and renaming it over the old one are handled.
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		writef(stderr, "skydoc: watching %s: %v\n", g.filename, err)
+		return 1
+	}
+
+	regenerate := func() {
+		if g.output == "" {
+			writef(stdout, "\033[2J\033[H") // ANSI escape to clear screen and move cursor home
+		}
+		if err := g.generate(stdout); err != nil {
+			writef(stderr, "skydoc: %v\n", err)
+			return
+		}
+		writef(stderr, "skydoc: generated %s at %s\n", g.describeOutput(), time.Now().Format("15:04:05"))
+	}
+
+	regenerate()
+	writef(stderr, "skydoc: watching %s for changes (Ctrl+C to stop)\n", g.filename)
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return 0
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if changed, err := filepath.Abs(event.Name); err != nil || changed != absPath {
+				continue
+			}
+			pending = time.After(watchDebounce)
+
+		case <-pending:
+			pending = nil
+			regenerate()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return 0
+			}
+			writef(stderr, "skydoc: watcher error: %v\n", err)
+		}
+	}
+}
+
+// describeOutput returns where the documentation is written, for messages.
+func (g generator) describeOutput() string {
+	if g.output == "" {
+		return "documentation"
+	}
+	return g.output
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/pmezard/go-difflib/difflib"
 
+	"github.com/albertocavalcante/sky/internal/cli"
 	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 	"github.com/albertocavalcante/sky/internal/starlark/formatter"
+	"github.com/albertocavalcante/sky/internal/starlark/pathmatch"
 )
 
 // compareStdin runs both engines against stdin and writes a divergence
@@ -17,8 +19,8 @@ import (
 // one returns ErrEngineDoesNotSupport, which is treated as a known
 // abstention rather than disagreement). Returns exitNeedsFormat on any
 // real divergence.
-func compareStdin(stdin io.Reader, stdout, stderr io.Writer, kind filekind.Kind) int {
-	src, err := io.ReadAll(stdin)
+func compareStdin(stdin io.Reader, stdout, stderr io.Writer, kind filekind.Kind, maxSize int64) int {
+	src, err := readAllLimited(stdin, maxSize)
 	if err != nil {
 		writef(stderr, "skyfmt: reading stdin: %v\n", err)
 		return exitError
@@ -30,23 +32,28 @@ func compareStdin(stdin io.Reader, stdout, stderr io.Writer, kind filekind.Kind)
 }
 
 // comparePaths walks each path (file or directory) and runs both engines
-// on every Starlark file, accumulating a divergence summary.
+// on every Starlark file, accumulating a divergence summary. Paths in
+// warnings and diff headers are shown through display.
 //
 // Exit code:
 //
 //	exitOK           — every file agreed
 //	exitNeedsFormat  — at least one file diverged
 //	exitError        — IO or unexpected error
-func comparePaths(paths []string, stdout, stderr io.Writer, kind filekind.Kind) int {
+func comparePaths(paths []string, excludes pathmatch.Excludes, display cli.PathDisplay, stdout, stderr io.Writer, kind filekind.Kind, maxSize int64) int {
 	var files []string
 	for _, path := range paths {
-		expanded, err := expandPath(path)
+		expanded, err := expandPath(path, excludes)
 		if err != nil {
 			writef(stderr, "skyfmt: %v\n", err)
 			return exitError
 		}
 		files = append(files, expanded...)
 	}
+	files = skipLargeFiles(files, maxSize, display, stderr)
+	if kind == filekind.KindUnknown {
+		warnInvalidOverrides(files, stderr)
+	}
 	if len(files) == 0 {
 		writeln(stderr, "skyfmt: no files to compare")
 		return exitOK
@@ -61,7 +68,7 @@ func comparePaths(paths []string, stdout, stderr io.Writer, kind filekind.Kind)
 	for _, path := range files {
 		src, err := os.ReadFile(path)
 		if err != nil {
-			writef(stderr, "skyfmt: %s: %v\n", path, err)
+			writef(stderr, "skyfmt: %s: %v\n", display.Path(path), err)
 			errored++
 			continue
 		}
@@ -69,7 +76,7 @@ func comparePaths(paths []string, stdout, stderr io.Writer, kind filekind.Kind)
 		if fileKind == "" || fileKind == filekind.KindUnknown {
 			fileKind = formatter.DetectKind(path)
 		}
-		status := reportCompare(stdout, stderr, path, src, fileKind)
+		status := reportCompare(stdout, stderr, display.Path(path), src, fileKind)
 		switch status {
 		case exitOK:
 			agreed++
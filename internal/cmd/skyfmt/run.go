@@ -10,15 +10,18 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/albertocavalcante/sky/internal/cli"
+	"github.com/albertocavalcante/sky/internal/starlark/classifier"
 	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 	"github.com/albertocavalcante/sky/internal/starlark/formatter"
+	"github.com/albertocavalcante/sky/internal/starlark/pathmatch"
 	"github.com/albertocavalcante/sky/internal/version"
 )
 
 // Exit codes
 const (
 	exitOK          = 0
-	exitNeedsFormat = 1 // --check mode: files need formatting; --engine=compare: engines disagree
+	exitNeedsFormat = 1 // --check or -d --diff-exit-code: files need formatting; --engine=compare: engines disagree
 	exitError       = 2 // error occurred
 )
 
@@ -39,20 +42,35 @@ func RunWithIO(_ context.Context, args []string, stdin io.Reader, stdout, stderr
 	var (
 		writeFlag   bool
 		diffFlag    bool
+		diffExit    bool
 		checkFlag   bool
 		typeFlag    string
 		versionFlag bool
+		jsonFlag    bool
 		engineFlag  string
+		showKind    bool
+		maxSizeFlag string
+		relative    bool
+		configFlag  string
+		excludes    pathmatch.Excludes
 	)
 
 	fs := flag.NewFlagSet("skyfmt", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	fs.BoolVar(&writeFlag, "w", false, "write result to file instead of stdout")
 	fs.BoolVar(&diffFlag, "d", false, "display diff instead of formatted output")
+	fs.BoolVar(&diffExit, "diff-exit-code", false, "with -d, exit with non-zero status if any diff was printed")
 	fs.BoolVar(&checkFlag, "check", false, "exit with non-zero status if files need formatting")
 	fs.StringVar(&typeFlag, "type", "", "file type: build, bzl, workspace, module, default")
 	fs.BoolVar(&versionFlag, "version", false, "print version and exit")
+	fs.BoolVar(&jsonFlag, "json", false, "with -version, print the build information as JSON")
 	fs.StringVar(&engineFlag, "engine", "", "format engine: buildtools (default), cst, or compare")
+	fs.BoolVar(&showKind, "show-kind", false, "print the detected file kind for each path instead of formatting")
+	fs.Var(&excludes, "exclude", "skip files and directories matching this glob when walking directories (repeatable)")
+	fs.StringVar(&maxSizeFlag, "max-file-size", "0", "skip files larger than this size, e.g. 512KB or 16MB (0 for no limit)")
+	fs.StringVar(&configFlag, "config", "", "formatter config file (default: search for "+formatter.ConfigFileName+")")
+	fs.BoolVar(&relative, "relative", false, "show paths relative to the workspace root ($SKY_WORKSPACE_ROOT, or the current directory)")
+	color := cli.ColorFlag(fs)
 
 	fs.Usage = func() {
 		writeln(stderr, "Usage: skyfmt [flags] [path ...]")
@@ -69,6 +87,14 @@ func RunWithIO(_ context.Context, args []string, stdin io.Reader, stdout, stderr
 		writeln(stderr, "  module     MODULE.bazel files")
 		writeln(stderr, "  default    Generic Starlark files")
 		writeln(stderr)
+		writeln(stderr, "  Without --type, kinds come from .skyfiletypes overrides, then file names.")
+		writeln(stderr)
+		writeln(stderr, "Config:")
+		writeln(stderr, "  Options in "+formatter.ConfigFileName+" (or --config) turn off buildifier rewrites:")
+		writeln(stderr, "  reorderLoads, sortLists, sortArguments, formatDocstrings, and disableRewrites.")
+		writeln(stderr, "  They apply to every file kind --type selects; rewrites that only exist for")
+		writeln(stderr, "  BUILD-like files, such as sortArguments, do nothing for other kinds.")
+		writeln(stderr)
 		writeln(stderr, "Engines:")
 		writeln(stderr, "  buildtools  Upstream bazelbuild/buildtools (default, stable)")
 		writeln(stderr, "  cst         Native Roslyn-style stack (opt-in, in migration)")
@@ -83,15 +109,23 @@ func RunWithIO(_ context.Context, args []string, stdin io.Reader, stdout, stderr
 	}
 
 	if versionFlag {
-		writef(stdout, "skyfmt %s\n", version.String())
+		version.Write(stdout, "skyfmt", jsonFlag)
 		return exitOK
 	}
 
 	// Validate flag combinations
+	if showKind && (writeFlag || diffFlag || checkFlag) {
+		writeln(stderr, "skyfmt: --show-kind cannot be combined with -w, -d, or --check")
+		return exitError
+	}
 	if writeFlag && diffFlag {
 		writeln(stderr, "skyfmt: cannot use -w and -d together")
 		return exitError
 	}
+	if diffExit && !diffFlag {
+		writeln(stderr, "skyfmt: --diff-exit-code requires -d")
+		return exitError
+	}
 	if writeFlag && checkFlag {
 		writeln(stderr, "skyfmt: cannot use -w and --check together")
 		return exitError
@@ -107,26 +141,76 @@ func RunWithIO(_ context.Context, args []string, stdin io.Reader, stdout, stderr
 		return exitError
 	}
 
+	engine, err = configureEngine(engine, isCompare, configFlag, stderr)
+	if err != nil {
+		writef(stderr, "skyfmt: %v\n", err)
+		return exitError
+	}
+
+	maxSize, err := parseSize(maxSizeFlag)
+	if err != nil {
+		writef(stderr, "skyfmt: --max-file-size: %v\n", err)
+		return exitError
+	}
+
 	kind := parseTypeFlag(typeFlag)
 	paths := fs.Args()
 
+	if showKind {
+		return showKinds(paths, excludes, stdout, stderr, kind)
+	}
+
+	var display cli.PathDisplay
+	if relative {
+		display = cli.RelativeToWorkspace()
+	}
+
 	// Compare mode runs both engines and reports divergence regardless of
 	// the other flags; it never writes formatted output to stdout (the
 	// divergence report goes to stdout instead).
 	if isCompare {
 		if len(paths) == 0 {
-			return compareStdin(stdin, stdout, stderr, kind)
+			return compareStdin(stdin, stdout, stderr, kind, maxSize)
 		}
-		return comparePaths(paths, stdout, stderr, kind)
+		return comparePaths(paths, excludes, display, stdout, stderr, kind, maxSize)
+	}
+
+	opts := formatOptions{
+		engine:   engine,
+		kind:     kind,
+		maxSize:  maxSize,
+		excludes: excludes,
+		display:  display,
+		write:    writeFlag,
+		diff:     diffFlag,
+		diffExit: diffExit,
+		check:    checkFlag,
+		color:    color.Enabled(stdout),
 	}
 
 	// No paths: read from stdin
 	if len(paths) == 0 {
-		return formatStdinWith(engine, stdin, stdout, stderr, kind, checkFlag, diffFlag)
+		return formatStdin(opts, stdin, stdout, stderr)
 	}
 
 	// Format files
-	return formatPathsWith(engine, paths, stdout, stderr, kind, writeFlag, diffFlag, checkFlag)
+	return formatPaths(opts, paths, stdout, stderr)
+}
+
+// formatOptions holds the settings formatStdin and formatPaths share,
+// resolved from the command line.
+type formatOptions struct {
+	engine   formatter.Engine
+	kind     filekind.Kind // empty to detect per file
+	maxSize  int64         // 0 for no limit
+	excludes pathmatch.Excludes
+	display  cli.PathDisplay
+
+	write    bool // -w: rewrite files in place
+	diff     bool // -d: print a diff instead of the formatted source
+	diffExit bool // --diff-exit-code: with diff, report changes in the exit code
+	check    bool // --check: only report files that need formatting
+	color    bool // color diffs
 }
 
 // resolveEngine maps the -engine flag value to an Engine. Returns
@@ -149,6 +233,28 @@ func resolveEngine(name string) (formatter.Engine, bool, error) {
 	return nil, false, fmt.Errorf("unknown engine %q (known: %s)", name, strings.Join(known, ", "))
 }
 
+// configureEngine applies the formatter config at path, or the
+// .skyfmt.json found from the current directory, to engine. Only the
+// buildtools engine has options: a config passed with --config is an error
+// for other engines, and a discovered one is ignored with a warning.
+func configureEngine(engine formatter.Engine, isCompare bool, path string, stderr io.Writer) (formatter.Engine, error) {
+	config, err := formatter.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if config.IsZero() {
+		return engine, nil
+	}
+	if isCompare || engine.Name() != formatter.Buildtools.Name() {
+		if path != "" {
+			return nil, fmt.Errorf("--config options apply only to the buildtools engine")
+		}
+		writef(stderr, "skyfmt: ignoring %s: its options apply only to the buildtools engine\n", formatter.ConfigFileName)
+		return engine, nil
+	}
+	return formatter.NewBuildtools(config)
+}
+
 func parseTypeFlag(t string) filekind.Kind {
 	switch strings.ToLower(t) {
 	case "build":
@@ -166,25 +272,26 @@ func parseTypeFlag(t string) filekind.Kind {
 	}
 }
 
-func formatStdinWith(engine formatter.Engine, stdin io.Reader, stdout, stderr io.Writer, kind filekind.Kind, checkFlag, diffFlag bool) int {
-	src, err := io.ReadAll(stdin)
+func formatStdin(opts formatOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+	src, err := readAllLimited(stdin, opts.maxSize)
 	if err != nil {
 		writef(stderr, "skyfmt: reading stdin: %v\n", err)
 		return exitError
 	}
 
 	// Use default kind if not specified
+	kind := opts.kind
 	if kind == "" {
 		kind = filekind.KindStarlark
 	}
 
-	formatted, err := engine.Format(src, "<stdin>", kind)
+	formatted, err := opts.engine.Format(src, "<stdin>", kind)
 	if err != nil {
 		writef(stderr, "skyfmt: %v\n", err)
 		return exitError
 	}
 
-	if checkFlag {
+	if opts.check {
 		if !bytes.Equal(src, formatted) {
 			writeln(stderr, "<stdin>")
 			return exitNeedsFormat
@@ -192,10 +299,13 @@ func formatStdinWith(engine formatter.Engine, stdin io.Reader, stdout, stderr io
 		return exitOK
 	}
 
-	if diffFlag {
-		diff := computeDiff("<stdin>", src, formatted)
+	if opts.diff {
+		diff := colorDiff(computeDiff("<stdin>", src, formatted), opts.color)
 		if diff != "" {
 			write(stdout, diff)
+			if opts.diffExit {
+				return exitNeedsFormat
+			}
 		}
 		return exitOK
 	}
@@ -204,18 +314,23 @@ func formatStdinWith(engine formatter.Engine, stdin io.Reader, stdout, stderr io
 	return exitOK
 }
 
-func formatPathsWith(engine formatter.Engine, paths []string, stdout, stderr io.Writer, kind filekind.Kind, writeFlag, diffFlag, checkFlag bool) int {
+func formatPaths(opts formatOptions, paths []string, stdout, stderr io.Writer) int {
+	display := opts.display
 	var files []string
 
 	// Expand paths (including directories)
 	for _, path := range paths {
-		expanded, err := expandPath(path)
+		expanded, err := expandPath(path, opts.excludes)
 		if err != nil {
 			writef(stderr, "skyfmt: %v\n", err)
 			return exitError
 		}
 		files = append(files, expanded...)
 	}
+	files = skipLargeFiles(files, opts.maxSize, display, stderr)
+	if opts.kind == filekind.KindUnknown {
+		warnInvalidOverrides(files, stderr)
+	}
 
 	if len(files) == 0 {
 		writeln(stderr, "skyfmt: no files to format")
@@ -226,10 +341,10 @@ func formatPathsWith(engine formatter.Engine, paths []string, stdout, stderr io.
 	hasError := false
 
 	for _, path := range files {
-		result := formatter.FormatFileWith(engine, path, kind)
+		result := formatter.FormatFileWith(opts.engine, path, opts.kind)
 
 		if result.Err != nil {
-			writef(stderr, "skyfmt: %s: %v\n", path, result.Err)
+			writef(stderr, "skyfmt: %s: %v\n", display.Path(path), result.Err)
 			hasError = true
 			continue
 		}
@@ -240,22 +355,22 @@ func formatPathsWith(engine formatter.Engine, paths []string, stdout, stderr io.
 
 		needsFormat = true
 
-		if checkFlag {
-			writeln(stdout, path)
+		if opts.check {
+			writeln(stdout, display.Path(path))
 			continue
 		}
 
-		if writeFlag {
+		if opts.write {
 			if err := os.WriteFile(path, result.Formatted, 0644); err != nil {
-				writef(stderr, "skyfmt: %s: %v\n", path, err)
+				writef(stderr, "skyfmt: %s: %v\n", display.Path(path), err)
 				hasError = true
 				continue
 			}
 			continue
 		}
 
-		if diffFlag {
-			diff := computeDiff(path, result.Original, result.Formatted)
+		if opts.diff {
+			diff := colorDiff(computeDiff(display.Path(path), result.Original, result.Formatted), opts.color)
 			if diff != "" {
 				write(stdout, diff)
 			}
@@ -263,7 +378,7 @@ func formatPathsWith(engine formatter.Engine, paths []string, stdout, stderr io.
 		}
 
 		// Default: print formatted output
-		writef(stdout, "==> %s <==\n", path)
+		writef(stdout, "==> %s <==\n", display.Path(path))
 		writeBytes(stdout, result.Formatted)
 		writeln(stdout)
 	}
@@ -271,15 +386,25 @@ func formatPathsWith(engine formatter.Engine, paths []string, stdout, stderr io.
 	if hasError {
 		return exitError
 	}
-	if checkFlag && needsFormat {
+	if (opts.check || opts.diffExit) && needsFormat {
 		return exitNeedsFormat
 	}
 	return exitOK
 }
 
+// warnInvalidOverrides warns about each invalid .skyfiletypes file that
+// applies to files, since their file types fall back to name-based
+// detection.
+func warnInvalidOverrides(files []string, stderr io.Writer) {
+	for _, err := range classifier.InvalidOverrides(files) {
+		writef(stderr, "skyfmt: warning: ignoring %v\n", err)
+	}
+}
+
 // expandPath expands a path to a list of files to format.
-// If path is a directory, it recursively finds all Starlark files.
-func expandPath(path string) ([]string, error) {
+// If path is a directory, it recursively finds all Starlark files,
+// skipping those matching excludes.
+func expandPath(path string, excludes pathmatch.Excludes) ([]string, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
@@ -294,6 +419,12 @@ func expandPath(path string) ([]string, error) {
 		if err != nil {
 			return err
 		}
+		if excludes.MatchIn(path, p) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if d.IsDir() {
 			// Skip hidden directories
 			if strings.HasPrefix(d.Name(), ".") && d.Name() != "." {
@@ -301,7 +432,7 @@ func expandPath(path string) ([]string, error) {
 			}
 			return nil
 		}
-		if filekind.IsStarlarkFile(d.Name()) {
+		if classifier.IsStarlarkPath(p) {
 			files = append(files, p)
 		}
 		return nil
@@ -336,6 +467,33 @@ func computeDiff(path string, original, formatted []byte) string {
 	return buf.String()
 }
 
+// colorDiff colors the removed, added, and hunk header lines of diff when
+// color is set.
+func colorDiff(diff string, color bool) string {
+	if !color || diff == "" {
+		return diff
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+		var style string
+		switch {
+		case i < 2: // the ---/+++ file header
+			style = cli.Bold
+		case strings.HasPrefix(text, "@@"):
+			style = cli.Cyan
+		case strings.HasPrefix(text, "-"):
+			style = cli.Red
+		case strings.HasPrefix(text, "+"):
+			style = cli.Green
+		default:
+			continue
+		}
+		lines[i] = cli.Paint(true, style, text) + line[len(text):]
+	}
+	return strings.Join(lines, "")
+}
+
 // Helper functions for writing output.
 // Write errors are intentionally ignored because:
 //  1. These functions write to stdout/stderr where there's no reasonable recovery
@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -20,6 +21,18 @@ func TestRun_Version(t *testing.T) {
 	}
 }
 
+func TestRun_VersionJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"-version", "-json"}, nil, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("RunWithIO(-version -json) returned %d, want 0", code)
+	}
+	if !strings.HasPrefix(stdout.String(), `{"version":`) {
+		t.Errorf("version output = %q, want a JSON object", stdout.String())
+	}
+}
+
 func TestRun_Help(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	code := RunWithIO(context.Background(), []string{"-help"}, nil, &stdout, &stderr)
@@ -161,6 +174,88 @@ func TestRun_DiffMode(t *testing.T) {
 	}
 }
 
+func TestColorDiff(t *testing.T) {
+	diff := computeDiff("a.star", []byte("x=1\n"), []byte("x = 1\n"))
+	if got := colorDiff(diff, false); got != diff {
+		t.Errorf("colorDiff(false) changed the diff:\n%s", got)
+	}
+	got := colorDiff(diff, true)
+	for _, want := range []string{"\033[1m--- a.star\033[0m\n", "\033[31m-x=1\033[0m\n", "\033[32m+x = 1\033[0m\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("colorDiff(true) missing %q:\n%q", want, got)
+		}
+	}
+}
+
+func TestRun_DiffExitCode(t *testing.T) {
+	dir := t.TempDir()
+	dirtyFile := filepath.Join(dir, "dirty.star")
+	if err := os.WriteFile(dirtyFile, []byte("def foo():\n  return   1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	cleanFile := filepath.Join(dir, "clean.star")
+	if err := os.WriteFile(cleanFile, []byte("def foo():\n    return 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantDiff bool
+	}{
+		{name: "dirty file", args: []string{"-d", "--diff-exit-code", dirtyFile}, wantCode: exitNeedsFormat, wantDiff: true},
+		{name: "clean file", args: []string{"-d", "--diff-exit-code", cleanFile}, wantCode: exitOK},
+		{name: "dirty stdin", args: []string{"-d", "--diff-exit-code"}, stdin: "x  =  1\n", wantCode: exitNeedsFormat, wantDiff: true},
+		{name: "without -d", args: []string{"--diff-exit-code", dirtyFile}, wantCode: exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := RunWithIO(context.Background(), tt.args, bytes.NewBufferString(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("RunWithIO(%q) returned %d, want %d\nstderr: %s", tt.args, code, tt.wantCode, stderr.String())
+			}
+			if got := stdout.Len() > 0; got != tt.wantDiff {
+				t.Errorf("RunWithIO(%q) printed diff = %v, want %v: %q", tt.args, got, tt.wantDiff, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_Relative(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "pkg", "dirty.star")
+	if err := os.WriteFile(file, []byte("x  =  1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	t.Setenv("SKY_WORKSPACE_ROOT", root)
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "check", args: []string{"--relative", "--check"}, want: "pkg/dirty.star\n"},
+		{name: "diff", args: []string{"--relative", "-d"}, want: "--- pkg/dirty.star\n"},
+		{name: "print", args: []string{"--relative"}, want: "==> pkg/dirty.star <==\n"},
+		{name: "absolute by default", args: []string{"--check"}, want: file + "\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			RunWithIO(context.Background(), append(tc.args, file), nil, &stdout, &stderr)
+			if !strings.HasPrefix(stdout.String(), tc.want) {
+				t.Errorf("expected output to start with %q, got:\n%s", tc.want, stdout.String())
+			}
+		})
+	}
+}
+
 func TestRun_FormatMultipleFiles(t *testing.T) {
 	dir := t.TempDir()
 
@@ -221,3 +316,55 @@ func TestRun_SyntaxError(t *testing.T) {
 		t.Error("RunWithIO(syntax error) returned 0, want non-zero")
 	}
 }
+
+func TestRun_Config(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "fmt.json")
+	if err := os.WriteFile(config, []byte(`{"reorderLoads": false}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := "x = 1\n\nload(\"//b:defs.bzl\", \"z\", \"a\")\n"
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--config", config, "--type", "build"}, strings.NewReader(input), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("RunWithIO(--config) returned %d\nstderr: %s", code, stderr.String())
+	}
+	if stdout.String() != input {
+		t.Errorf("reorderLoads=false still reordered loads:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithIO(context.Background(), []string{"--config", config, "--engine", "cst"}, strings.NewReader(input), &stdout, &stderr)
+	if code != exitError || !strings.Contains(stderr.String(), "only to the buildtools engine") {
+		t.Errorf("--config with -engine=cst returned %d, stderr: %s", code, stderr.String())
+	}
+
+	if err := os.WriteFile(config, []byte(`{"indent": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	code = RunWithIO(context.Background(), []string{"--config", config}, strings.NewReader(input), &stdout, &stderr)
+	if code != exitError || !strings.Contains(stderr.String(), `unknown field "indent"`) {
+		t.Errorf("unsupported option returned %d, stderr: %s", code, stderr.String())
+	}
+}
+
+func TestRun_ConfigDiscovered(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".skyfmt.json"), []byte(`{"reorderLoads": false}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(root, "pkg"))
+
+	input := "x = 1\n\nload(\"//b:defs.bzl\", \"z\", \"a\")\n"
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--type", "build"}, strings.NewReader(input), &stdout, &stderr)
+	if code != exitOK || stdout.String() != input {
+		t.Errorf("discovered config not applied (exit %d):\n%s\nstderr: %s", code, stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,67 @@
+package skyfmt
+
+import (
+	"io"
+	"strings"
+
+	"github.com/albertocavalcante/sky/internal/starlark/classifier"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+	"github.com/albertocavalcante/sky/internal/starlark/pathmatch"
+)
+
+// showKinds prints the file kind skyfmt would use for each input instead of
+// formatting. Directories are expanded the same way as in format mode.
+//
+// Output is one "path: kind" line per file. When the kind comes from a
+// .skyfiletypes override, the overrides file is appended in parentheses so
+// users can see which rule applied. An explicit --type wins over both
+// overrides and name-based detection, matching format mode.
+func showKinds(paths []string, excludes pathmatch.Excludes, stdout, stderr io.Writer, kind filekind.Kind) int {
+	if len(paths) == 0 {
+		if kind == "" {
+			kind = filekind.KindStarlark
+		}
+		writef(stdout, "<stdin>: %s\n", kindName(kind))
+		return exitOK
+	}
+
+	var files []string
+	for _, path := range paths {
+		expanded, err := expandPath(path, excludes)
+		if err != nil {
+			writef(stderr, "skyfmt: %v\n", err)
+			return exitError
+		}
+		files = append(files, expanded...)
+	}
+
+	if kind == "" {
+		warnInvalidOverrides(files, stderr)
+	}
+	cls := classifier.New()
+	for _, path := range files {
+		if kind != "" {
+			writef(stdout, "%s: %s\n", path, kindName(kind))
+			continue
+		}
+
+		classification, err := cls.Classify(path)
+		if err != nil {
+			writef(stdout, "%s: %s\n", path, kindName(filekind.KindUnknown))
+			continue
+		}
+		if classification.ConfigPath != "" {
+			writef(stdout, "%s: %s (%s)\n", path, kindName(classification.FileKind), classification.ConfigPath)
+			continue
+		}
+		writef(stdout, "%s: %s\n", path, kindName(classification.FileKind))
+	}
+	return exitOK
+}
+
+// kindName returns the lowercase name used by --type for kinds it accepts
+// (build, bzl, workspace, module, starlark), and the lowercase kind
+// otherwise.
+func kindName(kind filekind.Kind) string {
+	return strings.ToLower(kind.String())
+}
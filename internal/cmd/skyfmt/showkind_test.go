@@ -0,0 +1,100 @@
+package skyfmt
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShowKind(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"BUILD.bazel":   "x = 1\n",
+		"defs.bzl":      "x = 1\n",
+		"rules.tpl":     "x = 1\n",
+		".skyfiletypes": "*.tpl bzl\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--show-kind", dir}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("RunWithIO(--show-kind) returned %d, want 0\nstderr: %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		filepath.Join(dir, "BUILD.bazel") + ": build\n",
+		filepath.Join(dir, "defs.bzl") + ": bzl\n",
+		filepath.Join(dir, "rules.tpl") + ": bzl (" + filepath.Join(dir, ".skyfiletypes") + ")\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\ngot:\n%s", want, out)
+		}
+	}
+}
+
+func TestShowKind_InvalidOverrides(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rules.tpl":     "x = 1\n",
+		"sub/defs.bzl":  "x = 1\n",
+		".skyfiletypes": "*.tpl bzl\n*.cfg nonsense\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--show-kind", filepath.Join(dir, "rules.tpl"), filepath.Join(dir, "sub")}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("RunWithIO(--show-kind) returned %d, want 0\nstderr: %s", code, stderr.String())
+	}
+
+	// The invalid file is ignored, with one warning saying why.
+	want := "skyfmt: warning: ignoring " + filepath.Join(dir, ".skyfiletypes") + `: line 2: unknown file kind "nonsense"` + "\n"
+	if got := stderr.String(); got != want {
+		t.Errorf("stderr = %q, want %q", got, want)
+	}
+	if want := filepath.Join(dir, "rules.tpl") + ": unknown\n"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("output missing %q\ngot:\n%s", want, stdout.String())
+	}
+}
+
+func TestShowKind_TypeOverride(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "BUILD.bazel")
+	if err := os.WriteFile(file, []byte("x = 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--show-kind", "--type=bzl", file}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("RunWithIO(--show-kind --type=bzl) returned %d, want 0\nstderr: %s", code, stderr.String())
+	}
+	if got, want := stdout.String(), file+": bzl\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestShowKind_RejectsWrite(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--show-kind", "-w", "x.star"}, nil, &stdout, &stderr)
+	if code != exitError {
+		t.Errorf("RunWithIO(--show-kind -w) returned %d, want %d", code, exitError)
+	}
+}
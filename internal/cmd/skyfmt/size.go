@@ -0,0 +1,79 @@
+package skyfmt
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/albertocavalcante/sky/internal/cli"
+)
+
+// parseSize parses a byte size such as "512", "64KB", "16MB" or "1GB".
+// Units are binary (1KB = 1024 bytes) and case-insensitive; "0" means no
+// limit.
+func parseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(str, unit.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (want e.g. 512KB, 16MB, or 0 for no limit)", s)
+	}
+	if n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return n * multiplier, nil
+}
+
+// skipLargeFiles drops files larger than maxSize, warning on stderr for each.
+// A maxSize of 0 disables the check. Files that cannot be stat'ed are kept so
+// the formatter reports the underlying error.
+func skipLargeFiles(files []string, maxSize int64, display cli.PathDisplay, stderr io.Writer) []string {
+	if maxSize <= 0 {
+		return files
+	}
+	kept := make([]string, 0, len(files))
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err == nil && info.Size() > maxSize {
+			writef(stderr, "skyfmt: skipping %s: %d bytes exceeds --max-file-size (%d bytes)\n", display.Path(path), info.Size(), maxSize)
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept
+}
+
+// readAllLimited reads r, failing if it holds more than maxSize bytes.
+// A maxSize of 0 disables the check.
+func readAllLimited(r io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("input exceeds --max-file-size (%d bytes)", maxSize)
+	}
+	return data, nil
+}
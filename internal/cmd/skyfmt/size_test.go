@@ -0,0 +1,118 @@
+package skyfmt
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "0", want: 0},
+		{input: "512", want: 512},
+		{input: "512B", want: 512},
+		{input: "64KB", want: 64 << 10},
+		{input: "16mb", want: 16 << 20},
+		{input: "1 GB", want: 1 << 30},
+		{input: "", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "10XB", wantErr: true},
+		{input: "99999999999G", wantErr: true},
+		{input: "8589934592GB", wantErr: true},
+		{input: "8589934591GB", want: 8589934591 << 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_MaxFileSizeSkipsLargeFiles(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.star")
+	large := filepath.Join(dir, "large.star")
+	if err := os.WriteFile(small, []byte("x  =  1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(large, []byte(strings.Repeat("x  =  1\n", 512)), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--check", "--max-file-size=1KB", dir}, nil, &stdout, &stderr)
+
+	// The large file is skipped, so only the small one is reported.
+	if code != exitNeedsFormat {
+		t.Errorf("RunWithIO(--check --max-file-size) returned %d, want %d\nstderr: %s", code, exitNeedsFormat, stderr.String())
+	}
+	if got := stdout.String(); got != small+"\n" {
+		t.Errorf("stdout = %q, want only %q", got, small)
+	}
+	if !strings.Contains(stderr.String(), "skipping "+large) {
+		t.Errorf("stderr = %q, want skip warning for %s", stderr.String(), large)
+	}
+
+	// There is no limit by default, so --check covers every file.
+	stdout.Reset()
+	stderr.Reset()
+	RunWithIO(context.Background(), []string{"--check", dir}, nil, &stdout, &stderr)
+	if got, want := stdout.String(), large+"\n"+small+"\n"; got != want {
+		t.Errorf("without --max-file-size: stdout = %q, want %q", got, want)
+	}
+	if strings.Contains(stderr.String(), "skipping") {
+		t.Errorf("without --max-file-size: stderr = %q, want no skip warning", stderr.String())
+	}
+}
+
+func TestRun_MaxFileSizeCompareRelative(t *testing.T) {
+	root := t.TempDir()
+	large := filepath.Join(root, "pkg", "large.star")
+	if err := os.MkdirAll(filepath.Dir(large), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, []byte(strings.Repeat("x = 1\n", 512)), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	t.Setenv("SKY_WORKSPACE_ROOT", root)
+
+	var stdout, stderr bytes.Buffer
+	RunWithIO(context.Background(), []string{"-engine=compare", "--relative", "--max-file-size=1KB", large}, nil, &stdout, &stderr)
+	if !strings.Contains(stderr.String(), "skipping pkg/large.star:") {
+		t.Errorf("stderr = %q, want skip warning for pkg/large.star", stderr.String())
+	}
+}
+
+func TestRun_MaxFileSizeStdin(t *testing.T) {
+	stdin := bytes.NewBufferString(strings.Repeat("x = 1\n", 100))
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--max-file-size=64"}, stdin, &stdout, &stderr)
+
+	if code != exitError {
+		t.Errorf("RunWithIO(stdin over limit) returned %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stderr.String(), "exceeds --max-file-size") {
+		t.Errorf("stderr = %q, want size error", stderr.String())
+	}
+}
+
+func TestRun_MaxFileSizeInvalid(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--max-file-size=lots", "x.star"}, nil, &stdout, &stderr)
+	if code != exitError {
+		t.Errorf("RunWithIO(--max-file-size=lots) returned %d, want %d", code, exitError)
+	}
+}
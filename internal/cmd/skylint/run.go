@@ -2,14 +2,20 @@ package skylint
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/albertocavalcante/sky/internal/cli"
 	"github.com/albertocavalcante/sky/internal/starlark/linter"
 	"github.com/albertocavalcante/sky/internal/starlark/linter/buildtools"
+	"github.com/albertocavalcante/sky/internal/starlark/pathmatch"
 	"github.com/albertocavalcante/sky/internal/version"
 )
 
@@ -31,30 +37,41 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 	var (
 		enableFlag         string
 		disableFlag        string
+		onlyCategoryFlag   string
 		formatFlag         string
 		configFlag         string
 		warningsAsErrors   bool
+		maxWarnings        int
 		listRulesFlag      bool
 		listCategoriesFlag bool
 		explainFlag        string
 		versionFlag        bool
 		fixFlag            bool
 		diffFlag           bool
+		generateConfigFlag bool
+		relativeFlag       bool
+		excludes           pathmatch.Excludes
 	)
 
 	fs := flag.NewFlagSet("skylint", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	fs.StringVar(&enableFlag, "enable", "", "enable rules (comma-separated, supports 'all' and categories)")
 	fs.StringVar(&disableFlag, "disable", "", "disable rules (comma-separated, supports patterns like 'native-*')")
-	fs.StringVar(&formatFlag, "format", "text", "output format: text, compact, json, github")
+	fs.StringVar(&onlyCategoryFlag, "only-category", "", "run only rules in these categories (comma-separated, see --list-categories)")
+	fs.StringVar(&formatFlag, "format", "text", "output format: "+strings.Join(linter.ReporterNames(), ", "))
 	fs.StringVar(&configFlag, "config", "", "config file path (default: search for .skylint.json)")
 	fs.BoolVar(&warningsAsErrors, "warnings-as-errors", false, "treat warnings as errors")
+	fs.IntVar(&maxWarnings, "max-warnings", -1, "fail if more than N warnings are found (-1 for unlimited)")
 	fs.BoolVar(&listRulesFlag, "list-rules", false, "list all available rules")
 	fs.BoolVar(&listCategoriesFlag, "list-categories", false, "list all rule categories")
 	fs.StringVar(&explainFlag, "explain", "", "show detailed explanation for a rule")
+	fs.BoolVar(&generateConfigFlag, "generate-config", false, "write a .skylint.json listing every rule (to --config if set, '-' for stdout) and exit")
+	fs.Var(&excludes, "exclude", "skip files and directories matching this glob when walking directories (repeatable)")
 	fs.BoolVar(&versionFlag, "version", false, "print version and exit")
 	fs.BoolVar(&fixFlag, "fix", false, "automatically fix issues where possible")
 	fs.BoolVar(&diffFlag, "diff", false, "show diff of fixes without applying (use with --fix)")
+	color := cli.ColorFlag(fs)
+	fs.BoolVar(&relativeFlag, "relative", false, "show paths relative to the workspace root ($SKY_WORKSPACE_ROOT, or the current directory)")
 
 	fs.Usage = func() {
 		writeln(stderr, "Usage: skylint [flags] path ...")
@@ -69,10 +86,15 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 		writeln(stderr, "  skylint ./...                    # Lint all files recursively")
 		writeln(stderr, "  skylint --enable=all .           # Enable all rules")
 		writeln(stderr, "  skylint --disable=native-* .     # Disable native-* rules")
+		writeln(stderr, "  skylint --only-category=style .  # Run only style rules")
+		writeln(stderr, "  skylint --max-warnings=10 .      # Fail on more than 10 warnings")
+		writeln(stderr, "  skylint --exclude=third_party .  # Skip third_party directories")
+		writeln(stderr, "  skylint --relative .             # Report workspace-relative paths")
 		writeln(stderr, "  skylint --fix .                  # Fix issues automatically")
 		writeln(stderr, "  skylint --fix --diff .           # Preview fixes as diff")
 		writeln(stderr, "  skylint --list-rules             # List all available rules")
 		writeln(stderr, "  skylint --explain=load           # Explain the 'load' rule")
+		writeln(stderr, "  skylint --generate-config        # Scaffold a .skylint.json")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -83,10 +105,15 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 	}
 
 	if versionFlag {
-		writef(stdout, "skylint %s\n", version.String())
+		version.Write(stdout, "skylint", formatFlag == "json")
 		return exitOK
 	}
 
+	if maxWarnings < -1 {
+		writef(stderr, "skylint: --max-warnings must be -1 (unlimited) or a non-negative number, got %d\n", maxWarnings)
+		return exitError
+	}
+
 	// Create registry and register all buildtools rules
 	registry := linter.NewRegistry()
 	if err := registry.Register(buildtools.AllRules()...); err != nil {
@@ -109,6 +136,11 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 		return explainRule(stdout, stderr, registry, explainFlag)
 	}
 
+	// Handle --generate-config
+	if generateConfigFlag {
+		return generateConfig(stdout, stderr, registry, configFlag)
+	}
+
 	// Load configuration file
 	config, err := linter.LoadConfig(configFlag)
 	if err != nil {
@@ -122,6 +154,14 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 		return exitError
 	}
 
+	// Apply --only-category (overrides config file; --enable/--disable still apply on top)
+	if onlyCategoryFlag != "" {
+		if err := registry.OnlyCategories(parseCommaSeparated(onlyCategoryFlag)...); err != nil {
+			writef(stderr, "skylint: --only-category: %v\n", err)
+			return exitError
+		}
+	}
+
 	// Apply enable/disable flags (these override config file)
 	if enableFlag != "" {
 		rules := parseCommaSeparated(enableFlag)
@@ -154,11 +194,20 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 
 	// Create driver and run linter
 	driver := linter.NewDriver(registry)
+	driver.SetExcludes(excludes)
 	result, err := driver.Run(ctx, paths)
 	if err != nil {
 		writef(stderr, "skylint: %v\n", err)
 		return exitError
 	}
+	for _, err := range result.InvalidOverrides {
+		writef(stderr, "skylint: warning: ignoring %v\n", err)
+	}
+
+	var display cli.PathDisplay
+	if relativeFlag {
+		display = cli.RelativeToWorkspace()
+	}
 
 	// Handle --fix mode
 	if fixFlag {
@@ -188,6 +237,7 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 				// Show diff without applying
 				for _, fr := range fixResults {
 					if fr.HasChanges() {
+						fr.Path = display.Path(fr.Path)
 						writef(stdout, "%s", fr.Diff())
 					}
 				}
@@ -196,6 +246,7 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 					writef(stderr, " (%d skipped due to conflicts)", totalSkipped)
 				}
 				writeln(stderr)
+				writeRuleBreakdown(stderr, fixResults, "to fix")
 			} else {
 				// Apply fixes
 				if err := linter.WriteFixResults(fixResults); err != nil {
@@ -207,28 +258,29 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 					writef(stderr, " (%d skipped due to conflicts)", totalSkipped)
 				}
 				writeln(stderr)
+				writeRuleBreakdown(stderr, fixResults, "fixed")
 			}
 		}
 		return exitOK
 	}
 
-	// Create reporter based on format
-	var reporter linter.Reporter
-	switch formatFlag {
-	case "text":
-		reporter = linter.NewTextReporter()
-	case "compact":
-		reporter = linter.NewCompactReporter()
-	case "json":
-		reporter = linter.NewJSONReporter()
-	case "github":
-		reporter = linter.NewGitHubReporter()
-	default:
-		writef(stderr, "skylint: unknown format: %s\n", formatFlag)
+	// Create reporter based on format (built-ins plus linter.RegisterReporter)
+	reporter, err := linter.NewReporter(formatFlag)
+	if err != nil {
+		writef(stderr, "skylint: %v\n", err)
 		return exitError
 	}
+	switch r := reporter.(type) {
+	case *linter.TextReporter:
+		r.ColorOutput = color.Enabled(stdout)
+	case *linter.CompactReporter:
+		r.ColorOutput = color.Enabled(stdout)
+	}
 
 	// Report results
+	if relativeFlag {
+		displayPaths(result, display)
+	}
 	if err := reporter.Report(stdout, result); err != nil {
 		writef(stderr, "skylint: failed to report results: %v\n", err)
 		return exitError
@@ -242,6 +294,11 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 	if warningsAsErrors && result.HasWarnings() {
 		return exitError
 	}
+	// Fail when the warning budget is exceeded
+	if maxWarnings >= 0 && result.WarningCount() > maxWarnings {
+		writef(stderr, "skylint: too many warnings (%d, max %d)\n", result.WarningCount(), maxWarnings)
+		return exitError
+	}
 	if result.HasWarnings() {
 		return exitWarning
 	}
@@ -249,6 +306,47 @@ func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr i
 	return exitOK
 }
 
+// displayPaths rewrites the paths in result for display. The findings are
+// only reported afterwards, so they need not keep the paths they were read
+// from.
+func displayPaths(result *linter.Result, display cli.PathDisplay) {
+	for i := range result.Findings {
+		result.Findings[i].FilePath = display.Path(result.Findings[i].FilePath)
+	}
+	for i := range result.Errors {
+		result.Errors[i].Path = display.Path(result.Errors[i].Path)
+	}
+}
+
+// writeRuleBreakdown prints per-rule fix counts on one line, e.g.
+// "  load: 3 fixed; native-py: 1 skipped". verb describes applied fixes.
+func writeRuleBreakdown(w io.Writer, results []linter.FixResult, verb string) {
+	counts := linter.FixCountsByRule(results)
+	if len(counts) == 0 {
+		return
+	}
+
+	rules := make([]string, 0, len(counts))
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	parts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		c := counts[rule]
+		var stats []string
+		if c.Applied > 0 {
+			stats = append(stats, fmt.Sprintf("%d %s", c.Applied, verb))
+		}
+		if c.Skipped > 0 {
+			stats = append(stats, fmt.Sprintf("%d skipped", c.Skipped))
+		}
+		parts = append(parts, rule+": "+strings.Join(stats, ", "))
+	}
+	writef(w, "  %s\n", strings.Join(parts, "; "))
+}
+
 // listRules outputs all available rules.
 func listRules(w io.Writer, registry *linter.Registry) int {
 	rules := registry.AllRules()
@@ -277,6 +375,46 @@ func listRules(w io.Writer, registry *linter.Registry) int {
 	return exitOK
 }
 
+// generateConfig writes a config listing every rule to path
+// (.skylint.json if empty, stdout if "-"). It refuses to overwrite a file.
+func generateConfig(stdout, stderr io.Writer, registry *linter.Registry, path string) int {
+	data, err := json.MarshalIndent(linter.GenerateConfig(registry), "", "  ")
+	if err != nil {
+		writef(stderr, "skylint: %v\n", err)
+		return exitError
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, _ = stdout.Write(data)
+		return exitOK
+	}
+	if path == "" {
+		path = ".skylint.json"
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		writef(stderr, "skylint: %s already exists; remove it or pass --config to write elsewhere\n", path)
+		return exitError
+	}
+	if err != nil {
+		writef(stderr, "skylint: %v\n", err)
+		return exitError
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		writef(stderr, "skylint: %v\n", err)
+		return exitError
+	}
+	if err := f.Close(); err != nil {
+		writef(stderr, "skylint: %v\n", err)
+		return exitError
+	}
+	writef(stderr, "skylint: wrote %s with %d rules\n", path, len(registry.AllRules()))
+	return exitOK
+}
+
 // listCategories outputs all rule categories.
 func listCategories(w io.Writer, registry *linter.Registry) int {
 	categories := registry.Categories()
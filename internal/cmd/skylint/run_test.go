@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -136,6 +137,33 @@ func TestRun_LintDirectory(t *testing.T) {
 	}
 }
 
+func TestRun_Exclude(t *testing.T) {
+	dir := t.TempDir()
+	vendored := filepath.Join(dir, "third_party")
+	if err := os.MkdirAll(vendored, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.star"), []byte("\"\"\"Module a.\"\"\"\n\nx = 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	issues := "def BadName():\n    x = 1\n    return None\n"
+	if err := os.WriteFile(filepath.Join(vendored, "issues.star"), []byte(issues), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithIO(context.Background(), []string{dir}, nil, &stdout, &stderr); code == 0 {
+		t.Fatal("RunWithIO(directory) returned 0, want non-zero for third_party issues")
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithIO(context.Background(), []string{"--exclude=third_party", dir}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("RunWithIO(--exclude) returned %d, want 0\nstdout: %s\nstderr: %s", code, stdout.String(), stderr.String())
+	}
+}
+
 func TestRun_OutputFormats(t *testing.T) {
 	dir := t.TempDir()
 	file := filepath.Join(dir, "test.star")
@@ -155,3 +183,151 @@ func TestRun_OutputFormats(t *testing.T) {
 		})
 	}
 }
+
+func TestRun_OnlyCategoryUnknown(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "BUILD")
+	if err := os.WriteFile(file, []byte("x = 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--only-category=bogus", file}, nil, &stdout, &stderr)
+
+	if code != exitError {
+		t.Errorf("RunWithIO(--only-category=bogus) returned %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stderr.String(), "unknown categories: bogus") {
+		t.Errorf("stderr = %q, want unknown category error", stderr.String())
+	}
+}
+
+func TestRun_MaxWarnings(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "BUILD")
+	// Two warnings: an unused load and an unused variable.
+	content := "load(\"//a.bzl\", \"unused\")\n\nx = 1\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "unlimited", args: []string{"--max-warnings=-1"}, want: exitWarning},
+		{name: "within budget", args: []string{"--max-warnings=2"}, want: exitWarning},
+		{name: "exceeded", args: []string{"--max-warnings=1"}, want: exitError},
+		{name: "zero", args: []string{"--max-warnings=0"}, want: exitError},
+		{name: "warnings-as-errors wins", args: []string{"--max-warnings=5", "--warnings-as-errors"}, want: exitError},
+		{name: "invalid", args: []string{"--max-warnings=-2"}, want: exitError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append(tc.args, file)
+			code := RunWithIO(context.Background(), args, nil, &stdout, &stderr)
+			if code != tc.want {
+				t.Errorf("RunWithIO(%v) returned %d, want %d\nstderr: %s", tc.args, code, tc.want, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_FixDiffRuleBreakdown(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "BUILD")
+	content := "load(\"//a.bzl\", \"used\", \"unused\")\n\nused()\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--fix", "--diff", file}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("RunWithIO returned %d, want %d\nstderr: %s", code, exitOK, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "load: 1 to fix") {
+		t.Errorf("expected per-rule breakdown, got stderr: %s", stderr.String())
+	}
+
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("--diff must not modify the file, got %q", got)
+	}
+}
+
+func TestRun_Relative(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "pkg", "BUILD")
+	content := "load(\"//a.bzl\", \"used\", \"unused\")\n\nused()\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	t.Setenv("SKY_WORKSPACE_ROOT", root)
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "text", args: []string{"--relative"}, want: "pkg/BUILD:1:"},
+		{name: "json", args: []string{"--relative", "--format=json"}, want: `"path": "pkg/BUILD"`},
+		{name: "fix diff", args: []string{"--relative", "--fix", "--diff"}, want: "--- pkg/BUILD"},
+		{name: "absolute by default", args: nil, want: file + ":1:"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			RunWithIO(context.Background(), append(tc.args, file), nil, &stdout, &stderr)
+			if !strings.Contains(stdout.String(), tc.want) {
+				t.Errorf("expected output to contain %q, got:\n%s", tc.want, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_GenerateConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".skylint.json")
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--generate-config", "--config", path}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("RunWithIO(--generate-config) returned %d, want %d\nstderr: %s", code, exitOK, stderr.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read generated config: %v", err)
+	}
+	for _, want := range []string{`"rules": {`, `"enabled": true`, `"severity": "warning"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected generated config to contain %q", want)
+		}
+	}
+
+	// The generated config is accepted by skylint
+	file := filepath.Join(t.TempDir(), "BUILD.bazel")
+	if err := os.WriteFile(file, []byte("filegroup(name = \"x\")\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	stderr.Reset()
+	if code := RunWithIO(context.Background(), []string{"--config", path, file}, nil, &stdout, &stderr); code == exitError {
+		t.Errorf("linting with the generated config failed:\n%s", stderr.String())
+	}
+
+	// An existing file is not overwritten
+	stderr.Reset()
+	code = RunWithIO(context.Background(), []string{"--generate-config", "--config", path}, nil, &stdout, &stderr)
+	if code != exitError || !strings.Contains(stderr.String(), "already exists") {
+		t.Errorf("expected refusal to overwrite, got code %d and stderr: %s", code, stderr.String())
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"sync"
 
 	"github.com/albertocavalcante/sky/internal/lsp"
+	"github.com/albertocavalcante/sky/internal/starlark/builtins/loader"
 	"github.com/albertocavalcante/sky/internal/version"
 )
 
@@ -26,21 +30,32 @@ func Run(args []string) int {
 // RunWithIO allows custom IO for testing.
 func RunWithIO(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 	var (
-		versionFlag bool
-		verboseFlag bool
+		versionFlag    bool
+		jsonFlag       bool
+		verboseFlag    bool
+		maxDiagnostics int
+		builtinsDir    string
+		socketAddr     string
+		port           int
 	)
 
 	fs := flag.NewFlagSet("skyls", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	fs.BoolVar(&versionFlag, "version", false, "print version and exit")
+	fs.BoolVar(&jsonFlag, "json", false, "with -version, print the build information as JSON")
 	fs.BoolVar(&verboseFlag, "v", false, "verbose logging to stderr")
+	fs.StringVar(&builtinsDir, "builtins-dir", os.Getenv(loader.BuiltinsDirEnv), "directory of builtins proto data files (e.g. bazel_build.pb) overriding the embedded ones (default $"+loader.BuiltinsDirEnv+")")
+	fs.StringVar(&socketAddr, "socket", "", "listen on the TCP address `ADDR` (e.g. 127.0.0.1:9257) instead of using stdio")
+	fs.IntVar(&port, "port", 0, "listen on TCP port `N` of 127.0.0.1 instead of using stdio")
+	fs.IntVar(&maxDiagnostics, "max-diagnostics", lsp.DefaultMaxDiagnostics, "maximum diagnostics published per document (0 = no limit); clients can override it with the maxDiagnostics setting")
 
 	fs.Usage = func() {
 		writeln(stderr, "Usage: skyls [flags]")
 		writeln(stderr)
 		writeln(stderr, "Starlark Language Server Protocol (LSP) implementation.")
 		writeln(stderr)
-		writeln(stderr, "The server communicates over stdio using JSON-RPC 2.0.")
+		writeln(stderr, "The server communicates over stdio using JSON-RPC 2.0, or over")
+		writeln(stderr, "TCP with -socket or -port, serving each connection as a session.")
 		writeln(stderr, "Configure your editor to launch this binary as an LSP server.")
 		writeln(stderr)
 		writeln(stderr, "Features:")
@@ -68,10 +83,18 @@ func RunWithIO(ctx context.Context, args []string, stdin io.Reader, stdout, stde
 	}
 
 	if versionFlag {
-		writef(stdout, "skyls %s\n", version.String())
+		version.Write(stdout, "skyls", jsonFlag)
 		return exitOK
 	}
 
+	if socketAddr != "" && port != 0 {
+		writeln(stderr, "skyls: -socket and -port are mutually exclusive")
+		return exitError
+	}
+	if port != 0 {
+		socketAddr = net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	}
+
 	// Setup logging
 	if verboseFlag {
 		log.SetOutput(stderr)
@@ -80,32 +103,94 @@ func RunWithIO(ctx context.Context, args []string, stdin io.Reader, stdout, stde
 		log.SetOutput(io.Discard)
 	}
 
-	// Create context with cancellation for clean shutdown
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	if builtinsDir != "" {
+		if info, err := os.Stat(builtinsDir); err != nil || !info.IsDir() {
+			writef(stderr, "skyls: builtins directory %s is not a directory\n", builtinsDir)
+			return exitError
+		}
+		log.Printf("skyls: loading builtins from %s", builtinsDir)
+	}
+
+	// Sessions share the builtins, whose data is cached once loaded
+	provider := lsp.NewDefaultProviderWithDir(builtinsDir)
+	newServer := func(onExit func()) *lsp.Server {
+		server := lsp.NewServerWithProvider(onExit, provider)
+		settings := lsp.DefaultSettings()
+		settings.MaxDiagnostics = maxDiagnostics
+		server.SetSettings(settings)
+		return server
+	}
 
-	// Create server
-	server := lsp.NewServer(cancel)
+	if socketAddr != "" {
+		return serveTCP(ctx, socketAddr, newServer, stderr)
+	}
 
-	// Create stdio connection
-	rwc := &stdioConn{
-		Reader: stdin,
-		Writer: stdout,
+	log.Printf("skyls: starting server")
+	if err := serve(ctx, &stdioConn{Reader: stdin, Writer: stdout}, newServer); err != nil {
+		writef(stderr, "skyls: %v\n", err)
+		return exitError
 	}
+	log.Printf("skyls: server stopped")
+	return exitOK
+}
+
+// serve runs one LSP session over rwc until the client exits or
+// disconnects, or ctx is canceled.
+func serve(ctx context.Context, rwc io.ReadWriteCloser, newServer func(onExit func()) *lsp.Server) error {
+	// Create context with cancellation for clean shutdown
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Unblock the session's read once it ends, on exit or server shutdown
+	stop := context.AfterFunc(ctx, func() { _ = rwc.Close() })
+	defer stop()
 
+	server := newServer(cancel)
 	conn := lsp.NewConn(rwc, server)
 	server.SetConn(conn)
 
-	log.Printf("skyls: starting server")
-
-	// Run the server
 	if err := conn.Run(ctx); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// serveTCP listens on addr and serves each connection as its own session,
+// with its own documents and settings, until ctx is canceled. The address
+// is printed so that a client can find a port chosen by the system.
+func serveTCP(ctx context.Context, addr string, newServer func(onExit func()) *lsp.Server, stderr io.Writer) int {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
 		writef(stderr, "skyls: %v\n", err)
 		return exitError
 	}
+	writef(stderr, "skyls: listening on %s\n", ln.Addr())
+	stop := context.AfterFunc(ctx, func() { _ = ln.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return exitOK
+			}
+			writef(stderr, "skyls: %v\n", err)
+			return exitError
+		}
 
-	log.Printf("skyls: server stopped")
-	return exitOK
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { _ = c.Close() }()
+
+			log.Printf("skyls: client %s connected", c.RemoteAddr())
+			if err := serve(ctx, c, newServer); err != nil {
+				log.Printf("skyls: client %s: %v", c.RemoteAddr(), err)
+			}
+			log.Printf("skyls: client %s disconnected", c.RemoteAddr())
+		}()
+	}
 }
 
 // stdioConn wraps stdin/stdout as an io.ReadWriteCloser.
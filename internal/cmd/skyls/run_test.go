@@ -0,0 +1,141 @@
+package skyls
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_SocketAndPortExclusive(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"-socket", "127.0.0.1:0", "-port", "9257"}, nil, &stdout, &stderr)
+	if code != exitError {
+		t.Errorf("RunWithIO(-socket -port) returned %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stderr.String(), "-socket and -port are mutually exclusive") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestRun_Socket(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// serveTCP prints the address it listens on, which the client needs to
+	// find the port the system chose.
+	stderrR, stderrW := io.Pipe()
+	done := make(chan int, 1)
+	go func() {
+		done <- RunWithIO(ctx, []string{"-socket", "127.0.0.1:0"}, nil, io.Discard, stderrW)
+		_ = stderrW.Close()
+	}()
+
+	lines := bufio.NewReader(stderrR)
+	line, err := lines.ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading listen address: %v", err)
+	}
+	addr, ok := strings.CutPrefix(strings.TrimSpace(line), "skyls: listening on ")
+	if !ok {
+		t.Fatalf("unexpected first line %q", line)
+	}
+	go func() { _, _ = io.Copy(io.Discard, lines) }()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial(%s): %v", addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	r := bufio.NewReader(conn)
+
+	send(t, conn, map[string]any{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{}})
+	var init struct {
+		ID     int `json:"id"`
+		Result struct {
+			Capabilities map[string]any `json:"capabilities"`
+		} `json:"result"`
+	}
+	receive(t, r, &init)
+	if init.ID != 1 || len(init.Result.Capabilities) == 0 {
+		t.Errorf("initialize response = %+v, want capabilities for id 1", init)
+	}
+
+	send(t, conn, map[string]any{"jsonrpc": "2.0", "id": 2, "method": "shutdown"})
+	var shutdown struct {
+		ID    int             `json:"id"`
+		Error json.RawMessage `json:"error"`
+	}
+	receive(t, r, &shutdown)
+	if shutdown.ID != 2 || shutdown.Error != nil {
+		t.Errorf("shutdown response = %+v", shutdown)
+	}
+
+	// exit ends the session, and the server closes the connection.
+	send(t, conn, map[string]any{"jsonrpc": "2.0", "method": "exit"})
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Errorf("after exit: read error = %v, want EOF", err)
+	}
+
+	// The listener keeps serving other clients until ctx is canceled.
+	cancel()
+	select {
+	case code := <-done:
+		if code != exitOK {
+			t.Errorf("RunWithIO(-socket) returned %d, want %d", code, exitOK)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not stop after ctx was canceled")
+	}
+}
+
+// send writes msg as an LSP message.
+func send(t *testing.T, w io.Writer, msg any) {
+	t.Helper()
+	body, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+}
+
+// receive reads one LSP message into v.
+func receive(t *testing.T, r *bufio.Reader, v any) {
+	t.Helper()
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading header: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length: "); ok {
+			if length, err = strconv.Atoi(value); err != nil {
+				t.Fatalf("bad Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		t.Fatal("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+}
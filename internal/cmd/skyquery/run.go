@@ -72,7 +72,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	}
 
 	if versionFlag {
-		writef(stdout, "skyquery %s\n", version.String())
+		version.Write(stdout, "skyquery", outputFormat == "json")
 		return exitOK
 	}
 
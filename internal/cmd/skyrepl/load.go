@@ -0,0 +1,74 @@
+package skyrepl
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// loadEntry is a cached result of loading a module.
+type loadEntry struct {
+	globals starlark.StringDict
+	err     error
+}
+
+// makeLoad returns a load function like repl.MakeLoad that also searches
+// loadPath. Labels starting with "//" ("//pkg:file.star" or
+// "//pkg/file.star") resolve against the first directory in loadPath. Other
+// relative names are tried in each directory in order, then relative to the
+// working directory. Modules are cached by resolved path, and load cycles
+// are reported as errors. If maxSteps is positive, each module's execution
+// is limited to that many steps. Each module runs on its own thread, which is
+// added to threads so that canceling the run also stops the module.
+func makeLoad(loadPath []string, maxSteps uint64, threads *threadGroup) func(*starlark.Thread, string) (starlark.StringDict, error) {
+	cache := make(map[string]*loadEntry)
+
+	return func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		path := resolveLoad(loadPath, module)
+		e, ok := cache[path]
+		if e == nil {
+			if ok {
+				// Request for a module that is still being loaded.
+				return nil, fmt.Errorf("cycle in load graph")
+			}
+
+			// Add a placeholder to indicate "load in progress".
+			cache[path] = nil
+
+			// Load it with the same load function.
+			t := &starlark.Thread{Name: "exec " + path, Load: thread.Load}
+			limitSteps(t, maxSteps)
+			threads.add(t)
+			globals, err := starlark.ExecFile(t, path, nil, nil)
+			e = &loadEntry{globals, err}
+			cache[path] = e
+		}
+		return e.globals, e.err
+	}
+}
+
+// resolveLoad returns the file that module refers to.
+func resolveLoad(loadPath []string, module string) string {
+	if label, ok := strings.CutPrefix(module, "//"); ok {
+		rel := filepath.FromSlash(strings.Replace(label, ":", "/", 1))
+		if len(loadPath) == 0 {
+			return rel
+		}
+		return filepath.Join(loadPath[0], rel)
+	}
+	if filepath.IsAbs(module) {
+		return module
+	}
+	for _, dir := range loadPath {
+		candidate := filepath.Join(dir, module)
+		if _, err := os.Stat(candidate); !errors.Is(err, fs.ErrNotExist) {
+			return candidate
+		}
+	}
+	return module
+}
@@ -2,6 +2,7 @@ package skyrepl
 
 import (
 	"context"
+	gojson "encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -20,9 +21,22 @@ import (
 	"go.starlark.net/syntax"
 	"golang.org/x/term"
 
+	"github.com/albertocavalcante/sky/internal/cli"
 	"github.com/albertocavalcante/sky/internal/version"
 )
 
+// stringSliceFlag allows a flag to be specified multiple times.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 // Run executes skyrepl with the given arguments.
 // Returns exit code.
 func Run(args []string) int {
@@ -32,9 +46,14 @@ func Run(args []string) int {
 // RunWithIO allows custom IO for embedding/testing.
 // Note: The REPL mode currently uses os.Stdin directly for terminal detection
 // and interactive input. The stdin parameter is reserved for future use.
-func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) int {
+func RunWithIO(ctx context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) int {
 	var (
 		execExpr    string
+		execProgram string
+		outputFlag  string
+		timeoutFlag gosystime.Duration
+		loadPath    stringSliceFlag
+		maxSteps    uint64
 		preloadFlag string
 		showEnv     bool
 		recursion   bool
@@ -44,10 +63,16 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	fs := flag.NewFlagSet("skyrepl", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	fs.StringVar(&execExpr, "e", "", "evaluate `expr` and exit")
+	fs.StringVar(&execProgram, "c", "", "execute `program` (statements separated by ';' or newlines) and exit with a status from its last expression")
+	fs.StringVar(&outputFlag, "output", "text", "format of the -e/-c result and of evaluation errors: text or json")
+	fs.DurationVar(&timeoutFlag, "timeout", 0, "abort a file, -e, or -c run (including preloads) after this long (0 = no limit)")
+	fs.Uint64Var(&maxSteps, "max-steps", 0, "abort a file, -e, or -c run (including preloads and loads) after this many execution steps (0 = unbounded)")
+	fs.Var(&loadPath, "load-path", "directory to search for load() targets; //-labels resolve against the first (can be specified multiple times)")
 	fs.StringVar(&preloadFlag, "preload", "", "comma-separated files to preload")
 	fs.BoolVar(&showEnv, "showenv", false, "print final environment on exit")
 	fs.BoolVar(&recursion, "recursion", false, "allow recursion and while statements")
 	fs.BoolVar(&versionFlag, "version", false, "print version and exit")
+	color := cli.ColorFlag(fs)
 
 	fs.Usage = func() {
 		writeln(stderr, "Usage: skyrepl [flags] [file]")
@@ -66,7 +91,10 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		writeln(stderr, "  skyrepl                     # Start interactive REPL")
 		writeln(stderr, "  skyrepl script.star         # Execute file")
 		writeln(stderr, "  skyrepl -e '1 + 1'          # Evaluate expression")
+		writeln(stderr, "  skyrepl -c 'x = 3; x > 2'   # Run statements; exit 1 if the last is False")
+		writeln(stderr, "  skyrepl -output json -e 'x'  # Print the result as JSON")
 		writeln(stderr, "  skyrepl -preload lib.star   # Preload file, then start REPL")
+		writeln(stderr, "  skyrepl -load-path . -e 'x' # Resolve load(\"//pkg:defs.star\", ...) from .")
 		writeln(stderr)
 		writeln(stderr, "REPL shortcuts:")
 		writeln(stderr, "  _                           # Value of last expression")
@@ -82,10 +110,28 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	}
 
 	if versionFlag {
-		writef(stdout, "skyrepl %s\n", version.String())
+		version.Write(stdout, "skyrepl", outputFlag == "json")
 		return 0
 	}
 
+	if outputFlag != "text" && outputFlag != "json" {
+		writef(stderr, "skyrepl: invalid -output %q: want text or json\n", outputFlag)
+		return 2
+	}
+
+	if execExpr != "" && execProgram != "" {
+		writeln(stderr, "skyrepl: -e and -c cannot be used together")
+		return 2
+	}
+
+	reportError := func(err error) {
+		if outputFlag == "json" {
+			printErrorJSON(stdout, err)
+			return
+		}
+		printError(stderr, err, color.Enabled(stderr))
+	}
+
 	// Configure dialect
 	if recursion {
 		resolve.AllowRecursion = true
@@ -97,8 +143,19 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	starlark.Universe["math"] = math.Module
 
 	// Create thread and globals
-	thread := &starlark.Thread{Load: repl.MakeLoad()}
+	// Bound non-interactive runs by --timeout, --max-steps, and ctx
+	batch := execExpr != "" || execProgram != "" || fs.NArg() == 1
+	if !batch {
+		maxSteps = 0
+	}
+	threads := &threadGroup{}
+	thread := &starlark.Thread{Load: makeLoad(loadPath, maxSteps, threads)}
+	limitSteps(thread, maxSteps)
+	threads.add(thread)
 	globals := make(starlark.StringDict)
+	if batch {
+		defer watchdog(ctx, threads, timeoutFlag)()
+	}
 
 	// Preload files
 	if preloadFlag != "" {
@@ -110,7 +167,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 			thread.Name = "exec " + file
 			fileGlobals, err := starlark.ExecFile(thread, file, nil, nil)
 			if err != nil {
-				repl.PrintError(err)
+				reportError(err)
 				return 1
 			}
 			// Merge into globals
@@ -125,15 +182,31 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		thread.Name = "eval"
 		v, err := starlark.Eval(thread, "<expr>", execExpr, globals)
 		if err != nil {
-			repl.PrintError(err)
+			reportError(err)
 			return 1
 		}
-		if v != starlark.None {
-			writeln(stdout, v.String())
+		if err := printResult(stdout, thread, v, outputFlag); err != nil {
+			writef(stderr, "skyrepl: %v\n", err)
+			return 1
 		}
 		return 0
 	}
 
+	// Mode: execute program (-c flag)
+	if execProgram != "" {
+		thread.Name = "exec"
+		v, err := execChunk(thread, execProgram, globals)
+		if err != nil {
+			reportError(err)
+			return 1
+		}
+		if err := printResult(stdout, thread, v, outputFlag); err != nil {
+			writef(stderr, "skyrepl: %v\n", err)
+			return 1
+		}
+		return exitCodeFor(v)
+	}
+
 	// Mode: execute file
 	if fs.NArg() == 1 {
 		filename := fs.Arg(0)
@@ -141,7 +214,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		var err error
 		globals, err = starlark.ExecFile(thread, filename, nil, globals)
 		if err != nil {
-			repl.PrintError(err)
+			reportError(err)
 			return 1
 		}
 		if showEnv {
@@ -211,6 +284,179 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	return 0
 }
 
+// printError writes err to w like repl.PrintError, with the backtrace of an
+// evaluation error, and colors the error message when color is set.
+func printError(w io.Writer, err error, color bool) {
+	msg := err.Error()
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		msg = evalErr.Backtrace()
+	}
+	// The backtrace ends with the error message.
+	i := strings.LastIndexByte(msg, '\n') + 1
+	writeln(w, msg[:i]+cli.Paint(color, cli.Red, msg[i:]))
+}
+
+// jsonError is the -output json form of an evaluation error.
+type jsonError struct {
+	Error jsonErrorDetail `json:"error"`
+}
+
+type jsonErrorDetail struct {
+	Msg string `json:"msg"`
+	// Backtrace lists the active calls, outermost first, like the text
+	// traceback. It is empty for errors found before execution, such as
+	// syntax errors, whose position is part of Msg.
+	Backtrace []jsonFrame `json:"backtrace"`
+}
+
+type jsonFrame struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Line   int32  `json:"line"`
+	Column int32  `json:"column"`
+}
+
+// printErrorJSON writes err to w as a jsonError.
+func printErrorJSON(w io.Writer, err error) {
+	detail := jsonErrorDetail{Msg: err.Error(), Backtrace: []jsonFrame{}}
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		detail.Msg = evalErr.Msg
+		for _, fr := range evalErr.CallStack {
+			detail.Backtrace = append(detail.Backtrace, jsonFrame{
+				Name:   fr.Name,
+				File:   fr.Pos.Filename(),
+				Line:   fr.Pos.Line,
+				Column: fr.Pos.Col,
+			})
+		}
+	}
+	encoded, _ := gojson.Marshal(jsonError{Error: detail})
+	writeln(w, string(encoded))
+}
+
+// threadGroup is the set of threads of a run: the main thread and one per
+// loaded module. Canceling the group cancels all of them, including threads
+// added later.
+type threadGroup struct {
+	mu      sync.Mutex
+	threads []*starlark.Thread
+	reason  string // why the group was canceled, or "" if it was not
+}
+
+// add adds thread to the group, canceling it if the group was canceled.
+func (g *threadGroup) add(thread *starlark.Thread) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.reason != "" {
+		thread.Cancel(g.reason)
+	}
+	g.threads = append(g.threads, thread)
+}
+
+// cancel cancels every thread in the group with reason.
+func (g *threadGroup) cancel(reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.reason = reason
+	for _, thread := range g.threads {
+		thread.Cancel(reason)
+	}
+}
+
+// watchdog cancels threads when timeout elapses (if positive) or ctx is
+// done, whichever comes first. The returned function stops the watchdog.
+func watchdog(ctx context.Context, threads *threadGroup, timeout gosystime.Duration) func() {
+	done := make(chan struct{})
+	var expired <-chan gosystime.Time
+	if timeout > 0 {
+		expired = gosystime.After(timeout)
+	}
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			threads.cancel(ctx.Err().Error())
+		case <-expired:
+			threads.cancel(fmt.Sprintf("timeout after %s", timeout))
+		}
+	}()
+	return func() { close(done) }
+}
+
+// limitSteps makes thread fail after maxSteps execution steps, if positive.
+func limitSteps(thread *starlark.Thread, maxSteps uint64) {
+	if maxSteps == 0 {
+		return
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	thread.OnMaxSteps = func(thread *starlark.Thread) {
+		thread.Cancel(fmt.Sprintf("exceeded limit of %d execution steps", maxSteps))
+	}
+}
+
+// execChunk executes program in globals, which it updates. If the last
+// statement is an expression, its value is returned; otherwise None.
+func execChunk(thread *starlark.Thread, program string, globals starlark.StringDict) (starlark.Value, error) {
+	opts := syntax.LegacyFileOptions()
+	// As in the interactive REPL, loaded names are visible to later statements.
+	opts.LoadBindsGlobally = true
+	f, err := opts.Parse("<cmd>", program, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var last syntax.Expr
+	if n := len(f.Stmts); n > 0 {
+		if stmt, ok := f.Stmts[n-1].(*syntax.ExprStmt); ok {
+			last = stmt.X
+			f.Stmts = f.Stmts[:n-1]
+		}
+	}
+
+	if err := starlark.ExecREPLChunk(f, thread, globals); err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return starlark.None, nil
+	}
+	return starlark.EvalExprOptions(opts, thread, last, globals)
+}
+
+// printResult writes the result of -e or -c. In text format, None is not
+// printed; in json format every value is encoded, None as null.
+func printResult(w io.Writer, thread *starlark.Thread, v starlark.Value, format string) error {
+	if format != "json" {
+		if v != starlark.None {
+			writeln(w, v.String())
+		}
+		return nil
+	}
+	encoded, err := starlark.Call(thread, json.Module.Members["encode"], starlark.Tuple{v}, nil)
+	if err != nil {
+		return fmt.Errorf("cannot encode result as JSON: %w", err)
+	}
+	writeln(w, string(encoded.(starlark.String)))
+	return nil
+}
+
+// exitCodeFor maps the value of a -c program's last expression to an exit
+// code: False exits 1, an int is used as the exit code (1 if outside
+// 0-255), and anything else exits 0.
+func exitCodeFor(v starlark.Value) int {
+	switch v := v.(type) {
+	case starlark.Bool:
+		if !v {
+			return 1
+		}
+	case starlark.Int:
+		if n, ok := v.Int64(); ok && n >= 0 && n <= 255 {
+			return int(n)
+		}
+		return 1
+	}
+	return 0
+}
+
 func printEnv(w io.Writer, globals starlark.StringDict) {
 	for _, name := range globals.Keys() {
 		if !strings.HasPrefix(name, "_") {
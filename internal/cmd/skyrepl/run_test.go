@@ -3,8 +3,13 @@ package skyrepl
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRun_Version(t *testing.T) {
@@ -19,6 +24,19 @@ func TestRun_Version(t *testing.T) {
 	}
 }
 
+func TestRun_VersionJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"-version", "-output", "json"}, nil, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("RunWithIO(-version -output json) returned %d, want 0", code)
+	}
+	var info map[string]string
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil || info["version"] == "" {
+		t.Errorf("version output = %q, want a JSON object with a version", stdout.String())
+	}
+}
+
 func TestRun_Help(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	code := RunWithIO(context.Background(), []string{"-help"}, nil, &stdout, &stderr)
@@ -142,3 +160,386 @@ func TestRun_Recursion(t *testing.T) {
 		t.Errorf("recursion without flag returned %d, want 1", code)
 	}
 }
+
+func TestRun_Program(t *testing.T) {
+	tests := []struct {
+		name     string
+		program  string
+		wantOut  string
+		wantCode int
+	}{
+		{
+			name:     "statements then true",
+			program:  "x = [1, 2]; y = len(x)\ny == 2",
+			wantOut:  "True\n",
+			wantCode: 0,
+		},
+		{
+			name:     "false fails",
+			program:  "x = 1; x > 2",
+			wantOut:  "False\n",
+			wantCode: 1,
+		},
+		{
+			name:     "int is exit code",
+			program:  "def f():\n    return 3\nf()",
+			wantOut:  "3\n",
+			wantCode: 3,
+		},
+		{
+			name:     "zero succeeds",
+			program:  "n = 0; n",
+			wantOut:  "0\n",
+			wantCode: 0,
+		},
+		{
+			name:     "int out of range fails",
+			program:  "256",
+			wantOut:  "256\n",
+			wantCode: 1,
+		},
+		{
+			name:     "no final expression",
+			program:  "x = 1; print(x)",
+			wantOut:  "",
+			wantCode: 0,
+		},
+		{
+			name:     "other values succeed",
+			program:  `"done"`,
+			wantOut:  `"done"` + "\n",
+			wantCode: 0,
+		},
+		{
+			name:     "runtime error",
+			program:  "x = 1; x + 'a'",
+			wantCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := RunWithIO(context.Background(), []string{"-c", tt.program}, nil, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("RunWithIO(-c %q) returned %d, want %d", tt.program, code, tt.wantCode)
+			}
+			if tt.wantOut != "" && stdout.String() != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRun_ProgramAndExpr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"-c", "1", "-e", "1"}, nil, &stdout, &stderr)
+
+	if code != 2 {
+		t.Errorf("RunWithIO(-c, -e) returned %d, want 2", code)
+	}
+}
+
+func TestRun_OutputJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantErr  string
+		wantCode int
+	}{
+		{
+			name:    "dict",
+			args:    []string{"-output", "json", "-e", `{"a": [1, 2], "b": None}`},
+			wantOut: `{"a":[1,2],"b":null}` + "\n",
+		},
+		{
+			name:    "string",
+			args:    []string{"-output", "json", "-e", `"hi"`},
+			wantOut: `"hi"` + "\n",
+		},
+		{
+			name:    "None is null",
+			args:    []string{"-output", "json", "-e", "None"},
+			wantOut: "null\n",
+		},
+		{
+			name:    "program result",
+			args:    []string{"-output", "json", "-c", "x = [1]; x + [2]"},
+			wantOut: "[1,2]\n",
+		},
+		{
+			name:     "not encodable",
+			args:     []string{"-output", "json", "-e", "len"},
+			wantErr:  "cannot encode result as JSON",
+			wantCode: 1,
+		},
+		{
+			name:     "invalid format",
+			args:     []string{"-output", "yaml", "-e", "1"},
+			wantErr:  "invalid -output",
+			wantCode: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := RunWithIO(context.Background(), tt.args, nil, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("RunWithIO(%v) returned %d, want %d\nstderr: %s", tt.args, code, tt.wantCode, stderr.String())
+			}
+			if stdout.String() != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantOut)
+			}
+			if tt.wantErr != "" && !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("stderr = %q, want to contain %q", stderr.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun_OutputJSONError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fail.star")
+	if err := os.WriteFile(file, []byte("def f():\n    return 1 // 0\n\nf()\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	type frame struct {
+		Name   string `json:"name"`
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+	tests := []struct {
+		name      string
+		args      []string
+		wantMsg   string
+		wantStack []frame
+	}{
+		{
+			name:      "file",
+			args:      []string{"-output", "json", file},
+			wantMsg:   "floored division by zero",
+			wantStack: []frame{{"<toplevel>", file, 4, 2}, {"f", file, 2, 14}},
+		},
+		{
+			name:      "expression",
+			args:      []string{"-output", "json", "-e", "1 + None"},
+			wantMsg:   "unknown binary op: int + NoneType",
+			wantStack: []frame{{"<expr>", "<expr>", 1, 3}},
+		},
+		{
+			name:      "syntax error",
+			args:      []string{"-output", "json", "-e", "1 +"},
+			wantMsg:   "<expr>:1:4: got end of file, want primary expression",
+			wantStack: []frame{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := RunWithIO(context.Background(), tt.args, nil, &stdout, &stderr); code != 1 {
+				t.Errorf("RunWithIO(%v) returned %d, want 1", tt.args, code)
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("stderr = %q, want empty", stderr.String())
+			}
+			var got struct {
+				Error struct {
+					Msg       string  `json:"msg"`
+					Backtrace []frame `json:"backtrace"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON %q: %v", stdout.String(), err)
+			}
+			if got.Error.Msg != tt.wantMsg {
+				t.Errorf("msg = %q, want %q", got.Error.Msg, tt.wantMsg)
+			}
+			if !reflect.DeepEqual(got.Error.Backtrace, tt.wantStack) {
+				t.Errorf("backtrace = %+v, want %+v", got.Error.Backtrace, tt.wantStack)
+			}
+		})
+	}
+}
+
+const slowProgram = "def f():\n    for i in range(1 << 40):\n        pass\nf()"
+
+func TestRun_Timeout(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "slow.star")
+	if err := os.WriteFile(file, []byte(slowProgram+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	// main.star spends its time in a loaded module, which runs on its own
+	// thread.
+	loader := filepath.Join(dir, "main.star")
+	if err := os.WriteFile(loader, []byte(`load("slow.star", "f")`+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "expression", args: []string{"-timeout", "50ms", "-e", "[x for x in range(1 << 40)]"}},
+		{name: "program", args: []string{"-timeout", "50ms", "-c", slowProgram}},
+		{name: "file", args: []string{"-timeout", "50ms", file}},
+		{name: "loaded module", args: []string{"-timeout", "50ms", "-load-path", dir, loader}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			start := time.Now()
+			code := RunWithIO(context.Background(), tt.args, nil, &stdout, &stderr)
+
+			if code != 1 {
+				t.Errorf("RunWithIO(%v) returned %d, want 1", tt.args, code)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("RunWithIO(%v) took %s, want it cancelled after the timeout", tt.args, elapsed)
+			}
+		})
+	}
+}
+
+func TestRun_TimeoutNotReached(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"-timeout", "10s", "-e", "1 + 1"}, nil, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("RunWithIO(-timeout 10s) returned %d, want 0", code)
+	}
+	if stdout.String() != "2\n" {
+		t.Errorf("stdout = %q, want %q", stdout.String(), "2\n")
+	}
+}
+
+func TestRun_ContextCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(ctx, []string{"-c", slowProgram}, nil, &stdout, &stderr)
+
+	if code != 1 {
+		t.Errorf("RunWithIO with cancelled context returned %d, want 1", code)
+	}
+}
+
+func TestRun_LoadPath(t *testing.T) {
+	root := t.TempDir()
+	lib := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(root, "pkg", "defs.star"): "ANSWER = 42\n",
+		filepath.Join(lib, "util.star"):         "def double(x):\n    return 2 * x\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		program string
+		wantOut string
+	}{
+		{
+			name:    "label with colon",
+			program: `load("//pkg:defs.star", "ANSWER"); ANSWER == 42`,
+			wantOut: "True\n",
+		},
+		{
+			name:    "label with slash",
+			program: `load("//pkg/defs.star", "ANSWER"); ANSWER == 42`,
+			wantOut: "True\n",
+		},
+		{
+			name:    "relative name searches every directory",
+			program: `load("util.star", "double"); double(21) == 42`,
+			wantOut: "True\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := []string{"-load-path", root, "-load-path", lib, "-c", tt.program}
+			code := RunWithIO(context.Background(), args, nil, &stdout, &stderr)
+
+			if code != 0 {
+				t.Fatalf("RunWithIO(%v) returned %d, want 0\nstderr: %s", args, code, stderr.String())
+			}
+			if stdout.String() != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestResolveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "found.star"), nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		loadPath []string
+		module   string
+		want     string
+	}{
+		{"label against first path", []string{dir, "/other"}, "//a/b:c.star", filepath.Join(dir, "a", "b", "c.star")},
+		{"label without load path", nil, "//a:c.star", filepath.Join("a", "c.star")},
+		{"found in load path", []string{"/missing", dir}, "found.star", filepath.Join(dir, "found.star")},
+		{"falls back to working directory", []string{dir}, "missing.star", "missing.star"},
+		{"absolute path unchanged", []string{dir}, "/abs/x.star", "/abs/x.star"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveLoad(tt.loadPath, tt.module); got != tt.want {
+				t.Errorf("resolveLoad(%v, %q) = %q, want %q", tt.loadPath, tt.module, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_MaxSteps(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "slow.star"), []byte("X = [x for x in range(100000)]\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{name: "under limit", args: []string{"-max-steps", "100000", "-e", "1 + 1"}, wantCode: 0},
+		{name: "expression over limit", args: []string{"-max-steps", "1000", "-e", "[x for x in range(100000)]"}, wantCode: 1},
+		{name: "program over limit", args: []string{"-max-steps", "1000", "-c", slowProgram}, wantCode: 1},
+		{name: "loaded module over limit", args: []string{"-max-steps", "1000", "-load-path", dir, "-c", `load("slow.star", "X")`}, wantCode: 1},
+		{name: "unbounded by default", args: []string{"-e", "len([x for x in range(100000)])"}, wantCode: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := RunWithIO(context.Background(), tt.args, nil, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("RunWithIO(%v) returned %d, want %d", tt.args, code, tt.wantCode)
+			}
+		})
+	}
+}
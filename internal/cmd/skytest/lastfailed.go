@@ -0,0 +1,165 @@
+package skytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/albertocavalcante/sky/internal/starlark/tester"
+)
+
+// cacheDir is the directory, relative to the working directory, where
+// skytest keeps state between runs.
+const cacheDir = ".skytest_cache"
+
+// lastFailedFile holds the ids of the tests that failed in previous runs.
+const lastFailedFile = "lastfailed.json"
+
+// lastFailed is the on-disk format of lastFailedFile. Ids are
+// "<absolute file>::<test name>", or "<absolute file>::" for a file that
+// failed to load, so none of its tests ran.
+type lastFailed struct {
+	Failed []string `json:"failed"`
+}
+
+// readLastFailed returns the test ids recorded as failed in dir.
+// A missing cache is not an error.
+func readLastFailed(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, lastFailedFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lf lastFailed
+	if err := json.Unmarshal(data, &lf); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, lastFailedFile), err)
+	}
+	return lf.Failed, nil
+}
+
+// writeLastFailed updates the failed test ids in dir with result: tests that
+// ran are recorded or cleared by their outcome, files are recorded or cleared
+// by whether they loaded, and failures from earlier runs of tests that did
+// not run this time are kept.
+func writeLastFailed(dir string, previous []string, result *tester.RunResult) error {
+	ran := make(map[string]bool)
+	failed := make(map[string]bool)
+	for _, fr := range result.Files {
+		fileID := testID(fr.File, "")
+		ran[fileID] = true
+		if fr.SetupError != nil {
+			failed[fileID] = true
+		}
+		for _, t := range fr.Tests {
+			id := testID(fr.File, t.Name)
+			ran[id] = true
+			if !t.Passed {
+				failed[id] = true
+			}
+		}
+	}
+	for _, id := range previous {
+		if !ran[id] {
+			failed[id] = true
+		}
+	}
+
+	lf := lastFailed{Failed: make([]string, 0, len(failed))}
+	for id := range failed {
+		lf.Failed = append(lf.Failed, id)
+	}
+	sort.Strings(lf.Failed)
+
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// Keep the cache out of version control, like .pytest_cache.
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*\n"), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, lastFailedFile), append(data, '\n'), 0o644)
+}
+
+// testID returns the cache id of a test in file, or of file itself if name
+// is empty.
+func testID(file, name string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return file + "::" + name
+}
+
+// selectLastFailed narrows files to those with previously failed tests and
+// records the failed test names in fileTestNames. Files that failed to load
+// are selected with all their tests. It returns files unchanged if none of
+// them has a recorded failure.
+func selectLastFailed(files []string, failed []string, fileTestNames map[string][]string) []string {
+	byFile := make(map[string][]string)
+	for _, id := range failed {
+		file, name, ok := strings.Cut(id, "::")
+		if !ok {
+			continue
+		}
+		if name == "" {
+			byFile[file] = nil
+			continue
+		}
+		if names, seen := byFile[file]; !seen || names != nil {
+			byFile[file] = append(names, name)
+		}
+	}
+
+	var selected []string
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			abs = file
+		}
+		if names, ok := byFile[abs]; ok {
+			selected = append(selected, file)
+			if names != nil {
+				fileTestNames[file] = names
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return files
+	}
+	return selected
+}
+
+// failedFilesFirst moves files with previously failed tests to the front,
+// keeping the relative order of both groups.
+func failedFilesFirst(files []string, failed []string) []string {
+	hasFailed := make(map[string]bool)
+	for _, id := range failed {
+		if file, _, ok := strings.Cut(id, "::"); ok {
+			hasFailed[file] = true
+		}
+	}
+	sorted := make([]string, 0, len(files))
+	var rest []string
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			abs = file
+		}
+		if hasFailed[abs] {
+			sorted = append(sorted, file)
+		} else {
+			rest = append(rest, file)
+		}
+	}
+	return append(sorted, rest...)
+}
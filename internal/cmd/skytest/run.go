@@ -18,6 +18,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/albertocavalcante/sky/internal/cli"
+	"github.com/albertocavalcante/sky/internal/plugins"
 	"github.com/albertocavalcante/sky/internal/skyconfig"
 	"github.com/albertocavalcante/sky/internal/starlark/coverage"
 	"github.com/albertocavalcante/sky/internal/starlark/tester"
@@ -75,12 +77,26 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		parallelFlag        string
 		configFlag          string
 		configTimeoutFlag   time.Duration
+		listFlag            bool
+		junitOutFlag        string
+		lastFailedFlag      bool
+		failedFirstFlag     bool
+		benchFlag           bool
+		benchTimeFlag       time.Duration
+		deadlineFlag        time.Duration
+		dialectFlag         string
+		seedFlag            string
+		maxStepsFlag        uint64
+		outputDirFlag       string
+		allowEnvFlag        bool
+		allowEnvVarFlags    stringSliceFlag
 	)
 
 	fs := flag.NewFlagSet("skytest", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	fs.BoolVar(&jsonFlag, "json", false, "output results as JSON")
 	fs.BoolVar(&junitFlag, "junit", false, "output results as JUnit XML")
+	fs.StringVar(&junitOutFlag, "junit-out", "", "also write a JUnit XML report to this file")
 	fs.BoolVar(&markdownFlag, "markdown", false, "output results as GitHub-flavored Markdown (for $GITHUB_STEP_SUMMARY)")
 	fs.BoolVar(&githubFlag, "github", false, "output GitHub workflow commands for native PR annotations")
 	fs.BoolVar(&versionFlag, "version", false, "print version and exit")
@@ -91,7 +107,10 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	fs.StringVar(&filterFlag, "k", "", "filter tests by name pattern (supports 'not' prefix)")
 	fs.StringVar(&markerFilter, "m", "", "filter tests by marker (supports 'not' prefix, e.g., '-m slow', '-m \"not slow\"')")
 	fs.Var(&preludeFlags, "prelude", "prelude file to load before tests (can be specified multiple times)")
+	fs.StringVar(&dialectFlag, "builtins-dialect", "", "predeclare stub native rules of a dialect (e.g., bazel) for testing macros")
 	fs.DurationVar(&timeoutFlag, "timeout", 0, "timeout per test (0 to use config default)")
+	fs.DurationVar(&timeoutFlag, "timeout-per-test", 0, "timeout per test (alias for --timeout)")
+	fs.DurationVar(&deadlineFlag, "deadline", 0, "wall-clock limit for the whole run (0 for none)")
 	fs.BoolVar(&bailFlag, "bail", false, "stop on first test failure")
 	fs.BoolVar(&bailShortFlag, "x", false, "stop on first test failure (short for --bail)")
 	// EXPERIMENTAL: Coverage collection requires starlark-go-x with OnExec hook.
@@ -101,8 +120,22 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	fs.StringVar(&coverageOut, "coverprofile", "", "coverage output file (default: from config or coverage.json)")
 	fs.BoolVar(&updateSnapshotsFlag, "update-snapshots", false, "update snapshots instead of comparing")
 	fs.BoolVar(&updateSnapshotsFlag, "u", false, "update snapshots (short for --update-snapshots)")
+	color := cli.ColorFlag(fs)
+	fs.BoolVar(&allowEnvFlag, "allow-env", false, "let tests read allowlisted environment variables through the env module")
+	fs.Var(&allowEnvVarFlags, "allow-env-var", "add an environment variable to the env allowlist and imply --allow-env (can be specified multiple times)")
+	fs.StringVar(&outputDirFlag, "output-dir", "", "base directory for test artifacts, one subdirectory per test (see test_output_dir())")
 	fs.BoolVar(&watchFlag, "watch", false, "watch for file changes and re-run tests")
 	fs.BoolVar(&watchFlag, "w", false, "watch mode (short for --watch)")
+	fs.BoolVar(&listFlag, "list", false, "list the tests that would run (file::test_name[case]) without running them")
+	fs.BoolVar(&listFlag, "collect-only", false, "list tests without running them (alias for --list)")
+	fs.BoolVar(&benchFlag, "bench", false, "also run bench_* benchmark functions")
+	fs.DurationVar(&benchTimeFlag, "benchtime", tester.DefaultBenchTime, "target run time for each benchmark")
+	fs.BoolVar(&lastFailedFlag, "last-failed", false, "only run the tests that failed in the last run (all tests if none failed)")
+	fs.BoolVar(&lastFailedFlag, "lf", false, "only run last failed tests (short for --last-failed)")
+	fs.BoolVar(&failedFirstFlag, "failed-first", false, "run the tests that failed in the last run first")
+	fs.BoolVar(&failedFirstFlag, "ff", false, "run last failed tests first (short for --failed-first)")
+	fs.Uint64Var(&maxStepsFlag, "max-steps", 0, "fail a test (or a file's top-level code) after this many Starlark execution steps (0 = unbounded)")
+	fs.StringVar(&seedFlag, "seed", "", "run each file's tests in a shuffled order from this seed ('random' picks one)")
 	fs.BoolVar(&affectedOnlyFlag, "affected-only", false, "in watch mode, only run tests affected by changes")
 	fs.StringVar(&parallelFlag, "j", "", "number of parallel test files (auto, 1-N)")
 	fs.StringVar(&configFlag, "config", "", "config file path (config.sky, sky.star, or sky.toml)")
@@ -123,10 +156,15 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		writeln(stderr, "  - Multiple output formats (text, JSON, JUnit, Markdown)")
 		writeln(stderr, "  - Test filtering with -k flag")
 		writeln(stderr, "  - Prelude files for shared helpers (--prelude)")
-		writeln(stderr, "  - Per-test timeouts (--timeout)")
+		writeln(stderr, "  - Stub native rules for testing macros (--builtins-dialect)")
+		writeln(stderr, "  - Per-test timeouts (--timeout) and a whole-run deadline (--deadline)")
 		writeln(stderr, "  - Fail-fast mode (--bail / -x)")
 		writeln(stderr, "  - Parallel test execution (-j)")
 		writeln(stderr, "  - Watch mode for continuous testing (--watch / -w)")
+		writeln(stderr, "  - Test collection without running (--list / --collect-only)")
+		writeln(stderr, "  - Benchmarks for bench_* functions (--bench)")
+		writeln(stderr, "  - Per-test artifact directories (--output-dir)")
+		writeln(stderr, "  - Rerun failures (--last-failed / --lf, --failed-first / --ff)")
 		writeln(stderr, "  - Coverage collection (EXPERIMENTAL, requires starlark-go-x)")
 		writeln(stderr, "  - Unified configuration via config.sky, sky.star, or sky.toml")
 		writeln(stderr)
@@ -140,13 +178,22 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		writeln(stderr, "  skytest -k parse                # Run tests containing 'parse'")
 		writeln(stderr, "  skytest -k 'not slow'           # Exclude tests containing 'slow'")
 		writeln(stderr, "  skytest test.star::test_foo     # Run specific test function")
+		writeln(stderr, "  skytest --list -m slow .        # List tests marked 'slow' without running")
+		writeln(stderr, "  skytest --lf .                  # Rerun only the tests that failed last time")
+		writeln(stderr, "  skytest --seed=random .         # Shuffle test order, printing the seed")
+		writeln(stderr, "  skytest --bench -k concat .     # Tests and benchmarks matching concat")
 		writeln(stderr, "  skytest --prelude=helpers.star  # Load prelude before tests")
+		writeln(stderr, "  skytest --builtins-dialect=bazel  # Test macros against native.* stubs")
 		writeln(stderr, "  skytest --timeout=10s           # Set test timeout")
 		writeln(stderr, "  skytest --timeout=0             # Disable timeouts")
+		writeln(stderr, "  skytest --deadline=10m          # Abort the run after 10 minutes")
+		writeln(stderr, "  skytest --output-dir=out .      # Give each test a directory for artifacts")
+		writeln(stderr, "  skytest --allow-env .           # Let tests read CI markers via env.get()")
 		writeln(stderr, "  skytest --bail                  # Stop on first failure")
 		writeln(stderr, "  skytest -x                      # Stop on first failure (short)")
 		writeln(stderr, "  skytest -json tests/            # JSON output")
 		writeln(stderr, "  skytest -junit tests/ > out.xml # JUnit output for CI")
+		writeln(stderr, "  skytest --junit-out=out.xml .   # Text output plus a JUnit report file")
 		writeln(stderr, "  skytest -markdown tests/ >> $GITHUB_STEP_SUMMARY  # Markdown for GitHub")
 		writeln(stderr, "  skytest -github tests/          # GitHub native annotations (PR comments)")
 		writeln(stderr, "  skytest --watch tests/          # Watch mode, re-run on changes")
@@ -198,7 +245,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	}
 
 	if versionFlag {
-		writef(stdout, "skytest %s\n", version.String())
+		version.Write(stdout, "skytest", jsonFlag)
 		return exitOK
 	}
 
@@ -260,6 +307,10 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	effectivePreludes := append([]string{}, cfg.Test.Prelude...)
 	effectivePreludes = append(effectivePreludes, preludeFlags...)
 
+	// Env allowlist: config + CLI (additive)
+	effectiveEnvAllowlist := append([]string{}, cfg.Test.EnvAllowlist...)
+	effectiveEnvAllowlist = append(effectiveEnvAllowlist, allowEnvVarFlags...)
+
 	// FailFast: CLI > config
 	effectiveFailFast := cfg.Test.FailFast || bailFlag || bailShortFlag
 
@@ -310,6 +361,22 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		return exitError
 	}
 
+	// Previously failed tests, for --last-failed and --failed-first
+	previousFailed, err := readLastFailed(cacheDir)
+	if err != nil {
+		writef(stderr, "skytest: warning: ignoring test cache: %v\n", err)
+	}
+	if lastFailedFlag {
+		if len(previousFailed) == 0 {
+			writeln(stderr, "skytest: no previously failed tests, running all tests")
+		} else {
+			files = selectLastFailed(files, previousFailed, fileTestNames)
+		}
+	}
+	if failedFirstFlag {
+		files = failedFilesFirst(files, previousFailed)
+	}
+
 	// Create base options for runners
 	opts := tester.DefaultOptions()
 	opts.TestPrefix = effectivePrefix
@@ -319,8 +386,52 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	opts.MarkerFilter = markerFilter
 	opts.Preludes = effectivePreludes
 	opts.Timeout = effectiveTimeout
+	opts.MaxSteps = maxStepsFlag
 	opts.FailFast = effectiveFailFast
 	opts.UpdateSnapshots = updateSnapshotsFlag
+	opts.AllowEnv = allowEnvFlag || len(allowEnvVarFlags) > 0
+	opts.EnvAllowlist = effectiveEnvAllowlist
+	if outputDirFlag != "" {
+		// Absolute, so test_output_dir() gives tests a usable path
+		abs, err := filepath.Abs(outputDirFlag)
+		if err != nil {
+			writef(stderr, "skytest: %v\n", err)
+			return exitError
+		}
+		opts.OutputDir = abs
+	}
+	if failedFirstFlag {
+		opts.RunFirst = previousFailed
+	}
+	opts.Bench = benchFlag
+	opts.BenchTime = benchTimeFlag
+	if seedFlag != "" {
+		seed, err := parseSeed(seedFlag)
+		if err != nil {
+			writef(stderr, "skytest: %v\n", err)
+			return exitError
+		}
+		opts.Shuffle = true
+		opts.Seed = seed
+		// Print the seed so a failing order can be reproduced
+		writef(stderr, "skytest: shuffling tests with --seed=%d\n", seed)
+	}
+	if dialectFlag != "" {
+		stubs, err := tester.DialectBuiltins(dialectFlag)
+		if err != nil {
+			writef(stderr, "skytest: --builtins-dialect %s: %v\n", dialectFlag, err)
+			return exitError
+		}
+		for name, v := range stubs {
+			opts.Predeclared[name] = v
+		}
+	}
+	// Stop the conftest.star search at the workspace root when run via sky
+	opts.ConftestRoot = os.Getenv(plugins.EnvWorkspaceRoot)
+
+	if listFlag {
+		return runList(files, opts, fileTestNames, stdout, stderr)
+	}
 
 	// Create a single runner for coverage reporting (if enabled)
 	// Note: We create per-file runners for execution to support :: syntax,
@@ -345,6 +456,7 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		reporter = &tester.TextReporter{
 			Verbose:      effectiveVerbose,
 			ShowDuration: durationFlag,
+			Color:        color.Enabled(stdout),
 		}
 	}
 
@@ -353,6 +465,11 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		return runWatchMode(files, opts, fileTestNames, reporter, affectedOnlyFlag, stdout, stderr)
 	}
 
+	// The deadline starts now and applies to the whole run
+	if deadlineFlag > 0 {
+		opts.Deadline = time.Now().Add(deadlineFlag)
+	}
+
 	// Determine parallelism level
 	workers := parseParallelism(effectiveParallel)
 
@@ -373,6 +490,18 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 	// Report summary
 	reporter.ReportSummary(stdout, result)
 
+	// Remember failures for --last-failed and --failed-first
+	if err := writeLastFailed(cacheDir, previousFailed, result); err != nil {
+		writef(stderr, "skytest: warning: writing test cache: %v\n", err)
+	}
+
+	if junitOutFlag != "" {
+		if err := writeJUnitReport(result, junitOutFlag); err != nil {
+			writef(stderr, "skytest: junit: %v\n", err)
+			return exitError
+		}
+	}
+
 	// Write coverage output if enabled
 	// EXPERIMENTAL: Coverage collection requires starlark-go-x with OnExec hook.
 	// TODO(upstream): Remove experimental note once OnExec is merged.
@@ -383,6 +512,11 @@ func RunWithIO(_ context.Context, args []string, _ io.Reader, stdout, stderr io.
 		}
 	}
 
+	if !opts.Deadline.IsZero() && time.Now().After(opts.Deadline) {
+		writef(stderr, "skytest: run deadline of %s exceeded; remaining tests were not run\n", deadlineFlag)
+		return exitFailed
+	}
+
 	if result.HasFailures() {
 		return exitFailed
 	}
@@ -415,6 +549,17 @@ func writeCoverageReport(runner *tester.Runner, outPath string, stderr io.Writer
 	return nil
 }
 
+// writeJUnitReport writes a JUnit XML report for result to outPath,
+// independently of the reporter used for stdout.
+func writeJUnitReport(result *tester.RunResult, outPath string) error {
+	var buf bytes.Buffer
+	(&tester.JUnitReporter{}).ReportSummary(&buf, result)
+	if err := os.WriteFile(outPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", outPath, err)
+	}
+	return nil
+}
+
 // coverageJSONOutput represents the top-level JSON coverage output.
 // Uses snake_case keys for consistency with internal/starlark/coverage/reporter.go.
 type coverageJSONOutput struct {
@@ -459,6 +604,55 @@ func writeln(w io.Writer, args ...any) {
 	_, _ = fmt.Fprintln(w, args...)
 }
 
+// runList prints the id (file::test_name[case]) of every test that would run,
+// one per line, without running any of them.
+func runList(
+	files []string,
+	opts tester.Options,
+	fileTestNames map[string][]string,
+	stdout, stderr io.Writer,
+) int {
+	total := 0
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			writef(stderr, "skytest: %v\n", err)
+			return exitError
+		}
+
+		absPath, _ := filepath.Abs(file)
+		if absPath == "" {
+			absPath = file
+		}
+
+		// Check if this file has specific test names from :: syntax
+		var testNames []string
+		for origPath, names := range fileTestNames {
+			origAbs, _ := filepath.Abs(origPath)
+			if origPath == file || origAbs == absPath {
+				testNames = names
+				break
+			}
+		}
+
+		fileOpts := opts
+		fileOpts.TestNames = testNames
+		names, err := tester.New(fileOpts).CollectFile(absPath, src)
+		if err != nil {
+			writef(stderr, "skytest: %s: %v\n", file, err)
+			return exitError
+		}
+
+		for _, name := range names {
+			writef(stdout, "%s::%s\n", file, name)
+		}
+		total += len(names)
+	}
+
+	writef(stderr, "skytest: %d test(s) collected from %d file(s)\n", total, len(files))
+	return exitOK
+}
+
 // runWatchMode runs tests in watch mode, re-running on file changes.
 func runWatchMode(
 	files []string,
@@ -490,6 +684,22 @@ func runWatchMode(
 		}
 	}
 
+	// Preludes affect every test file; conftest.star files affect the test
+	// files below them
+	for _, prelude := range opts.Preludes {
+		if err := watcher.AddDependency(prelude, files...); err != nil {
+			writef(stderr, "skytest: watching %s: %v\n", prelude, err)
+		}
+	}
+	conftestRunner := tester.New(opts)
+	for _, file := range files {
+		for _, conftest := range conftestRunner.ConftestFiles(file) {
+			if err := watcher.AddDependency(conftest, file); err != nil {
+				writef(stderr, "skytest: watching %s: %v\n", conftest, err)
+			}
+		}
+	}
+
 	writef(stdout, "\n🔍 Watch mode active. Watching %d test file(s).\n", len(files))
 	writef(stdout, "   Press Ctrl+C to stop.\n\n")
 
@@ -553,39 +763,7 @@ func runTests(
 	start := time.Now()
 
 	for _, file := range files {
-		src, err := os.ReadFile(file)
-		if err != nil {
-			writef(stderr, "skytest: %v\n", err)
-			continue
-		}
-
-		// Convert to absolute path for clearer output
-		absPath, _ := filepath.Abs(file)
-		if absPath == "" {
-			absPath = file
-		}
-
-		// Check if this file has specific test names from :: syntax
-		var testNames []string
-		for origPath, names := range fileTestNames {
-			origAbs, _ := filepath.Abs(origPath)
-			if origPath == file || origAbs == absPath {
-				testNames = names
-				break
-			}
-		}
-
-		// Create a runner with the appropriate test names for this file
-		fileOpts := opts
-		fileOpts.TestNames = testNames
-		fileRunner := tester.New(fileOpts)
-
-		fileResult, err := fileRunner.RunFile(absPath, src)
-		if err != nil {
-			writef(stderr, "skytest: %s: %v\n", file, err)
-			continue
-		}
-
+		fileResult := runFile(file, opts, fileTestNames)
 		result.Files = append(result.Files, *fileResult)
 
 		// Report file immediately for text and GitHub reporters
@@ -608,6 +786,57 @@ func runTests(
 	reporter.ReportSummary(stdout, result)
 }
 
+// runFile runs the tests in one file. Errors that keep the file's tests from
+// running (an unreadable file, a syntax error, a failing prelude or
+// conftest.star) are recorded as the result's SetupError rather than
+// returned, so that the remaining files still run.
+func runFile(file string, opts tester.Options, fileTestNames map[string][]string) *tester.FileResult {
+	// Convert to absolute path for clearer output
+	absPath, _ := filepath.Abs(file)
+	if absPath == "" {
+		absPath = file
+	}
+
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return &tester.FileResult{File: absPath, SetupError: err}
+	}
+
+	// Check if this file has specific test names from :: syntax
+	var testNames []string
+	for origPath, names := range fileTestNames {
+		origAbs, _ := filepath.Abs(origPath)
+		if origPath == file || origAbs == absPath {
+			testNames = names
+			break
+		}
+	}
+
+	// Create a runner with the appropriate test names for this file
+	fileOpts := opts
+	fileOpts.TestNames = testNames
+	fileRunner := tester.New(fileOpts)
+
+	fileResult, err := fileRunner.RunFile(absPath, src)
+	if err != nil {
+		return &tester.FileResult{File: absPath, SetupError: err}
+	}
+	return fileResult
+}
+
+// parseSeed parses the --seed value: an integer, or "random" for a seed
+// taken from the current time.
+func parseSeed(value string) (int64, error) {
+	if value == "random" {
+		return time.Now().UnixNano(), nil
+	}
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --seed %q: want an integer or \"random\"", value)
+	}
+	return seed, nil
+}
+
 // parseParallelism parses the -j flag value and returns the number of workers.
 // Returns 1 for sequential execution (empty, "1", invalid values).
 // Returns runtime.NumCPU() for "auto".
@@ -627,7 +856,8 @@ func parseParallelism(flag string) int {
 }
 
 // runSequential runs test files sequentially.
-// Returns an error if a file cannot be read or has a syntax error.
+// Files that fail to load are reported as failed files; an error is only
+// returned if writing the report fails.
 func runSequential(
 	files []string,
 	opts tester.Options,
@@ -639,37 +869,7 @@ func runSequential(
 	start := time.Now()
 
 	for _, file := range files {
-		src, err := os.ReadFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("reading file %s: %w", file, err)
-		}
-
-		// Convert to absolute path for clearer output
-		absPath, _ := filepath.Abs(file)
-		if absPath == "" {
-			absPath = file
-		}
-
-		// Check if this file has specific test names from :: syntax
-		var testNames []string
-		for origPath, names := range fileTestNames {
-			origAbs, _ := filepath.Abs(origPath)
-			if origPath == file || origAbs == absPath {
-				testNames = names
-				break
-			}
-		}
-
-		// Create a runner with the appropriate test names for this file
-		fileOpts := opts
-		fileOpts.TestNames = testNames
-		fileRunner := tester.New(fileOpts)
-
-		fileResult, err := fileRunner.RunFile(absPath, src)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", file, err)
-		}
-
+		fileResult := runFile(file, opts, fileTestNames)
 		result.Files = append(result.Files, *fileResult)
 
 		// Report file immediately for text and GitHub reporters
@@ -684,6 +884,11 @@ func runSequential(
 		if opts.FailFast && fileResult.HasFailures() {
 			break
 		}
+
+		// Stop once the run deadline has passed
+		if !opts.Deadline.IsZero() && time.Now().After(opts.Deadline) {
+			break
+		}
 	}
 
 	result.Duration = time.Since(start)
@@ -699,7 +904,8 @@ type fileRunResult struct {
 }
 
 // runParallel runs test files in parallel using a worker pool.
-// Returns an error if any file cannot be read or has a syntax error.
+// Files that fail to load are reported as failed files; an error is only
+// returned if writing the report fails.
 func runParallel(
 	files []string,
 	workers int,
@@ -732,7 +938,7 @@ func runParallel(
 			defer wg.Done()
 			for file := range jobs {
 				// Check if we should stop early
-				if shouldStop() {
+				if shouldStop() || !opts.Deadline.IsZero() && time.Now().After(opts.Deadline) {
 					continue // Drain the channel but don't process
 				}
 
@@ -809,40 +1015,7 @@ func runFileForParallel(
 	reporter tester.Reporter,
 ) fileRunResult {
 	result := fileRunResult{file: file}
-
-	src, err := os.ReadFile(file)
-	if err != nil {
-		result.err = err
-		return result
-	}
-
-	// Convert to absolute path for clearer output
-	absPath, _ := filepath.Abs(file)
-	if absPath == "" {
-		absPath = file
-	}
-
-	// Check if this file has specific test names from :: syntax
-	var testNames []string
-	for origPath, names := range fileTestNames {
-		origAbs, _ := filepath.Abs(origPath)
-		if origPath == file || origAbs == absPath {
-			testNames = names
-			break
-		}
-	}
-
-	// Create a runner with the appropriate test names for this file
-	fileOpts := opts
-	fileOpts.TestNames = testNames
-	fileRunner := tester.New(fileOpts)
-
-	fileResult, err := fileRunner.RunFile(absPath, src)
-	if err != nil {
-		result.err = err
-		return result
-	}
-
+	fileResult := runFile(file, opts, fileTestNames)
 	result.fileResult = fileResult
 
 	// Buffer the output for text and GitHub reporters
@@ -7,8 +7,26 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/albertocavalcante/sky/internal/starlark/tester"
 )
 
+// TestMain runs the tests from a temporary directory so that the
+// .skytest_cache written by each run stays out of the source tree.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "skytest")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestRun_Version(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	code := RunWithIO(context.Background(), []string{"-version"}, nil, &stdout, &stderr)
@@ -233,6 +251,50 @@ func TestRun_SyntaxError(t *testing.T) {
 	}
 }
 
+func TestRun_FileErrorDoesNotAbortRun(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "test_a_bad.star")
+	if err := os.WriteFile(bad, []byte("def test_syntax(\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	failingConftestDir := filepath.Join(dir, "sub")
+	if err := os.Mkdir(failingConftestDir, 0755); err != nil {
+		t.Fatalf("failed to create subdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(failingConftestDir, "conftest.star"), []byte("fail(\"conftest broke\")\n"), 0644); err != nil {
+		t.Fatalf("failed to write conftest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(failingConftestDir, "test_c.star"), []byte("def test_c():\n    pass\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	good := filepath.Join(dir, "test_b_good.star")
+	if err := os.WriteFile(good, []byte("def test_good():\n    pass\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	for _, args := range [][]string{{"-r"}, {"-r", "-j", "2"}} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := RunWithIO(context.Background(), append(args, dir), nil, &stdout, &stderr)
+			if code != 1 {
+				t.Fatalf("expected exit 1, got %d\nstdout: %s\nstderr: %s", code, stdout.String(), stderr.String())
+			}
+			out := stdout.String()
+			for _, want := range []string{"SETUP FAILED: " + bad, "conftest broke", "PASS  test_good", "1 passed, 2 failed"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out)
+				}
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	RunWithIO(context.Background(), []string{"-json", bad, good}, nil, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), `"error": `) || !strings.Contains(stdout.String(), `"test_good"`) {
+		t.Errorf("expected JSON output with file error and remaining results, got:\n%s", stdout.String())
+	}
+}
+
 func TestRun_NonexistentFile(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	code := RunWithIO(context.Background(), []string{"/nonexistent/test.star"}, nil, &stdout, &stderr)
@@ -461,6 +523,91 @@ func TestRun_PreludeNotFound(t *testing.T) {
 	}
 }
 
+func TestRun_BuiltinsDialect(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test_macro.star")
+	testContent := `def my_macro(name):
+    native.cc_library(name = name, srcs = [name + ".cc"])
+
+def test_macro():
+    my_macro("foo")
+    assert.eq(native.existing_rule("foo")["kind"], "cc_library")
+`
+	if err := os.WriteFile(testFile, []byte(testContent), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--builtins-dialect", "bazel", testFile}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("RunWithIO(--builtins-dialect bazel) returned %d, want 0\nstdout: %s\nstderr: %s", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithIO(context.Background(), []string{"--builtins-dialect", "nosuch", testFile}, nil, &stdout, &stderr)
+	if code != exitError {
+		t.Errorf("RunWithIO(--builtins-dialect nosuch) returned %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stderr.String(), "--builtins-dialect nosuch") {
+		t.Errorf("expected error naming the dialect, got: %s", stderr.String())
+	}
+}
+
+func TestRun_Seed(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test_order.star")
+	if err := os.WriteFile(testFile, []byte("def test_a():\n    pass\n\ndef test_b():\n    pass\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--seed", "123", testFile}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Errorf("RunWithIO(--seed 123) returned %d, want 0\nstderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "--seed=123") {
+		t.Errorf("expected the seed to be printed, got: %s", stderr.String())
+	}
+
+	stderr.Reset()
+	code = RunWithIO(context.Background(), []string{"--seed", "random", testFile}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Errorf("RunWithIO(--seed random) returned %d, want 0\nstderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "shuffling tests with --seed=") {
+		t.Errorf("expected the chosen seed to be printed, got: %s", stderr.String())
+	}
+
+	code = RunWithIO(context.Background(), []string{"--seed", "abc", testFile}, nil, &stdout, &stderr)
+	if code != exitError {
+		t.Errorf("RunWithIO(--seed abc) returned %d, want %d", code, exitError)
+	}
+}
+
+func TestRun_MaxSteps(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test_steps.star")
+	if err := os.WriteFile(testFile, []byte("def test_loop():\n    [x for x in range(100000)]\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--max-steps", "1000", testFile}, nil, &stdout, &stderr)
+	if code != exitFailed {
+		t.Errorf("RunWithIO(--max-steps 1000) returned %d, want %d", code, exitFailed)
+	}
+	if !strings.Contains(stdout.String(), "exceeded limit of 1000 execution steps") {
+		t.Errorf("expected a step limit error, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = RunWithIO(context.Background(), []string{testFile}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Errorf("RunWithIO without --max-steps returned %d, want 0\nstdout: %s", code, stdout.String())
+	}
+}
+
 // Test Timeouts (--timeout flag) tests
 
 func TestRun_TimeoutBasic(t *testing.T) {
@@ -533,6 +680,43 @@ func TestRun_TimeoutExpired(t *testing.T) {
 	}
 }
 
+func TestRun_DeadlineExceeded(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test_deadline.star")
+	// Neither test exceeds the per-test timeout, but together they exceed
+	// the deadline.
+	content := `def test_a_slow():
+    x = 0
+    for i in range(1000000000):
+        x = x + 1
+
+def test_b_never_started():
+    pass
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	code := RunWithIO(context.Background(), []string{"--timeout", "1m", "--deadline", "100ms", "-v", file}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d\nstdout: %s\nstderr: %s", code, stdout.String(), stderr.String())
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected the deadline to cancel the in-flight test, took %s", elapsed)
+	}
+	if !strings.Contains(stdout.String(), "run deadline exceeded") {
+		t.Errorf("expected the in-flight test to report the deadline, got:\n%s", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "test_b_never_started") {
+		t.Errorf("expected no tests to start after the deadline, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "run deadline of 100ms exceeded") {
+		t.Errorf("expected deadline message on stderr, got %q", stderr.String())
+	}
+}
+
 // Fail-Fast Mode (--bail / -x flag) tests
 
 func TestRun_BailOnFirstFailure(t *testing.T) {
@@ -793,9 +977,9 @@ func TestRun_FixtureScopeFile(t *testing.T) {
     # This is only called ONCE for file scope, result is cached
     return []
 
-# Configure fixture scope
+# Configure fixture scope; mutable, since shared values are frozen by default
 __fixture_config__ = {
-    "shared_list": "file",
+    "shared_list": {"scope": "file", "mutable": True},
 }
 
 def test_first(shared_list):
@@ -2044,3 +2228,345 @@ func TestRun_JUnitWithCoverage(t *testing.T) {
 		t.Error("expected coverage file to be created")
 	}
 }
+
+func TestRun_List(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test_list.star")
+	content := `def test_fast():
+    fail("should not run")
+
+def test_slow(case):
+    fail("should not run")
+
+def test_other():
+    fail("should not run")
+
+__test_params__ = {
+    "test_slow": [{"name": "small"}, {"name": "large"}],
+}
+
+__test_meta__ = {
+    "test_slow": {"markers": ["slow"]},
+    "test_other": {"markers": ["slow"]},
+}
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "all",
+			args: []string{"--list"},
+			want: []string{"test_fast", "test_other", "test_slow[small]", "test_slow[large]"},
+		},
+		{
+			name: "collect-only alias",
+			args: []string{"--collect-only"},
+			want: []string{"test_fast", "test_other", "test_slow[small]", "test_slow[large]"},
+		},
+		{
+			name: "marker filter",
+			args: []string{"--list", "-m", "slow"},
+			want: []string{"test_other", "test_slow[small]", "test_slow[large]"},
+		},
+		{
+			name: "name filter on case",
+			args: []string{"--list", "-k", "large"},
+			want: []string{"test_slow[large]"},
+		},
+		{
+			name: "not marker filter",
+			args: []string{"--list", "-m", "not slow"},
+			want: []string{"test_fast"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := RunWithIO(context.Background(), append(tt.args, file), nil, &stdout, &stderr)
+			if code != 0 {
+				t.Fatalf("expected exit 0, got %d\nstderr: %s", code, stderr.String())
+			}
+
+			var want []string
+			for _, name := range tt.want {
+				want = append(want, file+"::"+name)
+			}
+			got := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Fatalf("expected ids:\n%s\ngot:\n%s", strings.Join(want, "\n"), stdout.String())
+			}
+			if !strings.Contains(stderr.String(), "test(s) collected") {
+				t.Fatalf("expected collection summary on stderr, got %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_JUnitOut(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test_junit_out.star")
+	content := `def test_passes():
+    assert.eq(1, 1)
+
+def test_fails():
+    assert.eq(1, 2)
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	out := filepath.Join(dir, "results.xml")
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--junit-out", out, file}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d\nstderr: %s", code, stderr.String())
+	}
+
+	// stdout keeps the text reporter output
+	if strings.Contains(stdout.String(), "<testsuites") {
+		t.Fatalf("expected no JUnit XML on stdout, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "test_fails") {
+		t.Fatalf("expected text output on stdout, got:\n%s", stdout.String())
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("expected JUnit report to be written: %v", err)
+	}
+	report := string(data)
+	for _, want := range []string{"<testsuites", `name="test_passes"`, `name="test_fails"`, "<failure"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+}
+
+func TestRun_JUnitOutUnwritable(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test_junit_out.star")
+	if err := os.WriteFile(file, []byte("def test_ok():\n    pass\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	out := filepath.Join(dir, "missing", "results.xml")
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--junit-out", out, file}, nil, &stdout, &stderr)
+	if code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "skytest: junit:") {
+		t.Fatalf("expected junit error on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRun_LastFailed(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	fileA := filepath.Join(dir, "test_a.star")
+	fileB := filepath.Join(dir, "test_b.star")
+	writeStar := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+	writeStar(fileA, `def test_a_ok():
+    pass
+
+def test_a_zfail():
+    assert.eq(1, 2)
+`)
+	writeStar(fileB, `def test_b_ok():
+    pass
+`)
+
+	run := func(args ...string) (int, string, string) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		code := RunWithIO(context.Background(), append(append(args, "-v"), dir), nil, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	// No cache yet: --lf runs everything.
+	code, _, stderr := run("--lf")
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+	if !strings.Contains(stderr, "no previously failed tests") {
+		t.Fatalf("expected fallback note, got %q", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".skytest_cache", "lastfailed.json")); err != nil {
+		t.Fatalf("expected cache to be written: %v", err)
+	}
+
+	// Only the failed test runs.
+	code, stdout, _ := run("--last-failed")
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+	if !strings.Contains(stdout, "test_a_zfail") || strings.Contains(stdout, "test_a_ok") || strings.Contains(stdout, "test_b_ok") {
+		t.Fatalf("expected only test_a_zfail to run, got:\n%s", stdout)
+	}
+
+	// --failed-first runs the failed test before the others in its file.
+	_, stdout, _ = run("--ff")
+	if i, j := strings.Index(stdout, "test_a_zfail"), strings.Index(stdout, "test_a_ok"); i < 0 || j < 0 || i > j {
+		t.Fatalf("expected test_a_zfail before test_a_ok, got:\n%s", stdout)
+	}
+
+	// Fix the test; the passing run clears it from the cache.
+	writeStar(fileA, `def test_a_ok():
+    pass
+
+def test_a_zfail():
+    pass
+`)
+	if code, _, _ := run("--lf"); code != 0 {
+		t.Fatalf("expected exit 0 after fix, got %d", code)
+	}
+	_, _, stderr = run("--lf")
+	if !strings.Contains(stderr, "no previously failed tests") {
+		t.Fatalf("expected cache to be cleared, got %q", stderr)
+	}
+}
+
+func TestRun_LastFailedSetupError(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	broken := filepath.Join(dir, "test_broken.star")
+	failing := filepath.Join(dir, "test_failing.star")
+	writeStar := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+	writeStar(broken, "def test_broken(:\n    pass\n")
+	writeStar(failing, `def test_fail():
+    assert.eq(1, 2)
+`)
+
+	run := func(args ...string) (int, string) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		code := RunWithIO(context.Background(), append(append(args, "-v"), dir), nil, &stdout, &stderr)
+		return code, stdout.String()
+	}
+
+	if code, _ := run(); code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+	failed, err := readLastFailed(filepath.Join(dir, ".skytest_cache"))
+	if err != nil {
+		t.Fatalf("readLastFailed: %v", err)
+	}
+	want := []string{broken + "::", failing + "::test_fail"}
+	if strings.Join(failed, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, failed)
+	}
+
+	// --lf runs the file that failed to load, as well as the failed test.
+	_, stdout := run("--lf")
+	if !strings.Contains(stdout, "SETUP FAILED") || !strings.Contains(stdout, "test_fail") {
+		t.Fatalf("expected the broken file and test_fail to run, got:\n%s", stdout)
+	}
+
+	// Once the file loads, all its tests run and its failure is cleared.
+	writeStar(broken, `def test_broken():
+    pass
+
+def test_other():
+    pass
+`)
+	_, stdout = run("--lf")
+	if !strings.Contains(stdout, "test_broken") || !strings.Contains(stdout, "test_other") {
+		t.Fatalf("expected every test of the fixed file to run, got:\n%s", stdout)
+	}
+	failed, err = readLastFailed(filepath.Join(dir, ".skytest_cache"))
+	if err != nil {
+		t.Fatalf("readLastFailed: %v", err)
+	}
+	if want := []string{failing + "::test_fail"}; strings.Join(failed, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, failed)
+	}
+}
+
+func TestWriteLastFailed_KeepsUnrunFailures(t *testing.T) {
+	dir := t.TempDir()
+	previous := []string{"/x/test_a.star::test_one", "/x/test_a.star::test_two"}
+	result := &tester.RunResult{Files: []tester.FileResult{{
+		File:  "/x/test_a.star",
+		Tests: []tester.TestResult{{Name: "test_one", Passed: true}, {Name: "test_three"}},
+	}}}
+
+	if err := writeLastFailed(dir, previous, result); err != nil {
+		t.Fatalf("writeLastFailed: %v", err)
+	}
+	got, err := readLastFailed(dir)
+	if err != nil {
+		t.Fatalf("readLastFailed: %v", err)
+	}
+	want := []string{"/x/test_a.star::test_three", "/x/test_a.star::test_two"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRun_OutputDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "artifact_test.star")
+	content := `def test_artifact():
+    write_test_output("result.json", json.encode({"ok": True}))
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	t.Chdir(dir)
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithIO(context.Background(), []string{"--output-dir", "out", "artifact_test.star"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run returned %d, want 0\nstderr: %s\nstdout: %s", code, stderr.String(), stdout.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "out", "artifact_test.star", "test_artifact", "result.json"))
+	if err != nil {
+		t.Fatalf("artifact not written: %v", err)
+	}
+	if string(data) != `{"ok":true}` {
+		t.Errorf("artifact = %s", data)
+	}
+}
+
+func TestRun_AllowEnv(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "env_test.star")
+	content := `def test_token():
+    assert.eq(env.get("SKYTEST_TOKEN"), "secret")
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	t.Setenv("SKYTEST_TOKEN", "secret")
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithIO(context.Background(), []string{file}, nil, &stdout, &stderr); code == 0 {
+		t.Errorf("Run without --allow-env returned 0\nstdout: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := RunWithIO(context.Background(), []string{"--allow-env-var", "SKYTEST_TOKEN", file}, nil, &stdout, &stderr); code != 0 {
+		t.Errorf("Run with --allow-env-var returned %d\nstderr: %s\nstdout: %s", code, stderr.String(), stdout.String())
+	}
+}
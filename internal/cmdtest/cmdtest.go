@@ -18,6 +18,7 @@
 package cmdtest
 
 import (
+	"maps"
 	"os"
 	"testing"
 
@@ -48,14 +49,23 @@ func Run(t *testing.T, dir string) {
 // Main is the TestMain function that should be called from test files.
 // It sets up the CLI tools as testscript commands.
 func Main(m *testing.M) {
-	os.Exit(testscript.RunMain(m, map[string]func() int{
+	MainWith(m, nil)
+}
+
+// MainWith is like Main, but also sets up commands as testscript commands.
+// It lets tests of a binary that runs the Sky tools, such as sky, run it
+// next to the real tools.
+func MainWith(m *testing.M, commands map[string]func() int) {
+	all := map[string]func() int{
 		"skylint":  wrapRun(skylint.Run),
 		"skyfmt":   wrapRun(skyfmt.Run),
 		"skycheck": wrapRun(skycheck.Run),
 		"skyquery": wrapRun(skyquery.Run),
 		"skydoc":   wrapRun(skydoc.Run),
 		"skyls":    wrapRun(skyls.Run),
-	}))
+	}
+	maps.Copy(all, commands)
+	os.Exit(testscript.RunMain(m, all))
 }
 
 // wrapRun wraps a Run(args []string) int function to func() int for testscript.
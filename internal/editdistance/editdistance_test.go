@@ -0,0 +1,28 @@
+package editdistance_test
+
+import (
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/editdistance"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"lint", "lint", 0},
+		{"lnt", "lint", 1},
+		{"fmat", "fmt", 1},
+		{"kitten", "sitting", 3},
+		{"sitting", "kitten", 3},
+	}
+	for _, tt := range tests {
+		if got := editdistance.Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package lsp
+
+import (
+	"sync"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+// astCache holds the parse of each open document, so that the handlers
+// answering the requests an editor sends for one keystroke (hover,
+// completion, highlights, inlay hints) share a single parse. Entries are
+// keyed on the document version and dropped when the document changes.
+//
+// The cached files are shared between handlers and must not be modified.
+type astCache struct {
+	mu      sync.Mutex
+	entries map[string]map[filekind.Kind]*parsedDocument
+}
+
+// parsedDocument is one parse of a document version.
+type parsedDocument struct {
+	version int32
+	// content guards against clients that reuse a version number for
+	// different content.
+	content string
+	file    *build.File
+	err     error
+}
+
+func newASTCache() *astCache {
+	return &astCache{entries: make(map[string]map[filekind.Kind]*parsedDocument)}
+}
+
+// invalidate drops the parses of the document at uri.
+func (c *astCache) invalidate(uri string) {
+	c.mu.Lock()
+	delete(c.entries, uri)
+	c.mu.Unlock()
+}
+
+// parseDocument parses the current version of doc with the parser for kind,
+// reusing an earlier parse of the same version and content. Parse errors are
+// cached too, since reparsing would fail the same way.
+func (s *Server) parseDocument(doc *Document, kind filekind.Kind) (*build.File, error) {
+	s.mu.RLock()
+	uri, version, content := doc.URI, doc.Version, doc.Content
+	s.mu.RUnlock()
+
+	kind = parserKind(kind)
+
+	c := s.asts
+	c.mu.Lock()
+	if p, ok := c.entries[uri][kind]; ok && p.version == version && p.content == content {
+		c.mu.Unlock()
+		return p.file, p.err
+	}
+	c.mu.Unlock()
+
+	// Parse without holding the lock; concurrent requests may parse the
+	// same version twice, and the last parse wins.
+	file, err := parseStarlarkFile([]byte(content), uriToPath(uri), kind)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[uri] == nil {
+		c.entries[uri] = make(map[filekind.Kind]*parsedDocument)
+	}
+	c.entries[uri][kind] = &parsedDocument{version: version, content: content, file: file, err: err}
+	return file, err
+}
+
+// parserKind maps a file kind to a representative of the kinds that
+// parseStarlarkFile parses the same way.
+func parserKind(kind filekind.Kind) filekind.Kind {
+	switch kind {
+	case filekind.KindBUILD, filekind.KindBUCK:
+		return filekind.KindBUILD
+	case filekind.KindWORKSPACE, filekind.KindMODULE:
+		return kind
+	case filekind.KindBzl, filekind.KindBzlmod, filekind.KindBzlBuck:
+		return filekind.KindBzl
+	default:
+		return filekind.KindStarlark
+	}
+}
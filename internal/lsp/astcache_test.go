@@ -0,0 +1,87 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+func TestParseDocument_Cache(t *testing.T) {
+	server := NewServer(nil)
+	uri := "file:///ws/BUILD.bazel"
+	doc := &Document{URI: uri, Version: 1, Content: "x = 1\n"}
+	server.documents[uri] = doc
+
+	first, err := server.parseDocument(doc, filekind.KindBUILD)
+	if err != nil {
+		t.Fatalf("parseDocument() error = %v", err)
+	}
+	if again, _ := server.parseDocument(doc, filekind.KindBUCK); again != first {
+		t.Error("expected BUILD and BUCK to share a parse of the same version")
+	}
+	if other, _ := server.parseDocument(doc, filekind.KindBzl); other == first {
+		t.Error("expected a separate parse for another parser")
+	}
+
+	// A client reusing a version for new content gets a new parse
+	doc.Content = "x = 2\n"
+	reused, _ := server.parseDocument(doc, filekind.KindBUILD)
+	if reused == first {
+		t.Error("expected a new parse for new content under the same version")
+	}
+
+	changeParams, _ := json.Marshal(map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "x = 3\n"}},
+	})
+	if _, err := server.handleDidChange(context.Background(), changeParams); err != nil {
+		t.Fatalf("handleDidChange() error = %v", err)
+	}
+	if doc.Content != "x = 3\n" {
+		t.Fatalf("didChange left content %q", doc.Content)
+	}
+	server.asts.mu.Lock()
+	_, cached := server.asts.entries[uri]
+	server.asts.mu.Unlock()
+	if cached {
+		t.Error("expected didChange to drop the cached parses")
+	}
+	changed, _ := server.parseDocument(doc, filekind.KindBUILD)
+	if changed == reused {
+		t.Error("expected a new parse after didChange")
+	}
+	if again, _ := server.parseDocument(doc, filekind.KindBUILD); again != changed {
+		t.Error("expected the parse of version 2 to be reused")
+	}
+}
+
+func TestParseDocument_SharedByHandlers(t *testing.T) {
+	server := NewServer(nil)
+	uri := "file:///ws/defs.star"
+	doc := &Document{URI: uri, Version: 1, Content: "def f(a, b):\n    return a\n\nf(\n"}
+	server.documents[uri] = doc
+
+	// Semantic tokens parse the unmodified document through the cache
+	params, _ := json.Marshal(map[string]any{"textDocument": map[string]any{"uri": uri}})
+	if _, err := server.handleSemanticTokensFull(context.Background(), params); err != nil {
+		t.Fatalf("handleSemanticTokensFull() error = %v", err)
+	}
+	server.asts.mu.Lock()
+	_, cached := server.asts.entries[uri][filekind.KindStarlark]
+	server.asts.mu.Unlock()
+	if !cached {
+		t.Error("expected semantic tokens to cache the parse")
+	}
+
+	// Signature help still falls back to patching an incomplete call
+	params, _ = json.Marshal(map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": 3, "character": 2},
+	})
+	result, err := server.handleSignatureHelp(context.Background(), params)
+	if err != nil || result == nil {
+		t.Fatalf("handleSignatureHelp() = %v, %v; want the signature of f", result, err)
+	}
+}
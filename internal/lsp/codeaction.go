@@ -28,14 +28,16 @@ func (s *Server) handleCodeAction(ctx context.Context, params json.RawMessage) (
 	log.Printf("codeAction: %s range=%v", path, p.Range)
 
 	// Run linter to get findings with replacements
-	findings, err := s.lintDriver.RunFile(path)
-	if err != nil {
+	var actions []protocol.CodeAction
+	if findings, err := s.lintDocument(string(p.TextDocument.Uri), doc.Content); err != nil {
 		log.Printf("codeAction: linter error: %v", err)
-		return []protocol.CodeAction{}, nil
+	} else {
+		// Convert findings to code actions
+		actions = findingsToCodeActions(string(p.TextDocument.Uri), findings, doc.Content)
 	}
 
-	// Convert findings to code actions
-	actions := findingsToCodeActions(string(p.TextDocument.Uri), findings, doc.Content)
+	// Suggest similar names for undefined ones
+	actions = append(actions, s.undefinedNameActions(doc, path)...)
 
 	// Filter by requested range
 	actions = filterCodeActionsByRange(actions, p.Range)
@@ -68,7 +70,7 @@ func findingsToCodeActions(uri string, findings []linter.Finding, content string
 		}
 
 		// Create the diagnostic that this action fixes
-		diag := lintFindingToDiagnostic(f)
+		diag := diagnosticToLSP(f.ToDiagnostic(uriToPath(uri)))
 
 		// Create the code action
 		action := protocol.CodeAction{
@@ -3,6 +3,7 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
@@ -340,3 +341,100 @@ func requestCodeActions(t *testing.T, server *Server, uri string, rng protocol.R
 
 	return actions
 }
+
+func TestCodeAction_UndefinedNameSuggestions(t *testing.T) {
+	server := NewServer(nil)
+	initializeServer(t, server)
+
+	content := `load("//lib:defs.bzl", "my_rule")
+
+def build_targets(target_name, count):
+    total = count + 1
+    my_rul(name = target_nam)
+    return totl
+
+pritn("done")
+`
+	uri := "file:///test.bzl"
+	openDocument(t, server, uri, content)
+
+	tests := []struct {
+		name  string
+		line  uint32
+		typo  string
+		wants []string
+	}{
+		{name: "loaded symbol", line: 4, typo: "my_rul", wants: []string{"my_rule"}},
+		{name: "parameter", line: 4, typo: "target_nam", wants: []string{"target_name"}},
+		{name: "local", line: 5, typo: "totl", wants: []string{"total"}},
+		{name: "builtin", line: 7, typo: "pritn", wants: []string{"print"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := requestCodeActions(t, server, uri, protocol.Range{
+				Start: protocol.Position{Line: tt.line, Character: 0},
+				End:   protocol.Position{Line: tt.line, Character: 40},
+			})
+			var got []protocol.CodeAction
+			for _, action := range actions {
+				if len(action.Diagnostics) == 1 && action.Diagnostics[0].Code.Value == "undefined" &&
+					strings.Contains(action.Title, `"`+tt.typo+`"`) {
+					got = append(got, action)
+				}
+			}
+			if len(got) == 0 {
+				t.Fatalf("no suggestions for %q among %+v", tt.typo, actions)
+			}
+			if !got[0].IsPreferred {
+				t.Errorf("expected the first suggestion to be preferred")
+			}
+			edit := got[0].Edit.Changes[uri][0]
+			if edit.NewText != tt.wants[0] {
+				t.Errorf("suggestion = %q, want %q", edit.NewText, tt.wants[0])
+			}
+			line := strings.Split(content, "\n")[tt.line]
+			if start := strings.Index(line, tt.typo); edit.Range.Start.Character != uint32(start) || edit.Range.End.Character != uint32(start+len(tt.typo)) {
+				t.Errorf("edit range %+v does not cover %q in %q", edit.Range, tt.typo, line)
+			}
+		})
+	}
+}
+
+func TestNearestNames(t *testing.T) {
+	candidates := []string{"print", "println", "len", "sorted", "pritn", "srcs"}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"pritn", []string{"print", "println"}},
+		{"prnt", []string{"print"}},
+		{"lenn", []string{"len"}},
+		{"x", nil},
+		{"completely_different", nil},
+	}
+	for _, tt := range tests {
+		got := nearestNames(tt.name, candidates, 3)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("nearestNames(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseUndefinedMessage(t *testing.T) {
+	tests := []struct {
+		msg       string
+		name      string
+		hint      string
+		wantValid bool
+	}{
+		{"undefined: pritn", "pritn", "", true},
+		{"undefined: totl (did you mean total?)", "totl", "total", true},
+		{"cannot reassign global x", "", "", false},
+	}
+	for _, tt := range tests {
+		name, hint, ok := parseUndefinedMessage(tt.msg)
+		if name != tt.name || hint != tt.hint || ok != tt.wantValid {
+			t.Errorf("parseUndefinedMessage(%q) = %q, %q, %v", tt.msg, name, hint, ok)
+		}
+	}
+}
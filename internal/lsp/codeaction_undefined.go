@@ -0,0 +1,185 @@
+package lsp
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/editdistance"
+	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+// maxNameSuggestions is the number of "did you mean" fixes offered for one
+// undefined name.
+const maxNameSuggestions = 3
+
+// undefinedNameActions returns quick fixes for the checker's undefined-name
+// diagnostics, each replacing the name with a similar one that is in scope:
+// a name bound in the file or the enclosing functions, a builtin, or the
+// resolver's own suggestion.
+func (s *Server) undefinedNameActions(doc *Document, path string) []protocol.CodeAction {
+	s.mu.RLock()
+	uri, content := doc.URI, doc.Content
+	s.mu.RUnlock()
+
+	diags, err := s.checker.CheckFile(path, []byte(content))
+	if err != nil {
+		log.Printf("codeAction: checker error: %v", err)
+		return nil
+	}
+	// The file may not parse with buildtools while the checker still
+	// resolves it; the builtins and the resolver's suggestion remain.
+	file, _ := s.parseDocument(doc, filekind.KindStarlark)
+	builtinNames := s.builtinNames(uri)
+
+	var actions []protocol.CodeAction
+	for _, d := range diags {
+		if d.Code != "undefined" {
+			continue
+		}
+		name, hint, ok := parseUndefinedMessage(d.Message)
+		if !ok {
+			continue
+		}
+		candidates := append(scopeNames(file, int(d.Pos.Line)), builtinNames...)
+		if hint != "" {
+			candidates = append(candidates, hint)
+		}
+
+		diag := diagnosticToLSP(d.ToDiagnostic(path))
+		for i, suggestion := range nearestNames(name, candidates, maxNameSuggestions) {
+			actions = append(actions, protocol.CodeAction{
+				Title:       fmt.Sprintf("Change %q to %q", name, suggestion),
+				Kind:        protocol.CodeActionKindQuickFix,
+				Diagnostics: []protocol.Diagnostic{diag},
+				IsPreferred: i == 0,
+				Edit: protocol.WorkspaceEdit{
+					Changes: map[string][]protocol.TextEdit{
+						uri: {{Range: diag.Range, NewText: suggestion}},
+					},
+				},
+			})
+		}
+	}
+	return actions
+}
+
+// parseUndefinedMessage extracts the name from a resolver message such as
+// "undefined: pritn", along with the resolver's suggestion from a trailing
+// "(did you mean print?)", if any.
+func parseUndefinedMessage(msg string) (name, hint string, ok bool) {
+	rest, ok := strings.CutPrefix(msg, "undefined: ")
+	if !ok {
+		return "", "", false
+	}
+	name, suggestion, found := strings.Cut(rest, " (did you mean ")
+	if found {
+		hint = strings.TrimSuffix(suggestion, "?)")
+	}
+	return name, hint, name != ""
+}
+
+// scopeNames returns the names visible on line (1-based): the file's
+// top-level bindings, and the parameters and local bindings of each function
+// enclosing the line.
+func scopeNames(file *build.File, line int) []string {
+	if file == nil {
+		return nil
+	}
+	var names []string
+	addTargets := func(x build.Expr) {
+		build.Walk(x, func(e build.Expr, _ []build.Expr) {
+			if id, ok := e.(*build.Ident); ok {
+				names = append(names, id.Name)
+			}
+		})
+	}
+	var addBlock func(stmts []build.Expr)
+	addBlock = func(stmts []build.Expr) {
+		for _, stmt := range stmts {
+			switch st := stmt.(type) {
+			case *build.DefStmt:
+				names = append(names, st.Name)
+				start, end := st.Span()
+				if line < start.Line || line > end.Line {
+					continue
+				}
+				for _, param := range st.Params {
+					switch p := param.(type) {
+					case *build.Ident:
+						names = append(names, p.Name)
+					case *build.AssignExpr:
+						addTargets(p.LHS)
+					case *build.UnaryExpr:
+						addTargets(p.X)
+					}
+				}
+				addBlock(st.Body)
+			case *build.AssignExpr:
+				addTargets(st.LHS)
+			case *build.ForStmt:
+				addTargets(st.Vars)
+				addBlock(st.Body)
+			case *build.IfStmt:
+				addBlock(st.True)
+				addBlock(st.False)
+			case *build.LoadStmt:
+				for _, to := range st.To {
+					names = append(names, to.Name)
+				}
+			}
+		}
+	}
+	addBlock(file.Stmt)
+	return names
+}
+
+// builtinNames returns the names of the builtins available in the document:
+// the core Starlark builtins, and those the provider offers for completion.
+func (s *Server) builtinNames(uri string) []string {
+	items := s.getProviderBuiltinCompletions("", uri)
+	names := make([]string, 0, len(starlarkBuiltins)+len(items))
+	names = append(names, starlarkBuiltins...)
+	for _, item := range items {
+		names = append(names, item.Label)
+	}
+	return names
+}
+
+// nearestNames returns up to limit candidates within a small edit distance
+// of name, nearest first. As in the resolver's own spelling check, at most
+// half of the name may differ.
+func nearestNames(name string, candidates []string, limit int) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+	threshold := (len(name) + 1) / 2
+	seen := map[string]bool{name: true}
+	var matches []match
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		if d := editdistance.Levenshtein(name, candidate); d < threshold {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var names []string
+	for i := 0; i < len(matches) && i < limit; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
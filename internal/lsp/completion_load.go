@@ -0,0 +1,290 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+)
+
+// loadContext describes a cursor inside a string argument of a load
+// statement.
+type loadContext struct {
+	// arg is the index of the argument containing the cursor; 0 is the
+	// module label.
+	arg int
+	// prefix is the string contents before the cursor.
+	prefix string
+	// module is the module label, when the cursor is past it.
+	module string
+	// loaded are the symbols named by the other arguments.
+	loaded []string
+}
+
+// findLoadContext reports whether the cursor is inside a string argument of
+// a load statement, scanning the text rather than the syntax tree since the
+// statement is usually incomplete while it is being typed.
+func findLoadContext(content string, line, character int) (loadContext, bool) {
+	offset, ok := lineOffset(content, line, character)
+	if !ok {
+		return loadContext{}, false
+	}
+	before := content[:offset]
+
+	// load statements are top-level, so they start at the beginning of a line.
+	start := strings.LastIndex(before, "\nload(")
+	if start >= 0 {
+		start += len("\nload(")
+	} else if strings.HasPrefix(before, "load(") {
+		start = len("load(")
+	} else {
+		return loadContext{}, false
+	}
+
+	var lc loadContext
+	var quote byte
+	var str strings.Builder
+	for i := start; i < len(before); i++ {
+		ch := before[i]
+		if quote != 0 {
+			switch ch {
+			case '\\':
+				i++
+			case quote:
+				if lc.arg == 0 {
+					lc.module = str.String()
+				} else {
+					lc.loaded = append(lc.loaded, str.String())
+				}
+				quote = 0
+			case '\n':
+				return loadContext{}, false
+			default:
+				str.WriteByte(ch)
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'':
+			quote = ch
+			str.Reset()
+		case ',':
+			lc.arg++
+		case ')':
+			return loadContext{}, false
+		case '#':
+			for i < len(before) && before[i] != '\n' {
+				i++
+			}
+		}
+	}
+	if quote == 0 {
+		return loadContext{}, false
+	}
+	lc.prefix = str.String()
+	return lc, true
+}
+
+// lineOffset converts a line and character to a byte offset in content.
+func lineOffset(content string, line, character int) (int, bool) {
+	offset := 0
+	for i := 0; i < line; i++ {
+		next := strings.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return 0, false
+		}
+		offset += next + 1
+	}
+	end := strings.IndexByte(content[offset:], '\n')
+	if end < 0 {
+		end = len(content) - offset
+	}
+	return offset + min(character, end), true
+}
+
+// getLoadCompletions completes a load statement argument: package paths and
+// .bzl files for the module label, and the symbols the module exports for the
+// other arguments. Each item replaces the whole string typed so far.
+func (s *Server) getLoadCompletions(lc loadContext, uri, rootURI string, line, character int) []protocol.CompletionItem {
+	fromPath := uriToPath(uri)
+	if fromPath == "" {
+		// Labels are relative to the document's location on disk
+		return nil
+	}
+	var candidates []loadCandidate
+	if lc.arg == 0 {
+		candidates = labelCandidates(lc.prefix, fromPath, uriToPath(rootURI))
+	} else {
+		candidates = s.symbolCandidates(lc, fromPath, rootURI)
+	}
+
+	replace := protocol.Range{
+		Start: protocol.Position{Line: uint32(line), Character: uint32(max(character-len(lc.prefix), 0))},
+		End:   protocol.Position{Line: uint32(line), Character: uint32(character)},
+	}
+	items := make([]protocol.CompletionItem, 0, len(candidates))
+	for _, c := range candidates {
+		items = append(items, protocol.CompletionItem{
+			Label:    c.label,
+			Kind:     c.kind,
+			Detail:   c.detail,
+			TextEdit: protocol.Or_InsertReplaceEdit_TextEdit{Value: protocol.TextEdit{Range: replace, NewText: c.label}},
+		})
+	}
+	return items
+}
+
+type loadCandidate struct {
+	label  string
+	kind   protocol.CompletionItemKind
+	detail string
+}
+
+// labelCandidates suggests module labels starting with prefix: packages
+// below the workspace root as "//pkg", .bzl files in a package as
+// "//pkg:file.bzl", and .bzl files next to the current file as ":file.bzl".
+// Labels in external repositories are not completed.
+func labelCandidates(prefix, fromPath, root string) []loadCandidate {
+	if root == "" {
+		root = filepath.Dir(fromPath)
+	}
+
+	var candidates []loadCandidate
+	switch {
+	case prefix == "" || strings.HasPrefix(prefix, ":"):
+		candidates = append(candidates, bzlFileCandidates(filepath.Dir(fromPath), ":")...)
+		if prefix == "" {
+			candidates = append(candidates, packageCandidates(root, "", "")...)
+		}
+
+	case strings.HasPrefix(prefix, "//"):
+		rest := prefix[len("//"):]
+		if pkg, _, ok := strings.Cut(rest, ":"); ok {
+			candidates = bzlFileCandidates(filepath.Join(root, pkg), "//"+pkg+":")
+			break
+		}
+		dir, base := "", rest
+		if i := strings.LastIndex(rest, "/"); i >= 0 {
+			dir, base = rest[:i], rest[i+1:]
+		}
+		candidates = packageCandidates(root, dir, base)
+		if base != "" || rest == "" {
+			candidates = append(candidates, bzlFileCandidates(filepath.Join(root, rest), "//"+rest+":")...)
+		}
+	}
+
+	var matches []loadCandidate
+	for _, c := range candidates {
+		if strings.HasPrefix(c.label, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].label < matches[j].label })
+	return matches
+}
+
+// packageCandidates lists the directories in root/dir whose names start with
+// base, as "//dir/name" labels. Hidden directories and Bazel's output
+// symlinks are skipped.
+func packageCandidates(root, dir, base string) []loadCandidate {
+	entries, err := os.ReadDir(filepath.Join(root, dir))
+	if err != nil {
+		return nil
+	}
+	var candidates []loadCandidate
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, base) ||
+			strings.HasPrefix(name, ".") || strings.HasPrefix(name, "bazel-") {
+			continue
+		}
+		label := "//" + name
+		if dir != "" {
+			label = "//" + dir + "/" + name
+		}
+		detail := "directory"
+		if isPackageDir(filepath.Join(root, dir, name)) {
+			detail = "package"
+		}
+		candidates = append(candidates, loadCandidate{label: label, kind: protocol.CompletionItemKindFolder, detail: detail})
+	}
+	return candidates
+}
+
+// bzlFileCandidates lists the .bzl files in dir, as labels starting with
+// labelPrefix.
+func bzlFileCandidates(dir, labelPrefix string) []loadCandidate {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var candidates []loadCandidate
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".bzl") {
+			continue
+		}
+		candidates = append(candidates, loadCandidate{label: labelPrefix + entry.Name(), kind: protocol.CompletionItemKindFile, detail: "file"})
+	}
+	return candidates
+}
+
+func isPackageDir(dir string) bool {
+	return isRegularFile(filepath.Join(dir, "BUILD.bazel")) || isRegularFile(filepath.Join(dir, "BUILD"))
+}
+
+// symbolCandidates suggests the public top-level functions and variables of
+// the loaded module that start with the typed prefix and are not already
+// loaded by the statement. An open document is read from the editor buffer
+// rather than from disk.
+func (s *Server) symbolCandidates(lc loadContext, fromPath, rootURI string) []loadCandidate {
+	modulePath := resolveLoadPath(lc.module, fromPath, rootURI)
+	if modulePath == "" {
+		return nil
+	}
+
+	// Clients escape URIs differently, so compare paths rather than keys
+	var content []byte
+	open := false
+	s.mu.RLock()
+	for docURI, doc := range s.documents {
+		if uriToPath(docURI) == modulePath {
+			content, open = []byte(doc.Content), true
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if !open {
+		var err error
+		if content, err = os.ReadFile(modulePath); err != nil {
+			return nil
+		}
+	}
+	f, err := build.ParseDefault(modulePath, content)
+	if err != nil {
+		return nil
+	}
+
+	var candidates []loadCandidate
+	add := func(name string, kind protocol.CompletionItemKind) {
+		if strings.HasPrefix(name, "_") || !strings.HasPrefix(name, lc.prefix) || slices.Contains(lc.loaded, name) {
+			return
+		}
+		candidates = append(candidates, loadCandidate{label: name, kind: kind, detail: "from " + lc.module})
+	}
+	for _, stmt := range f.Stmt {
+		switch st := stmt.(type) {
+		case *build.DefStmt:
+			add(st.Name, protocol.CompletionItemKindFunction)
+		case *build.AssignExpr:
+			if ident, ok := st.LHS.(*build.Ident); ok {
+				add(ident.Name, protocol.CompletionItemKindVariable)
+			}
+		}
+	}
+	return candidates
+}
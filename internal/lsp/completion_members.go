@@ -0,0 +1,171 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/builtins"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+// getTypeMemberCompletions completes the fields and methods of a builtin
+// type after ".", when receiver resolves to one (see resolveReceiverType).
+func (s *Server) getTypeMemberCompletions(receiver, prefix string, doc *Document, line int) []protocol.CompletionItem {
+	if s.builtins == nil {
+		return nil
+	}
+	b, err := s.builtins.Builtins(s.getDialectAndKind(doc.URI))
+	if err != nil {
+		return nil
+	}
+	typ, ok := resolveReceiverType(receiver, b, s.parseForMembers(doc, line), line)
+	if !ok {
+		return nil
+	}
+
+	var items []protocol.CompletionItem
+	for _, field := range typ.Fields {
+		if strings.HasPrefix(field.Name, prefix) {
+			detail := field.Type
+			if field.Doc != "" {
+				detail = field.Doc
+			}
+			items = append(items, completionItem(field.Name, protocol.CompletionItemKindField, detail, false))
+		}
+	}
+	for _, method := range typ.Methods {
+		if strings.HasPrefix(method.Name, prefix) {
+			items = append(items, completionItem(method.Name, protocol.CompletionItemKindMethod, formatFunctionDetail(method), true))
+		}
+	}
+	return items
+}
+
+// resolveReceiverType resolves a dotted receiver such as "ctx.actions" to
+// a builtin type. The first name resolves as:
+//
+//   - a type of that name, which covers parameters named by convention after
+//     their type, such as ctx and repository_ctx;
+//   - a global of a builtin type, such as native;
+//   - a variable assigned the result of a builtin function or type, such as
+//     x = depset(...).
+//
+// Each further name must be a field of a builtin type.
+func resolveReceiverType(receiver string, b builtins.Builtins, f *build.File, line int) (builtins.TypeDef, bool) {
+	types := make(map[string]builtins.TypeDef, len(b.Types))
+	for _, t := range b.Types {
+		types[t.Name] = t
+	}
+	lookup := func(typeName string) (builtins.TypeDef, bool) {
+		t, ok := types[baseTypeName(typeName)]
+		return t, ok
+	}
+
+	names := strings.Split(receiver, ".")
+	typ, ok := types[names[0]]
+	if !ok {
+		typ, ok = globalType(names[0], b, lookup)
+	}
+	if !ok {
+		typ, ok = variableType(names[0], b, f, line, lookup)
+	}
+	if !ok {
+		return builtins.TypeDef{}, false
+	}
+
+	for _, name := range names[1:] {
+		next, found := builtins.TypeDef{}, false
+		for _, field := range typ.Fields {
+			if field.Name == name {
+				next, found = lookup(field.Type)
+				break
+			}
+		}
+		if !found {
+			return builtins.TypeDef{}, false
+		}
+		typ = next
+	}
+	return typ, true
+}
+
+func globalType(name string, b builtins.Builtins, lookup func(string) (builtins.TypeDef, bool)) (builtins.TypeDef, bool) {
+	for _, g := range b.Globals {
+		if g.Name == name {
+			return lookup(g.Type)
+		}
+	}
+	return builtins.TypeDef{}, false
+}
+
+// parseForMembers parses doc for resolving a receiver on line (0-based). The
+// member access being typed usually keeps the document from parsing, so the
+// cursor's line is then blanked and the rest parsed on its own. It returns
+// nil if neither parses.
+func (s *Server) parseForMembers(doc *Document, line int) *build.File {
+	if f, err := s.parseDocument(doc, filekind.KindStarlark); err == nil {
+		return f
+	}
+	s.mu.RLock()
+	content := doc.Content
+	s.mu.RUnlock()
+	start, ok := lineOffset(content, line, 0)
+	if !ok {
+		return nil
+	}
+	end := start + strings.IndexByte(content[start:]+"\n", '\n')
+	f, err := build.ParseDefault("", []byte(content[:start]+strings.Repeat(" ", end-start)+content[end:]))
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+// variableType finds the last assignment to name before line (0-based) in f
+// and resolves the type returned by the builtin it calls.
+func variableType(name string, b builtins.Builtins, f *build.File, line int, lookup func(string) (builtins.TypeDef, bool)) (builtins.TypeDef, bool) {
+	if f == nil {
+		return builtins.TypeDef{}, false
+	}
+
+	var callee string
+	build.Walk(f, func(expr build.Expr, _ []build.Expr) {
+		assign, ok := expr.(*build.AssignExpr)
+		if !ok || assign.Op != "=" {
+			return
+		}
+		if lhs, ok := assign.LHS.(*build.Ident); !ok || lhs.Name != name {
+			return
+		}
+		if pos, _ := assign.Span(); pos.Line > line {
+			return
+		}
+		callee = ""
+		if call, ok := assign.RHS.(*build.CallExpr); ok {
+			if fn, ok := call.X.(*build.Ident); ok {
+				callee = fn.Name
+			}
+		}
+	})
+	if callee == "" {
+		return builtins.TypeDef{}, false
+	}
+
+	for _, fn := range b.Functions {
+		if fn.Name == callee {
+			return lookup(fn.ReturnType)
+		}
+	}
+	// Calling a type, as in depset(...), constructs a value of that type
+	return lookup(callee)
+}
+
+// baseTypeName strips type parameters and optionality from a type string, so
+// that "depset[File]" and "File | None" name depset and File.
+func baseTypeName(typeName string) string {
+	typeName, _, _ = strings.Cut(typeName, "|")
+	typeName, _, _ = strings.Cut(typeName, "[")
+	return strings.TrimSpace(typeName)
+}
@@ -3,9 +3,14 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/builtins"
 )
 
 // These tests document expected LSP completion behavior.
@@ -305,3 +310,230 @@ func TestCompletion_EmptyFile(t *testing.T) {
 		t.Error("handleCompletion() returned 0 items for empty file, want builtins/keywords")
 	}
 }
+
+func TestFindLoadContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		char    int
+		want    loadContext
+		wantOK  bool
+	}{
+		{name: "module", content: `load("//li`, char: 10, want: loadContext{prefix: "//li"}, wantOK: true},
+		{name: "empty module", content: `load("")`, char: 6, want: loadContext{}, wantOK: true},
+		{
+			name:    "symbol",
+			content: `load("//lib:defs.bzl", "a", b = "my`,
+			char:    35,
+			want:    loadContext{arg: 2, prefix: "my", module: "//lib:defs.bzl", loaded: []string{"a"}},
+			wantOK:  true,
+		},
+		{
+			name:    "multi-line",
+			content: "x = 1\nload(\n    \"//lib:defs.bzl\",\n    'r",
+			line:    3,
+			char:    6,
+			want:    loadContext{arg: 1, prefix: "r", module: "//lib:defs.bzl"},
+			wantOK:  true,
+		},
+		{name: "between arguments", content: `load("//lib:defs.bzl", `, char: 23},
+		{name: "after load", content: `load("//lib:defs.bzl", "a")` + "\nx = \"", line: 1, char: 5},
+		{name: "not load", content: `foo("//li`, char: 9},
+		{name: "nested load call", content: `x = load("//li`, char: 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := findLoadContext(tt.content, tt.line, tt.char)
+			if ok != tt.wantOK {
+				t.Fatalf("findLoadContext() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.arg != tt.want.arg || got.prefix != tt.want.prefix || got.module != tt.want.module ||
+				strings.Join(got.loaded, ",") != strings.Join(tt.want.loaded, ",") {
+				t.Errorf("findLoadContext() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletion_LoadLabels(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		"lib/BUILD.bazel",
+		"lib/defs.bzl",
+		"lib/util.bzl",
+		"lib/README.md",
+		"lib/sub/BUILD",
+		"libexec/tool.sh",
+		"app/BUILD.bazel",
+		"app/local.bzl",
+		".git/config",
+		"bazel-out/x",
+	} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"//li", []string{"//lib", "//libexec"}},
+		{"//lib", []string{"//lib", "//lib:defs.bzl", "//lib:util.bzl", "//libexec"}},
+		{"//lib/", []string{"//lib/sub"}},
+		{"//lib:u", []string{"//lib:util.bzl"}},
+		{"//", []string{"//app", "//lib", "//libexec"}},
+		{":", []string{":local.bzl"}},
+		{"@rules_cc//", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			s := NewServer(nil)
+			s.rootURI = "file://" + root
+			uri := "file://" + filepath.Join(root, "app", "BUILD.bazel")
+			content := `load("` + tt.prefix
+			s.documents[uri] = &Document{URI: uri, Version: 1, Content: content}
+
+			list := completeAt(t, s, uri, 0, len(content))
+			var got []string
+			for _, item := range list.Items {
+				got = append(got, item.Label)
+				edit, ok := item.TextEdit.Value.(protocol.TextEdit)
+				if !ok || edit.NewText != item.Label || edit.Range.Start.Character != 6 || int(edit.Range.End.Character) != len(content) {
+					t.Errorf("item %q: unexpected text edit %+v", item.Label, item.TextEdit.Value)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("labels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletion_LoadSymbols(t *testing.T) {
+	root := t.TempDir()
+	defs := "def my_rule(name):\n    pass\n\ndef _private():\n    pass\n\nMY_CONST = 1\n\ndef my_macro():\n    pass\n"
+	if err := os.MkdirAll(filepath.Join(root, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "lib", "defs.bzl"), []byte(defs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(nil)
+	s.rootURI = "file://" + root
+	uri := "file://" + filepath.Join(root, "BUILD.bazel")
+	content := `load("//lib:defs.bzl", "my_macro", "`
+	s.documents[uri] = &Document{URI: uri, Version: 1, Content: content}
+
+	list := completeAt(t, s, uri, 0, len(content))
+	var got []string
+	for _, item := range list.Items {
+		got = append(got, item.Label)
+	}
+	if want := []string{"my_rule", "MY_CONST"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("symbols = %v, want %v", got, want)
+	}
+
+	// An open document is read from the editor rather than from disk.
+	defsURI := "file://" + filepath.Join(root, "lib", "defs.bzl")
+	s.documents[defsURI] = &Document{URI: defsURI, Version: 2, Content: "def unsaved():\n    pass\n"}
+	list = completeAt(t, s, uri, 0, len(content))
+	if len(list.Items) != 1 || list.Items[0].Label != "unsaved" {
+		t.Errorf("expected symbols from the open document, got %+v", list.Items)
+	}
+}
+
+func completeAt(t *testing.T, s *Server, uri string, line, char int) *protocol.CompletionList {
+	t.Helper()
+	params, _ := json.Marshal(protocol.CompletionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{Uri: uri},
+			Position:     protocol.Position{Line: uint32(line), Character: uint32(char)},
+		},
+	})
+	result, err := s.handleCompletion(context.Background(), params)
+	if err != nil {
+		t.Fatalf("handleCompletion() error = %v", err)
+	}
+	list, ok := result.(*protocol.CompletionList)
+	if !ok {
+		t.Fatalf("handleCompletion() returned %T", result)
+	}
+	return list
+}
+
+func TestCompletion_TypeMembers(t *testing.T) {
+	provider := &mockProvider{
+		builtins: builtins.Builtins{
+			Functions: []builtins.Signature{
+				{Name: "depset", ReturnType: "depset"},
+				{Name: "struct", ReturnType: "struct"},
+			},
+			Types: []builtins.TypeDef{
+				{
+					Name: "ctx",
+					Fields: []builtins.Field{
+						{Name: "actions", Type: "actions"},
+						{Name: "attr", Type: "struct"},
+						{Name: "label", Type: "Label"},
+					},
+					Methods: []builtins.Signature{{Name: "expand_location"}},
+				},
+				{
+					Name:    "actions",
+					Methods: []builtins.Signature{{Name: "declare_file"}, {Name: "run"}, {Name: "run_shell"}},
+				},
+				{
+					Name:    "depset",
+					Methods: []builtins.Signature{{Name: "to_list"}},
+				},
+				{
+					Name:   "native_module",
+					Fields: []builtins.Field{{Name: "package_name", Type: "function"}},
+				},
+			},
+			Globals: []builtins.Field{{Name: "native", Type: "native_module"}},
+		},
+	}
+	s := NewServerWithProvider(nil, provider)
+	uri := "file:///ws/rules.bzl"
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"type name", "def _impl(ctx):\n    ctx.", []string{"actions", "attr", "label", "expand_location"}},
+		{"member prefix", "def _impl(ctx):\n    ctx.a", []string{"actions", "attr"}},
+		{"field chain", "def _impl(ctx):\n    ctx.actions.run", []string{"run", "run_shell"}},
+		{"global", "native.", []string{"package_name"}},
+		{"variable", "d = depset([])\nd.", []string{"to_list"}},
+		{"reassigned variable", "d = depset([])\nd = struct()\nd.", nil},
+		{"unknown field", "def _impl(ctx):\n    ctx.label.", nil},
+		{"unknown name", "foo.", nil},
+		{"module", "json.enc", []string{"encode"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.documents[uri] = &Document{URI: uri, Version: 1, Content: tt.content}
+			lines := strings.Split(tt.content, "\n")
+			list := completeAt(t, s, uri, len(lines)-1, len(lines[len(lines)-1]))
+			var got []string
+			for _, item := range list.Items {
+				got = append(got, item.Label)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("completions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
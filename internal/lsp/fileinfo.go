@@ -0,0 +1,51 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/classifier"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+// FileInfo is the result of the custom sky/fileInfo request: how skyls
+// classified a document, which decides the builtins offered by completion
+// and hover and the lint rules that run.
+type FileInfo struct {
+	URI      string        `json:"uri"`
+	FileKind filekind.Kind `json:"fileKind"`
+	Dialect  string        `json:"dialect"`
+	// ConfigPath is the .skyfiletypes file that set the classification, if
+	// any; otherwise it came from the file name.
+	ConfigPath string `json:"configPath,omitempty"`
+}
+
+// handleFileInfo answers sky/fileInfo, whose params are
+// {"textDocument": {"uri": ...}}. The document need not be open, since
+// classification only uses its path.
+func (s *Server) handleFileInfo(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	info := FileInfo{URI: p.TextDocument.Uri, FileKind: filekind.KindUnknown, Dialect: "starlark"}
+	path := uriToPath(p.TextDocument.Uri)
+	if path == "" {
+		return info, nil
+	}
+	classification, err := classifier.New().Classify(path)
+	if err != nil {
+		// Same fallback as getDialectAndKind
+		log.Printf("fileInfo: %s: %v", info.URI, err)
+		return info, nil
+	}
+	info.FileKind = classification.FileKind
+	info.Dialect = classification.Dialect
+	info.ConfigPath = classification.ConfigPath
+	return info, nil
+}
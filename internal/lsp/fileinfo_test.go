@@ -0,0 +1,48 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+func TestHandleFileInfo(t *testing.T) {
+	dir := t.TempDir()
+	overrides := filepath.Join(dir, ".skyfiletypes")
+	if err := os.WriteFile(overrides, []byte("*.tpl bzl\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantKind    filekind.Kind
+		wantDialect string
+		wantConfig  string
+	}{
+		{name: "BUILD", path: "pkg/BUILD.bazel", wantKind: filekind.KindBUILD, wantDialect: "bazel"},
+		{name: "bzl", path: "defs.bzl", wantKind: filekind.KindBzl, wantDialect: "bazel"},
+		{name: "star", path: "script.star", wantKind: filekind.KindStarlark, wantDialect: "starlark"},
+		{name: "override", path: "rules.tpl", wantKind: filekind.KindBzl, wantDialect: "bazel", wantConfig: overrides},
+		{name: "unknown", path: "notes.txt", wantKind: filekind.KindUnknown, wantDialect: "starlark"},
+	}
+	server := NewServer(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri := "file://" + filepath.Join(dir, tt.path)
+			params, _ := json.Marshal(map[string]any{"textDocument": map[string]string{"uri": uri}})
+			result, err := server.handleFileInfo(context.Background(), params)
+			if err != nil {
+				t.Fatalf("handleFileInfo() error = %v", err)
+			}
+			info := result.(FileInfo)
+			if info.URI != uri || info.FileKind != tt.wantKind || info.Dialect != tt.wantDialect || info.ConfigPath != tt.wantConfig {
+				t.Errorf("got %+v, want kind %s, dialect %s, config %q", info, tt.wantKind, tt.wantDialect, tt.wantConfig)
+			}
+		})
+	}
+}
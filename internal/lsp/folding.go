@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 	"github.com/bazelbuild/buildtools/build"
 )
 
@@ -22,88 +23,50 @@ func (s *Server) handleFoldingRange(ctx context.Context, params json.RawMessage)
 		return []protocol.FoldingRange{}, nil
 	}
 
-	path := uriToPath(p.TextDocument.Uri)
-	file, err := build.ParseDefault(path, []byte(doc.Content))
+	file, err := s.parseDocument(doc, filekind.KindStarlark)
 	if err != nil {
 		return []protocol.FoldingRange{}, nil
 	}
 
-	var ranges []protocol.FoldingRange
-	collectFoldingRanges(file.Stmt, &ranges)
-
+	ranges := collectFoldingRanges(file.Stmt)
+	if ranges == nil {
+		ranges = []protocol.FoldingRange{}
+	}
 	return ranges, nil
 }
 
-func collectFoldingRanges(stmts []build.Expr, ranges *[]protocol.FoldingRange) {
+// collectFoldingRanges returns a range for each statement and expression
+// that spans several lines: def, for, and if blocks, load statements, call
+// argument lists, and list, dict, tuple, and comprehension literals, at any
+// depth. When several nodes start on the same line, only the outermost is
+// kept, since clients fold one range per line.
+func collectFoldingRanges(stmts []build.Expr) []protocol.FoldingRange {
+	var ranges []protocol.FoldingRange
+	seen := make(map[int]bool)
 	for _, stmt := range stmts {
-		collectFoldingRangesFromExpr(stmt, ranges)
-	}
-}
-
-func collectFoldingRangesFromExpr(expr build.Expr, ranges *[]protocol.FoldingRange) {
-	switch e := expr.(type) {
-	case *build.DefStmt:
-		start, end := e.Span()
-		if end.Line > start.Line {
-			*ranges = append(*ranges, protocol.FoldingRange{
-				StartLine: uint32(start.Line - 1),
-				EndLine:   uint32(end.Line - 1),
-				Kind:      "region",
-			})
-		}
-		collectFoldingRanges(e.Body, ranges)
-
-	case *build.ForStmt:
-		start, end := e.Span()
-		if end.Line > start.Line {
-			*ranges = append(*ranges, protocol.FoldingRange{
-				StartLine: uint32(start.Line - 1),
-				EndLine:   uint32(end.Line - 1),
-				Kind:      "region",
-			})
-		}
-		collectFoldingRanges(e.Body, ranges)
-
-	case *build.IfStmt:
-		start, end := e.Span()
-		if end.Line > start.Line {
-			*ranges = append(*ranges, protocol.FoldingRange{
-				StartLine: uint32(start.Line - 1),
-				EndLine:   uint32(end.Line - 1),
-				Kind:      "region",
-			})
-		}
-		collectFoldingRanges(e.True, ranges)
-		collectFoldingRanges(e.False, ranges)
-
-	case *build.CallExpr:
-		start, end := e.Span()
-		if end.Line > start.Line {
-			*ranges = append(*ranges, protocol.FoldingRange{
-				StartLine: uint32(start.Line - 1),
-				EndLine:   uint32(end.Line - 1),
-				Kind:      "region",
-			})
-		}
-
-	case *build.ListExpr:
-		start, end := e.Span()
-		if end.Line > start.Line {
-			*ranges = append(*ranges, protocol.FoldingRange{
-				StartLine: uint32(start.Line - 1),
-				EndLine:   uint32(end.Line - 1),
-				Kind:      "region",
-			})
-		}
-
-	case *build.DictExpr:
-		start, end := e.Span()
-		if end.Line > start.Line {
-			*ranges = append(*ranges, protocol.FoldingRange{
+		build.Walk(stmt, func(x build.Expr, _ []build.Expr) {
+			var kind protocol.FoldingRangeKind
+			switch x.(type) {
+			case *build.DefStmt, *build.ForStmt, *build.IfStmt,
+				*build.CallExpr, *build.ListExpr, *build.DictExpr,
+				*build.TupleExpr, *build.Comprehension:
+				kind = protocol.FoldingRangeKindRegion
+			case *build.LoadStmt:
+				kind = protocol.FoldingRangeKindImports
+			default:
+				return
+			}
+			start, end := x.Span()
+			if end.Line <= start.Line || seen[start.Line] {
+				return
+			}
+			seen[start.Line] = true
+			ranges = append(ranges, protocol.FoldingRange{
 				StartLine: uint32(start.Line - 1),
 				EndLine:   uint32(end.Line - 1),
-				Kind:      "region",
+				Kind:      kind,
 			})
-		}
+		})
 	}
+	return ranges
 }
@@ -0,0 +1,86 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+)
+
+func TestFoldingRange(t *testing.T) {
+	content := `load(
+    "//rules:defs.bzl",
+    "my_rule",
+)
+
+cc_library(
+    name = "lib",
+    srcs = ["a.cc"],
+    deps = [
+        "//base",
+        "//util",
+    ],
+    copts = select({
+        "//conditions:default": [],
+    }),
+)
+
+CONFIG = {
+    "a": 1,
+    "b": 2,
+}
+
+def helper(ctx):
+    return ctx.actions.run(
+        outputs = [],
+    )
+`
+	uri := "file:///test/BUILD.bazel"
+	server := NewServer(nil)
+	server.documents[uri] = &Document{URI: uri, Version: 1, Content: content}
+
+	params, _ := json.Marshal(protocol.FoldingRangeParams{
+		TextDocument: protocol.TextDocumentIdentifier{Uri: uri},
+	})
+	result, err := server.handleFoldingRange(context.Background(), params)
+	if err != nil {
+		t.Fatalf("handleFoldingRange() error = %v", err)
+	}
+	ranges, ok := result.([]protocol.FoldingRange)
+	if !ok {
+		t.Fatalf("handleFoldingRange() returned %T", result)
+	}
+
+	want := []protocol.FoldingRange{
+		{StartLine: 0, EndLine: 3, Kind: protocol.FoldingRangeKindImports},
+		{StartLine: 5, EndLine: 15, Kind: protocol.FoldingRangeKindRegion},  // cc_library(
+		{StartLine: 8, EndLine: 11, Kind: protocol.FoldingRangeKindRegion},  // deps = [
+		{StartLine: 12, EndLine: 14, Kind: protocol.FoldingRangeKindRegion}, // select({, the call and dict share a line
+		{StartLine: 17, EndLine: 20, Kind: protocol.FoldingRangeKindRegion}, // CONFIG = {
+		{StartLine: 22, EndLine: 25, Kind: protocol.FoldingRangeKindRegion}, // def helper
+		{StartLine: 23, EndLine: 25, Kind: protocol.FoldingRangeKindRegion}, // ctx.actions.run(
+	}
+	if len(ranges) != len(want) {
+		t.Fatalf("got %d ranges, want %d: %+v", len(ranges), len(want), ranges)
+	}
+	for i := range want {
+		if ranges[i] != want[i] {
+			t.Errorf("range %d = %+v, want %+v", i, ranges[i], want[i])
+		}
+	}
+}
+
+func TestFoldingRange_UnknownDocument(t *testing.T) {
+	server := NewServer(nil)
+	params, _ := json.Marshal(protocol.FoldingRangeParams{
+		TextDocument: protocol.TextDocumentIdentifier{Uri: "file:///missing.star"},
+	})
+	result, err := server.handleFoldingRange(context.Background(), params)
+	if err != nil {
+		t.Fatalf("handleFoldingRange() error = %v", err)
+	}
+	if ranges, ok := result.([]protocol.FoldingRange); !ok || len(ranges) != 0 {
+		t.Errorf("expected no ranges, got %#v", result)
+	}
+}
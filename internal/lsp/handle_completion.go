@@ -11,6 +11,7 @@ import (
 
 	"github.com/albertocavalcante/sky/internal/protocol"
 	"github.com/albertocavalcante/sky/internal/starlark/builtins"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 )
 
 func (s *Server) handleCompletion(ctx context.Context, params json.RawMessage) (any, error) {
@@ -24,18 +25,28 @@ func (s *Server) handleCompletion(ctx context.Context, params json.RawMessage) (
 	doc, ok := s.documents[p.TextDocument.Uri]
 	var content string
 	var docURI string
+	var version int32
 	if ok {
 		content = doc.Content
 		docURI = doc.URI
+		version = doc.Version
 	}
+	rootURI := s.rootURI
 	s.mu.RUnlock()
 
 	if !ok {
 		return &protocol.CompletionList{Items: []protocol.CompletionItem{}}, nil
 	}
 
+	line, character := int(p.Position.Line), int(p.Position.Character)
+	if lc, ok := findLoadContext(content, line, character); ok {
+		return &protocol.CompletionList{
+			Items: s.getLoadCompletions(lc, p.TextDocument.Uri, rootURI, line, character),
+		}, nil
+	}
+
 	// Create a local document snapshot for completion
-	docSnapshot := &Document{URI: docURI, Content: content}
+	docSnapshot := &Document{URI: docURI, Version: version, Content: content}
 
 	// Get the prefix being typed
 	prefix := getCompletionPrefix(content, int(p.Position.Line), int(p.Position.Character))
@@ -47,6 +58,10 @@ func (s *Server) handleCompletion(ctx context.Context, params json.RawMessage) (
 		moduleName := prefix[:dotIdx]
 		memberPrefix := prefix[dotIdx+1:]
 		items = getModuleMemberCompletions(moduleName, memberPrefix)
+		if items == nil {
+			// Not a module; try a builtin type such as ctx or depset
+			items = s.getTypeMemberCompletions(moduleName, memberPrefix, docSnapshot, line)
+		}
 	} else {
 		// Complete keywords, builtins, and document symbols
 		// Use provider-aware keyword completions to avoid duplicates
@@ -277,7 +292,7 @@ func (s *Server) getDocumentSymbolCompletions(doc *Document, prefix string, line
 	var items []protocol.CompletionItem
 
 	// Parse the document to find defined symbols
-	f, err := build.ParseDefault(string(doc.URI), []byte(doc.Content))
+	f, err := s.parseDocument(doc, filekind.KindStarlark)
 	if err != nil {
 		return items
 	}
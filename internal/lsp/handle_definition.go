@@ -36,13 +36,13 @@ func (s *Server) handleDefinition(ctx context.Context, params json.RawMessage) (
 	log.Printf("definition: %s @ %d:%d -> %q", path, p.Position.Line, p.Position.Character, word)
 
 	// Classify and parse the file
-	cls := classifier.NewDefaultClassifier()
+	cls := classifier.New()
 	classification, err := cls.Classify(path)
 	if err != nil {
 		classification.FileKind = filekind.KindStarlark
 	}
 
-	file, err := parseStarlarkFile([]byte(doc.Content), path, classification.FileKind)
+	file, err := s.parseDocument(doc, classification.FileKind)
 	if err != nil {
 		log.Printf("definition: parse error: %v", err)
 		return nil, nil
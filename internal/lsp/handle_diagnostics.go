@@ -2,11 +2,14 @@ package lsp
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
-	"github.com/albertocavalcante/sky/internal/starlark/checker"
+	"github.com/albertocavalcante/sky/internal/starlark/classifier"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 	"github.com/albertocavalcante/sky/internal/starlark/linter"
+	"github.com/albertocavalcante/sky/internal/starlark/validator"
 )
 
 // publishDiagnostics runs linter and checker on a document and publishes results.
@@ -17,12 +20,13 @@ func (s *Server) publishDiagnostics(ctx context.Context, uri string, content str
 	}
 
 	path := uriToPath(uri)
-	var diagnostics []protocol.Diagnostic
+	s.publishOverridesDiagnostics(ctx, path)
+	var found []validator.Diagnostic
 
-	// Run linter (reads from disk - works for didSave)
-	if findings, err := s.lintDriver.RunFile(path); err == nil {
+	// Run linter on the content from memory, so unsaved edits are linted
+	if findings, err := s.lintDocument(uri, content); err == nil {
 		for _, f := range findings {
-			diagnostics = append(diagnostics, lintFindingToDiagnostic(f))
+			found = append(found, f.ToDiagnostic(path))
 		}
 	} else {
 		log.Printf("linter error: %v", err)
@@ -31,12 +35,20 @@ func (s *Server) publishDiagnostics(ctx context.Context, uri string, content str
 	// Run semantic checker (uses content from memory)
 	if checkerDiags, err := s.checker.CheckFile(path, []byte(content)); err == nil {
 		for _, d := range checkerDiags {
-			diagnostics = append(diagnostics, checkerDiagnosticToLSP(d))
+			found = append(found, d.ToDiagnostic(path))
 		}
 	} else {
 		log.Printf("checker error: %v", err)
 	}
 
+	diagnostics := make([]protocol.Diagnostic, 0, len(found))
+	for _, d := range found {
+		diagnostics = append(diagnostics, diagnosticToLSP(d))
+	}
+
+	// Keep a broken file from flooding the editor
+	diagnostics = capDiagnostics(diagnostics, s.currentSettings().MaxDiagnostics)
+
 	// Publish diagnostics to client
 	if err := s.conn.Notify(ctx, "textDocument/publishDiagnostics", protocol.PublishDiagnosticsParams{
 		Uri:         uri,
@@ -48,56 +60,86 @@ func (s *Server) publishDiagnostics(ctx context.Context, uri string, content str
 	log.Printf("published %d diagnostics for %s", len(diagnostics), path)
 }
 
-// lintFindingToDiagnostic converts a linter finding to an LSP diagnostic.
-func lintFindingToDiagnostic(f linter.Finding) protocol.Diagnostic {
-	// Convert 1-based to 0-based positions
-	startLine := uint32(0)
-	if f.Line > 0 {
-		startLine = uint32(f.Line - 1)
+// publishOverridesDiagnostics publishes the error of the .skyfiletypes file
+// that applies to path, if it is invalid and so ignored, as a diagnostic on
+// that file. It clears the diagnostic once the file is valid again, and only
+// publishes when the error changes.
+func (s *Server) publishOverridesDiagnostics(ctx context.Context, path string) {
+	file, err := classifier.OverridesFor(path)
+	if file == "" {
+		return
 	}
-	startChar := uint32(0)
-	if f.Column > 0 {
-		startChar = uint32(f.Column - 1)
+	message := ""
+	if err != nil {
+		message = err.Error()
 	}
-	endLine := startLine
-	if f.EndLine > 0 {
-		endLine = uint32(f.EndLine - 1)
+	s.mu.Lock()
+	previous, published := s.overridesErrors[file]
+	if previous == message && (published || err == nil) {
+		s.mu.Unlock()
+		return
 	}
-	endChar := startChar + 1 // Default to single character
-	if f.EndColumn > 0 {
-		endChar = uint32(f.EndColumn - 1)
+	s.overridesErrors[file] = message
+	s.mu.Unlock()
+
+	diagnostics := []protocol.Diagnostic{}
+	if err != nil {
+		line := uint32(0)
+		var oe *classifier.OverridesError
+		if errors.As(err, &oe) {
+			message = oe.Err.Error()
+			if oe.Line > 0 {
+				line = uint32(oe.Line - 1)
+			}
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: line},
+				End:   protocol.Position{Line: line + 1},
+			},
+			Severity: protocol.DiagnosticSeverityError,
+			Source:   "skyls",
+			Message:  message + "; file types in this file are ignored",
+		})
+	}
+	if err := s.conn.Notify(ctx, "textDocument/publishDiagnostics", protocol.PublishDiagnosticsParams{
+		Uri:         pathToURI(file),
+		Diagnostics: diagnostics,
+	}); err != nil {
+		log.Printf("failed to publish diagnostics: %v", err)
 	}
+}
 
-	return protocol.Diagnostic{
-		Range: protocol.Range{
-			Start: protocol.Position{Line: startLine, Character: startChar},
-			End:   protocol.Position{Line: endLine, Character: endChar},
-		},
-		Severity: lintSeverityToLSP(f.Severity),
-		Code:     protocol.Or_int32_string{Value: f.Rule},
-		Source:   "skylint",
-		Message:  f.Message,
+// lintDocument lints content as the file kind the document is classified
+// as, so that rules meant for other kinds, such as BUILD-only rules in a
+// .bzl file, do not fire.
+func (s *Server) lintDocument(uri, content string) ([]linter.Finding, error) {
+	_, kind := s.getDialectAndKind(uri)
+	if kind == filekind.KindUnknown {
+		kind = filekind.KindStarlark
 	}
+	return s.lintDriver.RunContentKind(uriToPath(uri), []byte(content), kind)
 }
 
-// checkerDiagnosticToLSP converts a checker diagnostic to an LSP diagnostic.
-func checkerDiagnosticToLSP(d checker.Diagnostic) protocol.Diagnostic {
+// diagnosticToLSP converts a linter or checker diagnostic, in their shared
+// validator representation, to an LSP diagnostic.
+func diagnosticToLSP(d validator.Diagnostic) protocol.Diagnostic {
 	// Convert 1-based to 0-based positions
 	startLine := uint32(0)
-	if d.Pos.Line > 0 {
-		startLine = uint32(d.Pos.Line - 1)
+	if d.Line > 0 {
+		startLine = uint32(d.Line - 1)
 	}
 	startChar := uint32(0)
-	if d.Pos.Col > 0 {
-		startChar = uint32(d.Pos.Col - 1)
+	if d.Column > 0 {
+		startChar = uint32(d.Column - 1)
 	}
 	endLine := startLine
+	if d.EndLine > 0 {
+		endLine = uint32(d.EndLine - 1)
+	}
 	endChar := startChar + 1 // Default to single character
-	if d.End.Line > 0 {
-		endLine = uint32(d.End.Line - 1)
-		if d.End.Col > 0 {
-			endChar = uint32(d.End.Col - 1)
-		}
+	if d.EndColumn > 0 {
+		endChar = uint32(d.EndColumn - 1)
 	}
 
 	return protocol.Diagnostic{
@@ -105,41 +147,27 @@ func checkerDiagnosticToLSP(d checker.Diagnostic) protocol.Diagnostic {
 			Start: protocol.Position{Line: startLine, Character: startChar},
 			End:   protocol.Position{Line: endLine, Character: endChar},
 		},
-		Severity: checkerSeverityToLSP(d.Severity),
+		Severity: severityToLSP(d.Severity),
 		Code:     protocol.Or_int32_string{Value: d.Code},
-		Source:   "skycheck",
+		Source:   d.Source,
 		Message:  d.Message,
 	}
 }
 
-// lintSeverityToLSP converts linter severity to LSP severity.
-// Linter: Error=0, Warning=1, Info=2, Hint=3
+// severityToLSP converts a validator severity to an LSP severity.
+// Validator: Error=0, Warning=1, Info=2, Hint=3
 // LSP: Error=1, Warning=2, Information=3, Hint=4
-func lintSeverityToLSP(s linter.Severity) protocol.DiagnosticSeverity {
+func severityToLSP(s validator.Severity) protocol.DiagnosticSeverity {
 	switch s {
-	case linter.SeverityError:
+	case validator.SeverityError:
 		return protocol.DiagnosticSeverityError
-	case linter.SeverityWarning:
+	case validator.SeverityWarning:
 		return protocol.DiagnosticSeverityWarning
-	case linter.SeverityInfo:
+	case validator.SeverityInfo:
 		return protocol.DiagnosticSeverityInformation
-	case linter.SeverityHint:
+	case validator.SeverityHint:
 		return protocol.DiagnosticSeverityHint
 	default:
 		return protocol.DiagnosticSeverityWarning
 	}
 }
-
-// checkerSeverityToLSP converts checker severity to LSP severity.
-func checkerSeverityToLSP(s checker.Severity) protocol.DiagnosticSeverity {
-	switch s {
-	case checker.SeverityError:
-		return protocol.DiagnosticSeverityError
-	case checker.SeverityWarning:
-		return protocol.DiagnosticSeverityWarning
-	case checker.SeverityInfo:
-		return protocol.DiagnosticSeverityInformation
-	default:
-		return protocol.DiagnosticSeverityWarning
-	}
-}
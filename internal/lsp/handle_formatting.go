@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"path/filepath"
 	"strings"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
@@ -29,8 +30,13 @@ func (s *Server) handleFormatting(ctx context.Context, params json.RawMessage) (
 	path := uriToPath(p.TextDocument.Uri)
 	log.Printf("formatting: %s", path)
 
-	// Format the document content
-	formatted, err := formatter.Format([]byte(doc.Content), path, filekind.KindUnknown)
+	// Format the document content with the options skyfmt would use
+	engine, err := formatEngine(path)
+	if err != nil {
+		log.Printf("formatting: %v", err)
+		return []protocol.TextEdit{}, nil
+	}
+	formatted, err := formatter.FormatWith(engine, []byte(doc.Content), path, filekind.KindUnknown)
 	if err != nil {
 		log.Printf("formatting error: %v", err)
 		// Return empty edits on error - don't break the editor
@@ -60,3 +66,20 @@ func (s *Server) handleFormatting(ctx context.Context, params json.RawMessage) (
 		},
 	}, nil
 }
+
+// formatEngine returns the engine for formatting the document at path: the
+// default engine, with the options of the .skyfmt.json nearest the document.
+func formatEngine(path string) (formatter.Engine, error) {
+	if path == "" {
+		return formatter.Default, nil
+	}
+	configPath := formatter.FindConfig(filepath.Dir(path))
+	if configPath == "" {
+		return formatter.Default, nil
+	}
+	config, err := formatter.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return formatter.NewBuildtools(config)
+}
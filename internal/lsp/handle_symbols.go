@@ -30,14 +30,14 @@ func (s *Server) handleDocumentSymbol(ctx context.Context, params json.RawMessag
 	log.Printf("documentSymbol: %s", path)
 
 	// Classify the file to determine its kind
-	cls := classifier.NewDefaultClassifier()
+	cls := classifier.New()
 	classification, err := cls.Classify(path)
 	if err != nil {
 		classification.FileKind = filekind.KindStarlark
 	}
 
 	// Parse the document
-	file, err := parseStarlarkFile([]byte(doc.Content), path, classification.FileKind)
+	file, err := s.parseDocument(doc, classification.FileKind)
 	if err != nil {
 		log.Printf("documentSymbol parse error: %v", err)
 		return []protocol.DocumentSymbol{}, nil
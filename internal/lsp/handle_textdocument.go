@@ -41,12 +41,19 @@ func (s *Server) handleDidChange(ctx context.Context, params json.RawMessage) (a
 	s.mu.Lock()
 	if doc, ok := s.documents[p.TextDocument.Uri]; ok {
 		doc.Version = p.TextDocument.Version
-		// Full sync - take the last change
+		// Full sync - take the last change. Its text is the whole document,
+		// though it decodes as a partial change, whose range is optional.
 		if len(p.ContentChanges) > 0 {
-			doc.Content = p.ContentChanges[len(p.ContentChanges)-1].Value.(protocol.TextDocumentContentChangeWholeDocument).Text
+			switch change := p.ContentChanges[len(p.ContentChanges)-1].Value.(type) {
+			case protocol.TextDocumentContentChangeWholeDocument:
+				doc.Content = change.Text
+			case protocol.TextDocumentContentChangePartial:
+				doc.Content = change.Text
+			}
 		}
 	}
 	s.mu.Unlock()
+	s.asts.invalidate(p.TextDocument.Uri)
 
 	log.Printf("didChange: %s v%d", p.TextDocument.Uri, p.TextDocument.Version)
 	return nil, nil
@@ -61,6 +68,7 @@ func (s *Server) handleDidClose(ctx context.Context, params json.RawMessage) (an
 	s.mu.Lock()
 	delete(s.documents, p.TextDocument.Uri)
 	s.mu.Unlock()
+	s.asts.invalidate(p.TextDocument.Uri)
 
 	log.Printf("didClose: %s", p.TextDocument.Uri)
 
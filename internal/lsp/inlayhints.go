@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 	"github.com/bazelbuild/buildtools/build"
 
 	"github.com/albertocavalcante/sky/internal/types"
@@ -54,7 +55,7 @@ func (s *Server) handleInlayHint(ctx context.Context, params json.RawMessage) (a
 		p.Range.End.Line, p.Range.End.Character)
 
 	// Parse the file
-	file, err := build.ParseDefault("", []byte(content))
+	file, err := s.parseDocument(doc, filekind.KindStarlark)
 	if err != nil {
 		log.Printf("inlayHint parse error: %v", err)
 		return []protocol.InlayHint{}, nil
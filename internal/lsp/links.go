@@ -3,14 +3,17 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 	"github.com/bazelbuild/buildtools/build"
 )
 
-// handleDocumentLink returns document links for load() statements.
+// handleDocumentLink returns document links for load() statements and for
+// labels in other string literals, such as dependencies.
 func (s *Server) handleDocumentLink(ctx context.Context, params json.RawMessage) (any, error) {
 	var p protocol.DocumentLinkParams
 	if err := json.Unmarshal(params, &p); err != nil {
@@ -25,56 +28,72 @@ func (s *Server) handleDocumentLink(ctx context.Context, params json.RawMessage)
 	}
 
 	path := uriToPath(p.TextDocument.Uri)
-	file, err := build.ParseDefault(path, []byte(doc.Content))
+	file, err := s.parseDocument(doc, filekind.KindStarlark)
 	if err != nil {
 		return []protocol.DocumentLink{}, nil
 	}
 
-	var links []protocol.DocumentLink
+	links := []protocol.DocumentLink{}
+	modules := make(map[*build.StringExpr]bool)
 	for _, stmt := range file.Stmt {
 		load, ok := stmt.(*build.LoadStmt)
 		if !ok {
 			continue
 		}
+		modules[load.Module] = true
 
 		// Resolve the load path
 		targetPath := resolveLoadPath(load.Module.Value, path, string(s.rootURI))
 		if targetPath == "" {
 			continue
 		}
+		links = append(links, stringLink(load.Module, targetPath))
+	}
 
-		// Get the range of the module string
-		start, end := load.Module.Span()
-		targetURI := string("file://" + targetPath)
-
-		links = append(links, protocol.DocumentLink{
-			Range: protocol.Range{
-				Start: protocol.Position{
-					Line:      uint32(start.Line - 1),
-					Character: uint32(start.LineRune - 1),
-				},
-				End: protocol.Position{
-					Line:      uint32(end.Line - 1),
-					Character: uint32(end.LineRune - 1),
-				},
-			},
-			Target: targetURI,
+	for _, stmt := range file.Stmt {
+		build.Walk(stmt, func(x build.Expr, _ []build.Expr) {
+			str, ok := x.(*build.StringExpr)
+			if !ok || modules[str] {
+				return
+			}
+			if targetPath := resolveLabelPath(str.Value, path, string(s.rootURI)); targetPath != "" {
+				links = append(links, stringLink(str, targetPath))
+			}
 		})
 	}
 
 	return links, nil
 }
 
+// stringLink returns a link from the whole of a string literal to a file.
+func stringLink(str *build.StringExpr, targetPath string) protocol.DocumentLink {
+	start, end := str.Span()
+	return protocol.DocumentLink{
+		Range: protocol.Range{
+			Start: protocol.Position{
+				Line:      uint32(start.Line - 1),
+				Character: uint32(start.LineRune - 1),
+			},
+			End: protocol.Position{
+				Line:      uint32(end.Line - 1),
+				Character: uint32(end.LineRune - 1),
+			},
+		},
+		Target: pathToURI(targetPath),
+	}
+}
+
 // resolveLoadPath resolves a Starlark load path to an absolute file path.
 // Returns empty string if the path cannot be resolved (e.g., external repos).
 func resolveLoadPath(module, fromPath, workspaceRoot string) string {
-	// External repo - cannot resolve locally
-	if strings.HasPrefix(module, "@") {
+	// External repo - cannot resolve locally; nor can anything relative to a
+	// document that is not on disk
+	if strings.HasPrefix(module, "@") || fromPath == "" {
 		return ""
 	}
 
-	// Strip file:// prefix from workspace root
-	workspaceRoot = strings.TrimPrefix(workspaceRoot, "file://")
+	// The workspace root may be a URI or a path
+	workspaceRoot = uriToPath(workspaceRoot)
 	if workspaceRoot == "" {
 		workspaceRoot = filepath.Dir(fromPath)
 	}
@@ -106,3 +125,53 @@ func resolveLoadPath(module, fromPath, workspaceRoot string) string {
 	// Relative path
 	return filepath.Join(filepath.Dir(fromPath), module)
 }
+
+// resolveLabelPath resolves a label in a string literal to the file it
+// names or, for a rule target, to the BUILD file of its package. Labels in
+// the main repository ("//pkg:name", "@//pkg:name", ":name") are resolved;
+// other strings, labels in external repositories, and labels that resolve to
+// no existing file return the empty string. Unlike load paths, which always
+// name a file, an arbitrary string only becomes a link when the file exists.
+func resolveLabelPath(label, fromPath, workspaceRoot string) string {
+	if fromPath == "" || !isLabel(label) || strings.ContainsAny(label, " \t\n") {
+		return ""
+	}
+	// "@//pkg" and "@@//pkg" name the main repository.
+	if strings.HasPrefix(label, "@") {
+		label = strings.TrimLeft(label, "@")
+		if !strings.HasPrefix(label, "//") {
+			return ""
+		}
+	}
+
+	workspaceRoot = uriToPath(workspaceRoot)
+	if workspaceRoot == "" {
+		workspaceRoot = filepath.Dir(fromPath)
+	}
+
+	var pkgDir, name string
+	if rest, ok := strings.CutPrefix(label, "//"); ok {
+		pkg, target, _ := strings.Cut(rest, ":")
+		pkgDir, name = filepath.Join(workspaceRoot, pkg), target
+	} else {
+		pkgDir, name = filepath.Dir(fromPath), strings.TrimPrefix(label, ":")
+	}
+
+	if name != "" {
+		if candidate := filepath.Join(pkgDir, name); isRegularFile(candidate) {
+			return candidate
+		}
+	}
+	for _, buildFile := range []string{"BUILD.bazel", "BUILD"} {
+		candidate := filepath.Join(pkgDir, buildFile)
+		if candidate != fromPath && isRegularFile(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+)
+
+func TestResolveLabelPath(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		"app/BUILD.bazel",
+		"app/main.cc",
+		"lib/BUILD",
+		"lib/defs.bzl",
+		"BUILD.bazel",
+	} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from := filepath.Join(root, "app", "BUILD.bazel")
+
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"//lib:defs.bzl", "lib/defs.bzl"},
+		{"//lib:util", "lib/BUILD"},
+		{"//lib", "lib/BUILD"},
+		{"@//lib:util", "lib/BUILD"},
+		{"@@//lib:util", "lib/BUILD"},
+		{"//:root", "BUILD.bazel"},
+		{":main.cc", "app/main.cc"},
+		{":bin", ""}, // the document itself
+		{"//missing:target", ""},
+		{"@rules_cc//cc:defs.bzl", ""},
+		{"@@rules_cc+//cc:defs.bzl", ""},
+		{"main.cc", ""},
+		{"// not a label", ""},
+	}
+	for _, tt := range tests {
+		want := tt.want
+		if want != "" {
+			want = filepath.Join(root, want)
+		}
+		if got := resolveLabelPath(tt.label, from, "file://"+root); got != want {
+			t.Errorf("resolveLabelPath(%q) = %q, want %q", tt.label, got, want)
+		}
+	}
+}
+
+func TestDocumentLink_Labels(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"lib/BUILD.bazel", "lib/defs.bzl", "app/BUILD.bazel"} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content := `load("//lib:defs.bzl", "my_rule")
+
+my_rule(
+    name = "app",
+    deps = ["//lib:util", "@rules_cc//cc:lib", ":other"],
+)
+`
+	uri := "file://" + filepath.Join(root, "app", "BUILD.bazel")
+	server := NewServer(nil)
+	server.rootURI = "file://" + root
+	server.documents[uri] = &Document{URI: uri, Version: 1, Content: content}
+
+	params, _ := json.Marshal(protocol.DocumentLinkParams{
+		TextDocument: protocol.TextDocumentIdentifier{Uri: uri},
+	})
+	result, err := server.handleDocumentLink(context.Background(), params)
+	if err != nil {
+		t.Fatalf("handleDocumentLink() error = %v", err)
+	}
+	links, ok := result.([]protocol.DocumentLink)
+	if !ok {
+		t.Fatalf("handleDocumentLink() returned %T", result)
+	}
+
+	want := []protocol.DocumentLink{
+		{
+			Range:  protocol.Range{Start: protocol.Position{Line: 0, Character: 5}, End: protocol.Position{Line: 0, Character: 21}},
+			Target: "file://" + filepath.Join(root, "lib", "defs.bzl"),
+		},
+		{
+			Range:  protocol.Range{Start: protocol.Position{Line: 4, Character: 12}, End: protocol.Position{Line: 4, Character: 24}},
+			Target: "file://" + filepath.Join(root, "lib", "BUILD.bazel"),
+		},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("got %d links, want %d: %+v", len(links), len(want), links)
+	}
+	for i := range want {
+		if links[i].Range != want[i].Range || links[i].Target != want[i].Target {
+			t.Errorf("link %d = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+}
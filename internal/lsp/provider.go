@@ -2,6 +2,8 @@
 package lsp
 
 import (
+	"os"
+
 	"github.com/albertocavalcante/sky/internal/starlark/builtins"
 	"github.com/albertocavalcante/sky/internal/starlark/builtins/loader"
 	"github.com/albertocavalcante/sky/internal/starlark/classifier"
@@ -11,24 +13,36 @@ import (
 // NewDefaultProvider creates a default builtins provider that chains
 // proto-based data (for Bazel) and JSON-based data (for core Starlark).
 // This is used by NewServer to provide builtins for completion and hover.
+// Proto data files in $SKY_BUILTINS_DIR override the embedded ones.
 func NewDefaultProvider() builtins.Provider {
+	return NewDefaultProviderWithDir(os.Getenv(loader.BuiltinsDirEnv))
+}
+
+// NewDefaultProviderWithDir is like NewDefaultProvider, but takes the proto
+// data files overriding the embedded ones from dir.
+func NewDefaultProviderWithDir(dir string) builtins.Provider {
 	// ProtoProvider has Bazel builtins extracted from bazelbuild/starlark
-	proto := loader.NewProtoProvider()
+	proto := loader.NewProtoProviderWithDir(dir)
 
 	// JSONProvider has core Starlark builtins
 	json := loader.NewJSONProvider()
 
-	// Chain providers: proto first (more specific), JSON second (fallback)
-	return builtins.NewChainProvider(proto, json)
+	// Layer providers: proto (more specific) over JSON, so that a builtin
+	// both define is listed once, with the Bazel definition
+	return builtins.NewMultiProvider(json, proto)
 }
 
 // getDialectAndKind determines the dialect and file kind based on the document URI.
 // Uses the classifier to determine file type from the path.
 func (s *Server) getDialectAndKind(uri string) (string, filekind.Kind) {
 	path := uriToPath(uri)
+	if path == "" {
+		// Not a file, e.g. an untitled buffer
+		return "starlark", filekind.KindUnknown
+	}
 
-	// Use the default classifier to determine dialect and kind from path
-	cls := classifier.NewDefaultClassifier()
+	// Use the shared classifier (.skyfiletypes overrides, then name-based detection)
+	cls := classifier.New()
 	classification, err := cls.Classify(path)
 	if err != nil {
 		// Fallback to generic starlark if classification fails
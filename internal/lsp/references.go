@@ -43,13 +43,13 @@ func (s *Server) handleReferences(ctx context.Context, params json.RawMessage) (
 	log.Printf("references: %s @ %d:%d -> %q", path, p.Position.Line, p.Position.Character, word)
 
 	// Classify and parse the file
-	cls := classifier.NewDefaultClassifier()
+	cls := classifier.New()
 	classification, err := cls.Classify(path)
 	if err != nil {
 		classification.FileKind = filekind.KindStarlark
 	}
 
-	file, err := parseStarlarkFile([]byte(doc.Content), path, classification.FileKind)
+	file, err := s.parseDocument(doc, classification.FileKind)
 	if err != nil {
 		log.Printf("references: parse error: %v", err)
 		return nil, nil
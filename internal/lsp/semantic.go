@@ -9,6 +9,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 	"github.com/bazelbuild/buildtools/build"
 )
 
@@ -19,12 +20,12 @@ func (s *Server) handleSemanticTokensFull(ctx context.Context, params json.RawMe
 		return nil, err
 	}
 
-	// Copy content while holding lock to avoid data race
+	// Copy the document while holding lock to avoid data race
 	s.mu.RLock()
 	doc, ok := s.documents[p.TextDocument.Uri]
-	var content string
+	var snapshot *Document
 	if ok {
-		content = doc.Content
+		snapshot = &Document{URI: doc.URI, Version: doc.Version, Content: doc.Content}
 	}
 	s.mu.RUnlock()
 
@@ -35,7 +36,7 @@ func (s *Server) handleSemanticTokensFull(ctx context.Context, params json.RawMe
 	log.Printf("semanticTokens/full: %s", p.TextDocument.Uri)
 
 	// Tokenize the content
-	tokens := tokenizeContent(content)
+	tokens := s.documentTokens(snapshot)
 
 	// Encode to LSP format
 	encoded := encodeTokens(tokens)
@@ -52,12 +53,12 @@ func (s *Server) handleSemanticTokensRange(ctx context.Context, params json.RawM
 		return nil, err
 	}
 
-	// Copy content while holding lock to avoid data race
+	// Copy the document while holding lock to avoid data race
 	s.mu.RLock()
 	doc, ok := s.documents[p.TextDocument.Uri]
-	var content string
+	var snapshot *Document
 	if ok {
-		content = doc.Content
+		snapshot = &Document{URI: doc.URI, Version: doc.Version, Content: doc.Content}
 	}
 	s.mu.RUnlock()
 
@@ -71,7 +72,7 @@ func (s *Server) handleSemanticTokensRange(ctx context.Context, params json.RawM
 		p.Range.End.Line, p.Range.End.Character)
 
 	// Tokenize the content
-	tokens := tokenizeContent(content)
+	tokens := s.documentTokens(snapshot)
 
 	// Filter to range
 	filtered := filterTokensInRange(tokens, p.Range)
@@ -84,18 +85,21 @@ func (s *Server) handleSemanticTokensRange(ctx context.Context, params json.RawM
 	}, nil
 }
 
-// tokenizeContent parses Starlark content and returns semantic tokens.
-func tokenizeContent(content string) []SemanticToken {
-	var tokens []SemanticToken
-
-	// Parse the file
-	file, err := build.ParseDefault("", []byte(content))
+// documentTokens returns the semantic tokens of doc.
+func (s *Server) documentTokens(doc *Document) []SemanticToken {
+	file, err := s.parseDocument(doc, filekind.KindStarlark)
 	if err != nil {
 		// Even with errors, try to tokenize what we can
 		// For now, just return empty
 		log.Printf("semantic tokenize parse error: %v", err)
-		return tokens
+		return nil
 	}
+	return tokenizeFile(file, doc.Content)
+}
+
+// tokenizeFile returns the semantic tokens of file, parsed from content.
+func tokenizeFile(file *build.File, content string) []SemanticToken {
+	var tokens []SemanticToken
 
 	// Build global scope
 	globalScope := NewScope(nil)
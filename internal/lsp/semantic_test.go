@@ -509,3 +509,9 @@ func filterByTypeAndMod(tokens []SemanticToken, typ uint32, mod uint32) []Semant
 	}
 	return result
 }
+
+// tokenizeContent returns the semantic tokens the server computes for an
+// open document with content.
+func tokenizeContent(content string) []SemanticToken {
+	return NewServer(nil).documentTokens(&Document{URI: "file:///test.star", Version: 1, Content: content})
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/bazelbuild/buildtools/build"
 
@@ -28,6 +29,8 @@ type Server struct {
 	shutdown    bool
 	documents   map[string]*Document
 	rootURI     string
+	settings    Settings
+	trace       protocol.TraceValue
 
 	// Diagnostics
 	lintDriver *linter.Driver
@@ -39,6 +42,12 @@ type Server struct {
 	// Workspace index for cross-file features
 	workspace *WorkspaceIndex
 
+	// Parsed documents shared between handlers
+	asts *astCache
+
+	// Published errors of .skyfiletypes files, by path ("" once fixed)
+	overridesErrors map[string]string
+
 	// Callbacks
 	onExit func()
 }
@@ -58,22 +67,31 @@ func NewServer(onExit func()) *Server {
 }
 
 // NewServerWithProvider creates a new LSP server with a custom builtins provider.
-// If provider is nil, the server will use hardcoded fallback builtins.
+// If provider is nil, the server will use hardcoded fallback builtins. To
+// layer custom builtins on the defaults, pass
+// builtins.NewMultiProvider(NewDefaultProvider(), custom).
 func NewServerWithProvider(onExit func(), provider builtins.Provider) *Server {
 	// Set up linter with buildtools rules
 	registry := linter.NewRegistry()
 	_ = registry.Register(buildtools.AllRules()...)
 	lintDriver := linter.NewDriver(registry)
 
-	// Set up semantic checker
-	chk := checker.New(checker.DefaultOptions())
+	// Set up semantic checker. Generated files get diagnostics too: someone
+	// who opens one in an editor is looking at it.
+	checkOpts := checker.DefaultOptions()
+	checkOpts.SkipGenerated = false
+	chk := checker.New(checkOpts)
 
 	return &Server{
-		documents:  make(map[string]*Document),
-		lintDriver: lintDriver,
-		checker:    chk,
-		builtins:   provider,
-		onExit:     onExit,
+		documents:       make(map[string]*Document),
+		lintDriver:      lintDriver,
+		checker:         chk,
+		builtins:        provider,
+		settings:        DefaultSettings(),
+		trace:           protocol.TraceValueOff,
+		asts:            newASTCache(),
+		onExit:          onExit,
+		overridesErrors: make(map[string]string),
 	}
 }
 
@@ -82,8 +100,16 @@ func (s *Server) SetConn(conn *Conn) {
 	s.conn = conn
 }
 
-// Handle implements Handler interface - routes requests to methods.
+// Handle implements Handler interface - routes requests to methods and
+// traces them to the client when it enabled tracing.
 func (s *Server) Handle(ctx context.Context, req *Request) (any, error) {
+	start := time.Now()
+	result, err := s.dispatch(ctx, req)
+	s.traceMessage(ctx, req, time.Since(start), err)
+	return result, err
+}
+
+func (s *Server) dispatch(ctx context.Context, req *Request) (any, error) {
 	s.mu.RLock()
 	shutdown := s.shutdown
 	initialized := s.initialized
@@ -121,6 +147,8 @@ func (s *Server) Handle(ctx context.Context, req *Request) (any, error) {
 		return s.handleShutdown(ctx)
 	case "exit":
 		return s.handleExit(ctx)
+	case "$/setTrace":
+		return s.handleSetTrace(ctx, req.Params)
 
 	// Text document sync
 	case "textDocument/didOpen":
@@ -161,6 +189,8 @@ func (s *Server) Handle(ctx context.Context, req *Request) (any, error) {
 	// Workspace features
 	case "workspace/symbol":
 		return s.handleWorkspaceSymbol(ctx, req.Params)
+	case "workspace/didChangeConfiguration":
+		return s.handleDidChangeConfiguration(ctx, req.Params)
 
 	// Semantic tokens
 	case "textDocument/semanticTokens/full":
@@ -172,6 +202,10 @@ func (s *Server) Handle(ctx context.Context, req *Request) (any, error) {
 	case "textDocument/inlayHint":
 		return s.handleInlayHint(ctx, req.Params)
 
+	// Custom requests
+	case "sky/fileInfo":
+		return s.handleFileInfo(ctx, req.Params)
+
 	default:
 		log.Printf("unhandled method: %s", req.Method)
 		return nil, ErrMethodNotFound
@@ -186,6 +220,17 @@ func (s *Server) handleInitialize(ctx context.Context, params json.RawMessage) (
 		return nil, fmt.Errorf("parsing initialize params: %w", err)
 	}
 
+	var options struct {
+		InitializationOptions json.RawMessage `json:"initializationOptions"`
+	}
+	if err := json.Unmarshal(params, &options); err == nil {
+		if err := s.applySettings(options.InitializationOptions); err != nil {
+			log.Printf("initialize: %v", err)
+		}
+	}
+
+	s.setTrace(p.Trace)
+
 	s.mu.Lock()
 	if p.WorkspaceFolders != nil && len(*p.WorkspaceFolders) > 0 {
 		s.rootURI = string((*p.WorkspaceFolders)[0].Uri)
@@ -341,16 +386,6 @@ func lineToRange(line int) protocol.Range {
 	}
 }
 
-// uriToPath converts a document URI to a file path.
-// Handles file:// URIs and returns just the path component.
-func uriToPath(uri string) string {
-	s := string(uri)
-	if strings.HasPrefix(s, "file://") {
-		return s[7:] // Remove "file://"
-	}
-	return s
-}
-
 // ptrInt32 returns a pointer to the given int32 value.
 func ptrInt32(v int32) *int32 {
 	return &v
@@ -1,8 +1,12 @@
 package lsp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/albertocavalcante/sky/internal/protocol"
@@ -272,6 +276,64 @@ func TestServerFormatting(t *testing.T) {
 	}
 }
 
+func TestServerFormattingConfig(t *testing.T) {
+	// The .skyfmt.json nearest the document applies, as it does for skyfmt.
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".skyfmt.json"), []byte(`{"reorderLoads": false}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(nil)
+	initParams, _ := json.Marshal(protocol.InitializeParams{})
+	_, _ = server.Handle(context.Background(), &Request{
+		Method: "initialize",
+		ID:     rawID(1),
+		Params: initParams,
+	})
+	_, _ = server.Handle(context.Background(), &Request{
+		Method: "initialized",
+		Params: json.RawMessage("{}"),
+	})
+
+	uri := "file://" + filepath.Join(root, "pkg", "BUILD")
+	src := "load(\"//b:defs.bzl\", \"z\")\nload(\"//a:defs.bzl\", \"y\")\n\nx   = 1\n"
+	openParams, _ := json.Marshal(protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			Uri:        uri,
+			LanguageId: "starlark",
+			Version:    1,
+			Text:       src,
+		},
+	})
+	_, _ = server.Handle(context.Background(), &Request{
+		Method: "textDocument/didOpen",
+		Params: openParams,
+	})
+
+	fmtParams, _ := json.Marshal(protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{Uri: uri},
+	})
+	result, err := server.Handle(context.Background(), &Request{
+		Method: "textDocument/formatting",
+		ID:     rawID(2),
+		Params: fmtParams,
+	})
+	if err != nil {
+		t.Fatalf("formatting failed: %v", err)
+	}
+	edits, ok := result.([]protocol.TextEdit)
+	if !ok || len(edits) != 1 {
+		t.Fatalf("expected 1 edit, got %#v", result)
+	}
+	want := "load(\"//b:defs.bzl\", \"z\")\nload(\"//a:defs.bzl\", \"y\")\n\nx = 1\n"
+	if edits[0].NewText != want {
+		t.Errorf("formatted text = %q, want %q", edits[0].NewText, want)
+	}
+}
+
 func TestServerFormattingNoChange(t *testing.T) {
 	server := NewServer(nil)
 
@@ -594,7 +656,7 @@ func rawID(n int) *json.RawMessage {
 	return &raw
 }
 
-func TestLintFindingToDiagnostic(t *testing.T) {
+func TestDiagnosticToLSP_LintFinding(t *testing.T) {
 	tests := []struct {
 		name     string
 		finding  linter.Finding
@@ -658,7 +720,7 @@ func TestLintFindingToDiagnostic(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			diag := lintFindingToDiagnostic(tt.finding)
+			diag := diagnosticToLSP(tt.finding.ToDiagnostic("test.star"))
 
 			if diag.Range.Start.Line != tt.wantLine {
 				t.Errorf("Line = %d, want %d", diag.Range.Start.Line, tt.wantLine)
@@ -679,7 +741,7 @@ func TestLintFindingToDiagnostic(t *testing.T) {
 	}
 }
 
-func TestCheckerDiagnosticToLSP(t *testing.T) {
+func TestDiagnosticToLSP_CheckerDiagnostic(t *testing.T) {
 	tests := []struct {
 		name     string
 		diag     checker.Diagnostic
@@ -715,7 +777,7 @@ func TestCheckerDiagnosticToLSP(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			lspDiag := checkerDiagnosticToLSP(tt.diag)
+			lspDiag := diagnosticToLSP(tt.diag.ToDiagnostic("test.star"))
 
 			if lspDiag.Range.Start.Line != tt.wantLine {
 				t.Errorf("Line = %d, want %d", lspDiag.Range.Start.Line, tt.wantLine)
@@ -738,3 +800,114 @@ func TestCheckerDiagnosticToLSP(t *testing.T) {
 func posAt(line, col int) syntax.Position {
 	return syntax.MakePosition(nil, int32(line), int32(col))
 }
+
+// TestLintDocument_FileKind verifies that rules restricted to a file kind
+// only fire in documents of that kind.
+func TestLintDocument_FileKind(t *testing.T) {
+	server := NewServer(nil)
+	content := "cc_library(\n    name = \"lib\",\n    deps = [\"//b\", \"//a\"],\n)\n"
+
+	tests := []struct {
+		uri  string
+		want bool
+	}{
+		{"file:///ws/pkg/BUILD.bazel", true},
+		{"file:///ws/pkg/defs.bzl", false},
+		{"file:///ws/pkg/script.star", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			findings, err := server.lintDocument(tt.uri, content)
+			if err != nil {
+				t.Fatalf("lintDocument() error = %v", err)
+			}
+			got := false
+			for _, f := range findings {
+				if f.Rule == "unsorted-list" {
+					got = true
+				}
+			}
+			if got != tt.want {
+				t.Errorf("unsorted-list reported = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishDiagnostics_Generated(t *testing.T) {
+	var out bytes.Buffer
+	server := NewServer(nil)
+	server.SetConn(NewConn(&mockConn{Reader: bytes.NewReader(nil), Writer: &out}, server))
+
+	// skycheck skips generated files, but an open one is still checked.
+	uri := "file:///gen.bzl"
+	content := "# @generated by a tool\nx = undefined_name\n"
+	server.publishDiagnostics(context.Background(), uri, content)
+
+	var codes []string
+	for _, d := range lastPublished(t, &out) {
+		if code, ok := d.Code.Value.(string); ok {
+			codes = append(codes, code)
+		}
+	}
+	if !strings.Contains(strings.Join(codes, ","), "undefined") {
+		t.Errorf("expected an undefined-name diagnostic, got codes %q", codes)
+	}
+}
+
+func TestPublishDiagnostics_InvalidOverrides(t *testing.T) {
+	root := t.TempDir()
+	overrides := filepath.Join(root, ".skyfiletypes")
+	if err := os.WriteFile(overrides, []byte("*.tpl bzl\n*.cfg nonsense\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := pathToURI(filepath.Join(root, "rules.tpl"))
+	overridesURI := pathToURI(overrides)
+
+	var out bytes.Buffer
+	server := NewServer(nil)
+	server.SetConn(NewConn(&mockConn{Reader: bytes.NewReader(nil), Writer: &out}, server))
+
+	server.publishDiagnostics(context.Background(), uri, "x = 1\n")
+	got, ok := publishedFor(&out, overridesURI)
+	if !ok || len(got) != 1 || got[0].Range.Start.Line != 1 || !strings.Contains(got[0].Message, `unknown file kind "nonsense"`) {
+		t.Fatalf("diagnostics for %s = %+v, want the error on line 2", overridesURI, got)
+	}
+
+	// The error is published once, not with every document.
+	out.Reset()
+	server.publishDiagnostics(context.Background(), uri, "x = 2\n")
+	if got, ok := publishedFor(&out, overridesURI); ok {
+		t.Errorf("diagnostics for %s republished: %+v", overridesURI, got)
+	}
+
+	// Fixing the file clears the diagnostic.
+	if err := os.WriteFile(overrides, []byte("*.tpl bzl\n*.cfg bzl\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	server.publishDiagnostics(context.Background(), uri, "x = 3\n")
+	if got, ok := publishedFor(&out, overridesURI); !ok || len(got) != 0 {
+		t.Errorf("diagnostics for %s after fixing it = %+v, %v; want them cleared", overridesURI, got, ok)
+	}
+}
+
+// publishedFor returns the diagnostics of the last publishDiagnostics
+// notification for uri written to out, and whether there was one.
+func publishedFor(out *bytes.Buffer, uri string) ([]protocol.Diagnostic, bool) {
+	messages := strings.Split(out.String(), "Content-Length:")
+	for i := len(messages) - 1; i >= 0; i-- {
+		_, body, ok := strings.Cut(messages[i], "\r\n\r\n")
+		if !ok {
+			continue
+		}
+		var msg struct {
+			Method string                            `json:"method"`
+			Params protocol.PublishDiagnosticsParams `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(body), &msg); err == nil && msg.Method == "textDocument/publishDiagnostics" && msg.Params.Uri == uri {
+			return msg.Params.Diagnostics, true
+		}
+	}
+	return nil, false
+}
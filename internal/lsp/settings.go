@@ -0,0 +1,116 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+)
+
+// DefaultMaxDiagnostics is the default cap on the diagnostics published for
+// one document.
+const DefaultMaxDiagnostics = 500
+
+// Settings are the user-configurable options of the server. Clients set them
+// with initializationOptions or workspace/didChangeConfiguration, either
+// directly or under a "skyls" key.
+type Settings struct {
+	// MaxDiagnostics caps the diagnostics published for one document; the
+	// rest are summarized by a single notice. Zero or less disables the cap.
+	MaxDiagnostics int `json:"maxDiagnostics"`
+}
+
+// DefaultSettings returns the settings a server starts with.
+func DefaultSettings() Settings {
+	return Settings{MaxDiagnostics: DefaultMaxDiagnostics}
+}
+
+// SetSettings replaces the server's settings. Settings sent by the client
+// later still override them.
+func (s *Server) SetSettings(settings Settings) {
+	s.mu.Lock()
+	s.settings = settings
+	s.mu.Unlock()
+}
+
+func (s *Server) currentSettings() Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings
+}
+
+// applySettings updates the settings from client configuration. Fields the
+// client leaves out keep their current values.
+func (s *Server) applySettings(raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var section struct {
+		Skyls json.RawMessage `json:"skyls"`
+	}
+	if err := json.Unmarshal(raw, &section); err == nil && len(section.Skyls) > 0 {
+		raw = section.Skyls
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	settings := s.settings
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return fmt.Errorf("parsing settings: %w", err)
+	}
+	s.settings = settings
+	return nil
+}
+
+// handleDidChangeConfiguration applies new settings and republishes the
+// diagnostics of open documents under them.
+func (s *Server) handleDidChangeConfiguration(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Settings json.RawMessage `json:"settings"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if err := s.applySettings(p.Settings); err != nil {
+		log.Printf("didChangeConfiguration: %v", err)
+		return nil, nil
+	}
+	log.Printf("didChangeConfiguration: %+v", s.currentSettings())
+
+	s.mu.RLock()
+	open := make(map[string]string, len(s.documents))
+	for uri, doc := range s.documents {
+		open[uri] = doc.Content
+	}
+	s.mu.RUnlock()
+	for uri, content := range open {
+		s.publishDiagnostics(ctx, uri, content)
+	}
+	return nil, nil
+}
+
+// capDiagnostics keeps the first limit diagnostics by position and replaces
+// the rest with one informational notice at the start of the document, so
+// that editors stay responsive on badly broken files.
+func capDiagnostics(diagnostics []protocol.Diagnostic, limit int) []protocol.Diagnostic {
+	if limit <= 0 || len(diagnostics) <= limit {
+		return diagnostics
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Range.Start, diagnostics[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+	omitted := len(diagnostics) - limit
+	return append(diagnostics[:limit:limit], protocol.Diagnostic{
+		Severity: protocol.DiagnosticSeverityInformation,
+		Code:     protocol.Or_int32_string{Value: "too-many-diagnostics"},
+		Source:   "skyls",
+		Message:  fmt.Sprintf("%d more diagnostics not shown; the limit is %d (maxDiagnostics setting)", omitted, limit),
+	})
+}
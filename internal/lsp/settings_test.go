@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+)
+
+func TestCapDiagnostics(t *testing.T) {
+	var diagnostics []protocol.Diagnostic
+	for _, line := range []uint32{4, 1, 3, 0, 2} {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:   protocol.Range{Start: protocol.Position{Line: line}},
+			Message: fmt.Sprintf("line %d", line),
+		})
+	}
+
+	if got := capDiagnostics(diagnostics, 0); len(got) != 5 {
+		t.Errorf("limit 0: got %d diagnostics, want all 5", len(got))
+	}
+	if got := capDiagnostics(diagnostics, 5); len(got) != 5 {
+		t.Errorf("limit 5: got %d diagnostics, want 5", len(got))
+	}
+
+	got := capDiagnostics(diagnostics, 3)
+	if len(got) != 4 {
+		t.Fatalf("limit 3: got %d diagnostics, want 3 and a notice", len(got))
+	}
+	for i, want := range []string{"line 0", "line 1", "line 2"} {
+		if got[i].Message != want {
+			t.Errorf("diagnostic %d = %q, want %q", i, got[i].Message, want)
+		}
+	}
+	notice := got[3]
+	if notice.Severity != protocol.DiagnosticSeverityInformation || notice.Code.Value != "too-many-diagnostics" ||
+		!strings.HasPrefix(notice.Message, "2 more diagnostics not shown; the limit is 3") {
+		t.Errorf("unexpected notice %+v", notice)
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{name: "flat", raw: `{"maxDiagnostics": 10}`, want: 10},
+		{name: "section", raw: `{"skyls": {"maxDiagnostics": 20}}`, want: 20},
+		{name: "omitted", raw: `{"other": true}`, want: DefaultMaxDiagnostics},
+		{name: "null", raw: `null`, want: DefaultMaxDiagnostics},
+		{name: "invalid", raw: `{"maxDiagnostics": "many"}`, want: DefaultMaxDiagnostics, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(nil)
+			err := server.applySettings(json.RawMessage(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applySettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := server.currentSettings().MaxDiagnostics; got != tt.want {
+				t.Errorf("MaxDiagnostics = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishDiagnostics_Capped(t *testing.T) {
+	var out bytes.Buffer
+	server := NewServer(nil)
+	server.SetConn(NewConn(&mockConn{Reader: bytes.NewReader(nil), Writer: &out}, server))
+
+	initParams, _ := json.Marshal(map[string]any{
+		"initializationOptions": map[string]any{"maxDiagnostics": 2},
+	})
+	if _, err := server.handleInitialize(context.Background(), initParams); err != nil {
+		t.Fatalf("handleInitialize() error = %v", err)
+	}
+
+	uri := "file:///broken.star"
+	content := "a1()\na2()\na3()\na4()\na5()\n"
+	server.documents[uri] = &Document{URI: uri, Version: 1, Content: content}
+	server.publishDiagnostics(context.Background(), uri, content)
+	if got := lastPublished(t, &out); len(got) != 3 || got[2].Source != "skyls" {
+		t.Fatalf("expected 2 diagnostics and a notice, got %+v", got)
+	}
+
+	// Lifting the cap republishes open documents in full.
+	out.Reset()
+	changeParams, _ := json.Marshal(map[string]any{
+		"settings": map[string]any{"skyls": map[string]any{"maxDiagnostics": 0}},
+	})
+	if _, err := server.handleDidChangeConfiguration(context.Background(), changeParams); err != nil {
+		t.Fatalf("handleDidChangeConfiguration() error = %v", err)
+	}
+	got := lastPublished(t, &out)
+	if len(got) <= 3 {
+		t.Fatalf("expected all diagnostics, got %+v", got)
+	}
+	for _, d := range got {
+		if d.Source == "skyls" {
+			t.Errorf("unexpected truncation notice %+v", d)
+		}
+	}
+}
+
+// lastPublished returns the diagnostics of the last publishDiagnostics
+// notification written to out.
+func lastPublished(t *testing.T, out *bytes.Buffer) []protocol.Diagnostic {
+	t.Helper()
+	messages := strings.Split(out.String(), "Content-Length:")
+	for i := len(messages) - 1; i >= 0; i-- {
+		_, body, ok := strings.Cut(messages[i], "\r\n\r\n")
+		if !ok {
+			continue
+		}
+		var msg struct {
+			Method string                            `json:"method"`
+			Params protocol.PublishDiagnosticsParams `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(body), &msg); err == nil && msg.Method == "textDocument/publishDiagnostics" {
+			return msg.Params.Diagnostics
+		}
+	}
+	t.Fatalf("no diagnostics published in %q", out.String())
+	return nil
+}
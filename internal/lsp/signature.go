@@ -50,7 +50,7 @@ func (s *Server) handleSignatureHelp(ctx context.Context, params json.RawMessage
 	}
 
 	// Fall back to user-defined functions in the document
-	sig = s.getDocumentFunctionSignature(doc.Content, callCtx.FunctionName, p.TextDocument.Uri)
+	sig = s.getDocumentFunctionSignature(doc, callCtx.FunctionName)
 	if sig != nil {
 		return buildSignatureHelp(sig, callCtx.ArgumentIndex), nil
 	}
@@ -257,11 +257,11 @@ func (s *Server) getBuiltinSignature(name string, uri string) *builtins.Signatur
 }
 
 // getDocumentFunctionSignature extracts signature for a function defined in the document.
-func (s *Server) getDocumentFunctionSignature(content, name string, uri string) *builtins.Signature {
-	path := uriToPath(uri)
+func (s *Server) getDocumentFunctionSignature(doc *Document, name string) *builtins.Signature {
+	path := uriToPath(doc.URI)
 
 	// Classify the file
-	cls := classifier.NewDefaultClassifier()
+	cls := classifier.New()
 	classification, err := cls.Classify(path)
 	if err != nil {
 		classification.FileKind = filekind.KindStarlark
@@ -270,8 +270,12 @@ func (s *Server) getDocumentFunctionSignature(content, name string, uri string)
 	// For signature help, we need to handle incomplete code gracefully.
 	// If parse fails, try parsing just the portion before the cursor position.
 	// Alternatively, use a regex-based approach to find function definitions.
-	file, err := parseStarlarkFile([]byte(content), path, classification.FileKind)
+	file, err := s.parseDocument(doc, classification.FileKind)
 	if err != nil {
+		s.mu.RLock()
+		content := doc.Content
+		s.mu.RUnlock()
+
 		// Try to parse a "fixed" version by closing any incomplete function calls
 		fixedContent := content + ")"
 		file, err = parseStarlarkFile([]byte(fixedContent), path, classification.FileKind)
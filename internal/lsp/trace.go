@@ -0,0 +1,100 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+)
+
+// maxTracedParams caps the params summary of a verbose trace entry, so that
+// tracing didOpen or didChange does not echo whole files back to the client.
+const maxTracedParams = 200
+
+// messageTypeLog is the window/logMessage type for log entries, which clients
+// show in the server's output channel without raising them to the user.
+const messageTypeLog = 4
+
+// logMessageParams is the payload of window/logMessage.
+type logMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+// setTraceParams is the payload of $/setTrace.
+type setTraceParams struct {
+	Value protocol.TraceValue `json:"value"`
+}
+
+// handleSetTrace changes the trace level for the rest of the session.
+func (s *Server) handleSetTrace(ctx context.Context, params json.RawMessage) (any, error) {
+	var p setTraceParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("parsing setTrace params: %w", err)
+	}
+	s.setTrace(p.Value)
+	return nil, nil
+}
+
+// setTrace sets the trace level; unknown values turn tracing off.
+func (s *Server) setTrace(value protocol.TraceValue) {
+	switch value {
+	case protocol.TraceValueMessages, protocol.TraceValueVerbose:
+	default:
+		value = protocol.TraceValueOff
+	}
+	s.mu.Lock()
+	s.trace = value
+	s.mu.Unlock()
+}
+
+func (s *Server) traceLevel() protocol.TraceValue {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.trace
+}
+
+// traceMessage reports a handled message to the client's output channel.
+// At "messages" it logs the method and how long it took; "verbose" adds a
+// summary of the params and the error, if any.
+func (s *Server) traceMessage(ctx context.Context, req *Request, elapsed time.Duration, err error) {
+	level := s.traceLevel()
+	if level == protocol.TraceValueOff || s.conn == nil {
+		return
+	}
+
+	var b strings.Builder
+	if req.ID != nil {
+		fmt.Fprintf(&b, "request %s (%s) took %s", req.Method, *req.ID, elapsed.Round(time.Microsecond))
+	} else {
+		fmt.Fprintf(&b, "notification %s took %s", req.Method, elapsed.Round(time.Microsecond))
+	}
+	if err != nil {
+		b.WriteString(", failed")
+	}
+	if level == protocol.TraceValueVerbose {
+		if len(req.Params) > 0 {
+			fmt.Fprintf(&b, "\nparams: %s", summarizeParams(req.Params))
+		}
+		if err != nil {
+			fmt.Fprintf(&b, "\nerror: %v", err)
+		}
+	}
+
+	// The trace is best effort; a failed write shows up on the next response.
+	_ = s.conn.Notify(ctx, "window/logMessage", logMessageParams{
+		Type:    messageTypeLog,
+		Message: b.String(),
+	})
+}
+
+// summarizeParams returns params truncated to maxTracedParams bytes.
+func summarizeParams(params json.RawMessage) string {
+	if len(params) <= maxTracedParams {
+		return string(params)
+	}
+	return fmt.Sprintf("%s... (%d bytes)", params[:maxTracedParams], len(params))
+}
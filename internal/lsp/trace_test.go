@@ -0,0 +1,101 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHandle_Trace(t *testing.T) {
+	var out bytes.Buffer
+	server := NewServer(nil)
+	server.SetConn(NewConn(&mockConn{Reader: bytes.NewReader(nil), Writer: &out}, server))
+
+	handle := func(id int, method string, params any) {
+		t.Helper()
+		req := &Request{JSONRPC: "2.0", Method: method}
+		if id != 0 {
+			raw := json.RawMessage(strconv.Itoa(id))
+			req.ID = &raw
+		}
+		if params != nil {
+			req.Params = mustMarshal(t, params)
+		}
+		if _, err := server.Handle(context.Background(), req); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+	}
+
+	handle(1, "initialize", map[string]any{"capabilities": map[string]any{}, "trace": "messages"})
+	handle(0, "initialized", map[string]any{})
+	uri := "file:///project/BUILD"
+	server.documents[uri] = &Document{URI: uri, Version: 1, Content: "x = 1\n"}
+
+	out.Reset()
+	handle(2, "textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	logs := logMessages(t, &out)
+	if len(logs) != 1 || !strings.HasPrefix(logs[0], "request textDocument/documentSymbol (2) took ") {
+		t.Fatalf("messages trace = %q", logs)
+	}
+
+	out.Reset()
+	handle(0, "$/setTrace", map[string]any{"value": "verbose"})
+	handle(3, "textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	logs = logMessages(t, &out)
+	if len(logs) != 2 || !strings.HasPrefix(logs[0], "notification $/setTrace took ") ||
+		!strings.Contains(logs[1], `params: {"textDocument":{"uri":"file:///project/BUILD"}}`) {
+		t.Fatalf("verbose trace = %q", logs)
+	}
+
+	out.Reset()
+	handle(0, "$/setTrace", map[string]any{"value": "off"})
+	handle(4, "textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	if logs := logMessages(t, &out); len(logs) != 0 {
+		t.Fatalf("trace off logged %q", logs)
+	}
+}
+
+func TestSummarizeParams(t *testing.T) {
+	short := json.RawMessage(`{"a":1}`)
+	if got := summarizeParams(short); got != `{"a":1}` {
+		t.Errorf("summarizeParams(short) = %q", got)
+	}
+	long := json.RawMessage(`"` + strings.Repeat("x", 500) + `"`)
+	got := summarizeParams(long)
+	if len(got) > maxTracedParams+20 || !strings.HasSuffix(got, "... (502 bytes)") {
+		t.Errorf("summarizeParams(long) = %q", got)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// logMessages returns the messages of the window/logMessage notifications
+// written to out.
+func logMessages(t *testing.T, out *bytes.Buffer) []string {
+	t.Helper()
+	var logs []string
+	for _, message := range strings.Split(out.String(), "Content-Length:") {
+		_, body, ok := strings.Cut(message, "\r\n\r\n")
+		if !ok {
+			continue
+		}
+		var msg struct {
+			Method string           `json:"method"`
+			Params logMessageParams `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(body), &msg); err == nil && msg.Method == "window/logMessage" {
+			logs = append(logs, msg.Params.Message)
+		}
+	}
+	return logs
+}
@@ -0,0 +1,65 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// uriToPath converts a document URI to a file path. File URIs are
+// unescaped, so "file:///a%20b/BUILD" becomes "/a b/BUILD", and Windows
+// forms such as "file:///C:/src/BUILD" and "file://server/share/BUILD"
+// become "C:/src/BUILD" and "//server/share/BUILD" in the local path
+// syntax.
+//
+// Documents with other schemes, such as "untitled:Untitled-1", have no file
+// on disk, and the empty string is returned; features then work on the
+// in-memory content only. A string without a scheme is taken to be a path
+// already and returned unchanged.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		// Not a valid URI, e.g. a file URI with a stray "%"
+		if rest, ok := strings.CutPrefix(uri, "file://"); ok {
+			return filepath.FromSlash(rest)
+		}
+		return uri
+	}
+	// A one-letter scheme is a Windows drive, as in "C:\src\BUILD".
+	if len(u.Scheme) <= 1 {
+		return uri
+	}
+	if !strings.EqualFold(u.Scheme, "file") {
+		return ""
+	}
+
+	path := u.Path
+	if hasDriveLetter(strings.TrimPrefix(path, "/")) {
+		path = strings.TrimPrefix(path, "/")
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		path = "//" + u.Host + path
+	}
+	return filepath.FromSlash(path)
+}
+
+// pathToURI converts a file path to a file URI, escaping characters such
+// as spaces. It is the inverse of uriToPath for file paths.
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// "C:/src" becomes "file:///C:/src"
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// hasDriveLetter reports whether path starts with a Windows drive such as
+// "C:".
+func hasDriveLetter(path string) bool {
+	if len(path) < 2 || path[1] != ':' {
+		return false
+	}
+	c := path[0]
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
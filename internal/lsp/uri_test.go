@@ -0,0 +1,119 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/protocol"
+)
+
+func TestURIToPath(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"file:///ws/pkg/BUILD", "/ws/pkg/BUILD"},
+		{"file:///my%20ws/pkg/BUILD", "/my ws/pkg/BUILD"},
+		{"file://localhost/ws/BUILD", "/ws/BUILD"},
+		{"file:///C:/src/BUILD", "C:/src/BUILD"},
+		{"file:///c%3A/src/BUILD", "c:/src/BUILD"},
+		{"file://server/share/BUILD", "//server/share/BUILD"},
+		{"file:///ws/100%/BUILD", "/ws/100%/BUILD"},
+		{"untitled:Untitled-1", ""},
+		{"vscode-notebook-cell:/ws/nb.ipynb#cell1", ""},
+		{"/ws/pkg/BUILD", "/ws/pkg/BUILD"},
+		{`C:\src\BUILD`, `C:\src\BUILD`},
+	}
+	for _, tt := range tests {
+		if got := uriToPath(tt.uri); got != filepath.FromSlash(tt.want) {
+			t.Errorf("uriToPath(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestPathToURI(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/ws/pkg/BUILD", "file:///ws/pkg/BUILD"},
+		{"/my ws/pkg/BUILD", "file:///my%20ws/pkg/BUILD"},
+		{"C:/src/BUILD", "file:///C:/src/BUILD"},
+	}
+	for _, tt := range tests {
+		got := pathToURI(tt.path)
+		if got != tt.want {
+			t.Errorf("pathToURI(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+		if back := uriToPath(got); back != filepath.FromSlash(tt.path) {
+			t.Errorf("uriToPath(pathToURI(%q)) = %q", tt.path, back)
+		}
+	}
+}
+
+// TestUntitledDocument verifies that documents without a file on disk get
+// in-memory features and skip those that read the disk.
+func TestUntitledDocument(t *testing.T) {
+	server := NewServer(nil)
+	uri := "untitled:Untitled-1"
+	content := "load(\"//lib:defs.bzl\", \"x\")\n\ndef f():\n    pass\n"
+	server.documents[uri] = &Document{URI: uri, Version: 1, Content: content}
+
+	if links := documentLinks(t, server, uri); len(links) != 0 {
+		t.Errorf("expected no links, got %+v", links)
+	}
+
+	params, _ := json.Marshal(protocol.DocumentSymbolParams{TextDocument: protocol.TextDocumentIdentifier{Uri: uri}})
+	result, err := server.handleDocumentSymbol(context.Background(), params)
+	if err != nil {
+		t.Fatalf("handleDocumentSymbol() error = %v", err)
+	}
+	if symbols := result.([]protocol.DocumentSymbol); len(symbols) == 0 {
+		t.Error("expected document symbols for untitled document")
+	}
+
+	if list := completeAt(t, server, uri, 0, len(`load("//`)); len(list.Items) != 0 {
+		t.Errorf("expected no load completions, got %d", len(list.Items))
+	}
+}
+
+// TestDocumentLink_EscapedURI verifies that links resolve in a workspace
+// whose path needs escaping in URIs.
+func TestDocumentLink_EscapedURI(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "my ws")
+	for _, file := range []string{"lib/defs.bzl", "app/BUILD.bazel"} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := NewServer(nil)
+	server.rootURI = pathToURI(root)
+	uri := pathToURI(filepath.Join(root, "app", "BUILD.bazel"))
+	server.documents[uri] = &Document{URI: uri, Version: 1, Content: "load(\"//lib:defs.bzl\", \"x\")\n"}
+
+	links := documentLinks(t, server, uri)
+	want := "file://" + filepath.ToSlash(filepath.Dir(root)) + "/my%20ws/lib/defs.bzl"
+	if len(links) != 1 || links[0].Target != want {
+		t.Errorf("got links %+v, want one to %s", links, want)
+	}
+}
+
+func documentLinks(t *testing.T, s *Server, uri string) []protocol.DocumentLink {
+	t.Helper()
+	params, _ := json.Marshal(protocol.DocumentLinkParams{
+		TextDocument: protocol.TextDocumentIdentifier{Uri: uri},
+	})
+	result, err := s.handleDocumentLink(context.Background(), params)
+	if err != nil {
+		t.Fatalf("handleDocumentLink() error = %v", err)
+	}
+	return result.([]protocol.DocumentLink)
+}
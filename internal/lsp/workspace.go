@@ -74,7 +74,7 @@ func (w *WorkspaceIndex) AddFile(indexedFile *index.File, absPath string) {
 			Name: def.Name,
 			Kind: protocol.SymbolKindFunction,
 			Location: protocol.Location{
-				Uri:   string("file://" + absPath),
+				Uri:   pathToURI(absPath),
 				Range: lineToRange(def.Line),
 			},
 			File: absPath,
@@ -89,7 +89,7 @@ func (w *WorkspaceIndex) AddFile(indexedFile *index.File, absPath string) {
 			Name: assign.Name,
 			Kind: protocol.SymbolKindVariable,
 			Location: protocol.Location{
-				Uri:   string("file://" + absPath),
+				Uri:   pathToURI(absPath),
 				Range: lineToRange(assign.Line),
 			},
 			File: absPath,
@@ -280,7 +280,7 @@ func (s *Server) resolveLoadedSymbol(word string, docURI string) *protocol.Locat
 	path := uriToPath(docURI)
 
 	// Parse the current file to find load statements
-	file, err := parseStarlarkFile([]byte(doc.Content), path, filekind.KindStarlark)
+	file, err := s.parseDocument(doc, filekind.KindStarlark)
 	if err != nil {
 		return nil
 	}
@@ -0,0 +1,273 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+// CapabilityDaemon declares that a plugin can serve repeated requests from a
+// single long-running process (see DaemonPool).
+const CapabilityDaemon = skyplugin.CapabilityDaemon
+
+// daemonStopTimeout is how long Close waits for a daemon to exit after its
+// stdin is closed before killing it.
+const daemonStopTimeout = 2 * time.Second
+
+// daemonStdin returns the contents of stdin for a daemon request. It
+// reports false for input that is only safe to hand to a one-shot process:
+// a terminal, or a pipe that another process may keep open, which would
+// block a read to EOF. Nil, in-memory readers, regular files, and the null
+// device are forwarded.
+func daemonStdin(stdin io.Reader) ([]byte, bool) {
+	switch in := stdin.(type) {
+	case nil:
+		return nil, true
+	case *os.File:
+		info, err := in.Stat()
+		if err != nil {
+			return nil, false
+		}
+		if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+			return nil, true
+		}
+		if !info.Mode().IsRegular() {
+			return nil, false
+		}
+	case interface{ Len() int }: // *bytes.Buffer, *bytes.Reader, *strings.Reader
+	default:
+		return nil, false
+	}
+	data, err := io.ReadAll(stdin)
+	return data, err == nil
+}
+
+// SupportsDaemon reports whether plugin declared the daemon capability and
+// is of a type that can run as a daemon. WASM plugins always run one-shot.
+func SupportsDaemon(plugin Plugin) bool {
+	if !slices.Contains(plugin.Capabilities, CapabilityDaemon) {
+		return false
+	}
+	switch plugin.EffectiveType() {
+	case TypeExecutable, TypeScript:
+		return true
+	}
+	return false
+}
+
+// DaemonPool keeps daemon plugins running between invocations so that
+// frequent callers, such as editors, do not start a process per call.
+// Set it as Runner.Daemons. A pool is safe for concurrent use; requests to
+// the same plugin are served one at a time. Call Close to stop the daemons.
+type DaemonPool struct {
+	// Stderr receives what daemons write to stderr outside of a request.
+	// Nil discards it.
+	Stderr io.Writer
+
+	mu      sync.Mutex
+	daemons map[string]*daemon
+}
+
+// daemon is one running plugin process and its protocol pipes.
+type daemon struct {
+	path  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	out   *bufio.Reader
+
+	// mu serializes requests.
+	mu sync.Mutex
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+// Run sends args and the contents of stdin to the plugin's daemon, starting
+// it if needed, and copies the response to stdout and stderr. If the daemon
+// cannot be reached it is stopped, and the next call starts a new one.
+func (p *DaemonPool) Run(ctx context.Context, plugin Plugin, args []string, stdin []byte, stdout, stderr io.Writer) (int, error) {
+	d, err := p.daemon(plugin)
+	if err != nil {
+		return 1, err
+	}
+
+	req := skyplugin.DaemonRequest{Args: args, Env: make(map[string]string), Stdin: stdin}
+	if req.Args == nil {
+		req.Args = []string{}
+	}
+	for _, kv := range PluginEnv(plugin.Name, ModeExec) {
+		key, value, _ := strings.Cut(kv, "=")
+		req.Env[key] = value
+	}
+	if dir, err := os.Getwd(); err == nil {
+		req.Dir = dir
+	}
+
+	d.mu.Lock()
+	resp, err := d.call(ctx, req)
+	d.mu.Unlock()
+	if err != nil {
+		p.remove(plugin.Name, d)
+		return 1, fmt.Errorf("plugin %q daemon: %w", plugin.Name, err)
+	}
+
+	if _, err := io.WriteString(stdout, resp.Stdout); err != nil {
+		return 1, err
+	}
+	if _, err := io.WriteString(stderr, resp.Stderr); err != nil {
+		return 1, err
+	}
+	return resp.ExitCode, nil
+}
+
+// Close stops every daemon: it closes their stdin, which ends the request
+// loop, and kills those that have not exited after a short wait.
+func (p *DaemonPool) Close() error {
+	p.mu.Lock()
+	daemons := p.daemons
+	p.daemons = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for name, d := range daemons {
+		if err := d.stop(); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %q daemon: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// daemon returns the running daemon for plugin, starting one if there is
+// none or if the plugin binary has moved.
+func (p *DaemonPool) daemon(plugin Plugin) (*daemon, error) {
+	if !SupportsDaemon(plugin) {
+		return nil, fmt.Errorf("plugin %q does not support daemon mode", plugin.Name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if d, ok := p.daemons[plugin.Name]; ok {
+		if d.path == plugin.Path {
+			return d, nil
+		}
+		delete(p.daemons, plugin.Name)
+		_ = d.stop()
+	}
+
+	d, err := startDaemon(plugin, p.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if p.daemons == nil {
+		p.daemons = make(map[string]*daemon)
+	}
+	p.daemons[plugin.Name] = d
+	return d, nil
+}
+
+// remove stops d and forgets it, unless it has already been replaced.
+func (p *DaemonPool) remove(name string, d *daemon) {
+	p.mu.Lock()
+	if p.daemons[name] == d {
+		delete(p.daemons, name)
+	}
+	p.mu.Unlock()
+	d.kill()
+}
+
+func startDaemon(plugin Plugin, stderr io.Writer) (*daemon, error) {
+	program, argv := plugin.Path, []string(nil)
+	if plugin.EffectiveType() == TypeScript {
+		shebang, err := readShebang(plugin.Path)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %q: %w", plugin.Name, err)
+		}
+		interpreter, interpArgs, err := resolveInterpreter(shebang)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %q: %w", plugin.Name, err)
+		}
+		program = interpreter
+		argv = append(interpArgs, plugin.Path)
+	}
+
+	cmd := exec.Command(program, argv...)
+	cmd.Env = append(os.Environ(), PluginEnv(plugin.Name, ModeDaemon)...)
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin %q: starting daemon: %w", plugin.Name, err)
+	}
+	return &daemon{path: plugin.Path, cmd: cmd, stdin: stdin, out: bufio.NewReader(stdout)}, nil
+}
+
+// call sends one request and waits for its response. If ctx is done first,
+// the daemon is killed, since its protocol state is no longer known.
+func (d *daemon) call(ctx context.Context, req skyplugin.DaemonRequest) (skyplugin.DaemonResponse, error) {
+	var resp skyplugin.DaemonResponse
+	done := make(chan error, 1)
+	go func() {
+		if err := skyplugin.WriteFrame(d.stdin, req); err != nil {
+			done <- err
+			return
+		}
+		done <- skyplugin.ReadFrame(d.out, &resp)
+	}()
+
+	select {
+	case err := <-done:
+		if errors.Is(err, io.EOF) {
+			err = errors.New("exited before responding")
+		}
+		return resp, err
+	case <-ctx.Done():
+		d.kill()
+		<-done
+		return skyplugin.DaemonResponse{}, ctx.Err()
+	}
+}
+
+// stop closes the daemon's stdin and waits for it to exit, killing it after
+// daemonStopTimeout.
+func (d *daemon) stop() error {
+	_ = d.stdin.Close()
+	exited := make(chan error, 1)
+	go func() { exited <- d.wait() }()
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(daemonStopTimeout):
+		_ = d.cmd.Process.Kill()
+		<-exited
+		return errors.New("did not exit after stdin was closed; killed")
+	}
+}
+
+// kill terminates the daemon immediately.
+func (d *daemon) kill() {
+	_ = d.stdin.Close()
+	_ = d.cmd.Process.Kill()
+	_ = d.wait()
+}
+
+// wait reaps the daemon process. It is safe to call more than once.
+func (d *daemon) wait() error {
+	d.waitOnce.Do(func() { d.waitErr = d.cmd.Wait() })
+	return d.waitErr
+}
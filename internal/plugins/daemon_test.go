@@ -0,0 +1,234 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+// envTestDaemon makes the test binary act as a daemon plugin (see TestMain).
+const envTestDaemon = "SKY_TEST_DAEMON"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestDaemon) == "1" {
+		switch os.Getenv(EnvPluginMode) {
+		case ModeDaemon:
+			os.Exit(serveTestDaemon(os.Stdin, os.Stdout))
+		case ModeExec:
+			fmt.Println("one-shot")
+			os.Exit(0)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+// serveTestDaemon answers each request with the daemon's pid and the args.
+// "exit" makes it quit without answering, "sleep" makes it hang, and "cat"
+// answers with the request's stdin.
+func serveTestDaemon(in io.Reader, out io.Writer) int {
+	for {
+		var req skyplugin.DaemonRequest
+		if err := skyplugin.ReadFrame(in, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0
+			}
+			return 1
+		}
+		switch strings.Join(req.Args, " ") {
+		case "exit":
+			return 0
+		case "sleep":
+			time.Sleep(time.Minute)
+		case "cat":
+			if err := skyplugin.WriteFrame(out, skyplugin.DaemonResponse{Stdout: string(req.Stdin)}); err != nil {
+				return 1
+			}
+			continue
+		}
+		resp := skyplugin.DaemonResponse{
+			ExitCode: len(req.Args),
+			Stdout:   fmt.Sprintf("pid=%d args=%s name=%s\n", os.Getpid(), strings.Join(req.Args, ","), req.Env[EnvPluginName]),
+			Stderr:   "mode=" + req.Env[EnvPluginMode] + "\n",
+		}
+		if err := skyplugin.WriteFrame(out, resp); err != nil {
+			return 1
+		}
+	}
+}
+
+func testDaemonPlugin(t *testing.T) Plugin {
+	t.Helper()
+	t.Setenv(envTestDaemon, "1")
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	return Plugin{Name: "demo", Path: exe, Type: TypeExecutable, Capabilities: []string{CapabilityDaemon}}
+}
+
+func TestSupportsDaemon(t *testing.T) {
+	cases := []struct {
+		plugin Plugin
+		want   bool
+	}{
+		{Plugin{Type: TypeExecutable, Capabilities: []string{"net", CapabilityDaemon}}, true},
+		{Plugin{Type: TypeScript, Capabilities: []string{CapabilityDaemon}}, true},
+		{Plugin{Type: TypeWasm, Capabilities: []string{CapabilityDaemon}}, false},
+		{Plugin{Type: TypeExecutable, Capabilities: []string{"net"}}, false},
+	}
+	for _, tc := range cases {
+		if got := SupportsDaemon(tc.plugin); got != tc.want {
+			t.Errorf("SupportsDaemon(%+v) = %v, want %v", tc.plugin, got, tc.want)
+		}
+	}
+}
+
+func TestDaemonPool_ReusesProcess(t *testing.T) {
+	plugin := testDaemonPlugin(t)
+	pool := &DaemonPool{}
+	runner := Runner{Daemons: pool}
+
+	var pids []string
+	for _, args := range [][]string{{"a"}, {"b", "c"}} {
+		var stdout, stderr bytes.Buffer
+		code, err := runner.Run(context.Background(), plugin, args, nil, &stdout, &stderr)
+		if err != nil {
+			t.Fatalf("Run(%q): %v", args, err)
+		}
+		if code != len(args) {
+			t.Errorf("Run(%q) exit code = %d, want %d", args, code, len(args))
+		}
+		pid, rest, _ := strings.Cut(stdout.String(), " ")
+		pids = append(pids, pid)
+		if want := fmt.Sprintf("args=%s name=demo\n", strings.Join(args, ",")); rest != want {
+			t.Errorf("Run(%q) stdout = %q, want %q", args, rest, want)
+		}
+		if stderr.String() != "mode=exec\n" {
+			t.Errorf("Run(%q) stderr = %q, want the request to be in exec mode", args, stderr.String())
+		}
+	}
+	if pids[0] != pids[1] {
+		t.Errorf("expected both requests to reach one daemon, got %v", pids)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestDaemonPool_RestartsAfterExit(t *testing.T) {
+	plugin := testDaemonPlugin(t)
+	pool := &DaemonPool{}
+	defer func() { _ = pool.Close() }()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := pool.Run(context.Background(), plugin, []string{"exit"}, nil, &stdout, &stderr); err == nil {
+		t.Fatal("expected an error when the daemon exits without responding")
+	}
+	code, err := pool.Run(context.Background(), plugin, []string{"again"}, nil, &stdout, &stderr)
+	if err != nil || code != 1 {
+		t.Fatalf("expected a new daemon to answer, got code %d, err %v", code, err)
+	}
+}
+
+func TestDaemonPool_ContextCanceled(t *testing.T) {
+	plugin := testDaemonPlugin(t)
+	pool := &DaemonPool{}
+	defer func() { _ = pool.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	if _, err := pool.Run(ctx, plugin, []string{"sleep"}, nil, &stdout, &stderr); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRunner_DaemonRequiresCapability(t *testing.T) {
+	plugin := testDaemonPlugin(t)
+	plugin.Capabilities = nil
+	pool := &DaemonPool{}
+	defer func() { _ = pool.Close() }()
+
+	if _, err := pool.Run(context.Background(), plugin, nil, nil, io.Discard, io.Discard); err == nil {
+		t.Fatal("expected DaemonPool.Run to reject a plugin without the daemon capability")
+	}
+	if len(pool.daemons) != 0 {
+		t.Fatalf("expected no daemon to start, got %d", len(pool.daemons))
+	}
+}
+
+func TestRunner_DaemonStdin(t *testing.T) {
+	plugin := testDaemonPlugin(t)
+	pool := &DaemonPool{}
+	defer func() { _ = pool.Close() }()
+	runner := Runner{Daemons: pool}
+
+	var stdout bytes.Buffer
+	if _, err := runner.Run(context.Background(), plugin, []string{"cat"}, strings.NewReader("input\n"), &stdout, io.Discard); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if stdout.String() != "input\n" {
+		t.Errorf("expected stdin to reach the daemon, got %q", stdout.String())
+	}
+
+	// A pipe may be held open by another process, so reading it to EOF
+	// could block; such runs fall back to a one-shot process.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close(); _ = w.Close() }()
+	stdout.Reset()
+	if _, err := runner.Run(context.Background(), plugin, []string{"cat"}, r, &stdout, io.Discard); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if stdout.String() != "one-shot\n" {
+		t.Errorf("expected a pipe on stdin to run one-shot, got %q", stdout.String())
+	}
+}
+
+func TestDaemonStdin(t *testing.T) {
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = null.Close() }()
+	file := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(file, []byte("from file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	regular, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = regular.Close() }()
+
+	cases := []struct {
+		name   string
+		stdin  io.Reader
+		want   string
+		wantOK bool
+	}{
+		{"nil", nil, "", true},
+		{"null device", null, "", true},
+		{"regular file", regular, "from file", true},
+		{"in memory", bytes.NewBufferString("buffered"), "buffered", true},
+		{"unknown reader", io.MultiReader(strings.NewReader("x")), "", false},
+	}
+	for _, tc := range cases {
+		got, ok := daemonStdin(tc.stdin)
+		if string(got) != tc.want || ok != tc.wantOK {
+			t.Errorf("%s: daemonStdin() = %q, %v; want %q, %v", tc.name, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitSource records the repository a plugin was built from, so that it can
+// be fetched and built again later.
+type GitSource struct {
+	URL string `json:"url"`
+	// Ref is the branch, tag, or commit that was requested; empty means the
+	// repository's default branch.
+	Ref string `json:"ref,omitempty"`
+	// Commit is the commit that was built.
+	Commit string `json:"commit,omitempty"`
+}
+
+// String formats the source as "URL@REF", or just URL when Ref is empty.
+func (g GitSource) String() string {
+	if g.Ref == "" {
+		return g.URL
+	}
+	return g.URL + "@" + g.Ref
+}
+
+// ParseGitSource parses "URL[@REF]". The ref is split off at the last "@"
+// only when it follows the last "/" and ":", so that the user in URLs such
+// as "git@github.com:org/repo" is kept. Refs therefore cannot contain "/".
+func ParseGitSource(spec string) (GitSource, error) {
+	if spec == "" {
+		return GitSource{}, fmt.Errorf("git url is required")
+	}
+	at := strings.LastIndex(spec, "@")
+	if at > 0 && at > strings.LastIndexAny(spec, "/:") {
+		if at == len(spec)-1 {
+			return GitSource{}, fmt.Errorf("git source %q: empty ref after @", spec)
+		}
+		return GitSource{URL: spec[:at], Ref: spec[at+1:]}, nil
+	}
+	return GitSource{URL: spec}, nil
+}
+
+// InstallFromGit fetches src with a shallow clone, builds the Go module at
+// the repository root with "go build", and installs the result. A wasm
+// pluginType builds for GOOS=wasip1 GOARCH=wasm; script plugins cannot be
+// built. When expectedSHA is set, the built binary must have that digest.
+// Builds use -trimpath so that the same commit and toolchain produce the
+// same binary.
+func (s *Store) InstallFromGit(ctx context.Context, name string, src GitSource, expectedSHA, version string, pluginType PluginType) (Plugin, error) {
+	if err := ValidateName(name); err != nil {
+		return Plugin{}, err
+	}
+	if pluginType == "" {
+		pluginType = TypeExecutable
+	}
+	if pluginType == TypeScript {
+		return Plugin{}, fmt.Errorf("cannot build a %s plugin from git; use --type exe or wasm", TypeScript)
+	}
+
+	workDir, err := os.MkdirTemp("", "sky-plugin-git-")
+	if err != nil {
+		return Plugin{}, fmt.Errorf("create temp: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	repoDir := filepath.Join(workDir, "src")
+	commit, err := shallowClone(ctx, src, repoDir)
+	if err != nil {
+		return Plugin{}, err
+	}
+	src.Commit = commit
+
+	artifact := filepath.Join(workDir, name)
+	if pluginType == TypeWasm {
+		artifact += ".wasm"
+	}
+	if err := goBuild(ctx, repoDir, artifact, pluginType); err != nil {
+		return Plugin{}, err
+	}
+
+	if version == "" {
+		version = src.Ref
+	}
+	if version == "" {
+		version = shortCommit(commit)
+	}
+	plugin, err := s.InstallFromURL(ctx, name, artifact, expectedSHA, version, "", pluginType)
+	if err != nil {
+		return Plugin{}, err
+	}
+	plugin.Source = src.String()
+	plugin.Git = &src
+	if err := s.UpsertPlugin(plugin); err != nil {
+		return Plugin{}, err
+	}
+	return plugin, nil
+}
+
+// shallowClone fetches a single commit of src into dir and returns its hash.
+// Fetching the ref directly, rather than "git clone --branch", lets the ref
+// be a commit as well as a branch or tag.
+func shallowClone(ctx context.Context, src GitSource, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	ref := src.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", src.URL},
+		{"fetch", "--quiet", "--depth", "1", "origin", ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if _, err := runGit(ctx, dir, args...); err != nil {
+			return "", fmt.Errorf("fetch %s: %w", src, err)
+		}
+	}
+	out, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", src, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never prompt for credentials; a private repository should fail
+	// rather than hang the install.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", commandError("git "+args[0], err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// goBuild builds the main package in dir to out.
+func goBuild(ctx context.Context, dir, out string, pluginType PluginType) error {
+	cmd := exec.CommandContext(ctx, "go", "build", "-trimpath", "-o", out, ".")
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	if pluginType == TypeWasm {
+		cmd.Env = append(cmd.Env, "GOOS=wasip1", "GOARCH=wasm")
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("build plugin: %w", commandError("go build", err, stderr.String()))
+	}
+	return nil
+}
+
+// commandError adds a failed command's stderr, which explains the failure,
+// to its error.
+func commandError(name string, err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%s: %s", name, msg)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
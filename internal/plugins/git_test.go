@@ -0,0 +1,142 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    GitSource
+		wantErr string
+	}{
+		{spec: "https://github.com/org/repo", want: GitSource{URL: "https://github.com/org/repo"}},
+		{spec: "https://github.com/org/repo@v1.2.0", want: GitSource{URL: "https://github.com/org/repo", Ref: "v1.2.0"}},
+		{spec: "git@github.com:org/repo.git", want: GitSource{URL: "git@github.com:org/repo.git"}},
+		{spec: "git@github.com:org/repo.git@main", want: GitSource{URL: "git@github.com:org/repo.git", Ref: "main"}},
+		{spec: "ssh://git@example.com/org/repo", want: GitSource{URL: "ssh://git@example.com/org/repo"}},
+		{spec: "https://github.com/org/repo@", wantErr: "empty ref"},
+		{spec: "", wantErr: "git url is required"},
+	}
+	for _, tt := range tests {
+		got, err := ParseGitSource(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseGitSource(%q): expected error containing %q, got %v", tt.spec, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseGitSource(%q) = %+v, %v; want %+v", tt.spec, got, err, tt.want)
+		}
+		if got.String() != tt.spec {
+			t.Errorf("String() = %q, want %q", got.String(), tt.spec)
+		}
+	}
+}
+
+// newGitPluginRepo creates a git repository holding a Go main package, tagged
+// v1.0.0, and returns its path.
+func newGitPluginRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/demo\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init"},
+		{"tag", "v1.0.0"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestInstallFromGit(t *testing.T) {
+	repo := newGitPluginRepo(t)
+	store := NewStore(t.TempDir())
+	ctx := context.Background()
+
+	plugin, err := store.InstallFromGit(ctx, "demo", GitSource{URL: repo, Ref: "v1.0.0"}, "", "", TypeExecutable)
+	if err != nil {
+		t.Fatalf("InstallFromGit: %v", err)
+	}
+	if plugin.Version != "v1.0.0" || plugin.Source != repo+"@v1.0.0" {
+		t.Errorf("unexpected version %q or source %q", plugin.Version, plugin.Source)
+	}
+	if plugin.Git == nil || plugin.Git.URL != repo || plugin.Git.Ref != "v1.0.0" || len(plugin.Git.Commit) != 40 {
+		t.Fatalf("unexpected git source %+v", plugin.Git)
+	}
+	if _, err := os.Stat(plugin.Path); err != nil {
+		t.Fatalf("expected installed binary: %v", err)
+	}
+	recorded, err := store.FindPlugin("demo")
+	if err != nil || recorded == nil || recorded.Git == nil || recorded.Git.Commit != plugin.Git.Commit {
+		t.Fatalf("expected the git source to be recorded, got %+v, %v", recorded, err)
+	}
+
+	// Without a ref the default branch is built, and the commit is the version.
+	plugin, err = store.InstallFromGit(ctx, "demo", GitSource{URL: repo}, "", "", TypeExecutable)
+	if err != nil {
+		t.Fatalf("InstallFromGit: %v", err)
+	}
+	if plugin.Version != plugin.Git.Commit[:12] {
+		t.Errorf("expected the short commit as version, got %q", plugin.Version)
+	}
+
+	_, err = store.InstallFromGit(ctx, "demo", GitSource{URL: repo}, strings.Repeat("0", 64), "", TypeExecutable)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestInstallFromGit_Errors(t *testing.T) {
+	repo := newGitPluginRepo(t)
+	store := NewStore(t.TempDir())
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		src        GitSource
+		pluginType PluginType
+		wantErr    string
+	}{
+		{name: "unknown ref", src: GitSource{URL: repo, Ref: "v9.9.9"}, wantErr: "git fetch"},
+		{name: "missing repo", src: GitSource{URL: filepath.Join(t.TempDir(), "missing")}, wantErr: "git fetch"},
+		{name: "script", src: GitSource{URL: repo}, pluginType: TypeScript, wantErr: "cannot build a script plugin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.InstallFromGit(ctx, "demo", tt.src, "", "", tt.pluginType)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	if plugin, _ := store.FindPlugin("demo"); plugin != nil {
+		t.Errorf("expected nothing installed, got %+v", plugin)
+	}
+}
@@ -0,0 +1,66 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CapabilityHealth declares that a plugin answers health mode requests.
+// Plugins without it are never started in health mode, since older plugins
+// would treat the request as an ordinary run.
+const CapabilityHealth = "health"
+
+// Health is a plugin's report in health mode.
+type Health struct {
+	// Ready reports whether the plugin can run.
+	Ready bool `json:"ready"`
+	// Message explains why the plugin is not ready.
+	Message string `json:"message,omitempty"`
+	// Missing lists dependencies the plugin needs but could not find, such
+	// as tools that must be in PATH.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// SupportsHealth reports whether plugin declared the health capability.
+func SupportsHealth(plugin Plugin) bool {
+	return slices.Contains(plugin.Capabilities, CapabilityHealth)
+}
+
+// HealthCheck runs plugin in health mode and returns its report. A plugin
+// that exits non-zero is not ready, whatever it printed; an error means the
+// plugin could not be run or its report could not be read.
+func (r Runner) HealthCheck(ctx context.Context, plugin Plugin) (Health, error) {
+	if plugin.Path == "" {
+		return Health{}, fmt.Errorf("plugin %q has no path", plugin.Name)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode, err := r.runWithMode(ctx, plugin, ModeHealth, nil, strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		return Health{}, err
+	}
+
+	var health Health
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &health); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = strings.TrimSpace(stdout.String())
+		}
+		if exitCode != 0 {
+			return Health{}, fmt.Errorf("plugin %q exited with %d: %s", plugin.Name, exitCode, message)
+		}
+		return Health{}, fmt.Errorf("plugin %q health parse failed: %v", plugin.Name, message)
+	}
+	if exitCode != 0 {
+		health.Ready = false
+		if health.Message == "" {
+			health.Message = fmt.Sprintf("exited with %d", exitCode)
+		}
+	}
+	return health, nil
+}
@@ -0,0 +1,69 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunnerHealthCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		want      Health
+		wantError string
+	}{
+		{name: "ready", body: `echo '{"ready":true}'`, want: Health{Ready: true}},
+		{
+			name: "missing dependencies",
+			body: `echo '{"ready":false,"message":"missing dependencies: jq","missing":["jq"]}'; exit 1`,
+			want: Health{Message: "missing dependencies: jq", Missing: []string{"jq"}},
+		},
+		{name: "non-zero exit overrides ready", body: `echo '{"ready":true}'; exit 3`, want: Health{Message: "exited with 3"}},
+		{name: "no report", body: `echo 'broken' >&2; exit 2`, wantError: "exited with 2: broken"},
+		{name: "bad report", body: `echo 'not json'`, wantError: "health parse failed: not json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginPath := filepath.Join(t.TempDir(), "demo-plugin")
+			script := strings.Join([]string{
+				"#!/bin/sh",
+				"if [ \"$SKY_PLUGIN_MODE\" != \"health\" ]; then exit 9; fi",
+				tt.body,
+			}, "\n")
+			if err := os.WriteFile(pluginPath, []byte(script), 0o755); err != nil {
+				t.Fatalf("write script: %v", err)
+			}
+
+			got, err := Runner{}.HealthCheck(context.Background(), Plugin{Name: "demo", Path: pluginPath})
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("health check: %v", err)
+			}
+			if got.Ready != tt.want.Ready || got.Message != tt.want.Message || strings.Join(got.Missing, ",") != strings.Join(tt.want.Missing, ",") {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupportsHealth(t *testing.T) {
+	if SupportsHealth(Plugin{Capabilities: []string{CapabilityDaemon}}) {
+		t.Error("plugin without the health capability supports health")
+	}
+	if !SupportsHealth(Plugin{Capabilities: []string{CapabilityHealth}}) {
+		t.Error("plugin with the health capability does not support health")
+	}
+}
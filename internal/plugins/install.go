@@ -40,6 +40,10 @@ func (s *Store) InstallFromPath(name, path, version string, pluginType PluginTyp
 	if err := copyFile(path, dest, 0o755); err != nil {
 		return Plugin{}, fmt.Errorf("install plugin: %w", err)
 	}
+	digest, err := fileSHA256(dest)
+	if err != nil {
+		return Plugin{}, fmt.Errorf("hash plugin: %w", err)
+	}
 
 	plugin := Plugin{
 		Name:        name,
@@ -48,6 +52,7 @@ func (s *Store) InstallFromPath(name, path, version string, pluginType PluginTyp
 		InstalledAt: time.Now().UTC(),
 		Path:        dest,
 		Type:        pluginType,
+		SHA256:      digest,
 	}
 	if err := s.UpsertPlugin(plugin); err != nil {
 		return Plugin{}, err
@@ -88,21 +93,32 @@ func (s *Store) InstallFromURL(ctx context.Context, name, url, expectedSHA, vers
 
 	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
 		client := &http.Client{Timeout: 20 * time.Second}
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return Plugin{}, fmt.Errorf("build request: %w", err)
-		}
-		resp, err := client.Do(req)
+		err := s.retry(ctx, "download "+url, func() error {
+			// Start over after a partial download.
+			if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			if err := tmp.Truncate(0); err != nil {
+				return err
+			}
+			hasher.Reset()
+
+			resp, err := httpGet(ctx, client, url)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if _, err := io.Copy(writer, resp.Body); err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+				return &retryableError{err}
+			}
+			return nil
+		})
 		if err != nil {
 			return Plugin{}, fmt.Errorf("download plugin: %w", err)
 		}
-		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return Plugin{}, fmt.Errorf("download plugin: status %s", resp.Status)
-		}
-		if _, err := io.Copy(writer, resp.Body); err != nil {
-			return Plugin{}, fmt.Errorf("download plugin: %w", err)
-		}
 	} else {
 		path := strings.TrimPrefix(url, "file://")
 		src, err := os.Open(path)
@@ -122,8 +138,8 @@ func (s *Store) InstallFromURL(ctx context.Context, name, url, expectedSHA, vers
 		return Plugin{}, fmt.Errorf("chmod plugin: %w", err)
 	}
 
+	actual := hex.EncodeToString(hasher.Sum(nil))
 	if expectedSHA != "" {
-		actual := hex.EncodeToString(hasher.Sum(nil))
 		if !strings.EqualFold(actual, expectedSHA) {
 			return Plugin{}, fmt.Errorf("checksum mismatch: expected %s got %s", expectedSHA, actual)
 		}
@@ -143,6 +159,7 @@ func (s *Store) InstallFromURL(ctx context.Context, name, url, expectedSHA, vers
 		InstalledAt: time.Now().UTC(),
 		Path:        dest,
 		Type:        pluginType,
+		SHA256:      actual,
 	}
 	if err := s.UpsertPlugin(plugin); err != nil {
 		return Plugin{}, err
@@ -152,17 +169,32 @@ func (s *Store) InstallFromURL(ctx context.Context, name, url, expectedSHA, vers
 
 // InstallFromMarketplace installs a plugin using configured marketplaces.
 func (s *Store) InstallFromMarketplace(ctx context.Context, name, marketplaceName string) (Plugin, error) {
+	return s.InstallFromMarketplacePinned(ctx, name, marketplaceName, "")
+}
+
+// InstallFromMarketplacePinned installs a plugin using configured
+// marketplaces, requiring its binary to have the digest expectedSHA. If the
+// marketplace index lists a different digest, nothing is downloaded. An
+// empty expectedSHA uses the digest from the index, as InstallFromMarketplace
+// does.
+func (s *Store) InstallFromMarketplacePinned(ctx context.Context, name, marketplaceName, expectedSHA string) (Plugin, error) {
 	marketplace, entry, err := s.ResolveMarketplacePlugin(ctx, name, marketplaceName)
 	if err != nil {
 		return Plugin{}, err
 	}
 
+	if expectedSHA == "" {
+		expectedSHA = entry.SHA256
+	} else if entry.SHA256 != "" && !strings.EqualFold(entry.SHA256, expectedSHA) {
+		return Plugin{}, fmt.Errorf("marketplace %q lists sha256 %s for %q, expected %s", marketplace.Name, entry.SHA256, name, expectedSHA)
+	}
+
 	pluginType := entry.Type
 	if pluginType == "" {
 		pluginType = TypeExecutable
 	}
 
-	plugin, err := s.InstallFromURL(ctx, name, entry.URL, entry.SHA256, entry.Version, entry.Description, pluginType)
+	plugin, err := s.InstallFromURL(ctx, name, entry.URL, expectedSHA, entry.Version, entry.Description, pluginType)
 	if err != nil {
 		return Plugin{}, err
 	}
@@ -174,6 +206,21 @@ func (s *Store) InstallFromMarketplace(ctx context.Context, name, marketplaceNam
 	return plugin, nil
 }
 
+// fileSHA256 returns the hex-encoded sha256 digest of a file.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
 func copyFile(srcPath, destPath string, mode os.FileMode) error {
 	src, err := os.Open(srcPath)
 	if err != nil {
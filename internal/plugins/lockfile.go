@@ -0,0 +1,120 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// LockfileName is the file "sky plugin lock" writes at the workspace root.
+const LockfileName = "sky-plugins.lock"
+
+// LockfileVersion is the version of the lockfile format written by Lock.
+const LockfileVersion = 1
+
+// Lockfile pins the sha256 digest of each plugin in a set, so that frozen
+// installs can refuse binaries that differ from the ones that were locked.
+type Lockfile struct {
+	Version int            `json:"version"`
+	Plugins []LockedPlugin `json:"plugins"`
+}
+
+// LockedPlugin is a plugin entry in a Lockfile.
+type LockedPlugin struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Source is where the locked binary was installed from; it is recorded
+	// for reference and not used to verify installs.
+	Source string `json:"source,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+// Digest returns the locked digest for the named plugin.
+func (l Lockfile) Digest(name string) (string, bool) {
+	for _, plugin := range l.Plugins {
+		if plugin.Name == name {
+			return plugin.SHA256, true
+		}
+	}
+	return "", false
+}
+
+// Lock returns a lockfile of the installed plugins, sorted by name. Plugins
+// installed before digests were recorded are hashed from their binaries.
+func (s *Store) Lock() (Lockfile, error) {
+	installed, err := s.LoadPlugins()
+	if err != nil {
+		return Lockfile{}, err
+	}
+
+	lock := Lockfile{Version: LockfileVersion, Plugins: []LockedPlugin{}}
+	for _, plugin := range installed {
+		digest := plugin.SHA256
+		if digest == "" {
+			digest, err = fileSHA256(plugin.Path)
+			if err != nil {
+				return Lockfile{}, fmt.Errorf("hash plugin %q: %w", plugin.Name, err)
+			}
+		}
+		lock.Plugins = append(lock.Plugins, LockedPlugin{
+			Name:    plugin.Name,
+			Version: plugin.Version,
+			Source:  plugin.Source,
+			SHA256:  strings.ToLower(digest),
+		})
+	}
+	sort.Slice(lock.Plugins, func(i, j int) bool {
+		return lock.Plugins[i].Name < lock.Plugins[j].Name
+	})
+	return lock, nil
+}
+
+// ReadLockfile reads and validates the lockfile at path.
+func ReadLockfile(path string) (Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Lockfile{}, err
+	}
+	var lock Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return Lockfile{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if lock.Version < 1 || lock.Version > LockfileVersion {
+		return Lockfile{}, fmt.Errorf("%s: unsupported lockfile version %d (supported: %d)", path, lock.Version, LockfileVersion)
+	}
+
+	seen := make(map[string]bool)
+	for _, plugin := range lock.Plugins {
+		if err := ValidateName(plugin.Name); err != nil {
+			return Lockfile{}, fmt.Errorf("%s: %w", path, err)
+		}
+		if seen[plugin.Name] {
+			return Lockfile{}, fmt.Errorf("%s: plugin %q is locked more than once", path, plugin.Name)
+		}
+		seen[plugin.Name] = true
+		if !isSHA256(plugin.SHA256) {
+			return Lockfile{}, fmt.Errorf("%s: plugin %q: invalid sha256 %q", path, plugin.Name, plugin.SHA256)
+		}
+	}
+	return lock, nil
+}
+
+// WriteLockfile writes lock to path as indented JSON.
+func WriteLockfile(path string, lock Lockfile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(path, data, 0o644)
+}
+
+// isSHA256 reports whether s is a hex-encoded sha256 digest.
+func isSHA256(s string) bool {
+	decoded, err := hex.DecodeString(s)
+	return err == nil && len(decoded) == sha256.Size
+}
@@ -0,0 +1,131 @@
+package plugins
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreLock(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "demo")
+	if err := os.WriteFile(src, []byte("demo-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	store := NewStore(t.TempDir())
+	demo, err := store.InstallFromPath("demo", src, "1.0.0", TypeExecutable)
+	if err != nil {
+		t.Fatalf("InstallFromPath: %v", err)
+	}
+	alpha, err := store.InstallFromPath("alpha", src, "", TypeExecutable)
+	if err != nil {
+		t.Fatalf("InstallFromPath: %v", err)
+	}
+	// Plugins recorded before digests were tracked are hashed from disk.
+	alpha.SHA256 = ""
+	if err := store.UpsertPlugin(alpha); err != nil {
+		t.Fatalf("UpsertPlugin: %v", err)
+	}
+
+	lock, err := store.Lock()
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if len(lock.Plugins) != 2 || lock.Plugins[0].Name != "alpha" || lock.Plugins[1].Name != "demo" {
+		t.Fatalf("unexpected lock: %+v", lock.Plugins)
+	}
+	if lock.Plugins[0].SHA256 != demo.SHA256 {
+		t.Errorf("alpha digest = %q, want %q", lock.Plugins[0].SHA256, demo.SHA256)
+	}
+
+	path := filepath.Join(t.TempDir(), LockfileName)
+	if err := WriteLockfile(path, lock); err != nil {
+		t.Fatalf("WriteLockfile: %v", err)
+	}
+	read, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatalf("ReadLockfile: %v", err)
+	}
+	if digest, ok := read.Digest("demo"); !ok || digest != demo.SHA256 {
+		t.Errorf("Digest(demo) = %q, %v", digest, ok)
+	}
+	if _, ok := read.Digest("missing"); ok {
+		t.Error("expected no digest for an unlocked plugin")
+	}
+}
+
+func TestReadLockfile(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	cases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "valid", input: `{"version":1,"plugins":[{"name":"demo","sha256":"` + digest + `"}]}`},
+		{name: "future version", input: `{"version":2,"plugins":[]}`, wantErr: "unsupported lockfile version 2"},
+		{name: "bad name", input: `{"version":1,"plugins":[{"name":"Demo","sha256":"` + digest + `"}]}`, wantErr: "invalid plugin name"},
+		{name: "duplicate", input: `{"version":1,"plugins":[{"name":"demo","sha256":"` + digest + `"},{"name":"demo","sha256":"` + digest + `"}]}`, wantErr: "locked more than once"},
+		{name: "missing sha256", input: `{"version":1,"plugins":[{"name":"demo"}]}`, wantErr: "invalid sha256"},
+		{name: "short sha256", input: `{"version":1,"plugins":[{"name":"demo","sha256":"abcd"}]}`, wantErr: "invalid sha256"},
+		{name: "not json", input: `plugins:`, wantErr: "parse"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), LockfileName)
+			if err := os.WriteFile(path, []byte(tc.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ReadLockfile(path)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ReadLockfile: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestInstallFromMarketplacePinned(t *testing.T) {
+	binary := []byte("demo-binary")
+	sum := sha256.Sum256(binary)
+	digest := hex.EncodeToString(sum[:])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/demo" {
+			_, _ = w.Write(binary)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"team","plugins":[
+			{"name":"demo","url":"` + "http://" + r.Host + `/demo"},
+			{"name":"listed","url":"` + "http://" + r.Host + `/demo","sha256":"` + digest + `"}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	store := NewStore(t.TempDir())
+	if err := store.UpsertMarketplace(Marketplace{Name: "team", URL: server.URL + "/index.json"}); err != nil {
+		t.Fatalf("add marketplace: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := store.InstallFromMarketplacePinned(ctx, "demo", "", digest); err != nil {
+		t.Fatalf("pinned install: %v", err)
+	}
+	_, err := store.InstallFromMarketplacePinned(ctx, "demo", "", strings.Repeat("0", 64))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+	// The index's own digest is checked against the pin before downloading.
+	_, err = store.InstallFromMarketplacePinned(ctx, "listed", "", strings.Repeat("0", 64))
+	if err == nil || !strings.Contains(err.Error(), "lists sha256") {
+		t.Errorf("expected an index digest mismatch, got %v", err)
+	}
+}
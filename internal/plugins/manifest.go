@@ -0,0 +1,251 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestVersion is the version of the manifest format written by Export.
+const ManifestVersion = 1
+
+// Manifest describes a set of plugins and marketplaces, so that it can be
+// recreated in another store. It records where each plugin was fetched from
+// rather than the binaries themselves.
+type Manifest struct {
+	Version      int                   `json:"version"`
+	Marketplaces []ManifestMarketplace `json:"marketplaces,omitempty"`
+	Plugins      []ManifestPlugin      `json:"plugins"`
+}
+
+// ManifestMarketplace is a marketplace entry in a Manifest.
+type ManifestMarketplace struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ManifestPlugin is a plugin entry in a Manifest.
+type ManifestPlugin struct {
+	Name        string     `json:"name"`
+	Version     string     `json:"version,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Type        PluginType `json:"type,omitempty"`
+	// Source is the URL or local path the plugin binary is fetched from.
+	Source string `json:"source"`
+	// Marketplace names the marketplace that listed Source, if any.
+	Marketplace string `json:"marketplace,omitempty"`
+	// SHA256 is the expected digest of the binary. When set, installing
+	// from the manifest fails if the fetched binary does not match.
+	SHA256 string `json:"sha256,omitempty"`
+	// Git is set for plugins built from a git repository; Source then holds
+	// the same repository in "URL@REF" form.
+	Git *GitSource `json:"git,omitempty"`
+}
+
+// Export returns a manifest of the installed plugins and configured
+// marketplaces, sorted by name.
+func (s *Store) Export() (Manifest, error) {
+	marketplaces, err := s.LoadMarketplaces()
+	if err != nil {
+		return Manifest{}, err
+	}
+	installed, err := s.LoadPlugins()
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	manifest := Manifest{Version: ManifestVersion, Plugins: []ManifestPlugin{}}
+	for _, marketplace := range marketplaces {
+		manifest.Marketplaces = append(manifest.Marketplaces, ManifestMarketplace{Name: marketplace.Name, URL: marketplace.URL})
+	}
+	for _, plugin := range installed {
+		entry := ManifestPlugin{
+			Name:        plugin.Name,
+			Version:     plugin.Version,
+			Description: plugin.Description,
+			Type:        plugin.EffectiveType(),
+			SHA256:      plugin.SHA256,
+			Git:         plugin.Git,
+		}
+		if plugin.Git != nil {
+			entry.Source = plugin.Git.String()
+		} else {
+			entry.Marketplace, entry.Source = splitSource(plugin.Source)
+		}
+		manifest.Plugins = append(manifest.Plugins, entry)
+	}
+
+	sort.Slice(manifest.Marketplaces, func(i, j int) bool {
+		return manifest.Marketplaces[i].Name < manifest.Marketplaces[j].Name
+	})
+	sort.Slice(manifest.Plugins, func(i, j int) bool {
+		return manifest.Plugins[i].Name < manifest.Plugins[j].Name
+	})
+	return manifest, nil
+}
+
+// splitSource separates the marketplace name from a plugin source recorded
+// by InstallFromMarketplace as "NAME (URL)". Other sources are returned
+// unchanged with an empty marketplace.
+func splitSource(source string) (marketplace, location string) {
+	name, rest, ok := strings.Cut(source, " (")
+	if !ok || !strings.HasSuffix(rest, ")") || ValidateName(name) != nil {
+		return "", source
+	}
+	return name, strings.TrimSuffix(rest, ")")
+}
+
+// ReadManifest decodes and validates a manifest.
+func ReadManifest(r io.Reader) (Manifest, error) {
+	var manifest Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return Manifest{}, fmt.Errorf("parse manifest: %w", err)
+	}
+	if manifest.Version < 1 || manifest.Version > ManifestVersion {
+		return Manifest{}, fmt.Errorf("unsupported manifest version %d (supported: %d)", manifest.Version, ManifestVersion)
+	}
+
+	seen := make(map[string]bool)
+	for _, marketplace := range manifest.Marketplaces {
+		if err := ValidateName(marketplace.Name); err != nil {
+			return Manifest{}, fmt.Errorf("manifest marketplace: %w", err)
+		}
+		if marketplace.URL == "" {
+			return Manifest{}, fmt.Errorf("manifest marketplace %q: url is required", marketplace.Name)
+		}
+	}
+	for i, plugin := range manifest.Plugins {
+		if err := ValidateName(plugin.Name); err != nil {
+			return Manifest{}, fmt.Errorf("manifest plugin: %w", err)
+		}
+		if seen[plugin.Name] {
+			return Manifest{}, fmt.Errorf("manifest plugin %q is listed more than once", plugin.Name)
+		}
+		seen[plugin.Name] = true
+		if plugin.Source == "" {
+			return Manifest{}, fmt.Errorf("manifest plugin %q: source is required", plugin.Name)
+		}
+		if plugin.Git != nil && plugin.Git.URL == "" {
+			return Manifest{}, fmt.Errorf("manifest plugin %q: git url is required", plugin.Name)
+		}
+		pluginType, err := ParsePluginType(string(plugin.Type))
+		if err != nil {
+			return Manifest{}, fmt.Errorf("manifest plugin %q: %w", plugin.Name, err)
+		}
+		manifest.Plugins[i].Type = pluginType
+	}
+	return manifest, nil
+}
+
+// ImportStatus is the outcome of importing one manifest plugin.
+type ImportStatus string
+
+const (
+	ImportInstalled ImportStatus = "installed"
+	ImportUnchanged ImportStatus = "unchanged"
+	ImportFailed    ImportStatus = "failed"
+	// ImportSkipped marks plugins left untried after a failure when
+	// ImportOptions.FailFast is set.
+	ImportSkipped ImportStatus = "skipped"
+)
+
+// ImportOptions controls Import.
+type ImportOptions struct {
+	// FailFast stops installing plugins after the first failure.
+	FailFast bool
+	// Check, if set, vets each plugin before it is installed. A plugin it
+	// returns an error for is not installed and fails with that error.
+	Check func(ManifestPlugin) error
+}
+
+// ImportResult reports what Import did with one manifest plugin.
+type ImportResult struct {
+	Plugin ManifestPlugin
+	Status ImportStatus
+	// Err is set when Status is ImportFailed.
+	Err error
+}
+
+// Import adds the manifest's marketplaces and installs its plugins from
+// their recorded sources. Plugins already installed with the recorded
+// digest are left alone. A plugin that fails to install does not stop the
+// others unless opts.FailFast is set, in which case the rest are reported as
+// ImportSkipped; its error is in its ImportResult. The returned error is for
+// failures that affect the whole import, such as an unwritable store.
+func (s *Store) Import(ctx context.Context, manifest Manifest, opts ImportOptions) ([]ImportResult, error) {
+	existing, err := s.LoadMarketplaces()
+	if err != nil {
+		return nil, err
+	}
+	for _, marketplace := range manifest.Marketplaces {
+		if hasMarketplace(existing, marketplace) {
+			continue
+		}
+		if err := s.UpsertMarketplace(Marketplace{Name: marketplace.Name, URL: marketplace.URL, AddedAt: time.Now().UTC()}); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]ImportResult, 0, len(manifest.Plugins))
+	failed := false
+	for _, entry := range manifest.Plugins {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := ImportResult{Plugin: entry, Status: ImportInstalled}
+		if failed && opts.FailFast {
+			result.Status = ImportSkipped
+		} else if err := checkImport(opts, entry); err != nil {
+			result.Status, result.Err = ImportFailed, err
+			failed = true
+		} else if installed, err := s.FindPlugin(entry.Name); err == nil && installed != nil &&
+			entry.SHA256 != "" && strings.EqualFold(installed.SHA256, entry.SHA256) {
+			result.Status = ImportUnchanged
+		} else if err := s.installManifestPlugin(ctx, entry); err != nil {
+			result.Status, result.Err = ImportFailed, err
+			failed = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// checkImport runs opts.Check, if any, on entry.
+func checkImport(opts ImportOptions, entry ManifestPlugin) error {
+	if opts.Check == nil {
+		return nil
+	}
+	return opts.Check(entry)
+}
+
+// installManifestPlugin fetches a manifest plugin from its source, or builds
+// it from git, checking the recorded digest, and records the marketplace it
+// came from.
+func (s *Store) installManifestPlugin(ctx context.Context, entry ManifestPlugin) error {
+	if entry.Git != nil {
+		_, err := s.InstallFromGit(ctx, entry.Name, GitSource{URL: entry.Git.URL, Ref: entry.Git.Ref}, entry.SHA256, entry.Version, entry.Type)
+		return err
+	}
+	plugin, err := s.InstallFromURL(ctx, entry.Name, entry.Source, entry.SHA256, entry.Version, entry.Description, entry.Type)
+	if err != nil {
+		return err
+	}
+	if entry.Marketplace == "" {
+		return nil
+	}
+	plugin.Source = fmt.Sprintf("%s (%s)", entry.Marketplace, entry.Source)
+	return s.UpsertPlugin(plugin)
+}
+
+func hasMarketplace(list []Marketplace, want ManifestMarketplace) bool {
+	for _, marketplace := range list {
+		if marketplace.Name == want.Name && marketplace.URL == want.URL {
+			return true
+		}
+	}
+	return false
+}
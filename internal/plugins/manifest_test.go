@@ -0,0 +1,213 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreExportImport(t *testing.T) {
+	src := t.TempDir()
+	binA := filepath.Join(src, "alpha")
+	binB := filepath.Join(src, "beta.wasm")
+	if err := os.WriteFile(binA, []byte("alpha-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	if err := os.WriteFile(binB, []byte("beta-binary"), 0o644); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+
+	from := NewStore(t.TempDir())
+	if err := from.UpsertMarketplace(Marketplace{Name: "team", URL: "https://example.com/index.json"}); err != nil {
+		t.Fatalf("UpsertMarketplace: %v", err)
+	}
+	if _, err := from.InstallFromPath("alpha", binA, "1.0.0", TypeExecutable); err != nil {
+		t.Fatalf("InstallFromPath: %v", err)
+	}
+	beta, err := from.InstallFromURL(context.Background(), "beta", binB, "", "2.0.0", "Beta plugin", TypeWasm)
+	if err != nil {
+		t.Fatalf("InstallFromURL: %v", err)
+	}
+	// Record beta as a marketplace install, as InstallFromMarketplace does.
+	beta.Source = "team (" + binB + ")"
+	if err := from.UpsertPlugin(beta); err != nil {
+		t.Fatalf("UpsertPlugin: %v", err)
+	}
+
+	manifest, err := from.Export()
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(manifest.Marketplaces) != 1 || manifest.Marketplaces[0].Name != "team" {
+		t.Fatalf("unexpected marketplaces: %+v", manifest.Marketplaces)
+	}
+	if len(manifest.Plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %+v", manifest.Plugins)
+	}
+	alpha, betaEntry := manifest.Plugins[0], manifest.Plugins[1]
+	if alpha.Name != "alpha" || alpha.Source != binA || alpha.Marketplace != "" || alpha.Version != "1.0.0" || alpha.SHA256 == "" {
+		t.Errorf("unexpected alpha entry: %+v", alpha)
+	}
+	if betaEntry.Source != binB || betaEntry.Marketplace != "team" || betaEntry.Type != TypeWasm {
+		t.Errorf("unexpected beta entry: %+v", betaEntry)
+	}
+
+	to := NewStore(t.TempDir())
+	results, err := to.Import(context.Background(), manifest, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != ImportInstalled {
+			t.Errorf("%s: status %s, err %v", result.Plugin.Name, result.Status, result.Err)
+		}
+	}
+	roundTrip, err := to.Export()
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	for i := range manifest.Plugins {
+		if roundTrip.Plugins[i] != manifest.Plugins[i] {
+			t.Errorf("round trip changed plugin:\n got %+v\nwant %+v", roundTrip.Plugins[i], manifest.Plugins[i])
+		}
+	}
+	if len(roundTrip.Marketplaces) != 1 || roundTrip.Marketplaces[0] != manifest.Marketplaces[0] {
+		t.Errorf("round trip changed marketplaces: %+v", roundTrip.Marketplaces)
+	}
+
+	// Importing again leaves matching plugins alone.
+	results, err = to.Import(context.Background(), manifest, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != ImportUnchanged {
+			t.Errorf("%s: expected unchanged, got %s", result.Plugin.Name, result.Status)
+		}
+	}
+}
+
+func TestStoreImport_ContinuesPastFailures(t *testing.T) {
+	src := t.TempDir()
+	bin := filepath.Join(src, "good")
+	if err := os.WriteFile(bin, []byte("good-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+
+	manifest := Manifest{
+		Version: ManifestVersion,
+		Plugins: []ManifestPlugin{
+			{Name: "missing", Source: filepath.Join(src, "missing")},
+			{Name: "tampered", Source: bin, SHA256: strings.Repeat("0", 64)},
+			{Name: "good", Source: bin, Type: TypeExecutable},
+		},
+	}
+	store := NewStore(t.TempDir())
+	results, err := store.Import(context.Background(), manifest, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := []ImportStatus{ImportFailed, ImportFailed, ImportInstalled}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("%s: status %s (err %v), want %s", result.Plugin.Name, result.Status, result.Err, want[i])
+		}
+	}
+	if !strings.Contains(results[1].Err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", results[1].Err)
+	}
+	if plugin, _ := store.FindPlugin("tampered"); plugin != nil {
+		t.Errorf("expected tampered plugin not to be installed, got %+v", plugin)
+	}
+
+	store = NewStore(t.TempDir())
+	results, err = store.Import(context.Background(), manifest, ImportOptions{FailFast: true})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want = []ImportStatus{ImportFailed, ImportSkipped, ImportSkipped}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("fail fast: %s: status %s, want %s", result.Plugin.Name, result.Status, want[i])
+		}
+	}
+	if plugin, _ := store.FindPlugin("good"); plugin != nil {
+		t.Errorf("expected fail fast to skip good plugin, got %+v", plugin)
+	}
+}
+
+func TestStoreImport_Check(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "plugin")
+	if err := os.WriteFile(bin, []byte("plugin-binary"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+
+	manifest := Manifest{
+		Version: ManifestVersion,
+		Plugins: []ManifestPlugin{
+			{Name: "reserved", Source: bin, Type: TypeExecutable},
+			{Name: "good", Source: bin, Type: TypeExecutable},
+		},
+	}
+	check := func(entry ManifestPlugin) error {
+		if entry.Name == "reserved" {
+			return errors.New("name is reserved")
+		}
+		return nil
+	}
+	store := NewStore(t.TempDir())
+	results, err := store.Import(context.Background(), manifest, ImportOptions{Check: check})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := []ImportStatus{ImportFailed, ImportInstalled}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("%s: status %s (err %v), want %s", result.Plugin.Name, result.Status, result.Err, want[i])
+		}
+	}
+	if results[0].Err == nil || results[0].Err.Error() != "name is reserved" {
+		t.Errorf("expected the check error, got %v", results[0].Err)
+	}
+	if plugin, _ := store.FindPlugin("reserved"); plugin != nil {
+		t.Errorf("expected rejected plugin not to be installed, got %+v", plugin)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "valid", input: `{"version":1,"plugins":[{"name":"demo","source":"https://example.com/demo","type":"bin"}]}`},
+		{name: "future version", input: `{"version":2,"plugins":[]}`, wantErr: "unsupported manifest version 2"},
+		{name: "missing version", input: `{"plugins":[]}`, wantErr: "unsupported manifest version 0"},
+		{name: "bad name", input: `{"version":1,"plugins":[{"name":"Demo","source":"x"}]}`, wantErr: "invalid plugin name"},
+		{name: "duplicate", input: `{"version":1,"plugins":[{"name":"demo","source":"x"},{"name":"demo","source":"y"}]}`, wantErr: "listed more than once"},
+		{name: "no source", input: `{"version":1,"plugins":[{"name":"demo"}]}`, wantErr: "source is required"},
+		{name: "bad type", input: `{"version":1,"plugins":[{"name":"demo","source":"x","type":"jar"}]}`, wantErr: "unknown plugin type"},
+		{name: "marketplace url", input: `{"version":1,"marketplaces":[{"name":"team"}],"plugins":[]}`, wantErr: "url is required"},
+		{name: "not json", input: `plugins:`, wantErr: "parse manifest"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest, err := ReadManifest(strings.NewReader(tc.input))
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ReadManifest: %v", err)
+				}
+				if manifest.Plugins[0].Type != TypeExecutable {
+					t.Errorf("expected the type to be normalized, got %q", manifest.Plugins[0].Type)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -1,16 +1,22 @@
 package plugins
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
 
-// SearchMarketplaces returns plugins matching the query across marketplaces.
+// SearchMarketplaces returns plugins matching the query across marketplaces,
+// case-insensitively. Results are ordered by Rank, then by plugin name;
+// equal names keep marketplace order. An empty query matches everything.
 func (s *Store) SearchMarketplaces(ctx context.Context, query, marketplaceName string) ([]SearchResult, error) {
 	marketplaces, err := s.LoadMarketplaces()
 	if err != nil {
@@ -31,16 +37,14 @@ func (s *Store) SearchMarketplaces(ctx context.Context, query, marketplaceName s
 			matchedMarketplace = true
 		}
 
-		index, err := fetchMarketplaceIndex(ctx, marketplace)
+		index, err := s.FetchMarketplaceIndex(ctx, marketplace)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, plugin := range index.Plugins {
-			name := strings.ToLower(plugin.Name)
-			desc := strings.ToLower(plugin.Description)
-			if query == "" || strings.Contains(name, query) || strings.Contains(desc, query) {
-				results = append(results, SearchResult{Marketplace: marketplace, Plugin: plugin})
+			if rank, ok := matchRank(plugin, query); ok {
+				results = append(results, SearchResult{Marketplace: marketplace, Plugin: plugin, Rank: rank})
 			}
 		}
 	}
@@ -54,9 +58,34 @@ func (s *Store) SearchMarketplaces(ctx context.Context, query, marketplaceName s
 	if len(results) == 0 {
 		return nil, fmt.Errorf("no plugins matched %q", query)
 	}
+
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(a.Rank, b.Rank),
+			strings.Compare(a.Plugin.Name, b.Plugin.Name),
+		)
+	})
 	return results, nil
 }
 
+// matchRank reports how a plugin matches a lowercased query.
+func matchRank(plugin MarketplacePlugin, query string) (MatchRank, bool) {
+	name := strings.ToLower(plugin.Name)
+	switch {
+	case query == "":
+		return MatchName, true
+	case name == query:
+		return MatchExact, true
+	case strings.HasPrefix(name, query):
+		return MatchPrefix, true
+	case strings.Contains(name, query):
+		return MatchName, true
+	case strings.Contains(strings.ToLower(plugin.Description), query):
+		return MatchDescription, true
+	}
+	return 0, false
+}
+
 // ResolveMarketplacePlugin finds a plugin entry by name.
 func (s *Store) ResolveMarketplacePlugin(ctx context.Context, name, marketplaceName string) (Marketplace, MarketplacePlugin, error) {
 	if err := ValidateName(name); err != nil {
@@ -80,7 +109,7 @@ func (s *Store) ResolveMarketplacePlugin(ctx context.Context, name, marketplaceN
 			matchedMarketplace = true
 		}
 
-		index, err := fetchMarketplaceIndex(ctx, marketplace)
+		index, err := s.FetchMarketplaceIndex(ctx, marketplace)
 		if err != nil {
 			return Marketplace{}, MarketplacePlugin{}, err
 		}
@@ -101,25 +130,28 @@ func (s *Store) ResolveMarketplacePlugin(ctx context.Context, name, marketplaceN
 	return Marketplace{}, MarketplacePlugin{}, fmt.Errorf("plugin %q not found in marketplaces", name)
 }
 
-func fetchMarketplaceIndex(ctx context.Context, marketplace Marketplace) (MarketplaceIndex, error) {
+func (s *Store) fetchMarketplaceIndex(ctx context.Context, marketplace Marketplace) (MarketplaceIndex, error) {
 	source := marketplace.URL
 	var decoder *json.Decoder
 
 	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
 		client := &http.Client{Timeout: 10 * time.Second}
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
-		if err != nil {
-			return MarketplaceIndex{}, fmt.Errorf("marketplace %q: %w", marketplace.Name, err)
-		}
-		resp, err := client.Do(req)
+		var body []byte
+		err := s.retry(ctx, fmt.Sprintf("marketplace %q", marketplace.Name), func() error {
+			resp, err := httpGet(ctx, client, source)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if body, err = io.ReadAll(resp.Body); err != nil && ctx.Err() == nil {
+				return &retryableError{err}
+			}
+			return err
+		})
 		if err != nil {
 			return MarketplaceIndex{}, fmt.Errorf("marketplace %q: %w", marketplace.Name, err)
 		}
-		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return MarketplaceIndex{}, fmt.Errorf("marketplace %q: unexpected status %s", marketplace.Name, resp.Status)
-		}
-		decoder = json.NewDecoder(resp.Body)
+		decoder = json.NewDecoder(bytes.NewReader(body))
 	} else {
 		path := strings.TrimPrefix(source, "file://")
 		file, err := os.Open(path)
@@ -0,0 +1,91 @@
+package plugins
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultIndexTTL is how long a fetched marketplace index stays fresh.
+const DefaultIndexTTL = time.Hour
+
+// cachedIndex is the on-disk envelope for a cached marketplace index.
+type cachedIndex struct {
+	URL       string           `json:"url"`
+	FetchedAt time.Time        `json:"fetched_at"`
+	Index     MarketplaceIndex `json:"index"`
+}
+
+// FetchMarketplaceIndex returns the index served by a marketplace.
+//
+// Remote indices are cached under IndexCacheDir and reused while younger
+// than IndexTTL unless RefreshIndex is set. When a fetch fails and a cached
+// copy exists, the stale copy is returned and a warning is reported via Warn.
+// Local file indices are always read directly.
+func (s *Store) FetchMarketplaceIndex(ctx context.Context, marketplace Marketplace) (MarketplaceIndex, error) {
+	ttl := s.IndexTTL
+	if ttl == 0 {
+		ttl = DefaultIndexTTL
+	}
+	if ttl < 0 || !isRemoteURL(marketplace.URL) {
+		return s.fetchMarketplaceIndex(ctx, marketplace)
+	}
+
+	cachePath := s.indexCachePath(marketplace.URL)
+	cached, cacheErr := readCachedIndex(cachePath, marketplace.URL)
+	if cacheErr == nil && !s.RefreshIndex && time.Since(cached.FetchedAt) < ttl {
+		return cached.Index, nil
+	}
+
+	index, err := s.fetchMarketplaceIndex(ctx, marketplace)
+	if err != nil {
+		if cacheErr != nil {
+			return MarketplaceIndex{}, err
+		}
+		s.warnf("%v; using cached index from %s", err, cached.FetchedAt.Local().Format(time.RFC3339))
+		return cached.Index, nil
+	}
+
+	entry := cachedIndex{URL: marketplace.URL, FetchedAt: time.Now().UTC(), Index: index}
+	if err := writeJSON(cachePath, entry); err != nil {
+		s.warnf("marketplace %q: cache index: %v", marketplace.Name, err)
+	}
+	return index, nil
+}
+
+// indexCachePath returns the cache file for an index URL. URLs are hashed so
+// that ad-hoc marketplaces and renamed entries never collide.
+func (s *Store) indexCachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(s.IndexCacheDir(), hex.EncodeToString(sum[:8])+".json")
+}
+
+func (s *Store) warnf(format string, args ...any) {
+	if s.Warn != nil {
+		s.Warn(fmt.Sprintf(format, args...))
+	}
+}
+
+func readCachedIndex(path, url string) (cachedIndex, error) {
+	if _, err := os.Stat(path); err != nil {
+		return cachedIndex{}, err
+	}
+	var entry cachedIndex
+	if err := readJSON(path, &entry); err != nil {
+		return cachedIndex{}, err
+	}
+	if entry.URL != url {
+		return cachedIndex{}, errors.New("cache entry url mismatch")
+	}
+	return entry, nil
+}
+
+func isRemoteURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
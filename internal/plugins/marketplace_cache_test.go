@@ -0,0 +1,130 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newIndexServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		_, _ = fmt.Fprintf(w, `{"name":"demo","plugins":[{"name":"p%d","version":"1.0.0","url":"https://example.com/p"}]}`, n)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchMarketplaceIndex_CachesWithinTTL(t *testing.T) {
+	var hits atomic.Int32
+	server := newIndexServer(t, &hits)
+	store := NewStore(t.TempDir())
+	marketplace := Marketplace{Name: "demo", URL: server.URL}
+
+	for i := 0; i < 3; i++ {
+		index, err := store.FetchMarketplaceIndex(context.Background(), marketplace)
+		if err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+		if index.Plugins[0].Name != "p1" {
+			t.Fatalf("fetch %d: expected cached p1, got %s", i, index.Plugins[0].Name)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("expected 1 network fetch, got %d", hits.Load())
+	}
+
+	store.RefreshIndex = true
+	index, err := store.FetchMarketplaceIndex(context.Background(), marketplace)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if index.Plugins[0].Name != "p2" || hits.Load() != 2 {
+		t.Fatalf("expected refresh to refetch, got %s after %d hits", index.Plugins[0].Name, hits.Load())
+	}
+}
+
+func TestFetchMarketplaceIndex_ExpiredTTL(t *testing.T) {
+	var hits atomic.Int32
+	server := newIndexServer(t, &hits)
+	store := NewStore(t.TempDir())
+	store.IndexTTL = time.Minute
+	marketplace := Marketplace{Name: "demo", URL: server.URL}
+
+	if _, err := store.FetchMarketplaceIndex(context.Background(), marketplace); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	var entry cachedIndex
+	path := store.indexCachePath(server.URL)
+	if err := readJSON(path, &entry); err != nil {
+		t.Fatalf("read cache: %v", err)
+	}
+	entry.FetchedAt = entry.FetchedAt.Add(-2 * time.Minute)
+	if err := writeJSON(path, entry); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+
+	if _, err := store.FetchMarketplaceIndex(context.Background(), marketplace); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("expected expired cache to refetch, got %d hits", hits.Load())
+	}
+}
+
+func TestFetchMarketplaceIndex_StaleFallback(t *testing.T) {
+	var hits atomic.Int32
+	server := newIndexServer(t, &hits)
+	store := NewStore(t.TempDir())
+	store.Retries = -1
+	var warnings []string
+	store.Warn = func(msg string) { warnings = append(warnings, msg) }
+	marketplace := Marketplace{Name: "demo", URL: server.URL}
+
+	if _, err := store.FetchMarketplaceIndex(context.Background(), marketplace); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	server.Close()
+
+	store.RefreshIndex = true
+	index, err := store.FetchMarketplaceIndex(context.Background(), marketplace)
+	if err != nil {
+		t.Fatalf("expected stale fallback, got %v", err)
+	}
+	if index.Plugins[0].Name != "p1" {
+		t.Fatalf("expected cached index, got %s", index.Plugins[0].Name)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "using cached index") {
+		t.Fatalf("expected stale warning, got %q", warnings)
+	}
+
+	other := Marketplace{Name: "other", URL: server.URL + "/other"}
+	if _, err := store.FetchMarketplaceIndex(context.Background(), other); err == nil {
+		t.Fatalf("expected error without cache")
+	}
+}
+
+func TestFetchMarketplaceIndex_LocalFilesBypassCache(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root)
+	path := filepath.Join(root, "index.json")
+	if err := os.WriteFile(path, []byte(`{"name":"local","plugins":[]}`), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+
+	if _, err := store.FetchMarketplaceIndex(context.Background(), Marketplace{Name: "local", URL: path}); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if _, err := os.Stat(store.IndexCacheDir()); !os.IsNotExist(err) {
+		t.Fatalf("expected no cache dir for local index, got %v", err)
+	}
+}
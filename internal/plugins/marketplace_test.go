@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchMarketplaces_Ranking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"demo","plugins":[
+			{"name":"zz-lint-helper","url":"https://example.com/a"},
+			{"name":"docs","description":"Lint your docs","url":"https://example.com/b"},
+			{"name":"lint-extra","url":"https://example.com/c"},
+			{"name":"lint","url":"https://example.com/d"},
+			{"name":"lint-aaa","url":"https://example.com/e"},
+			{"name":"unrelated","url":"https://example.com/f"}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	store := NewStore(t.TempDir())
+	if err := store.UpsertMarketplace(Marketplace{Name: "demo", URL: server.URL}); err != nil {
+		t.Fatalf("add marketplace: %v", err)
+	}
+
+	results, err := store.SearchMarketplaces(context.Background(), "LINT", "")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Plugin.Name)
+	}
+	want := []string{"lint", "lint-aaa", "lint-extra", "zz-lint-helper", "docs"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if results[0].Rank != MatchExact || results[4].Rank != MatchDescription {
+		t.Fatalf("unexpected ranks: %v, %v", results[0].Rank, results[4].Rank)
+	}
+}
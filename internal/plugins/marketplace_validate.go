@@ -0,0 +1,110 @@
+package plugins
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// IndexProblem describes a schema violation in a marketplace index.
+type IndexProblem struct {
+	// Entry is the position of the plugin entry in the index, or -1 for
+	// problems with the index itself.
+	Entry   int
+	Plugin  string
+	Field   string
+	Message string
+}
+
+func (p IndexProblem) String() string {
+	if p.Entry < 0 {
+		return fmt.Sprintf("index: %s: %s", p.Field, p.Message)
+	}
+	label := fmt.Sprintf("plugins[%d]", p.Entry)
+	if p.Plugin != "" {
+		label += " (" + p.Plugin + ")"
+	}
+	return fmt.Sprintf("%s: %s: %s", label, p.Field, p.Message)
+}
+
+// ValidateMarketplaceIndex checks an index against the marketplace schema.
+// Each plugin entry requires name, version and url; sha256, description and
+// type are optional but must be well-formed when present.
+func ValidateMarketplaceIndex(index MarketplaceIndex) []IndexProblem {
+	var problems []IndexProblem
+	if index.Plugins == nil {
+		problems = append(problems, IndexProblem{Entry: -1, Field: "plugins", Message: "missing required field"})
+	}
+
+	seen := make(map[string]int, len(index.Plugins))
+	for i, plugin := range index.Plugins {
+		add := func(field, format string, args ...any) {
+			problems = append(problems, IndexProblem{
+				Entry:   i,
+				Plugin:  plugin.Name,
+				Field:   field,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		switch {
+		case plugin.Name == "":
+			add("name", "missing required field")
+		case ValidateName(plugin.Name) != nil:
+			add("name", "invalid plugin name %q", plugin.Name)
+		default:
+			if first, ok := seen[plugin.Name]; ok {
+				add("name", "duplicate of plugins[%d]", first)
+			} else {
+				seen[plugin.Name] = i
+			}
+		}
+
+		if strings.TrimSpace(plugin.Version) == "" {
+			add("version", "missing required field")
+		}
+
+		if plugin.URL == "" {
+			add("url", "missing required field")
+		} else if strings.Contains(plugin.URL, "://") &&
+			!strings.HasPrefix(plugin.URL, "http://") &&
+			!strings.HasPrefix(plugin.URL, "https://") &&
+			!strings.HasPrefix(plugin.URL, "file://") {
+			add("url", "unsupported scheme in %q", plugin.URL)
+		}
+
+		if plugin.SHA256 != "" {
+			if decoded, err := hex.DecodeString(plugin.SHA256); err != nil || len(decoded) != 32 {
+				add("sha256", "expected 64 hex characters")
+			}
+		}
+
+		if plugin.Type != "" {
+			if _, err := ParsePluginType(string(plugin.Type)); err != nil {
+				add("type", "%v", err)
+			}
+		}
+	}
+	return problems
+}
+
+// ResolveMarketplace returns the configured marketplace with the given name.
+// Inputs that look like a URL or path are wrapped in an ad-hoc marketplace so
+// indices can be inspected without adding them first.
+func (s *Store) ResolveMarketplace(nameOrURL string) (Marketplace, error) {
+	if ValidateName(nameOrURL) == nil {
+		marketplaces, err := s.LoadMarketplaces()
+		if err != nil {
+			return Marketplace{}, err
+		}
+		for _, marketplace := range marketplaces {
+			if marketplace.Name == nameOrURL {
+				return marketplace, nil
+			}
+		}
+		if !strings.ContainsAny(nameOrURL, "/.") {
+			return Marketplace{}, fmt.Errorf("marketplace %q not configured", nameOrURL)
+		}
+	}
+	return Marketplace{Name: nameOrURL, URL: nameOrURL}, nil
+}
@@ -0,0 +1,88 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateMarketplaceIndex(t *testing.T) {
+	index := MarketplaceIndex{
+		Name: "demo",
+		Plugins: []MarketplacePlugin{
+			{Name: "good", Version: "1.0.0", URL: "https://example.com/good", SHA256: strings.Repeat("a", 64)},
+			{Name: "", Version: "1.0.0", URL: "https://example.com/anon"},
+			{Name: "good", Version: "", URL: ""},
+			{Name: "Bad_Name", Version: "1.0.0", URL: "ftp://example.com/bad", SHA256: "xyz", Type: "jar"},
+		},
+	}
+
+	problems := ValidateMarketplaceIndex(index)
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+
+	want := []string{
+		"plugins[1]: name: missing required field",
+		"plugins[2] (good): name: duplicate of plugins[0]",
+		"plugins[2] (good): version: missing required field",
+		"plugins[2] (good): url: missing required field",
+		`plugins[3] (Bad_Name): name: invalid plugin name "Bad_Name"`,
+		`plugins[3] (Bad_Name): url: unsupported scheme in "ftp://example.com/bad"`,
+		"plugins[3] (Bad_Name): sha256: expected 64 hex characters",
+		`plugins[3] (Bad_Name): type: unknown plugin type "jar"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateMarketplaceIndex_MissingPlugins(t *testing.T) {
+	problems := ValidateMarketplaceIndex(MarketplaceIndex{Name: "empty"})
+	if len(problems) != 1 || problems[0].String() != "index: plugins: missing required field" {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+}
+
+func TestResolveMarketplace(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root)
+	if err := store.UpsertMarketplace(Marketplace{Name: "local", URL: "file:///tmp/index.json"}); err != nil {
+		t.Fatalf("add marketplace: %v", err)
+	}
+
+	marketplace, err := store.ResolveMarketplace("local")
+	if err != nil {
+		t.Fatalf("resolve by name: %v", err)
+	}
+	if marketplace.URL != "file:///tmp/index.json" {
+		t.Fatalf("unexpected url %q", marketplace.URL)
+	}
+
+	indexPath := filepath.Join(root, "index.json")
+	marketplace, err = store.ResolveMarketplace(indexPath)
+	if err != nil {
+		t.Fatalf("resolve by path: %v", err)
+	}
+	if marketplace.URL != indexPath {
+		t.Fatalf("unexpected url %q", marketplace.URL)
+	}
+
+	if _, err := store.ResolveMarketplace("missing"); err == nil {
+		t.Fatalf("expected unknown marketplace error")
+	}
+
+	if err := os.WriteFile(indexPath, []byte(`{"name":"x","plugins":[{"name":"a","version":"1","url":"https://e/a"}]}`), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	index, err := store.FetchMarketplaceIndex(context.Background(), marketplace)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if problems := ValidateMarketplaceIndex(index); len(problems) != 0 {
+		t.Fatalf("expected valid index, got %v", problems)
+	}
+}
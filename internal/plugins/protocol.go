@@ -3,6 +3,8 @@ package plugins
 import (
 	"fmt"
 	"strings"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
 )
 
 const (
@@ -15,16 +17,55 @@ const (
 	EnvWorkspaceRoot = "SKY_WORKSPACE_ROOT"
 	EnvConfigDir     = "SKY_CONFIG_DIR"
 	EnvOutputFormat  = "SKY_OUTPUT_FORMAT"
-	EnvNoColor       = "SKY_NO_COLOR"
+	EnvNoColor       = skyplugin.EnvNoColor
 	EnvVerbose       = "SKY_VERBOSE"
+
+	// EnvDebugPlugins, when set to a non-empty value other than "0", makes
+	// the host trace each plugin invocation to stderr. It is read by the
+	// host only and not passed to plugins.
+	EnvDebugPlugins = "SKY_DEBUG_PLUGINS"
 )
 
 const (
 	ModeExec     = "exec"
 	ModeMetadata = "metadata"
+	// ModeDaemon starts a plugin that serves skyplugin.DaemonRequests on
+	// stdin until stdin is closed. See DaemonPool.
+	ModeDaemon = "daemon"
+	// ModeHealth asks a plugin to report whether it is ready to run as a
+	// Health JSON object. See Runner.HealthCheck.
+	ModeHealth = "health"
 )
 
-const MetadataAPIVersion = 1
+// MetadataAPIVersion is the newest plugin protocol version the host speaks,
+// and MinMetadataAPIVersion the oldest it still accepts.
+const (
+	MetadataAPIVersion    = 1
+	MinMetadataAPIVersion = 1
+)
+
+// CheckAPIVersion returns an error if the host cannot run a plugin that
+// reports the given api_version, telling the user which side to upgrade.
+func CheckAPIVersion(plugin string, version int) error {
+	switch {
+	case version <= 0:
+		return fmt.Errorf("plugin %q metadata has no api_version; host supports %s", plugin, supportedAPIVersions())
+	case version > MetadataAPIVersion:
+		return fmt.Errorf("plugin %q requires API v%d, host supports %s; upgrade sky", plugin, version, supportedAPIVersions())
+	case version < MinMetadataAPIVersion:
+		return fmt.Errorf("plugin %q uses API v%d, host supports %s; upgrade the plugin", plugin, version, supportedAPIVersions())
+	}
+	return nil
+}
+
+// supportedAPIVersions describes the accepted api_version range, e.g. "v1"
+// or "v1-v3".
+func supportedAPIVersions() string {
+	if MinMetadataAPIVersion == MetadataAPIVersion {
+		return fmt.Sprintf("v%d", MetadataAPIVersion)
+	}
+	return fmt.Sprintf("v%d-v%d", MinMetadataAPIVersion, MetadataAPIVersion)
+}
 
 // PluginType describes how a plugin is executed.
 type PluginType string
@@ -32,21 +73,32 @@ type PluginType string
 const (
 	TypeExecutable PluginType = "exe"
 	TypeWasm       PluginType = "wasm"
+	TypeScript     PluginType = "script"
 )
 
 // Metadata describes a plugin's capabilities.
 type Metadata struct {
-	APIVersion int               `json:"api_version"`
-	Name       string            `json:"name"`
-	Version    string            `json:"version,omitempty"`
-	Summary    string            `json:"summary,omitempty"`
-	Commands   []CommandMetadata `json:"commands,omitempty"`
+	APIVersion   int               `json:"api_version"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version,omitempty"`
+	Summary      string            `json:"summary,omitempty"`
+	Commands     []CommandMetadata `json:"commands,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
 }
 
 // CommandMetadata describes a single plugin command.
 type CommandMetadata struct {
-	Name    string `json:"name"`
-	Summary string `json:"summary,omitempty"`
+	Name    string         `json:"name"`
+	Summary string         `json:"summary,omitempty"`
+	Flags   []FlagMetadata `json:"flags,omitempty"`
+}
+
+// FlagMetadata describes a flag accepted by a plugin command.
+type FlagMetadata struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
 }
 
 // ParsePluginType normalizes user input into a PluginType.
@@ -57,16 +109,26 @@ func ParsePluginType(input string) (PluginType, error) {
 		return TypeExecutable, nil
 	case string(TypeWasm):
 		return TypeWasm, nil
+	case string(TypeScript), "shebang":
+		return TypeScript, nil
 	default:
 		return "", fmt.Errorf("unknown plugin type %q", input)
 	}
 }
 
 // DetectPluginType infers the plugin type from a path or URL.
+// Local files starting with a "#!" shebang line are detected as scripts;
+// remote sources can only be classified by extension.
 func DetectPluginType(source string) PluginType {
-	if strings.HasSuffix(strings.ToLower(strings.TrimSpace(source)), ".wasm") {
+	source = strings.TrimSpace(source)
+	if strings.HasSuffix(strings.ToLower(source), ".wasm") {
 		return TypeWasm
 	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		if _, err := readShebang(strings.TrimPrefix(source, "file://")); err == nil {
+			return TypeScript
+		}
+	}
 	return TypeExecutable
 }
 
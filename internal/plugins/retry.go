@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultRetries is how many times a failed download or marketplace index
+// fetch is retried when Store.Retries is zero.
+const DefaultRetries = 2
+
+// Backoff between attempts: retryBaseDelay, doubling up to retryMaxDelay.
+var (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// retryableError marks a failure that may succeed if tried again, such as a
+// timeout, a dropped connection, or a 5xx response.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// isRetryable reports whether err is worth another attempt.
+func isRetryable(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
+
+// httpGet fetches url, returning the response only for a 200 status. The
+// error is retryable for network failures and for 408, 429, and 5xx
+// statuses; other statuses, such as 404, are final.
+func httpGet(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &retryableError{err}
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	err = fmt.Errorf("status %s", resp.Status)
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return nil, &retryableError{err}
+	}
+	return nil, err
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// retryable, or has been retried Retries times, waiting with exponential
+// backoff between attempts. It stops early if ctx is done. what names the
+// operation in warnings.
+func (s *Store) retry(ctx context.Context, what string, fn func() error) error {
+	retries := s.Retries
+	if retries == 0 {
+		retries = DefaultRetries
+	}
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !isRetryable(err) || attempt >= retries {
+			return err
+		}
+		s.warnf("%s failed: %v; retrying in %s (%d of %d)", what, err, delay, attempt+1, retries)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(2*delay, retryMaxDelay)
+	}
+}
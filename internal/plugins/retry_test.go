@@ -0,0 +1,110 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status, then serves
+// body.
+func flakyServer(t *testing.T, failures int32, status int, body string, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fastRetries(t *testing.T) {
+	t.Helper()
+	base, maxDelay := retryBaseDelay, retryMaxDelay
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() { retryBaseDelay, retryMaxDelay = base, maxDelay })
+}
+
+func TestInstallFromURL_Retries(t *testing.T) {
+	fastRetries(t)
+
+	var hits atomic.Int32
+	server := flakyServer(t, 2, http.StatusServiceUnavailable, "binary", &hits)
+	var warnings []string
+	store := &Store{Root: t.TempDir(), Warn: func(msg string) { warnings = append(warnings, msg) }}
+
+	if _, err := store.InstallFromURL(context.Background(), "flaky", server.URL, "", "", "", TypeExecutable); err != nil {
+		t.Fatalf("InstallFromURL() error = %v", err)
+	}
+	if hits.Load() != 3 || len(warnings) != 2 || !strings.Contains(warnings[0], "503") {
+		t.Errorf("hits = %d, warnings = %q", hits.Load(), warnings)
+	}
+
+	hits.Store(0)
+	store.Retries = 1
+	if _, err := store.InstallFromURL(context.Background(), "flaky", server.URL, "", "", "", TypeExecutable); err == nil {
+		t.Error("InstallFromURL() succeeded after exhausting retries")
+	}
+	if hits.Load() != 2 {
+		t.Errorf("expected 2 attempts with Retries = 1, got %d", hits.Load())
+	}
+}
+
+func TestInstallFromURL_NoRetryOnFinalErrors(t *testing.T) {
+	fastRetries(t)
+
+	var hits atomic.Int32
+	server := flakyServer(t, 1, http.StatusNotFound, "binary", &hits)
+	store := NewStore(t.TempDir())
+	if _, err := store.InstallFromURL(context.Background(), "missing", server.URL, "", "", "", TypeExecutable); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("InstallFromURL() error = %v, want 404", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("404 was retried: %d attempts", hits.Load())
+	}
+
+	hits.Store(1)
+	if _, err := store.InstallFromURL(context.Background(), "mismatch", server.URL, strings.Repeat("0", 64), "", "", TypeExecutable); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("InstallFromURL() error = %v, want checksum mismatch", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("checksum mismatch was retried: %d attempts", hits.Load()-1)
+	}
+}
+
+func TestFetchMarketplaceIndex_Retries(t *testing.T) {
+	fastRetries(t)
+
+	var hits atomic.Int32
+	server := flakyServer(t, 1, http.StatusBadGateway, `{"plugins": [{"name": "p1"}]}`, &hits)
+	store := &Store{Root: t.TempDir(), IndexTTL: -1}
+	index, err := store.FetchMarketplaceIndex(context.Background(), Marketplace{Name: "demo", URL: server.URL})
+	if err != nil {
+		t.Fatalf("FetchMarketplaceIndex() error = %v", err)
+	}
+	if len(index.Plugins) != 1 || hits.Load() != 2 {
+		t.Errorf("index = %+v after %d attempts", index, hits.Load())
+	}
+}
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := NewStore(t.TempDir())
+	attempts := 0
+	err := store.retry(ctx, "test", func() error {
+		attempts++
+		cancel()
+		return &retryableError{errors.New("timeout")}
+	})
+	if !errors.Is(err, context.Canceled) || attempts != 1 {
+		t.Errorf("retry() = %v after %d attempts, want context.Canceled after 1", err, attempts)
+	}
+}
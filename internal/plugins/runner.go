@@ -6,21 +6,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"time"
 )
 
 // Runner executes plugins based on their type.
-type Runner struct{}
+type Runner struct {
+	// Trace, if non-nil, receives a description of each invocation: the
+	// plugin path, args, SKY_* environment, exit code, and duration.
+	Trace io.Writer
+
+	// Daemons, if non-nil, runs plugins that declare the daemon capability
+	// in long-running processes instead of starting one per Run. Other
+	// plugins, metadata requests, and runs whose stdin cannot be forwarded
+	// (see daemonStdin) still run one-shot.
+	Daemons *DaemonPool
+}
+
+// DebugEnabled reports whether SKY_DEBUG_PLUGINS asks for plugin tracing.
+func DebugEnabled() bool {
+	v := os.Getenv(EnvDebugPlugins)
+	return v != "" && v != "0"
+}
 
 // Metadata fetches plugin metadata using the metadata mode.
-func (Runner) Metadata(ctx context.Context, plugin Plugin) (Metadata, error) {
+func (r Runner) Metadata(ctx context.Context, plugin Plugin) (Metadata, error) {
 	if plugin.Path == "" {
 		return Metadata{}, fmt.Errorf("plugin %q has no path", plugin.Name)
 	}
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
-	exitCode, err := runWithMode(ctx, plugin, ModeMetadata, nil, strings.NewReader(""), &stdout, &stderr)
+	exitCode, err := r.runWithMode(ctx, plugin, ModeMetadata, nil, strings.NewReader(""), &stdout, &stderr)
 	if err != nil {
 		return Metadata{}, err
 	}
@@ -37,8 +55,8 @@ func (Runner) Metadata(ctx context.Context, plugin Plugin) (Metadata, error) {
 		return Metadata{}, fmt.Errorf("plugin %q metadata parse failed: %v", plugin.Name, message)
 	}
 
-	if metadata.APIVersion != MetadataAPIVersion {
-		return Metadata{}, fmt.Errorf("plugin %q metadata api_version %d is unsupported", plugin.Name, metadata.APIVersion)
+	if err := CheckAPIVersion(plugin.Name, metadata.APIVersion); err != nil {
+		return Metadata{}, err
 	}
 	if metadata.Name != "" && metadata.Name != plugin.Name {
 		return Metadata{}, fmt.Errorf("plugin %q metadata name mismatch (%s)", plugin.Name, metadata.Name)
@@ -51,11 +69,53 @@ func (Runner) Metadata(ctx context.Context, plugin Plugin) (Metadata, error) {
 }
 
 // Run executes a plugin with the provided args.
-func (Runner) Run(ctx context.Context, plugin Plugin, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+func (r Runner) Run(ctx context.Context, plugin Plugin, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
 	if plugin.Path == "" {
 		return 1, fmt.Errorf("plugin %q has no path", plugin.Name)
 	}
-	return runWithMode(ctx, plugin, ModeExec, args, stdin, stdout, stderr)
+	return r.runWithMode(ctx, plugin, ModeExec, args, stdin, stdout, stderr)
+}
+
+func (r Runner) runWithMode(ctx context.Context, plugin Plugin, mode string, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	var daemonInput []byte
+	useDaemon := mode == ModeExec && r.Daemons != nil && SupportsDaemon(plugin)
+	if useDaemon {
+		daemonInput, useDaemon = daemonStdin(stdin)
+	}
+	run := func() (int, error) {
+		if useDaemon {
+			return r.Daemons.Run(ctx, plugin, args, daemonInput, stdout, stderr)
+		}
+		return runWithMode(ctx, plugin, mode, args, stdin, stdout, stderr)
+	}
+	if r.Trace == nil {
+		return run()
+	}
+
+	tracef(r.Trace, "plugin %q (%s): %s", plugin.Name, plugin.EffectiveType(), plugin.Path)
+	if useDaemon {
+		tracef(r.Trace, "  mode: %s (daemon)", mode)
+	} else {
+		tracef(r.Trace, "  mode: %s", mode)
+	}
+	tracef(r.Trace, "  args: %q", args)
+	for _, kv := range PluginEnv(plugin.Name, mode) {
+		tracef(r.Trace, "  env: %s", kv)
+	}
+
+	start := time.Now()
+	exitCode, err := run()
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		tracef(r.Trace, "plugin %q failed after %s: %v", plugin.Name, elapsed, err)
+	} else {
+		tracef(r.Trace, "plugin %q exited with %d after %s", plugin.Name, exitCode, elapsed)
+	}
+	return exitCode, err
+}
+
+func tracef(w io.Writer, format string, args ...any) {
+	_, _ = fmt.Fprintf(w, "sky: debug: "+format+"\n", args...)
 }
 
 func runWithMode(ctx context.Context, plugin Plugin, mode string, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
@@ -64,6 +124,8 @@ func runWithMode(ctx context.Context, plugin Plugin, mode string, args []string,
 		return runExec(ctx, plugin, mode, args, stdin, stdout, stderr)
 	case TypeWasm:
 		return runWasm(ctx, plugin, mode, args, stdin, stdout, stderr)
+	case TypeScript:
+		return runScript(ctx, plugin, mode, args, stdin, stdout, stderr)
 	default:
 		return 1, fmt.Errorf("unsupported plugin type %q", plugin.Type)
 	}
@@ -6,11 +6,18 @@ import (
 	"io"
 	"os"
 	"os/exec"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
 )
 
 func runExec(ctx context.Context, plugin Plugin, mode string, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
-	cmd := exec.CommandContext(ctx, plugin.Path, args...)
-	cmd.Env = append(os.Environ(), pluginEnv(plugin.Name, mode)...)
+	return runCommand(ctx, plugin, mode, plugin.Path, args, stdin, stdout, stderr)
+}
+
+// runCommand runs program with the plugin environment and maps its exit status.
+func runCommand(ctx context.Context, plugin Plugin, mode, program string, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	cmd := exec.CommandContext(ctx, program, args...)
+	cmd.Env = append(os.Environ(), PluginEnv(plugin.Name, mode)...)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 	cmd.Stdin = stdin
@@ -25,8 +32,9 @@ func runExec(ctx context.Context, plugin Plugin, mode string, args []string, std
 	return 0, nil
 }
 
-// pluginEnv returns the environment variables for plugin execution.
-func pluginEnv(name, mode string) []string {
+// PluginEnv returns the SKY_* environment variables passed to a plugin
+// executed in the given mode, as "KEY=value" pairs.
+func PluginEnv(name, mode string) []string {
 	env := []string{
 		EnvPlugin + "=1",
 		EnvPluginMode + "=" + mode,
@@ -49,7 +57,7 @@ func pluginEnv(name, mode string) []string {
 	}
 
 	// Propagate no color if set
-	if os.Getenv(EnvNoColor) != "" || os.Getenv("NO_COLOR") != "" {
+	if skyplugin.NoColor() {
 		env = append(env, EnvNoColor+"=1")
 	}
 
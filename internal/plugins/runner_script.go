@@ -0,0 +1,94 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// maxShebangLen bounds how much of a file is read when looking for a shebang.
+const maxShebangLen = 512
+
+// shebangLine describes the interpreter line of a script plugin.
+type shebangLine struct {
+	// Interpreter is the program named on the shebang line.
+	Interpreter string
+	// Args are the arguments that follow the interpreter.
+	Args []string
+}
+
+// readShebang reads and parses the leading "#!" line of a file.
+func readShebang(path string) (shebangLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return shebangLine{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	line, err := bufio.NewReader(io.LimitReader(f, maxShebangLen)).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return shebangLine{}, err
+	}
+	return parseShebang(line)
+}
+
+// parseShebang parses a "#!interpreter [args]" line.
+func parseShebang(line string) (shebangLine, error) {
+	if !strings.HasPrefix(line, "#!") {
+		return shebangLine{}, fmt.Errorf("missing shebang")
+	}
+	fields := strings.Fields(strings.TrimSpace(line[2:]))
+	if len(fields) == 0 {
+		return shebangLine{}, fmt.Errorf("empty shebang")
+	}
+	return shebangLine{Interpreter: fields[0], Args: fields[1:]}, nil
+}
+
+// resolveInterpreter returns the absolute interpreter path and the arguments
+// to pass before the script path. "/usr/bin/env prog" shebangs are resolved
+// through PATH so a missing interpreter is reported before execution.
+func resolveInterpreter(shebang shebangLine) (string, []string, error) {
+	interpreter := shebang.Interpreter
+	args := shebang.Args
+
+	if filepath.Base(interpreter) == "env" && len(args) > 0 {
+		// Skip env options such as -S; the first operand is the program.
+		i := 0
+		for i < len(args) && strings.HasPrefix(args[i], "-") {
+			i++
+		}
+		if i < len(args) {
+			interpreter = args[i]
+			args = args[i+1:]
+		}
+	}
+
+	path, err := exec.LookPath(interpreter)
+	if err != nil {
+		return "", nil, fmt.Errorf("interpreter %q not found: %w", interpreter, err)
+	}
+	return path, args, nil
+}
+
+func runScript(ctx context.Context, plugin Plugin, mode string, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	shebang, err := readShebang(plugin.Path)
+	if err != nil {
+		return 1, fmt.Errorf("plugin %q: %w", plugin.Name, err)
+	}
+	interpreter, interpArgs, err := resolveInterpreter(shebang)
+	if err != nil {
+		return 1, fmt.Errorf("plugin %q: %w", plugin.Name, err)
+	}
+
+	argv := make([]string, 0, len(interpArgs)+1+len(args))
+	argv = append(argv, interpArgs...)
+	argv = append(argv, plugin.Path)
+	argv = append(argv, args...)
+	return runCommand(ctx, plugin, mode, interpreter, argv, stdin, stdout, stderr)
+}
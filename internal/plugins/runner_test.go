@@ -3,6 +3,7 @@ package plugins
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -19,6 +20,7 @@ func TestParsePluginType(t *testing.T) {
 		{input: "", expected: TypeExecutable, ok: true},
 		{input: "exe", expected: TypeExecutable, ok: true},
 		{input: "wasm", expected: TypeWasm, ok: true},
+		{input: "script", expected: TypeScript, ok: true},
 		{input: "bad", expected: "", ok: false},
 	}
 
@@ -55,6 +57,120 @@ func TestDetectPluginType(t *testing.T) {
 	}
 }
 
+func TestDetectPluginType_Shebang(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "tool")
+	if err := os.WriteFile(script, []byte("#!/usr/bin/env python3\nprint('hi')\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	binary := filepath.Join(dir, "binary")
+	if err := os.WriteFile(binary, []byte("\x7fELF"), 0o755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+
+	if got := DetectPluginType(script); got != TypeScript {
+		t.Fatalf("expected %q to detect %q, got %q", script, TypeScript, got)
+	}
+	if got := DetectPluginType("file://" + script); got != TypeScript {
+		t.Fatalf("expected file URL to detect %q, got %q", TypeScript, got)
+	}
+	if got := DetectPluginType(binary); got != TypeExecutable {
+		t.Fatalf("expected %q to detect %q, got %q", binary, TypeExecutable, got)
+	}
+}
+
+func TestParseShebang(t *testing.T) {
+	cases := []struct {
+		line        string
+		interpreter string
+		args        []string
+		ok          bool
+	}{
+		{line: "#!/bin/sh\n", interpreter: "/bin/sh", ok: true},
+		{line: "#! /usr/bin/env python3", interpreter: "/usr/bin/env", args: []string{"python3"}, ok: true},
+		{line: "#!/usr/bin/env -S node --flag", interpreter: "/usr/bin/env", args: []string{"-S", "node", "--flag"}, ok: true},
+		{line: "#!", ok: false},
+		{line: "echo hi", ok: false},
+	}
+
+	for _, tc := range cases {
+		got, err := parseShebang(tc.line)
+		if tc.ok != (err == nil) {
+			t.Fatalf("parseShebang(%q) error = %v, want ok=%v", tc.line, err, tc.ok)
+		}
+		if !tc.ok {
+			continue
+		}
+		if got.Interpreter != tc.interpreter {
+			t.Fatalf("parseShebang(%q) interpreter = %q, want %q", tc.line, got.Interpreter, tc.interpreter)
+		}
+		if strings.Join(got.Args, " ") != strings.Join(tc.args, " ") {
+			t.Fatalf("parseShebang(%q) args = %q, want %q", tc.line, got.Args, tc.args)
+		}
+	}
+}
+
+func TestScriptRunnerMetadataAndRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "demo-script")
+	script := strings.Join([]string{
+		"#!/usr/bin/env sh",
+		"if [ \"$SKY_PLUGIN_MODE\" = \"metadata\" ]; then",
+		"  echo '{\"api_version\":1,\"name\":\"demo\"}'",
+		"  exit 0",
+		"fi",
+		"echo \"$SKY_PLUGIN_NAME args:$@\"",
+		"exit 3",
+	}, "\n")
+
+	// Not executable: script plugins are run through their interpreter.
+	if err := os.WriteFile(pluginPath, []byte(script), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	runner := Runner{}
+	plugin := Plugin{Name: "demo", Path: pluginPath, Type: TypeScript}
+
+	metadata, err := runner.Metadata(context.Background(), plugin)
+	if err != nil {
+		t.Fatalf("metadata: %v", err)
+	}
+	if metadata.Name != "demo" {
+		t.Fatalf("expected metadata name demo, got %q", metadata.Name)
+	}
+
+	var stdout bytes.Buffer
+	exitCode, err := runner.Run(context.Background(), plugin, []string{"alpha"}, nil, &stdout, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if exitCode != 3 {
+		t.Fatalf("expected exit code 3, got %d", exitCode)
+	}
+	if !strings.Contains(stdout.String(), "demo args:alpha") {
+		t.Fatalf("unexpected stdout: %s", stdout.String())
+	}
+}
+
+func TestScriptRunnerMissingInterpreter(t *testing.T) {
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "demo-script")
+	if err := os.WriteFile(pluginPath, []byte("#!/usr/bin/env sky-no-such-interpreter\n"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	runner := Runner{}
+	plugin := Plugin{Name: "demo", Path: pluginPath, Type: TypeScript}
+	_, err := runner.Run(context.Background(), plugin, nil, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "sky-no-such-interpreter") {
+		t.Fatalf("expected missing interpreter error, got %v", err)
+	}
+}
+
 func TestExecRunnerMetadataAndRun(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("shell script plugins are not supported on windows")
@@ -65,7 +181,7 @@ func TestExecRunnerMetadataAndRun(t *testing.T) {
 	script := strings.Join([]string{
 		"#!/bin/sh",
 		"if [ \"$SKY_PLUGIN_MODE\" = \"metadata\" ]; then",
-		"  echo '{\"api_version\":1,\"name\":\"demo\",\"version\":\"0.1.0\",\"summary\":\"Demo plugin\",\"commands\":[{\"name\":\"hello\",\"summary\":\"Say hi\"}]}'",
+		"  echo '{\"api_version\":1,\"name\":\"demo\",\"version\":\"0.1.0\",\"summary\":\"Demo plugin\",\"commands\":[{\"name\":\"hello\",\"summary\":\"Say hi\"}],\"capabilities\":[\"fs:read\",\"net\"]}'",
 		"  exit 0",
 		"fi",
 		"echo \"args:$@\"",
@@ -99,6 +215,9 @@ func TestExecRunnerMetadataAndRun(t *testing.T) {
 	if len(metadata.Commands) != 1 || metadata.Commands[0].Name != "hello" {
 		t.Fatalf("expected command metadata")
 	}
+	if strings.Join(metadata.Capabilities, ",") != "fs:read,net" {
+		t.Fatalf("expected capabilities [fs:read net], got %v", metadata.Capabilities)
+	}
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -116,3 +235,129 @@ func TestExecRunnerMetadataAndRun(t *testing.T) {
 		t.Fatalf("expected empty stderr, got %s", stderr.String())
 	}
 }
+
+func TestExecRunnerMetadataNewerAPIVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "demo-plugin")
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		"echo '{\"api_version\":2,\"name\":\"demo\"}'",
+	}, "\n")
+	if err := os.WriteFile(pluginPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	plugin := Plugin{Name: "demo", Path: pluginPath, Type: TypeExecutable}
+	_, err := Runner{}.Metadata(context.Background(), plugin)
+	want := `plugin "demo" requires API v2, host supports v1; upgrade sky`
+	if err == nil || err.Error() != want {
+		t.Fatalf("expected error %q, got %v", want, err)
+	}
+}
+
+func TestRunnerTrace(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "demo-plugin")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\nexit 3\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	var trace bytes.Buffer
+	runner := Runner{Trace: &trace}
+	plugin := Plugin{Name: "demo", Path: pluginPath, Type: TypeExecutable}
+	exitCode, err := runner.Run(context.Background(), plugin, []string{"alpha"}, nil, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if exitCode != 3 {
+		t.Fatalf("expected exit code 3, got %d", exitCode)
+	}
+
+	for _, want := range []string{
+		`sky: debug: plugin "demo" (exe): ` + pluginPath + "\n",
+		"sky: debug:   mode: exec\n",
+		`sky: debug:   args: ["alpha"]` + "\n",
+		"sky: debug:   env: SKY_PLUGIN_NAME=demo\n",
+		`sky: debug: plugin "demo" exited with 3 after `,
+	} {
+		if !strings.Contains(trace.String(), want) {
+			t.Errorf("expected trace to contain %q, got:\n%s", want, trace.String())
+		}
+	}
+}
+
+func TestDebugEnabled(t *testing.T) {
+	for value, want := range map[string]bool{"": false, "0": false, "1": true, "true": true} {
+		t.Setenv(EnvDebugPlugins, value)
+		if got := DebugEnabled(); got != want {
+			t.Errorf("%s=%q: expected %v, got %v", EnvDebugPlugins, value, want, got)
+		}
+	}
+}
+
+func TestCheckAPIVersion(t *testing.T) {
+	cases := []struct {
+		version int
+		want    string
+	}{
+		{version: MetadataAPIVersion},
+		{version: MinMetadataAPIVersion},
+		{version: 0, want: "has no api_version"},
+		{version: MetadataAPIVersion + 1, want: "upgrade sky"},
+		{version: -1, want: "has no api_version"},
+	}
+
+	for _, tc := range cases {
+		err := CheckAPIVersion("demo", tc.version)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("CheckAPIVersion(%d) = %v, want nil", tc.version, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("CheckAPIVersion(%d) = %v, want error containing %q", tc.version, err, tc.want)
+		}
+	}
+}
+
+func TestPluginEnv(t *testing.T) {
+	t.Setenv(EnvConfigDir, "/tmp/sky-config")
+	t.Setenv(EnvOutputFormat, "json")
+	t.Setenv(EnvNoColor, "")
+	t.Setenv("NO_COLOR", "1")
+
+	env := map[string]string{}
+	for _, kv := range PluginEnv("demo", ModeMetadata) {
+		parts := splitEnvVar(kv)
+		if len(parts) != 2 {
+			t.Fatalf("malformed env entry %q", kv)
+		}
+		env[parts[0]] = parts[1]
+	}
+
+	want := map[string]string{
+		EnvPlugin:       "1",
+		EnvPluginMode:   ModeMetadata,
+		EnvPluginName:   "demo",
+		EnvConfigDir:    "/tmp/sky-config",
+		EnvOutputFormat: "json",
+		EnvNoColor:      "1",
+	}
+	for key, value := range want {
+		if env[key] != value {
+			t.Errorf("%s = %q, want %q", key, env[key], value)
+		}
+	}
+	if env[EnvWorkspaceRoot] == "" {
+		t.Errorf("expected %s to be set", EnvWorkspaceRoot)
+	}
+}
@@ -32,7 +32,7 @@ func runWasm(ctx context.Context, plugin Plugin, mode string, args []string, std
 		WithStderr(stderr)
 
 	// Add plugin environment variables
-	for _, kv := range pluginEnv(plugin.Name, mode) {
+	for _, kv := range PluginEnv(plugin.Name, mode) {
 		parts := splitEnvVar(kv)
 		if len(parts) == 2 {
 			config = config.WithEnv(parts[0], parts[1])
@@ -1,9 +1,11 @@
 package plugins
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,10 +15,40 @@ import (
 	"github.com/gofrs/flock"
 )
 
+// DefaultLockTimeout is how long store operations wait for the store lock,
+// held while another sky process reads or changes the catalog.
+const DefaultLockTimeout = 30 * time.Second
+
+// lockRetryDelay is how often a blocked operation retries the store lock.
+const lockRetryDelay = 50 * time.Millisecond
+
+// ErrLockTimeout is returned when the store lock is not released within
+// the store's LockTimeout.
+var ErrLockTimeout = errors.New("timed out waiting for the plugin store lock")
+
 // Store manages the on-disk plugin catalog and marketplace list.
 type Store struct {
 	Root string
 
+	// IndexTTL is how long fetched marketplace indices are served from the
+	// on-disk cache. Zero uses DefaultIndexTTL; a negative value disables
+	// caching.
+	IndexTTL time.Duration
+	// RefreshIndex forces marketplace indices to be fetched even when a
+	// fresh cache entry exists.
+	RefreshIndex bool
+	// Warn, if set, receives non-fatal warnings such as falling back to a
+	// stale marketplace index.
+	Warn func(msg string)
+	// Retries is how many times a download or marketplace index fetch that
+	// failed with a network error or a 5xx status is retried. Zero uses
+	// DefaultRetries; a negative value disables retries.
+	Retries int
+	// LockTimeout is how long an operation waits for another process to
+	// release the store lock. Zero uses DefaultLockTimeout; a negative
+	// value waits indefinitely.
+	LockTimeout time.Duration
+
 	mu                  sync.Mutex
 	cachedPlugins       []Plugin
 	pluginsModTime      time.Time
@@ -29,17 +61,22 @@ func NewStore(root string) *Store {
 	return &Store{Root: root}
 }
 
-// DefaultStore creates a store in the user config directory.
+// DefaultStore creates a store in the user config directory. Its warnings
+// are written to stderr.
 func DefaultStore() (*Store, error) {
-	if override := os.Getenv("SKY_CONFIG_DIR"); override != "" {
-		return &Store{Root: override}, nil
+	root := os.Getenv(EnvConfigDir)
+	if root == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("config dir: %w", err)
+		}
+		root = filepath.Join(base, "sky")
 	}
+	return &Store{Root: root, Warn: warnStderr}, nil
+}
 
-	base, err := os.UserConfigDir()
-	if err != nil {
-		return nil, fmt.Errorf("config dir: %w", err)
-	}
-	return &Store{Root: filepath.Join(base, "sky")}, nil
+func warnStderr(msg string) {
+	_, _ = fmt.Fprintf(os.Stderr, "sky: warning: %s\n", msg)
 }
 
 // Ensure creates the config directories if needed.
@@ -68,6 +105,11 @@ func (s *Store) MarketplacesFile() string {
 	return filepath.Join(s.Root, "marketplaces.json")
 }
 
+// IndexCacheDir returns the directory holding cached marketplace indices.
+func (s *Store) IndexCacheDir() string {
+	return filepath.Join(s.Root, "cache", "marketplaces")
+}
+
 // LockFile returns the path to the lock file.
 func (s *Store) LockFile() string {
 	return filepath.Join(s.Root, "lock")
@@ -81,7 +123,7 @@ func (s *Store) withWriteLock(fn func() error) error {
 	}
 
 	fileLock := flock.New(s.LockFile())
-	if err := fileLock.Lock(); err != nil {
+	if err := s.acquire(fileLock.Lock, fileLock.TryLockContext); err != nil {
 		return fmt.Errorf("acquire write lock: %w", err)
 	}
 	defer func() { _ = fileLock.Unlock() }()
@@ -97,7 +139,7 @@ func (s *Store) withReadLock(fn func() error) error {
 	}
 
 	fileLock := flock.New(s.LockFile())
-	if err := fileLock.RLock(); err != nil {
+	if err := s.acquire(fileLock.RLock, fileLock.TryRLockContext); err != nil {
 		return fmt.Errorf("acquire read lock: %w", err)
 	}
 	defer func() { _ = fileLock.Unlock() }()
@@ -105,6 +147,29 @@ func (s *Store) withReadLock(fn func() error) error {
 	return fn()
 }
 
+// acquire takes the store lock with lock, or with tryLock if LockTimeout
+// bounds the wait, returning ErrLockTimeout when it expires.
+func (s *Store) acquire(lock func() error, tryLock func(context.Context, time.Duration) (bool, error)) error {
+	timeout := s.LockTimeout
+	if timeout == 0 {
+		timeout = DefaultLockTimeout
+	}
+	if timeout < 0 {
+		return lock()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	locked, err := tryLock(ctx, lockRetryDelay)
+	if locked {
+		return nil
+	}
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w %s after %s; another sky command is using the store", ErrLockTimeout, s.LockFile(), timeout)
+	}
+	return err
+}
+
 // PluginPath returns the expected binary path for a plugin type.
 func (s *Store) PluginPath(name string, pluginType PluginType) string {
 	filename := name
@@ -149,7 +214,13 @@ func (s *Store) loadPluginsNL() ([]Plugin, error) {
 
 	var plugins []Plugin
 	if err := readJSON(s.PluginsFile(), &plugins); err != nil {
-		return nil, fmt.Errorf("load plugins: %w", err)
+		if !isCorruptJSON(err) {
+			return nil, fmt.Errorf("load plugins: %w", err)
+		}
+		if err := s.setAsideCorrupt(s.PluginsFile(), err); err != nil {
+			return nil, fmt.Errorf("load plugins: %w", err)
+		}
+		return []Plugin{}, nil
 	}
 	if plugins == nil {
 		plugins = []Plugin{}
@@ -306,7 +377,13 @@ func (s *Store) loadMarketplacesNL() ([]Marketplace, error) {
 
 	var marketplaces []Marketplace
 	if err := readJSON(s.MarketplacesFile(), &marketplaces); err != nil {
-		return nil, fmt.Errorf("load marketplaces: %w", err)
+		if !isCorruptJSON(err) {
+			return nil, fmt.Errorf("load marketplaces: %w", err)
+		}
+		if err := s.setAsideCorrupt(s.MarketplacesFile(), err); err != nil {
+			return nil, fmt.Errorf("load marketplaces: %w", err)
+		}
+		return []Marketplace{}, nil
 	}
 	if marketplaces == nil {
 		marketplaces = []Marketplace{}
@@ -397,6 +474,31 @@ func (s *Store) RemoveMarketplace(name string) (*Marketplace, error) {
 	return removed, err
 }
 
+// isCorruptJSON reports whether err from readJSON means the file holds
+// invalid or truncated JSON, rather than that it could not be read.
+func isCorruptJSON(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// setAsideCorrupt renames a corrupt catalog file to a timestamped backup,
+// so that the store starts over empty instead of failing every command,
+// and warns where the backup is.
+func (s *Store) setAsideCorrupt(path string, cause error) error {
+	backup := fmt.Sprintf("%s.corrupt-%s", path, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.Rename(path, backup); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Another process set it aside first.
+			return nil
+		}
+		return fmt.Errorf("%s is corrupt (%v) and could not be moved aside: %w", path, cause, err)
+	}
+	s.warnf("%s was corrupt (%v); moved it to %s and started with an empty list", path, cause, backup)
+	return nil
+}
+
 func readJSON(path string, target any) error {
 	f, err := os.Open(path)
 	if err != nil {
@@ -433,6 +535,12 @@ func writeJSON(path string, value any) error {
 		_ = tmp.Close()
 		return err
 	}
+	// Flush to disk before the rename, so a crash leaves the old or the new
+	// file but never a truncated one.
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
 	if err := tmp.Close(); err != nil {
 		return err
 	}
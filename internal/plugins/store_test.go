@@ -1,10 +1,15 @@
 package plugins
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gofrs/flock"
 )
 
 func TestValidateName(t *testing.T) {
@@ -175,3 +180,65 @@ func TestStoreConcurrentUpsert(t *testing.T) {
 		t.Fatalf("expected 1 plugin, got %d", len(plugins))
 	}
 }
+
+func TestStoreLockTimeout(t *testing.T) {
+	store := &Store{Root: t.TempDir(), LockTimeout: 100 * time.Millisecond}
+	if err := store.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process holding the lock.
+	held := flock.New(store.LockFile())
+	if err := held.Lock(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.UpsertPlugin(Plugin{Name: "blocked"})
+	if !errors.Is(err, ErrLockTimeout) || !strings.Contains(err.Error(), "another sky command") {
+		t.Fatalf("UpsertPlugin() error = %v, want ErrLockTimeout", err)
+	}
+	if _, err := store.LoadPlugins(); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("LoadPlugins() error = %v, want ErrLockTimeout", err)
+	}
+
+	if err := held.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertPlugin(Plugin{Name: "unblocked"}); err != nil {
+		t.Fatalf("UpsertPlugin() after unlock: %v", err)
+	}
+}
+
+func TestStoreCorruptIndex(t *testing.T) {
+	var warnings []string
+	store := &Store{Root: t.TempDir(), Warn: func(msg string) { warnings = append(warnings, msg) }}
+	if err := store.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	// A write interrupted before the store wrote files atomically.
+	if err := os.WriteFile(store.PluginsFile(), []byte(`[{"name": "half`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := store.LoadPlugins()
+	if err != nil || len(plugins) != 0 {
+		t.Fatalf("LoadPlugins() = %v, %v; want an empty list", plugins, err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "was corrupt") {
+		t.Errorf("warnings = %q", warnings)
+	}
+	backups, _ := filepath.Glob(store.PluginsFile() + ".corrupt-*")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != `[{"name": "half` {
+		t.Errorf("backup content = %q", data)
+	}
+
+	if err := store.UpsertPlugin(Plugin{Name: "fresh"}); err != nil {
+		t.Fatalf("UpsertPlugin() error = %v", err)
+	}
+	if plugin, err := store.FindPlugin("fresh"); err != nil || plugin == nil {
+		t.Errorf("FindPlugin() = %v, %v", plugin, err)
+	}
+}
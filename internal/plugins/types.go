@@ -11,6 +11,12 @@ type Plugin struct {
 	InstalledAt time.Time  `json:"installed_at,omitempty"`
 	Path        string     `json:"path,omitempty"`
 	Type        PluginType `json:"type,omitempty"`
+	SHA256      string     `json:"sha256,omitempty"`
+	// Capabilities is recorded from metadata when the plugin is installed,
+	// and refreshed by "sky plugin inspect".
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Git is set for plugins built from a git repository.
+	Git *GitSource `json:"git,omitempty"`
 }
 
 // Marketplace describes a plugin marketplace source.
@@ -41,4 +47,20 @@ type MarketplacePlugin struct {
 type SearchResult struct {
 	Marketplace Marketplace
 	Plugin      MarketplacePlugin
+	// Rank is how the query matched; lower ranks are more relevant.
+	Rank MatchRank
 }
+
+// MatchRank orders search results by relevance, most relevant first.
+type MatchRank int
+
+const (
+	// MatchExact means the plugin name equals the query.
+	MatchExact MatchRank = iota
+	// MatchPrefix means the plugin name starts with the query.
+	MatchPrefix
+	// MatchName means the plugin name contains the query.
+	MatchName
+	// MatchDescription means only the description contains the query.
+	MatchDescription
+)
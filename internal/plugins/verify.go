@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VerifyStatus is the outcome of verifying an installed plugin binary.
+type VerifyStatus string
+
+const (
+	// VerifyOK means the on-disk binary matches the recorded digest.
+	VerifyOK VerifyStatus = "ok"
+	// VerifyMismatch means the on-disk binary differs from the recorded digest.
+	VerifyMismatch VerifyStatus = "mismatch"
+	// VerifyMissing means the plugin binary no longer exists.
+	VerifyMissing VerifyStatus = "missing"
+	// VerifyUnrecorded means no digest was recorded at install time.
+	VerifyUnrecorded VerifyStatus = "unrecorded"
+)
+
+// VerifyResult describes the verification of a single plugin.
+type VerifyResult struct {
+	Plugin   Plugin       `json:"plugin"`
+	Status   VerifyStatus `json:"status"`
+	Expected string       `json:"expected,omitempty"`
+	Actual   string       `json:"actual,omitempty"`
+}
+
+// OK reports whether the plugin passed verification.
+func (r VerifyResult) OK() bool {
+	return r.Status == VerifyOK
+}
+
+// VerifyPlugin recomputes the sha256 of an installed plugin binary and
+// compares it with the digest recorded at install time.
+func (s *Store) VerifyPlugin(plugin Plugin) (VerifyResult, error) {
+	path := plugin.Path
+	if path == "" {
+		path = s.PluginPath(plugin.Name, plugin.EffectiveType())
+	}
+
+	result := VerifyResult{Plugin: plugin, Expected: plugin.SHA256}
+	actual, err := fileSHA256(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			result.Status = VerifyMissing
+			return result, nil
+		}
+		return VerifyResult{}, fmt.Errorf("verify %q: %w", plugin.Name, err)
+	}
+	result.Actual = actual
+
+	switch {
+	case plugin.SHA256 == "":
+		result.Status = VerifyUnrecorded
+	case strings.EqualFold(plugin.SHA256, actual):
+		result.Status = VerifyOK
+	default:
+		result.Status = VerifyMismatch
+	}
+	return result, nil
+}
+
+// VerifyPlugins verifies every installed plugin.
+func (s *Store) VerifyPlugins() ([]VerifyResult, error) {
+	plugins, err := s.LoadPlugins()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]VerifyResult, 0, len(plugins))
+	for _, plugin := range plugins {
+		result, err := s.VerifyPlugin(plugin)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyPlugin(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root)
+
+	src := filepath.Join(root, "plugin-bin")
+	if err := os.WriteFile(src, []byte("demo"), 0o755); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	plugin, err := store.InstallFromPath("demo", src, "1.0.0", TypeExecutable)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	// sha256("demo")
+	const want = "2a97516c354b68848cdbd8f54a226a0a55b21ed138e207ad6c5cbb9c00aa5aea"
+	if plugin.SHA256 != want {
+		t.Fatalf("expected recorded digest %s, got %s", want, plugin.SHA256)
+	}
+
+	result, err := store.VerifyPlugin(plugin)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !result.OK() {
+		t.Fatalf("expected ok, got %s", result.Status)
+	}
+
+	if err := os.WriteFile(plugin.Path, []byte("tampered"), 0o755); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	result, err = store.VerifyPlugin(plugin)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if result.Status != VerifyMismatch || result.Actual == want {
+		t.Fatalf("expected mismatch, got %+v", result)
+	}
+
+	if err := os.Remove(plugin.Path); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	result, err = store.VerifyPlugin(plugin)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if result.Status != VerifyMissing {
+		t.Fatalf("expected missing, got %s", result.Status)
+	}
+}
+
+func TestVerifyPlugins_RecordsURLDigest(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root)
+
+	src := filepath.Join(root, "plugin-bin")
+	if err := os.WriteFile(src, []byte("demo"), 0o755); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if _, err := store.InstallFromURL(context.Background(), "from-url", "file://"+src, "", "", "", TypeExecutable); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if err := store.UpsertPlugin(Plugin{Name: "legacy", Path: src}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	results, err := store.VerifyPlugins()
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	statuses := map[string]VerifyStatus{}
+	for _, result := range results {
+		statuses[result.Plugin.Name] = result.Status
+	}
+	if statuses["from-url"] != VerifyOK {
+		t.Fatalf("expected from-url ok, got %s", statuses["from-url"])
+	}
+	if statuses["legacy"] != VerifyUnrecorded {
+		t.Fatalf("expected legacy unrecorded, got %s", statuses["legacy"])
+	}
+}
@@ -9,16 +9,26 @@ import (
 var WorkspaceMarkers = []string{
 	".sky.yaml",
 	".sky.yml",
+	".sky",
+	"MODULE.bazel",
+	"WORKSPACE.bazel",
+	"WORKSPACE",
 	".git",
 }
 
 // FindWorkspaceRoot locates the workspace root by searching for marker files.
-// It searches upward from the current working directory for:
-//  1. .sky.yaml or .sky.yml (Sky config files)
-//  2. .git directory (version control root)
+// An explicit SKY_WORKSPACE_ROOT (e.g. from "sky --workspace DIR") takes
+// precedence. Otherwise it searches upward from the current working
+// directory; the nearest directory containing any of:
+//  1. .sky.yaml, .sky.yml or a .sky directory (Sky config)
+//  2. MODULE.bazel, WORKSPACE.bazel or WORKSPACE (Bazel workspace)
+//  3. .git directory (version control root)
 //
 // If no markers are found, it returns the current working directory.
 func FindWorkspaceRoot() string {
+	if root := os.Getenv(EnvWorkspaceRoot); root != "" {
+		return root
+	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		return ""
@@ -28,7 +38,15 @@ func FindWorkspaceRoot() string {
 
 // FindWorkspaceRootFrom locates the workspace root starting from the given directory.
 func FindWorkspaceRootFrom(startDir string) string {
-	dir := startDir
+	if root := NearestWorkspaceRoot(startDir); root != "" {
+		return root
+	}
+	return startDir
+}
+
+// NearestWorkspaceRoot returns the nearest ancestor of dir (inclusive) that
+// contains a workspace marker, or "" if there is none.
+func NearestWorkspaceRoot(dir string) string {
 	for {
 		for _, marker := range WorkspaceMarkers {
 			path := filepath.Join(dir, marker)
@@ -39,8 +57,8 @@ func FindWorkspaceRootFrom(startDir string) string {
 
 		parent := filepath.Dir(dir)
 		if parent == dir {
-			// Reached filesystem root, return start directory
-			return startDir
+			// Reached filesystem root
+			return ""
 		}
 		dir = parent
 	}
@@ -55,6 +55,42 @@ func TestFindWorkspaceRootFrom(t *testing.T) {
 			startSubdir:   "",
 			expectRelRoot: "",
 		},
+		{
+			name: "sky directory",
+			setup: func(dir string) {
+				_ = os.Mkdir(filepath.Join(dir, ".sky"), 0755)
+				_ = os.MkdirAll(filepath.Join(dir, "pkg"), 0755)
+			},
+			startSubdir:   "pkg",
+			expectRelRoot: "",
+		},
+		{
+			name: "bazel module file",
+			setup: func(dir string) {
+				_ = os.WriteFile(filepath.Join(dir, "MODULE.bazel"), []byte(""), 0644)
+				_ = os.MkdirAll(filepath.Join(dir, "a", "b"), 0755)
+			},
+			startSubdir:   "a/b",
+			expectRelRoot: "",
+		},
+		{
+			name: "bazel workspace file",
+			setup: func(dir string) {
+				_ = os.WriteFile(filepath.Join(dir, "WORKSPACE.bazel"), []byte(""), 0644)
+			},
+			startSubdir:   "",
+			expectRelRoot: "",
+		},
+		{
+			name: "nested bazel workspace inside git repo",
+			setup: func(dir string) {
+				_ = os.Mkdir(filepath.Join(dir, ".git"), 0755)
+				_ = os.MkdirAll(filepath.Join(dir, "third_party", "lib", "src"), 0755)
+				_ = os.WriteFile(filepath.Join(dir, "third_party", "lib", "WORKSPACE"), []byte(""), 0644)
+			},
+			startSubdir:   "third_party/lib/src",
+			expectRelRoot: "third_party/lib",
+		},
 		{
 			name: "no markers returns start dir",
 			setup: func(dir string) {
@@ -90,7 +126,28 @@ func TestFindWorkspaceRootFrom(t *testing.T) {
 	}
 }
 
+func TestNearestWorkspaceRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	start := filepath.Join(tmpDir, "a", "b")
+	_ = os.MkdirAll(start, 0755)
+
+	// Temp dirs normally sit outside any workspace; only then is "" defined.
+	if root := NearestWorkspaceRoot(tmpDir); root != "" {
+		t.Skipf("temp dir is inside workspace %s", root)
+	}
+	if got := NearestWorkspaceRoot(start); got != "" {
+		t.Errorf("NearestWorkspaceRoot(%q) = %q, want \"\"", start, got)
+	}
+
+	_ = os.WriteFile(filepath.Join(tmpDir, "a", "MODULE.bazel"), []byte(""), 0644)
+	if got, want := NearestWorkspaceRoot(start), filepath.Join(tmpDir, "a"); got != want {
+		t.Errorf("NearestWorkspaceRoot(%q) = %q, want %q", start, got, want)
+	}
+}
+
 func TestFindWorkspaceRoot(t *testing.T) {
+	t.Setenv(EnvWorkspaceRoot, "")
+
 	// Save and restore working directory
 	origWd, err := os.Getwd()
 	if err != nil {
@@ -117,3 +174,12 @@ func TestFindWorkspaceRoot(t *testing.T) {
 		t.Errorf("FindWorkspaceRoot() = %q, want %q", got, tmpDir)
 	}
 }
+
+func TestFindWorkspaceRoot_EnvOverride(t *testing.T) {
+	override := t.TempDir()
+	t.Setenv(EnvWorkspaceRoot, override)
+
+	if got := FindWorkspaceRoot(); got != override {
+		t.Errorf("FindWorkspaceRoot() = %q, want %q", got, override)
+	}
+}
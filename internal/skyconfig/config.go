@@ -66,6 +66,11 @@ type TestConfig struct {
 	// Verbose enables verbose output.
 	Verbose bool `json:"verbose" toml:"verbose"`
 
+	// EnvAllowlist lists environment variables, beyond the CI markers
+	// allowed by default, that tests may read through the env module when
+	// skytest runs with --allow-env.
+	EnvAllowlist []string `json:"env_allowlist" toml:"env_allowlist"`
+
 	// Coverage contains coverage configuration.
 	Coverage CoverageConfig `json:"coverage" toml:"coverage"`
 }
@@ -320,6 +325,9 @@ func (c *Config) Merge(other *Config) {
 	if other.Test.Verbose {
 		c.Test.Verbose = true
 	}
+	if len(other.Test.EnvAllowlist) > 0 {
+		c.Test.EnvAllowlist = append(c.Test.EnvAllowlist, other.Test.EnvAllowlist...)
+	}
 
 	// Merge coverage config
 	if other.Test.Coverage.Enabled {
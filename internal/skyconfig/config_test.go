@@ -146,10 +146,14 @@ def configure():
             "timeout": "90s",
             "parallel": "4",
             "prelude": ["helpers.star"],
+            "env_allowlist": ["DEPLOY_TOKEN"],
         },
     }
 `,
 			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Test.EnvAllowlist) != 1 || cfg.Test.EnvAllowlist[0] != "DEPLOY_TOKEN" {
+					t.Errorf("env_allowlist = %v, want [DEPLOY_TOKEN]", cfg.Test.EnvAllowlist)
+				}
 				if cfg.Test.Timeout.Duration != 90*time.Second {
 					t.Errorf("timeout = %v, want 90s", cfg.Test.Timeout.Duration)
 				}
@@ -240,6 +240,22 @@ func parseTestConfig(d *starlark.Dict, cfg *TestConfig) error {
 		cfg.Verbose = bool(b)
 	}
 
+	// env_allowlist
+	if v, found, _ := d.Get(starlark.String("env_allowlist")); found {
+		list, ok := v.(*starlark.List)
+		if !ok {
+			return fmt.Errorf("env_allowlist must be a list, got %s", v.Type())
+		}
+		cfg.EnvAllowlist = nil
+		for i := 0; i < list.Len(); i++ {
+			s, ok := starlark.AsString(list.Index(i))
+			if !ok {
+				return fmt.Errorf("env_allowlist[%d] must be a string", i)
+			}
+			cfg.EnvAllowlist = append(cfg.EnvAllowlist, s)
+		}
+	}
+
 	// coverage
 	if v, found, _ := d.Get(starlark.String("coverage")); found {
 		coverageDict, ok := v.(*starlark.Dict)
@@ -4,6 +4,9 @@ package loader
 import (
 	_ "embed"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -29,6 +32,11 @@ var bazelBzlPB []byte
 //go:embed data/proto/bazel_module.pb
 var bazelModulePB []byte
 
+// BuiltinsDirEnv names the environment variable that points
+// NewProtoProvider at a directory of proto data files overriding the
+// embedded ones.
+const BuiltinsDirEnv = "SKY_BUILTINS_DIR"
+
 // ProtoProvider loads builtin definitions from proto files.
 type ProtoProvider struct {
 	// mu protects the cache
@@ -39,6 +47,19 @@ type ProtoProvider struct {
 
 	// dataFS holds the proto data files (filesystem or mock in tests)
 	dataFS fsReader
+
+	// overrides, if set, holds data files that take precedence over dataFS
+	overrides fsReader
+}
+
+// dirFS reads data files by base name from a directory, so that
+// "data/proto/bazel_build.pb" is read from dir/bazel_build.pb.
+type dirFS struct {
+	dir string
+}
+
+func (d *dirFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(d.dir, path.Base(name)))
 }
 
 // embeddedProtoFS provides access to embedded proto files.
@@ -60,12 +81,26 @@ func (e *embeddedProtoFS) ReadFile(name string) ([]byte, error) {
 }
 
 // NewProtoProvider creates a new proto-based builtin provider.
-// Uses Go embed to load proto data files at compile time.
+// Uses Go embed to load proto data files at compile time, overridden by
+// the directory named by $SKY_BUILTINS_DIR, if set.
 func NewProtoProvider() *ProtoProvider {
-	return &ProtoProvider{
+	return NewProtoProviderWithDir(os.Getenv(BuiltinsDirEnv))
+}
+
+// NewProtoProviderWithDir creates a proto-based builtin provider whose data
+// files in dir, such as bazel_build.pb or bazel_build.pbtxt, replace the
+// embedded ones. Files missing from dir fall back to the embedded data, and
+// dir may add files for kinds that have no embedded data, such as
+// buck2_buck.pb. An empty dir uses the embedded data only.
+func NewProtoProviderWithDir(dir string) *ProtoProvider {
+	p := &ProtoProvider{
 		cache:  make(map[string]map[filekind.Kind]builtins.Builtins),
 		dataFS: &embeddedProtoFS{},
 	}
+	if dir != "" {
+		p.overrides = &dirFS{dir: dir}
+	}
+	return p
 }
 
 // newTestProtoProvider creates a proto provider for testing with injectable data.
@@ -186,20 +221,29 @@ func (p *ProtoProvider) protoFilename(dialect string, kind filekind.Kind) string
 	return fmt.Sprintf("data/proto/%s.pb", basename)
 }
 
-// loadProtoData loads proto data from the embedded filesystem.
+// loadProtoData loads proto data from the override directory, if any, and
+// then from the embedded filesystem. Either format in the override
+// directory takes precedence over the embedded data.
 func (p *ProtoProvider) loadProtoData(filename string) ([]byte, string, error) {
-	// Try to read the requested file from embedded FS
-	data, err := p.dataFS.ReadFile(filename)
-	if err == nil {
-		return data, filename, nil
+	readers := []fsReader{p.dataFS}
+	if p.overrides != nil {
+		readers = []fsReader{p.overrides, p.dataFS}
 	}
 
-	// Try alternative extension (.pbtxt instead of .pb)
-	if strings.HasSuffix(filename, ".pb") {
-		altFilename := strings.TrimSuffix(filename, ".pb") + ".pbtxt"
-		data, err = p.dataFS.ReadFile(altFilename)
+	for _, fs := range readers {
+		// Try to read the requested file
+		data, err := fs.ReadFile(filename)
 		if err == nil {
-			return data, altFilename, nil
+			return data, filename, nil
+		}
+
+		// Try alternative extension (.pbtxt instead of .pb)
+		if strings.HasSuffix(filename, ".pb") {
+			altFilename := strings.TrimSuffix(filename, ".pb") + ".pbtxt"
+			data, err = fs.ReadFile(altFilename)
+			if err == nil {
+				return data, altFilename, nil
+			}
 		}
 	}
 
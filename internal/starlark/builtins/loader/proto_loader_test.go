@@ -1,6 +1,8 @@
 package loader
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -27,6 +29,56 @@ func TestNewProtoProvider(t *testing.T) {
 	}
 }
 
+// TestNewProtoProviderWithDir verifies that data files in the override
+// directory replace or add to the embedded ones.
+func TestNewProtoProviderWithDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"bazel_build.pbtxt": `values { name: "company_library" callable { doc: "Internal rule." } }`,
+		"buck2_buck.pbtxt":  `values { name: "buck_rule" callable {} }`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := func(t *testing.T, provider *ProtoProvider) {
+		t.Helper()
+		build, err := provider.Builtins("bazel", filekind.KindBUILD)
+		if err != nil {
+			t.Fatalf("Builtins(bazel, BUILD) error = %v", err)
+		}
+		if len(build.Functions) != 1 || build.Functions[0].Name != "company_library" {
+			t.Errorf("BUILD functions = %+v, want only company_library", build.Functions)
+		}
+
+		buck, err := provider.Builtins("buck2", filekind.KindBUCK)
+		if err != nil {
+			t.Fatalf("Builtins(buck2, BUCK) error = %v", err)
+		}
+		if len(buck.Functions) != 1 || buck.Functions[0].Name != "buck_rule" {
+			t.Errorf("BUCK functions = %+v, want only buck_rule", buck.Functions)
+		}
+
+		// Files missing from the directory fall back to the embedded data
+		workspace, err := provider.Builtins("bazel", filekind.KindWORKSPACE)
+		if err != nil {
+			t.Fatalf("Builtins(bazel, WORKSPACE) error = %v", err)
+		}
+		if len(workspace.Functions) == 0 {
+			t.Error("expected embedded WORKSPACE builtins")
+		}
+	}
+
+	t.Run("dir", func(t *testing.T) {
+		check(t, NewProtoProviderWithDir(dir))
+	})
+	t.Run("env", func(t *testing.T) {
+		t.Setenv(BuiltinsDirEnv, dir)
+		check(t, NewProtoProvider())
+	})
+}
+
 // TestSupportedDialects verifies the list of supported dialects.
 func TestSupportedDialects(t *testing.T) {
 	provider := newTestProtoProvider()
@@ -2,6 +2,8 @@
 package builtins
 
 import (
+	"slices"
+
 	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 )
 
@@ -90,6 +92,36 @@ func (b *Builtins) Merge(other Builtins) {
 	b.Globals = append(b.Globals, other.Globals...)
 }
 
+// Overlay combines another Builtins into this one like Merge, except that
+// a function, type, or global in other replaces one of the same name in b
+// rather than being added next to it. A replaced type is replaced whole,
+// fields and methods included.
+func (b *Builtins) Overlay(other Builtins) {
+	b.Functions = overlayByName(b.Functions, other.Functions, func(s Signature) string { return s.Name })
+	b.Types = overlayByName(b.Types, other.Types, func(t TypeDef) string { return t.Name })
+	b.Globals = overlayByName(b.Globals, other.Globals, func(f Field) string { return f.Name })
+}
+
+// overlayByName replaces the items of base that share a name with an item of
+// top, keeping their position, and appends the rest of top.
+func overlayByName[T any](base, top []T, name func(T) string) []T {
+	// base may be shared with a provider's cache
+	base = slices.Clone(base)
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		index[name(item)] = i
+	}
+	for _, item := range top {
+		if i, ok := index[name(item)]; ok {
+			base[i] = item
+			continue
+		}
+		index[name(item)] = len(base)
+		base = append(base, item)
+	}
+	return base
+}
+
 // Provider supplies builtin definitions for dialects.
 type Provider interface {
 	// Builtins returns builtin definitions for a dialect and file kind.
@@ -138,9 +170,58 @@ func (c *ChainProvider) Builtins(dialect string, kind filekind.Kind) (Builtins,
 
 // SupportedDialects returns all dialects from all providers.
 func (c *ChainProvider) SupportedDialects() []string {
+	return supportedDialects(c.providers)
+}
+
+// MultiProvider layers an ordered list of providers, such as organization
+// builtins on top of the defaults. Unlike ChainProvider, which concatenates
+// its providers' builtins, a later provider overrides definitions of the
+// same name from earlier ones.
+type MultiProvider struct {
+	providers []Provider
+}
+
+// NewMultiProvider creates a provider that overlays the builtins of each
+// provider on those of the providers before it.
+func NewMultiProvider(providers ...Provider) *MultiProvider {
+	return &MultiProvider{providers: providers}
+}
+
+// Builtins overlays the builtins of all providers that support the dialect
+// and file kind. It returns the first provider's error only when no
+// provider supports them.
+func (m *MultiProvider) Builtins(dialect string, kind filekind.Kind) (Builtins, error) {
+	var result Builtins
+	var firstErr error
+	supported := false
+	for _, p := range m.providers {
+		b, err := p.Builtins(dialect, kind)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		supported = true
+		result.Overlay(b)
+	}
+	if !supported && firstErr != nil {
+		return Builtins{}, firstErr
+	}
+	return result, nil
+}
+
+// SupportedDialects returns all dialects from all providers.
+func (m *MultiProvider) SupportedDialects() []string {
+	return supportedDialects(m.providers)
+}
+
+// supportedDialects returns the dialects of providers in order, without
+// duplicates.
+func supportedDialects(providers []Provider) []string {
 	seen := make(map[string]bool)
 	var result []string
-	for _, p := range c.providers {
+	for _, p := range providers {
 		for _, d := range p.SupportedDialects() {
 			if !seen[d] {
 				seen[d] = true
@@ -0,0 +1,67 @@
+package builtins
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+func TestMultiProvider_Overlay(t *testing.T) {
+	defaults := ProviderFunc(func(dialect string, kind filekind.Kind) (Builtins, error) {
+		return Builtins{
+			Functions: []Signature{{Name: "cc_library", Doc: "default"}, {Name: "glob"}},
+			Types:     []TypeDef{{Name: "File", Doc: "default"}},
+			Globals:   []Field{{Name: "native"}},
+		}, nil
+	})
+	custom := ProviderFunc(func(dialect string, kind filekind.Kind) (Builtins, error) {
+		return Builtins{
+			Functions: []Signature{{Name: "company_library"}, {Name: "cc_library", Doc: "custom"}},
+			Types:     []TypeDef{{Name: "File", Doc: "custom"}},
+		}, nil
+	})
+	unsupported := ProviderFunc(func(dialect string, kind filekind.Kind) (Builtins, error) {
+		return Builtins{}, errors.New("unsupported")
+	})
+
+	result, err := NewMultiProvider(defaults, unsupported, custom).Builtins("bazel", filekind.KindBUILD)
+	if err != nil {
+		t.Fatalf("Builtins() error = %v", err)
+	}
+
+	var functions []string
+	for _, fn := range result.Functions {
+		functions = append(functions, fn.Name+":"+fn.Doc)
+	}
+	if want := []string{"cc_library:custom", "glob:", "company_library:"}; !slices.Equal(functions, want) {
+		t.Errorf("functions = %v, want %v", functions, want)
+	}
+	if len(result.Types) != 1 || result.Types[0].Doc != "custom" {
+		t.Errorf("types = %+v, want the custom File", result.Types)
+	}
+	if len(result.Globals) != 1 || result.Globals[0].Name != "native" {
+		t.Errorf("globals = %+v, want native", result.Globals)
+	}
+
+	// The layered results must not leak into the providers' own data
+	again, _ := defaults.Builtins("bazel", filekind.KindBUILD)
+	if again.Functions[0].Doc != "default" {
+		t.Errorf("default provider modified: %+v", again.Functions[0])
+	}
+}
+
+func TestMultiProvider_Unsupported(t *testing.T) {
+	errUnsupported := errors.New("unsupported")
+	unsupported := ProviderFunc(func(dialect string, kind filekind.Kind) (Builtins, error) {
+		return Builtins{}, errUnsupported
+	})
+
+	if _, err := NewMultiProvider(unsupported, unsupported).Builtins("tilt", filekind.KindStarlark); !errors.Is(err, errUnsupported) {
+		t.Errorf("Builtins() error = %v, want %v", err, errUnsupported)
+	}
+	if _, err := NewMultiProvider().Builtins("tilt", filekind.KindStarlark); err != nil {
+		t.Errorf("Builtins() with no providers error = %v", err)
+	}
+}
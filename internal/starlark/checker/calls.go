@@ -0,0 +1,247 @@
+package checker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.starlark.net/resolve"
+	"go.starlark.net/syntax"
+)
+
+// signature describes the parameters of a def, in the order Starlark binds
+// arguments to them.
+type signature struct {
+	name string
+
+	// positional are the parameters that can be passed by position, in
+	// order; required marks those without a default.
+	positional []param
+
+	// keywordOnly are the parameters after "*" or "*args".
+	keywordOnly []param
+
+	varargs string // name of *args, if any
+	kwargs  string // name of **kwargs, if any
+}
+
+type param struct {
+	name     string
+	required bool
+}
+
+func newSignature(def *syntax.DefStmt) *signature {
+	sig := &signature{name: def.Name.Name}
+	afterStar := false
+	for _, p := range def.Params {
+		switch p := p.(type) {
+		case *syntax.Ident:
+			sig.add(param{name: p.Name, required: true}, afterStar)
+		case *syntax.BinaryExpr: // name=default
+			if id, ok := p.X.(*syntax.Ident); ok {
+				sig.add(param{name: id.Name}, afterStar)
+			}
+		case *syntax.UnaryExpr:
+			id, _ := p.X.(*syntax.Ident)
+			if p.Op == syntax.STARSTAR {
+				if id != nil {
+					sig.kwargs = id.Name
+				}
+				continue
+			}
+			afterStar = true
+			if id != nil {
+				sig.varargs = id.Name
+			}
+		}
+	}
+	return sig
+}
+
+func (s *signature) add(p param, keywordOnly bool) {
+	if keywordOnly {
+		s.keywordOnly = append(s.keywordOnly, p)
+	} else {
+		s.positional = append(s.positional, p)
+	}
+}
+
+// String renders the signature as it would be written in the def, with
+// defaults elided, such as "f(a, b=..., *args, c, **kwargs)".
+func (s *signature) String() string {
+	var parts []string
+	render := func(p param) string {
+		if p.required {
+			return p.name
+		}
+		return p.name + "=..."
+	}
+	for _, p := range s.positional {
+		parts = append(parts, render(p))
+	}
+	switch {
+	case s.varargs != "":
+		parts = append(parts, "*"+s.varargs)
+	case len(s.keywordOnly) > 0:
+		parts = append(parts, "*")
+	}
+	for _, p := range s.keywordOnly {
+		parts = append(parts, render(p))
+	}
+	if s.kwargs != "" {
+		parts = append(parts, "**"+s.kwargs)
+	}
+	return s.name + "(" + strings.Join(parts, ", ") + ")"
+}
+
+// findCallArgErrors reports calls to functions defined in the same file
+// whose arguments do not fit the function's parameters: too many positional
+// arguments, missing required arguments, unknown keyword arguments, and
+// parameters given both by position and by keyword. Only calls through a
+// name bound by a def, and by nothing else, are checked, so that loaded,
+// predeclared, and reassigned functions, whose signatures are not known, are
+// never reported.
+func findCallArgErrors(f *syntax.File) []Diagnostic {
+	defs := make(map[*resolve.Binding]*syntax.DefStmt)
+	sites := make(map[*resolve.Binding]int)
+	count := func(id *syntax.Ident) {
+		if b, ok := id.Binding.(*resolve.Binding); ok {
+			sites[b]++
+		}
+	}
+	syntax.Walk(f, func(n syntax.Node) bool {
+		switch n := n.(type) {
+		case *syntax.DefStmt:
+			count(n.Name)
+			if b, ok := n.Name.Binding.(*resolve.Binding); ok {
+				defs[b] = n
+			}
+		case *syntax.AssignStmt:
+			bindTargets(n.LHS, count)
+		case *syntax.ForStmt:
+			bindTargets(n.Vars, count)
+		case *syntax.ForClause:
+			bindTargets(n.Vars, count)
+		case *syntax.LoadStmt:
+			for _, id := range n.To {
+				count(id)
+			}
+		}
+		return true
+	})
+
+	sigs := make(map[*syntax.DefStmt]*signature)
+	var diagnostics []Diagnostic
+	syntax.Walk(f, func(n syntax.Node) bool {
+		call, ok := n.(*syntax.CallExpr)
+		if !ok {
+			return true
+		}
+		fn, ok := call.Fn.(*syntax.Ident)
+		if !ok {
+			return true
+		}
+		b, ok := fn.Binding.(*resolve.Binding)
+		if !ok || sites[b] != 1 {
+			return true
+		}
+		def, ok := defs[b]
+		if !ok {
+			return true
+		}
+		sig, ok := sigs[def]
+		if !ok {
+			sig = newSignature(def)
+			sigs[def] = sig
+		}
+		diagnostics = append(diagnostics, checkCallArgs(call, sig)...)
+		return true
+	})
+	return diagnostics
+}
+
+// checkCallArgs matches the arguments of call against sig the way Starlark
+// binds them at run time. When the call spreads *args or **kwargs, the
+// arguments they supply are unknown, so only what is spelled out is checked.
+func checkCallArgs(call *syntax.CallExpr, sig *signature) []Diagnostic {
+	var diagnostics []Diagnostic
+	report := func(node syntax.Node, format string, args ...any) {
+		start, end := node.Span()
+		diagnostics = append(diagnostics, Diagnostic{
+			Pos:      start,
+			End:      end,
+			Severity: SeverityError,
+			Code:     "call-args",
+			Message:  fmt.Sprintf(format, args...) + "; signature is " + sig.String(),
+		})
+	}
+
+	var positional []syntax.Expr
+	var keywords []*syntax.Ident
+	spread := false
+	for _, arg := range call.Args {
+		switch arg := arg.(type) {
+		case *syntax.BinaryExpr:
+			if id, ok := arg.X.(*syntax.Ident); ok && arg.Op == syntax.EQ {
+				keywords = append(keywords, id)
+				continue
+			}
+			positional = append(positional, arg)
+		case *syntax.UnaryExpr:
+			if arg.Op == syntax.STAR || arg.Op == syntax.STARSTAR {
+				spread = true
+				continue
+			}
+			positional = append(positional, arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) > len(sig.positional) && sig.varargs == "" {
+		report(positional[len(sig.positional)], "%s() takes %s but %d were given",
+			sig.name, plural(len(sig.positional), "positional argument"), len(positional))
+	}
+
+	// Repeated keywords are left to the resolver, which rejects them.
+	given := make(map[string]bool)
+	byPosition := make(map[string]bool)
+	for i := 0; i < len(positional) && i < len(sig.positional); i++ {
+		byPosition[sig.positional[i].name] = true
+		given[sig.positional[i].name] = true
+	}
+	for _, kw := range keywords {
+		known := slices.ContainsFunc(sig.positional, func(p param) bool { return p.name == kw.Name }) ||
+			slices.ContainsFunc(sig.keywordOnly, func(p param) bool { return p.name == kw.Name })
+		switch {
+		case !known && sig.kwargs == "":
+			report(kw, "%s() got an unexpected keyword argument %q", sig.name, kw.Name)
+		case known && byPosition[kw.Name]:
+			report(kw, "%s() got multiple values for parameter %q", sig.name, kw.Name)
+		}
+		given[kw.Name] = true
+	}
+
+	if spread {
+		return diagnostics
+	}
+	var missing []string
+	for _, p := range slices.Concat(sig.positional, sig.keywordOnly) {
+		if p.required && !given[p.name] {
+			missing = append(missing, fmt.Sprintf("%q", p.name))
+		}
+	}
+	if len(missing) > 0 {
+		report(call, "%s() missing %s: %s", sig.name,
+			plural(len(missing), "required argument"), strings.Join(missing, ", "))
+	}
+	return diagnostics
+}
+
+// plural formats n and noun, adding "s" unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -4,14 +4,18 @@
 // uses starlark-go's resolver for proper semantic analysis including:
 //   - Undefined name detection
 //   - Unused binding detection
+//   - Redefinition detection
+//   - Argument checking for calls to functions defined in the same file
 //   - Scope analysis
 //   - (Future) Type checking
 package checker
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/albertocavalcante/sky/internal/starlark/sortutil"
+	"github.com/albertocavalcante/sky/internal/starlark/validator"
 	"go.starlark.net/resolve"
 	"go.starlark.net/syntax"
 )
@@ -21,7 +25,9 @@ type Diagnostic struct {
 	// Pos is the position of the issue in the source file.
 	Pos syntax.Position
 
-	// End is the end position (if known), otherwise equals Pos.
+	// End is the end position of the offending node, such as the whole
+	// identifier for undefined and unused names. It is the zero Position
+	// when unknown.
 	End syntax.Position
 
 	// Severity indicates the severity of the issue.
@@ -34,6 +40,25 @@ type Diagnostic struct {
 	Message string
 }
 
+// ToDiagnostic converts a Diagnostic to a validator.Diagnostic, the
+// representation it shares with linter findings.
+func (d Diagnostic) ToDiagnostic(filePath string) validator.Diagnostic {
+	vd := validator.Diagnostic{
+		Severity: d.Severity.toValidator(),
+		Message:  d.Message,
+		File:     filePath,
+		Line:     int(d.Pos.Line),
+		Column:   int(d.Pos.Col),
+		Code:     d.Code,
+		Source:   "skycheck",
+	}
+	if d.End.Line > 0 {
+		vd.EndLine = int(d.End.Line)
+		vd.EndColumn = int(d.End.Col)
+	}
+	return vd
+}
+
 // Severity indicates the severity of a diagnostic.
 type Severity int
 
@@ -43,6 +68,18 @@ const (
 	SeverityInfo
 )
 
+// toValidator maps s to the validator severity of the same name.
+func (s Severity) toValidator() validator.Severity {
+	switch s {
+	case SeverityError:
+		return validator.SeverityError
+	case SeverityInfo:
+		return validator.SeverityInfo
+	default:
+		return validator.SeverityWarning
+	}
+}
+
 func (s Severity) String() string {
 	switch s {
 	case SeverityError:
@@ -109,14 +146,29 @@ type Options struct {
 
 	// ReportUnused enables reporting of unused bindings.
 	ReportUnused bool
+
+	// ReportRedefinitions enables reporting of module-level names that are
+	// bound more than once.
+	ReportRedefinitions bool
+
+	// ReportCallArgs enables checking the arguments of calls to functions
+	// defined in the same file against their parameters.
+	ReportCallArgs bool
+
+	// SkipGenerated skips files marked as generated (see IsGenerated), whose
+	// unused and undefined names are not for a human to fix.
+	SkipGenerated bool
 }
 
 // DefaultOptions returns sensible default options.
 func DefaultOptions() Options {
 	return Options{
-		Predeclared:  make(map[string]bool),
-		Universal:    defaultUniversal(),
-		ReportUnused: true,
+		Predeclared:         make(map[string]bool),
+		Universal:           defaultUniversal(),
+		ReportUnused:        true,
+		ReportRedefinitions: true,
+		ReportCallArgs:      true,
+		SkipGenerated:       true,
 	}
 }
 
@@ -172,6 +224,10 @@ func New(opts Options) *Checker {
 
 // CheckFile checks a single file and returns diagnostics.
 func (c *Checker) CheckFile(filename string, src []byte) ([]Diagnostic, error) {
+	if c.opts.SkipGenerated && IsGenerated(src) {
+		return nil, nil
+	}
+
 	// Parse the file
 	f, err := syntax.Parse(filename, src, syntax.RetainComments)
 	if err != nil {
@@ -194,18 +250,39 @@ func (c *Checker) CheckFile(filename string, src []byte) ([]Diagnostic, error) {
 func (c *Checker) checkParsedFile(f *syntax.File) ([]Diagnostic, error) {
 	var diagnostics []Diagnostic
 
+	// Find redefinitions first: the resolver rejects most of them too, and
+	// those are reported once, as redefinition warnings
+	var redefinitions []Diagnostic
+	redefined := make(map[syntax.Position]bool)
+	if c.opts.ReportRedefinitions {
+		redefinitions = findRedefinitions(f)
+		for _, d := range redefinitions {
+			redefined[d.Pos] = true
+		}
+	}
+
 	// Run name resolution
 	isPredeclared := func(name string) bool { return c.opts.Predeclared[name] }
 	isUniversal := func(name string) bool { return c.opts.Universal[name] }
 
 	if err := resolve.File(f, isPredeclared, isUniversal); err != nil {
-		// Resolution errors indicate undefined names
+		// Resolution errors indicate undefined names, or top-level names
+		// that Starlark forbids rebinding
 		if errList, ok := err.(resolve.ErrorList); ok {
+			identEnds := identEndsByPos(f)
 			for _, e := range errList {
+				code := "undefined"
+				if strings.HasPrefix(e.Msg, "cannot reassign") {
+					if redefined[e.Pos] {
+						continue
+					}
+					code = "redefinition"
+				}
 				diagnostics = append(diagnostics, Diagnostic{
 					Pos:      e.Pos,
+					End:      identEnds[e.Pos],
 					Severity: SeverityError,
-					Code:     "undefined",
+					Code:     code,
 					Message:  e.Msg,
 				})
 			}
@@ -220,6 +297,13 @@ func (c *Checker) checkParsedFile(f *syntax.File) ([]Diagnostic, error) {
 		diagnostics = append(diagnostics, unused...)
 	}
 
+	diagnostics = append(diagnostics, redefinitions...)
+
+	// Check call arguments against same-file signatures
+	if c.opts.ReportCallArgs {
+		diagnostics = append(diagnostics, findCallArgErrors(f)...)
+	}
+
 	// Sort diagnostics by position
 	sortutil.ByLineColumn(diagnostics,
 		func(d Diagnostic) int { return int(d.Pos.Line) },
@@ -286,8 +370,10 @@ func (c *Checker) findUnusedBindings(f *syntax.File) []Diagnostic {
 	// Report unused bindings
 	for ident, used := range bindings {
 		if !used && !isUnderscore(ident.Name) {
+			_, end := ident.Span()
 			diagnostics = append(diagnostics, Diagnostic{
 				Pos:      ident.NamePos,
+				End:      end,
 				Severity: SeverityWarning,
 				Code:     "unused",
 				Message:  fmt.Sprintf("local variable %q is assigned but never used", ident.Name),
@@ -298,6 +384,117 @@ func (c *Checker) findUnusedBindings(f *syntax.File) []Diagnostic {
 	return diagnostics
 }
 
+// findRedefinitions reports defs, assignments, and loads that bind a
+// module-level name already bound earlier in the file. They are reported as
+// warnings, pointing to the previous definition, because they are usually
+// copy-paste mistakes, whether or not the file options allow rebinding.
+func findRedefinitions(f *syntax.File) []Diagnostic {
+	var diagnostics []Diagnostic
+	first := make(map[string]*syntax.Ident)
+
+	bind := func(id *syntax.Ident) {
+		prev, ok := first[id.Name]
+		if !ok {
+			first[id.Name] = id
+			return
+		}
+		_, end := id.Span()
+		diagnostics = append(diagnostics, Diagnostic{
+			Pos:      id.NamePos,
+			End:      end,
+			Severity: SeverityWarning,
+			Code:     "redefinition",
+			Message:  fmt.Sprintf("%q redefined; previously defined at line %d", id.Name, prev.NamePos.Line),
+		})
+	}
+
+	// Top-level if and for statements, where allowed, bind in module scope.
+	var stmts func([]syntax.Stmt)
+	stmts = func(list []syntax.Stmt) {
+		for _, stmt := range list {
+			switch stmt := stmt.(type) {
+			case *syntax.DefStmt:
+				bind(stmt.Name)
+			case *syntax.AssignStmt:
+				if stmt.Op == syntax.EQ {
+					bindTargets(stmt.LHS, bind)
+				}
+			case *syntax.LoadStmt:
+				for _, id := range stmt.To {
+					bind(id)
+				}
+			case *syntax.IfStmt:
+				stmts(stmt.True)
+				stmts(stmt.False)
+			case *syntax.ForStmt:
+				bindTargets(stmt.Vars, bind)
+				stmts(stmt.Body)
+			}
+		}
+	}
+	stmts(f.Stmts)
+
+	return diagnostics
+}
+
+// bindTargets calls bind for each identifier bound by the assignment target
+// e, such as both names in "a, b = ...". Targets like x[i] and x.f bind no
+// names.
+func bindTargets(e syntax.Expr, bind func(*syntax.Ident)) {
+	switch e := e.(type) {
+	case *syntax.Ident:
+		bind(e)
+	case *syntax.TupleExpr:
+		for _, elem := range e.List {
+			bindTargets(elem, bind)
+		}
+	case *syntax.ListExpr:
+		for _, elem := range e.List {
+			bindTargets(elem, bind)
+		}
+	case *syntax.ParenExpr:
+		bindTargets(e.X, bind)
+	}
+}
+
+// generatedMarker marks a file as generated when it appears in a comment in
+// the file's header. It is the convention of many code generators and code
+// review tools.
+const generatedMarker = "@generated"
+
+// IsGenerated reports whether src is a generated file: one whose leading
+// comments, before the first line of code, contain "@generated".
+func IsGenerated(src []byte) bool {
+	for line := range strings.Lines(string(src)) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			return false
+		}
+		if strings.Contains(line, generatedMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// identEndsByPos maps the start of each identifier in f to its end, so that
+// resolver errors, which carry only a start position, can span the name they
+// report.
+func identEndsByPos(f *syntax.File) map[syntax.Position]syntax.Position {
+	ends := make(map[syntax.Position]syntax.Position)
+	syntax.Walk(f, func(n syntax.Node) bool {
+		if ident, ok := n.(*syntax.Ident); ok {
+			start, end := ident.Span()
+			ends[start] = end
+		}
+		return true
+	})
+	return ends
+}
+
 // isUnderscore returns true if the name is "_" or starts with "_" (convention for unused).
 func isUnderscore(name string) bool {
 	return name == "_" || (len(name) > 1 && name[0] == '_')
@@ -3,6 +3,10 @@ package checker
 import (
 	"strings"
 	"testing"
+
+	"go.starlark.net/syntax"
+
+	"github.com/albertocavalcante/sky/internal/starlark/validator"
 )
 
 func TestChecker_UndefinedName(t *testing.T) {
@@ -216,3 +220,310 @@ func TestResult_Counts(t *testing.T) {
 		t.Errorf("WarningCount() = %d, want 1", got)
 	}
 }
+
+func TestChecker_DiagnosticEnd(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		code      string
+		line      int32
+		col       int32
+		endColumn int32
+	}{
+		{
+			name:      "undefined spans the reference",
+			src:       "def foo():\n    return undefined_name\n",
+			code:      "undefined",
+			line:      2,
+			col:       12,
+			endColumn: 26,
+		},
+		{
+			name:      "unused spans the identifier",
+			src:       "def foo():\n    counter = 1\n    return 42\n",
+			code:      "unused",
+			line:      2,
+			col:       5,
+			endColumn: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags, err := New(DefaultOptions()).CheckFile("test.star", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("CheckFile failed: %v", err)
+			}
+			if len(diags) != 1 || diags[0].Code != tt.code {
+				t.Fatalf("expected one %q diagnostic, got: %v", tt.code, diags)
+			}
+			d := diags[0]
+			if d.Pos.Line != tt.line || d.Pos.Col != tt.col {
+				t.Errorf("Pos = %d:%d, want %d:%d", d.Pos.Line, d.Pos.Col, tt.line, tt.col)
+			}
+			if d.End.Line != tt.line || d.End.Col != tt.endColumn {
+				t.Errorf("End = %d:%d, want %d:%d", d.End.Line, d.End.Col, tt.line, tt.endColumn)
+			}
+		})
+	}
+}
+
+func TestChecker_Redefinition(t *testing.T) {
+	src := `
+def helper():
+    pass
+
+def helper():
+    pass
+`
+	diags, err := New(DefaultOptions()).CheckFile("test.star", []byte(src))
+	if err != nil {
+		t.Fatalf("CheckFile failed: %v", err)
+	}
+	// The resolver rejects the rebinding too; it is reported once, as a
+	// warning that points to the previous definition.
+	if len(diags) != 1 {
+		t.Fatalf("expected one diagnostic, got: %v", diags)
+	}
+	d := diags[0]
+	if d.Code != "redefinition" || d.Severity != SeverityWarning || d.Pos.Line != 5 {
+		t.Errorf("expected redefinition warning on line 5, got: %v", d)
+	}
+	if want := `"helper" redefined; previously defined at line 2`; d.Message != want {
+		t.Errorf("message = %q, want %q", d.Message, want)
+	}
+
+	// Rebindings the pass does not cover keep the resolver's error.
+	diags, err = New(DefaultOptions()).CheckFile("test.star", []byte("x = [1]\nx += [2]\n"))
+	if err != nil {
+		t.Fatalf("CheckFile failed: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Code != "redefinition" || diags[0].Severity != SeverityError {
+		t.Errorf("expected a redefinition error for x +=, got: %v", diags)
+	}
+}
+
+func TestChecker_RedefinitionAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		messages []string
+	}{
+		{
+			name:     "def",
+			src:      "def f():\n    pass\n\ndef f():\n    pass\n",
+			messages: []string{`"f" redefined; previously defined at line 1`},
+		},
+		{
+			name:     "assignment after def",
+			src:      "def f():\n    pass\n\nf = 1\n",
+			messages: []string{`"f" redefined; previously defined at line 1`},
+		},
+		{
+			name:     "tuple assignment",
+			src:      "a = 1\nb, (c, a) = 2, (3, 4)\n",
+			messages: []string{`"a" redefined; previously defined at line 1`},
+		},
+		{
+			name:     "load",
+			src:      "x = 1\nload(\"//a.bzl\", \"x\")\n",
+			messages: []string{`"x" redefined; previously defined at line 1`},
+		},
+		{
+			name: "augmented assignment",
+			src:  "x = [1]\nx += [2]\n",
+		},
+		{
+			name: "local shadowing",
+			src:  "x = 1\n\ndef f():\n    x = 2\n    return x\n",
+		},
+	}
+
+	// Parse with options that allow rebinding, as some dialects do, so that
+	// the resolver accepts the file and the checker reports the rebinding.
+	opts := &syntax.FileOptions{GlobalReassign: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := opts.Parse("test.star", tt.src, 0)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			diags, err := New(DefaultOptions()).checkParsedFile(f)
+			if err != nil {
+				t.Fatalf("checkParsedFile failed: %v", err)
+			}
+
+			var messages []string
+			for _, d := range diags {
+				if d.Code != "redefinition" {
+					continue
+				}
+				if d.Severity != SeverityWarning {
+					t.Errorf("severity = %v, want warning", d.Severity)
+				}
+				messages = append(messages, d.Message)
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.messages, "\n") {
+				t.Errorf("messages = %q, want %q", messages, tt.messages)
+			}
+		})
+	}
+}
+
+func TestChecker_CallArgs(t *testing.T) {
+	const defs = `
+def f(a, b, c=None):
+    return a
+
+def g(a, *, key, opt=1):
+    return a
+
+def h(*args, **kwargs):
+    return args
+`
+	tests := []struct {
+		name     string
+		call     string
+		messages []string
+	}{
+		{name: "exact", call: "f(1, 2)"},
+		{name: "keywords", call: "f(1, c=3, b=2)"},
+		{name: "variadic", call: "h(1, 2, 3, x=4)"},
+		{name: "keyword-only", call: "g(1, key=2)"},
+		{name: "spread", call: "f(*[1, 2, 3])"},
+		{name: "spread still checks keywords", call: "f(1, **{}, d=4)", messages: []string{
+			`f() got an unexpected keyword argument "d"; signature is f(a, b, c=...)`,
+		}},
+		{name: "too many positional", call: "f(1, 2, 3, 4)", messages: []string{
+			"f() takes 3 positional arguments but 4 were given; signature is f(a, b, c=...)",
+		}},
+		{name: "too many before keyword-only", call: "g(1, 2, key=3)", messages: []string{
+			"g() takes 1 positional argument but 2 were given; signature is g(a, *, key, opt=...)",
+		}},
+		{name: "missing", call: "f(1)", messages: []string{
+			`f() missing 1 required argument: "b"; signature is f(a, b, c=...)`,
+		}},
+		{name: "missing keyword-only", call: "g(a=1)", messages: []string{
+			`g() missing 1 required argument: "key"; signature is g(a, *, key, opt=...)`,
+		}},
+		{name: "unknown keyword", call: "f(1, 2, d=4)", messages: []string{
+			`f() got an unexpected keyword argument "d"; signature is f(a, b, c=...)`,
+		}},
+		{name: "multiple values", call: "f(1, 2, a=3)", messages: []string{
+			`f() got multiple values for parameter "a"; signature is f(a, b, c=...)`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags, err := New(DefaultOptions()).CheckFile("test.star", []byte(defs+"\nx = "+tt.call+"\n"))
+			if err != nil {
+				t.Fatalf("CheckFile failed: %v", err)
+			}
+			var got []string
+			for _, d := range diags {
+				if d.Code != "call-args" {
+					continue
+				}
+				if d.Severity != SeverityError {
+					t.Errorf("expected an error, got %v", d.Severity)
+				}
+				got = append(got, d.Message)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.messages, "\n") {
+				t.Errorf("messages = %q, want %q", got, tt.messages)
+			}
+		})
+	}
+}
+
+func TestChecker_CallArgsUnknownSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "loaded", src: "load(\"//a.bzl\", \"f\")\n\nf(1, 2, 3)\n"},
+		{name: "builtin", src: "x = len(1, 2)\n"},
+		{name: "reassigned local", src: "def f(a):\n    pass\n\ndef g(other):\n    h = f\n    h = other\n    h(1, 2)\n"},
+		{name: "shadowed by parameter", src: "def f(a):\n    pass\n\ndef g(f):\n    f(1, 2)\n"},
+		{name: "conditional def", src: "def g():\n    if True:\n        def f(a):\n            pass\n    else:\n        def f(a, b):\n            pass\n    f(1, 2)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags, err := New(DefaultOptions()).CheckFile("test.star", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("CheckFile failed: %v", err)
+			}
+			for _, d := range diags {
+				if d.Code == "call-args" {
+					t.Errorf("unexpected call-args diagnostic: %v", d)
+				}
+			}
+		})
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{name: "marker", src: "# @generated by protoc-gen-starlark\nx = undefined\n", want: true},
+		{name: "after license header", src: "# Copyright 2026\n#\n\n# @generated\nx = 1\n", want: true},
+		{name: "no marker", src: "# Hand-written.\nx = 1\n", want: false},
+		{name: "marker after code", src: "x = 1\n# @generated\n", want: false},
+		{name: "marker in string", src: "doc = \"@generated\"\n", want: false},
+		{name: "empty", src: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsGenerated([]byte(tt.src)); got != tt.want {
+				t.Errorf("IsGenerated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker_SkipGenerated(t *testing.T) {
+	src := []byte("# @generated\n\ndef f():\n    unused = 1\n    return undefined_name\n")
+
+	diags, err := New(DefaultOptions()).CheckFile("gen.star", src)
+	if err != nil {
+		t.Fatalf("CheckFile failed: %v", err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("expected a generated file to be skipped, got: %v", diags)
+	}
+
+	opts := DefaultOptions()
+	opts.SkipGenerated = false
+	diags, err = New(opts).CheckFile("gen.star", src)
+	if err != nil {
+		t.Fatalf("CheckFile failed: %v", err)
+	}
+	if len(diags) != 2 {
+		t.Errorf("expected the unused and undefined names with SkipGenerated off, got: %v", diags)
+	}
+}
+
+func TestDiagnostic_ToDiagnostic(t *testing.T) {
+	d := Diagnostic{
+		Pos:      syntax.MakePosition(nil, 3, 5),
+		End:      syntax.MakePosition(nil, 3, 8),
+		Severity: SeverityInfo,
+		Code:     "unused",
+		Message:  "unused variable: foo",
+	}
+	got := d.ToDiagnostic("test.star")
+	if got.File != "test.star" || got.Line != 3 || got.Column != 5 || got.EndLine != 3 || got.EndColumn != 8 ||
+		got.Severity != validator.SeverityInfo || got.Code != "unused" || got.Source != "skycheck" || got.Message != d.Message {
+		t.Errorf("ToDiagnostic() = %+v", got)
+	}
+
+	// An unknown end leaves the end of the validator diagnostic unset.
+	d.End = syntax.Position{}
+	if got := d.ToDiagnostic("test.star"); got.EndLine != 0 || got.EndColumn != 0 {
+		t.Errorf("ToDiagnostic() without end = %+v", got)
+	}
+}
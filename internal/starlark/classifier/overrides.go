@@ -0,0 +1,354 @@
+package classifier
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+// OverridesFileName is the name of the file that maps glob patterns to
+// file kinds for a directory tree.
+//
+// Each non-blank, non-comment line has the form:
+//
+//	<pattern> <kind> [dialect]
+//
+// for example:
+//
+//	# Templates that are really Starlark
+//	*.tpl        bzl
+//	defs/**      bzl
+//	ci/*.cfg     starlark
+//
+// Patterns use '/' separators and are relative to the directory holding the
+// file. A pattern without '/' matches the base name at any depth, and "**"
+// matches any number of directories. The first matching line wins. Kinds are
+// filekind names (case-insensitive); the dialect defaults to "bazel",
+// "buck2" or "starlark" based on the kind.
+const OverridesFileName = ".skyfiletypes"
+
+// errNoOverride is returned by OverrideClassifier when no pattern matches,
+// letting a ChainClassifier fall through to builtin detection.
+var errNoOverride = errors.New("no file type override")
+
+// Override maps a glob pattern to a classification.
+type Override struct {
+	// Pattern is the glob pattern, relative to the overrides file.
+	Pattern string
+
+	// FileKind is the kind assigned to matching files.
+	FileKind filekind.Kind
+
+	// Dialect is the dialect assigned to matching files.
+	Dialect string
+}
+
+// OverridesError describes an overrides file that cannot be parsed. Such a
+// file is ignored, so the files below it are classified by name.
+type OverridesError struct {
+	// Path is the path to the overrides file, if known.
+	Path string
+
+	// Line is the 1-based line of the error, or 0 if it is not about a line.
+	Line int
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *OverridesError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		b.WriteString(e.Path + ": ")
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", e.Line)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *OverridesError) Unwrap() error { return e.Err }
+
+// Overrides is a parsed .skyfiletypes file.
+type Overrides struct {
+	// Path is the path to the overrides file.
+	Path string
+
+	// Rules are the overrides in file order.
+	Rules []Override
+}
+
+// New returns the classifier shared by Sky's tools: .skyfiletypes
+// overrides first, then DefaultClassifier's name-based detection.
+// Callers with an explicit kind (e.g. skyfmt --type) should skip
+// classification entirely.
+func New() Classifier {
+	return NewChainClassifier(NewOverrideClassifier(), NewDefaultClassifier())
+}
+
+// ParseOverrides parses the contents of a .skyfiletypes file.
+func ParseOverrides(r io.Reader) ([]Override, error) {
+	var rules []Override
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, &OverridesError{Line: lineNo, Err: errors.New(`expected "<pattern> <kind> [dialect]"`)}
+		}
+		pattern := strings.TrimPrefix(fields[0], "/")
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return nil, &OverridesError{Line: lineNo, Err: fmt.Errorf("invalid pattern %q: %w", fields[0], err)}
+		}
+		kind, ok := filekind.Parse(fields[1])
+		if !ok {
+			return nil, &OverridesError{Line: lineNo, Err: fmt.Errorf("unknown file kind %q", fields[1])}
+		}
+		dialect := dialectForKind(kind)
+		if len(fields) == 3 {
+			dialect = fields[2]
+		}
+
+		rules = append(rules, Override{Pattern: pattern, FileKind: kind, Dialect: dialect})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &OverridesError{Err: err}
+	}
+	return rules, nil
+}
+
+// LoadOverrides reads and parses the overrides file at path. Parse errors
+// are *OverridesError.
+func LoadOverrides(path string) (*Overrides, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := ParseOverrides(f)
+	if err != nil {
+		var oe *OverridesError
+		if errors.As(err, &oe) {
+			oe.Path = path
+		}
+		return nil, err
+	}
+	return &Overrides{Path: path, Rules: rules}, nil
+}
+
+// Match returns the first override whose pattern matches path. The path
+// must be absolute or relative to the current directory.
+func (o *Overrides) Match(path string) (Override, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Override{}, false
+	}
+	rel, err := filepath.Rel(filepath.Dir(o.Path), abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return Override{}, false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, rule := range o.Rules {
+		if matchOverridePattern(rule.Pattern, rel) {
+			return rule, true
+		}
+	}
+	return Override{}, false
+}
+
+// OverrideClassifier classifies files using the nearest .skyfiletypes
+// file found by walking up from the file's directory. Files that no pattern
+// matches, or whose overrides file is missing or invalid, return an error
+// so that a ChainClassifier falls through to the next classifier.
+//
+// Parsed files are cached and reloaded when their modification time or
+// size changes, so a long-running process such as skyls sees edits, and
+// overrides files that are created or deleted, without restarting.
+type OverrideClassifier struct {
+	mu sync.Mutex
+	// files caches parsed overrides by file path.
+	files map[string]cachedOverrides
+}
+
+// cachedOverrides is a parsed overrides file and the stat it was read at.
+type cachedOverrides struct {
+	overrides *Overrides // nil if the file is invalid
+	err       error      // why the file is invalid
+	modTime   time.Time
+	size      int64
+}
+
+// NewOverrideClassifier creates a classifier for .skyfiletypes overrides.
+func NewOverrideClassifier() *OverrideClassifier {
+	return &OverrideClassifier{files: make(map[string]cachedOverrides)}
+}
+
+// Classify implements the Classifier interface.
+func (c *OverrideClassifier) Classify(path string) (Classification, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Classification{}, err
+	}
+	_, overrides, _ := c.nearest(filepath.Dir(abs))
+	if overrides == nil {
+		return Classification{}, errNoOverride
+	}
+	rule, ok := overrides.Match(abs)
+	if !ok {
+		return Classification{}, errNoOverride
+	}
+	return Classification{
+		Dialect:    rule.Dialect,
+		FileKind:   rule.FileKind,
+		ConfigPath: overrides.Path,
+	}, nil
+}
+
+// SupportsDialect returns true for any dialect, since overrides may name
+// any dialect.
+func (c *OverrideClassifier) SupportsDialect(dialect string) bool {
+	return true
+}
+
+// nearest returns the path of the overrides file closest to dir and its
+// parsed contents, or the error it could not be loaded with. The path is ""
+// if there is no overrides file.
+func (c *OverrideClassifier) nearest(dir string) (string, *Overrides, error) {
+	for {
+		candidate := filepath.Join(dir, OverridesFileName)
+		if info, err := os.Stat(candidate); err == nil {
+			// An invalid file disables overrides below it rather than
+			// silently applying a parent's rules.
+			overrides, err := c.load(candidate, info)
+			return candidate, overrides, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil, nil
+		}
+		dir = parent
+	}
+}
+
+// load returns the parsed overrides file at path, reusing the cached parse
+// if the file has not changed since.
+func (c *OverrideClassifier) load(path string, info os.FileInfo) (*Overrides, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.files[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.overrides, cached.err
+	}
+	overrides, err := LoadOverrides(path)
+	c.files[path] = cachedOverrides{overrides: overrides, err: err, modTime: info.ModTime(), size: info.Size()}
+	return overrides, err
+}
+
+// defaultOverrides backs IsStarlarkPath and OverridesFor.
+var defaultOverrides = NewOverrideClassifier()
+
+// OverridesFor returns the path of the overrides file that applies to the
+// file at path, or "" if there is none. If that file is invalid, and so
+// ignored, it also returns the reason.
+func OverridesFor(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil
+	}
+	file, _, err := defaultOverrides.nearest(filepath.Dir(abs))
+	return file, err
+}
+
+// InvalidOverrides returns the errors of the invalid overrides files that
+// apply to files, one per overrides file, so that tools can warn that those
+// files are ignored.
+func InvalidOverrides(files []string) []error {
+	dirs := make(map[string]bool)
+	seen := make(map[string]bool)
+	var errs []error
+	for _, path := range files {
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		file, err := OverridesFor(path)
+		if err == nil || seen[file] {
+			continue
+		}
+		seen[file] = true
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// IsStarlarkPath reports whether path is a Starlark file, either by its
+// builtin name or extension or because a .skyfiletypes pattern matches it.
+// Directory walkers use it to pick up custom-named files.
+func IsStarlarkPath(path string) bool {
+	if filekind.IsStarlarkFile(filepath.Base(path)) {
+		return true
+	}
+	_, err := defaultOverrides.Classify(path)
+	return err == nil
+}
+
+// matchOverridePattern matches a slash-separated relative path against a
+// pattern, where "**" matches zero or more path segments.
+func matchOverridePattern(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// dialectForKind returns the default dialect for a file kind.
+func dialectForKind(kind filekind.Kind) string {
+	switch {
+	case kind.IsBazel():
+		return "bazel"
+	case kind.IsBuck():
+		return "buck2"
+	default:
+		return "starlark"
+	}
+}
@@ -0,0 +1,212 @@
+package classifier
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+func TestParseOverrides(t *testing.T) {
+	input := `# custom names
+*.tpl        bzl
+defs/**      BUILD
+/ci/*.cfg    starlark  drone
+`
+	rules, err := ParseOverrides(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseOverrides() error = %v", err)
+	}
+
+	want := []Override{
+		{Pattern: "*.tpl", FileKind: filekind.KindBzl, Dialect: "bazel"},
+		{Pattern: "defs/**", FileKind: filekind.KindBUILD, Dialect: "bazel"},
+		{Pattern: "ci/*.cfg", FileKind: filekind.KindStarlark, Dialect: "drone"},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules, want %d: %+v", len(rules), len(want), rules)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+}
+
+func TestParseOverrides_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"missing kind", "*.tpl\n", "line 1"},
+		{"unknown kind", "\n*.tpl python\n", `line 2: unknown file kind "python"`},
+		{"bad pattern", "[.tpl bzl\n", "invalid pattern"},
+		{"too many fields", "*.tpl bzl bazel extra\n", "line 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOverrides(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseOverrides() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchOverridePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"*.tpl", "rules.tpl", true},
+		{"*.tpl", "a/b/rules.tpl", true},
+		{"*.tpl", "rules.tpl.txt", false},
+		{"defs/**", "defs/a", true},
+		{"defs/**", "defs/x/y/a", true},
+		{"defs/**", "other/defs/a", false},
+		{"**/defs/*", "other/defs/a", true},
+		{"ci/*.cfg", "ci/drone.cfg", true},
+		{"ci/*.cfg", "ci/sub/drone.cfg", false},
+	}
+	for _, tt := range tests {
+		if got := matchOverridePattern(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("matchOverridePattern(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestNew_Overrides(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, OverridesFileName), "*.tpl bzl\ndefs/** bzl\nBUILD starlark\n")
+	// A nested overrides file replaces the parent's rules for its subtree.
+	writeFile(t, filepath.Join(root, "nested", OverridesFileName), "*.cfg BUILD\n")
+
+	tests := []struct {
+		path        string
+		wantKind    filekind.Kind
+		wantDialect string
+		wantConfig  string
+	}{
+		{"rules.tpl", filekind.KindBzl, "bazel", filepath.Join(root, OverridesFileName)},
+		{"defs/helpers", filekind.KindBzl, "bazel", filepath.Join(root, OverridesFileName)},
+		{"pkg/BUILD", filekind.KindStarlark, "starlark", filepath.Join(root, OverridesFileName)},
+		{"pkg/BUILD.bazel", filekind.KindBUILD, "bazel", ""},
+		{"nested/x.cfg", filekind.KindBUILD, "bazel", filepath.Join(root, "nested", OverridesFileName)},
+		{"nested/rules.tpl", filekind.KindUnknown, "starlark", ""},
+	}
+
+	cls := New()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := cls.Classify(filepath.Join(root, tt.path))
+			if err != nil {
+				t.Fatalf("Classify() error = %v", err)
+			}
+			if got.FileKind != tt.wantKind || got.Dialect != tt.wantDialect || got.ConfigPath != tt.wantConfig {
+				t.Errorf("Classify(%s) = %+v, want kind %q dialect %q config %q",
+					tt.path, got, tt.wantKind, tt.wantDialect, tt.wantConfig)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidOverridesFallsBack(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, OverridesFileName), "*.bzl nonsense\n")
+
+	got, err := New().Classify(filepath.Join(root, "defs.bzl"))
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	if got.FileKind != filekind.KindBzl {
+		t.Errorf("FileKind = %q, want builtin %q", got.FileKind, filekind.KindBzl)
+	}
+}
+
+func TestInvalidOverrides(t *testing.T) {
+	root := t.TempDir()
+	overridesPath := filepath.Join(root, OverridesFileName)
+	writeFile(t, overridesPath, "*.tpl bzl\n*.bzl nonsense\n")
+	files := []string{
+		filepath.Join(root, "BUILD.bazel"),
+		filepath.Join(root, "sub", "defs.bzl"),
+		filepath.Join(root, "sub", "rules.tpl"),
+	}
+
+	errs := InvalidOverrides(files)
+	if len(errs) != 1 {
+		t.Fatalf("InvalidOverrides() = %v, want one error", errs)
+	}
+	var oe *OverridesError
+	if !errors.As(errs[0], &oe) || oe.Path != overridesPath || oe.Line != 2 {
+		t.Errorf("InvalidOverrides() = %#v, want an *OverridesError for line 2 of %s", errs[0], overridesPath)
+	}
+	if file, err := OverridesFor(files[1]); file != overridesPath || err == nil {
+		t.Errorf("OverridesFor() = %q, %v; want %q and its error", file, err, overridesPath)
+	}
+
+	writeFile(t, overridesPath, "*.tpl bzl\n*.bzl bzl\n")
+	if errs := InvalidOverrides(files); len(errs) != 0 {
+		t.Errorf("after fixing the file: InvalidOverrides() = %v, want none", errs)
+	}
+}
+
+func TestIsStarlarkPath(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, OverridesFileName), "*.tpl bzl\n")
+
+	if !IsStarlarkPath(filepath.Join(root, "BUILD.bazel")) {
+		t.Error("expected BUILD.bazel to be a Starlark path")
+	}
+	if !IsStarlarkPath(filepath.Join(root, "sub", "rules.tpl")) {
+		t.Error("expected overridden rules.tpl to be a Starlark path")
+	}
+	if IsStarlarkPath(filepath.Join(root, "README.md")) {
+		t.Error("expected README.md not to be a Starlark path")
+	}
+}
+
+func TestOverrideClassifier_SeesEdits(t *testing.T) {
+	root := t.TempDir()
+	overridesPath := filepath.Join(root, OverridesFileName)
+	file := filepath.Join(root, "rules.tpl")
+	cls := NewOverrideClassifier()
+
+	if _, err := cls.Classify(file); err == nil {
+		t.Fatal("expected no override before .skyfiletypes exists")
+	}
+
+	writeFile(t, overridesPath, "*.tpl bzl\n")
+	got, err := cls.Classify(file)
+	if err != nil || got.FileKind != filekind.KindBzl {
+		t.Fatalf("after create: Classify() = %+v, %v; want kind %q", got, err, filekind.KindBzl)
+	}
+
+	writeFile(t, overridesPath, "*.tpl BUILD\n# edited\n")
+	got, err = cls.Classify(file)
+	if err != nil || got.FileKind != filekind.KindBUILD {
+		t.Fatalf("after edit: Classify() = %+v, %v; want kind %q", got, err, filekind.KindBUILD)
+	}
+
+	if err := os.Remove(overridesPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cls.Classify(file); err == nil {
+		t.Error("expected no override after .skyfiletypes is deleted")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
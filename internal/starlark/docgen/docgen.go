@@ -7,6 +7,7 @@ package docgen
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/albertocavalcante/sky/internal/starlark/sortutil"
@@ -80,6 +81,11 @@ type GlobalDoc struct {
 type Options struct {
 	// IncludePrivate includes private symbols (starting with _).
 	IncludePrivate bool
+
+	// Symbols, if non-empty, restricts the documentation to the functions
+	// and globals with these names, private or not. ExtractFile fails if
+	// one of them is not defined.
+	Symbols []string
 }
 
 // DefaultOptions returns sensible defaults.
@@ -101,6 +107,16 @@ func ExtractFile(filename string, src []byte, opts Options) (*ModuleDoc, error)
 		File: filename,
 	}
 
+	// include reports whether a symbol is documented.
+	include := func(name string, private bool) bool {
+		return opts.IncludePrivate || !private
+	}
+	if len(opts.Symbols) > 0 {
+		include = func(name string, _ bool) bool {
+			return slices.Contains(opts.Symbols, name)
+		}
+	}
+
 	// Extract module docstring (first statement if it's a string)
 	if len(f.Stmts) > 0 {
 		if docstring := extractExprDocstring(f.Stmts[0]); docstring != "" {
@@ -113,7 +129,7 @@ func ExtractFile(filename string, src []byte, opts Options) (*ModuleDoc, error)
 		switch s := stmt.(type) {
 		case *syntax.DefStmt:
 			funcDoc := extractFunctionDoc(s)
-			if opts.IncludePrivate || !funcDoc.IsPrivate {
+			if include(funcDoc.Name, funcDoc.IsPrivate) {
 				doc.Functions = append(doc.Functions, funcDoc)
 			}
 
@@ -126,19 +142,43 @@ func ExtractFile(filename string, src []byte, opts Options) (*ModuleDoc, error)
 					Line:      int(s.OpPos.Line),
 					IsPrivate: strings.HasPrefix(ident.Name, "_"),
 				}
-				if opts.IncludePrivate || !globalDoc.IsPrivate {
+				if include(globalDoc.Name, globalDoc.IsPrivate) {
 					doc.Globals = append(doc.Globals, globalDoc)
 				}
 			}
 		}
 	}
 
+	if err := checkSymbols(doc, opts.Symbols); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
 	// Sort functions by name
 	sortutil.ByName(doc.Functions, func(f FunctionDoc) string { return f.Name })
 
 	return doc, nil
 }
 
+// checkSymbols returns an error naming the symbols that doc does not
+// document.
+func checkSymbols(doc *ModuleDoc, symbols []string) error {
+	var missing []string
+	for _, name := range symbols {
+		found := slices.ContainsFunc(doc.Functions, func(f FunctionDoc) bool { return f.Name == name }) ||
+			slices.ContainsFunc(doc.Globals, func(g GlobalDoc) bool { return g.Name == name })
+		if !found && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("symbol %q not found", missing[0])
+	}
+	return fmt.Errorf("symbols not found: %s", strings.Join(missing, ", "))
+}
+
 // extractFunctionDoc extracts documentation from a function definition.
 func extractFunctionDoc(def *syntax.DefStmt) FunctionDoc {
 	doc := FunctionDoc{
@@ -2,8 +2,10 @@ package docgen
 
 import (
 	"bytes"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExtractFile(t *testing.T) {
@@ -100,6 +102,37 @@ def _private():
 	}
 }
 
+func TestExtractFileWithSymbols(t *testing.T) {
+	src := []byte(`
+def public():
+    pass
+
+def other():
+    pass
+
+def _helper():
+    pass
+
+VERSION = "1.0"
+`)
+
+	doc, err := ExtractFile("test.star", src, Options{Symbols: []string{"public", "_helper", "VERSION"}})
+	if err != nil {
+		t.Fatalf("ExtractFile failed: %v", err)
+	}
+	if len(doc.Functions) != 2 || doc.Functions[0].Name != "_helper" || doc.Functions[1].Name != "public" {
+		t.Errorf("unexpected functions %+v", doc.Functions)
+	}
+	if len(doc.Globals) != 1 || doc.Globals[0].Name != "VERSION" {
+		t.Errorf("unexpected globals %+v", doc.Globals)
+	}
+
+	_, err = ExtractFile("test.star", src, Options{Symbols: []string{"public", "missing", "gone"}})
+	if err == nil || err.Error() != "test.star: symbols not found: missing, gone" {
+		t.Errorf("ExtractFile() error = %v", err)
+	}
+}
+
 func TestParseDocstring(t *testing.T) {
 	docstring := `Short summary.
 
@@ -272,3 +305,100 @@ func TestHasDocumentation(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderMarkdownFrontmatter(t *testing.T) {
+	doc := &ModuleDoc{File: "example.star"}
+	opts := DefaultMarkdownOptions()
+	opts.Generated = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	opts.Frontmatter = []FrontmatterField{
+		{Key: "layout", Value: "api: docs"},
+		{Key: "weight", Value: "10"},
+		{Key: "title", Value: "Example API"},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderMarkdown(&buf, doc, opts); err != nil {
+		t.Fatalf("RenderMarkdown failed: %v", err)
+	}
+
+	want := "---\n" +
+		"title: \"Example API\"\n" +
+		"generated: 2026-03-01\n" +
+		"layout: \"api: docs\"\n" +
+		"weight: 10\n" +
+		"---\n\n" +
+		"# example.star\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected output to start with:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := RenderMarkdown(&buf, doc, DefaultMarkdownOptions()); err != nil {
+		t.Fatalf("RenderMarkdown failed: %v", err)
+	}
+	if strings.HasPrefix(buf.String(), "---") {
+		t.Errorf("expected no frontmatter by default, got:\n%s", buf.String())
+	}
+}
+
+func TestExamples(t *testing.T) {
+	src := []byte(`
+def indented():
+    """Indented.
+
+    Example:
+        def twice(x):
+            return x * 2
+
+        twice(2)
+    """
+
+def prompts():
+    """Prompts.
+
+    Example:
+        >>> x = [1]
+        >>> for v in x:
+        ...     v
+        1
+    """
+
+def fenced():
+    """Fenced.
+
+    Example:
+        ` + "```starlark" + `
+        fenced()
+        ` + "```" + `
+    """
+
+def shell():
+    """Shell.
+
+    Example:
+        ` + "```bash" + `
+        sky doc lib.star
+        ` + "```" + `
+    """
+
+def none():
+    """No example."""
+`)
+
+	doc, err := ExtractFile("test.star", src, Options{})
+	if err != nil {
+		t.Fatalf("ExtractFile failed: %v", err)
+	}
+	got := map[string]string{}
+	for _, ex := range Examples(doc) {
+		got[ex.Function] = ex.Code
+	}
+	want := map[string]string{
+		"indented": "def twice(x):\n    return x * 2\n\ntwice(2)\n",
+		"prompts":  "x = [1]\nfor v in x:\n    v\n",
+		"fenced":   "fenced()\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Examples() = %q, want %q", got, want)
+	}
+}
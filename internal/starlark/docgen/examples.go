@@ -0,0 +1,77 @@
+package docgen
+
+import (
+	"strings"
+)
+
+// Example is the Example: section of a function's docstring.
+type Example struct {
+	// Function is the documented function.
+	Function string
+
+	// Line is the line number where the function is defined.
+	Line int
+
+	// Code is the Starlark code of the example, with Markdown fences and
+	// ">>>" prompts removed. Lines after a prompt that are not
+	// continuations (the expected output) are dropped.
+	Code string
+}
+
+// Examples returns the examples in the docstrings of doc's functions, in
+// the order of doc.Functions. Examples fenced as a language other than
+// Starlark or Python are skipped.
+func Examples(doc *ModuleDoc) []Example {
+	var examples []Example
+	for _, fn := range doc.Functions {
+		if fn.Parsed == nil || fn.Parsed.Example == "" {
+			continue
+		}
+		code, ok := exampleCode(fn.Parsed.Example)
+		if !ok {
+			continue
+		}
+		examples = append(examples, Example{Function: fn.Name, Line: fn.Line, Code: code})
+	}
+	return examples
+}
+
+// exampleLanguages are the fence languages exampleCode accepts.
+var exampleLanguages = []string{"", "starlark", "star", "python", "py", "bzl"}
+
+// exampleCode returns the code in example, reporting false if it is fenced
+// as another language.
+func exampleCode(example string) (string, bool) {
+	var lines []string
+	prompts := false
+	for line := range strings.SplitSeq(example, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if lang, ok := strings.CutPrefix(trimmed, "```"); ok {
+			if !isExampleLanguage(strings.TrimSpace(lang)) {
+				return "", false
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(trimmed, ">>>"):
+			prompts = true
+			lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(trimmed, ">>>"), " "))
+		case prompts && strings.HasPrefix(trimmed, "..."):
+			lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(trimmed, "..."), " "))
+		case prompts:
+			// Expected output of the previous prompt.
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n", true
+}
+
+func isExampleLanguage(lang string) bool {
+	for _, l := range exampleLanguages {
+		if strings.EqualFold(lang, l) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // MarkdownOptions configures markdown rendering.
@@ -21,6 +24,21 @@ type MarkdownOptions struct {
 
 	// SourceBaseURL is the base URL for source links.
 	SourceBaseURL string
+
+	// Frontmatter, if non-empty, adds a YAML frontmatter block for static
+	// site generators. The block always has "title" and "generated" keys,
+	// followed by these fields; a field with either key overrides it.
+	Frontmatter []FrontmatterField
+
+	// Generated is the date written as "generated" in the frontmatter
+	// (default: now).
+	Generated time.Time
+}
+
+// FrontmatterField is a key and value in a YAML frontmatter block.
+type FrontmatterField struct {
+	Key   string
+	Value string
 }
 
 // DefaultMarkdownOptions returns sensible defaults.
@@ -38,6 +56,10 @@ func RenderMarkdown(w io.Writer, doc *ModuleDoc, opts MarkdownOptions) error {
 		title = filepath.Base(doc.File)
 	}
 
+	if len(opts.Frontmatter) > 0 {
+		renderFrontmatter(w, title, opts)
+	}
+
 	// Header
 	writef(w, "# %s\n\n", title)
 
@@ -230,6 +252,47 @@ func toAnchor(name string) string {
 	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
 }
 
+// plainYAMLScalar matches values written unquoted in frontmatter, so that
+// numbers, booleans, and dates keep their YAML types.
+var plainYAMLScalar = regexp.MustCompile(`^(-?[0-9]+(\.[0-9]+)?|true|false|[0-9]{4}-[0-9]{2}-[0-9]{2})$`)
+
+// renderFrontmatter writes a "---"-delimited YAML block. Numbers, booleans,
+// and dates are written as is; other values are double-quoted so they need
+// no further escaping.
+func renderFrontmatter(w io.Writer, title string, opts MarkdownOptions) {
+	generated := opts.Generated
+	if generated.IsZero() {
+		generated = time.Now()
+	}
+	fields := []FrontmatterField{
+		{Key: "title", Value: title},
+		{Key: "generated", Value: generated.Format(time.DateOnly)},
+	}
+	for _, f := range opts.Frontmatter {
+		replaced := false
+		for i := range fields {
+			if fields[i].Key == f.Key {
+				fields[i].Value = f.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			fields = append(fields, f)
+		}
+	}
+
+	writeln(w, "---")
+	for _, f := range fields {
+		value := f.Value
+		if !plainYAMLScalar.MatchString(value) {
+			value = strconv.Quote(value)
+		}
+		writef(w, "%s: %s\n", f.Key, value)
+	}
+	writeln(w, "---\n")
+}
+
 func writef(w io.Writer, format string, args ...any) {
 	_, _ = fmt.Fprintf(w, format, args...)
 }
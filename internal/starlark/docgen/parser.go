@@ -75,7 +75,7 @@ func ParseDocstring(docstring string) *ParsedDocstring {
 			parsed.Raises = parseArgsSection(content)
 
 		case "example", "examples":
-			parsed.Example = strings.TrimSpace(content)
+			parsed.Example = dedentBlock(content)
 
 		case "note", "notes":
 			parsed.Note = strings.TrimSpace(content)
@@ -208,6 +208,38 @@ func parseArgsSection(content string) map[string]string {
 	return args
 }
 
+// dedentBlock removes the indentation common to the lines of a section,
+// keeping the relative indentation of code, and trims blank lines around
+// it. Text on the header line itself is not indented, so it is trimmed
+// separately.
+func dedentBlock(content string) string {
+	first, rest, _ := strings.Cut(content, "\n")
+	lines := strings.Split(rest, "\n")
+
+	common := -1
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if indent := len(line) - len(trimmed); common < 0 || indent < common {
+			common = indent
+		}
+	}
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		} else {
+			lines[i] = line[common:]
+		}
+	}
+
+	if first = strings.TrimSpace(first); first != "" {
+		lines = append([]string{first}, lines...)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
 // HasDocumentation returns true if the parsed docstring has meaningful content.
 func (p *ParsedDocstring) HasDocumentation() bool {
 	if p == nil {
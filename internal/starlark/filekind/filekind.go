@@ -1,7 +1,10 @@
 // Package filekind defines the types of Starlark files recognized by the SKY toolchain.
 package filekind
 
-import "path/filepath"
+import (
+	"path/filepath"
+	"strings"
+)
 
 // Kind represents the type of Starlark file.
 type Kind string
@@ -100,6 +103,18 @@ func AllKinds() []Kind {
 	}
 }
 
+// Parse returns the Kind named by s, compared case-insensitively against
+// the defined kinds (e.g. "build", "BUILD", "bzl"). KindUnknown and
+// unrecognized names return false.
+func Parse(s string) (Kind, bool) {
+	for _, k := range AllKinds() {
+		if k != KindUnknown && strings.EqualFold(string(k), s) {
+			return k, true
+		}
+	}
+	return KindUnknown, false
+}
+
 // IsStarlarkFile returns true if the filename is a recognized Starlark file.
 // Supports files from: Bazel, Buck2, Pants, Please, Tilt, Copybara, Skycfg,
 // Kurtosis, Drone CI, Isopod, Cirrus CI, and generic Starlark.
@@ -180,3 +180,28 @@ func TestIsStarlarkFile(t *testing.T) {
 		})
 	}
 }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   Kind
+		wantOK bool
+	}{
+		{"BUILD", KindBUILD, true},
+		{"build", KindBUILD, true},
+		{"bzl", KindBzl, true},
+		{"Starlark", KindStarlark, true},
+		{"bzl_buck", KindBzlBuck, true},
+		{"unknown", KindUnknown, false},
+		{"tpl", KindUnknown, false},
+		{"", KindUnknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := Parse(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Parse(%q) = (%q, %v), want (%q, %v)", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConfigFileName is the formatter configuration file that LoadConfig looks
+// for in the current directory and its parents.
+const ConfigFileName = ".skyfmt.json"
+
+// Config holds the formatting options of a .skyfmt.json file. Each option
+// turns off one or more of the rewrites buildifier applies on top of
+// reprinting; options left out keep buildifier's behavior.
+//
+// Indentation and line width are fixed by the buildifier style and cannot
+// be configured.
+type Config struct {
+	// Comment is free text for humans, ignored when the config is applied.
+	Comment string `json:"//,omitempty"`
+
+	// ReorderLoads moves load statements to the top of the file, merges
+	// loads of the same file, and sorts the loads and their symbols.
+	ReorderLoads *bool `json:"reorderLoads,omitempty"`
+
+	// SortLists sorts lists of strings marked "# keep sorted" and, in BUILD
+	// files, sortable attributes such as srcs and deps.
+	SortLists *bool `json:"sortLists,omitempty"`
+
+	// SortArguments sorts the keyword arguments of calls in BUILD files,
+	// with name first.
+	SortArguments *bool `json:"sortArguments,omitempty"`
+
+	// FormatDocstrings reindents docstrings.
+	FormatDocstrings *bool `json:"formatDocstrings,omitempty"`
+
+	// DisableRewrites names further buildifier rewrites to skip, as for
+	// buildifier's --buildifier_disable flag (see Rewrites).
+	DisableRewrites []string `json:"disableRewrites,omitempty"`
+}
+
+// optionRewrites maps each boolean option to the rewrites it controls.
+var optionRewrites = []struct {
+	option   func(*Config) *bool
+	rewrites []string
+}{
+	{func(c *Config) *bool { return c.ReorderLoads }, []string{"loadTop", "sameOriginLoad", "sortLoadStatements", "loadsort"}},
+	{func(c *Config) *bool { return c.SortLists }, []string{"listsort"}},
+	{func(c *Config) *bool { return c.SortArguments }, []string{"callsort"}},
+	{func(c *Config) *bool { return c.FormatDocstrings }, []string{"formatdocstrings"}},
+}
+
+// LoadConfig loads the formatter configuration from path. If path is
+// empty, it searches for .skyfmt.json in the current directory and its
+// parents, and returns an empty config when there is none.
+func LoadConfig(path string) (*Config, error) {
+	configPath := path
+	if configPath == "" {
+		found, err := findConfigFile()
+		if err != nil {
+			return nil, fmt.Errorf("finding config file: %w", err)
+		}
+		if found == "" {
+			return &Config{}, nil
+		}
+		configPath = found
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config file not found: %s", configPath)
+		}
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	// Unknown keys are errors, so that a misspelled option or one the
+	// buildifier style does not support (indent, lineWidth) is not
+	// silently ignored.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", configPath, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", configPath, err)
+	}
+	return &config, nil
+}
+
+// findConfigFile searches for .skyfmt.json in the current directory and
+// its parents. Returns an empty string if no config file is found.
+func findConfigFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get working directory: %w", err)
+	}
+	return FindConfig(dir), nil
+}
+
+// FindConfig returns the path of the .skyfmt.json in dir or the nearest of
+// its parents, or "" if there is none. Editors use it to find the config
+// that applies to a document.
+func FindConfig(dir string) string {
+	for {
+		configPath := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(configPath); err == nil {
+			return configPath
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Validate reports rewrite names in DisableRewrites that buildifier does
+// not have.
+func (c *Config) Validate() error {
+	for _, name := range c.DisableRewrites {
+		if _, ok := rewriteScopes[name]; !ok {
+			return fmt.Errorf("unknown rewrite %q in disableRewrites (known: %s)", name, strings.Join(Rewrites(), ", "))
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether c leaves every rewrite enabled, so that
+// formatting with it matches the default buildtools engine.
+func (c *Config) IsZero() bool {
+	return c == nil || len(c.disabledRewrites()) == 0
+}
+
+// disabledRewrites returns the set of rewrites c turns off.
+func (c *Config) disabledRewrites() map[string]bool {
+	disabled := make(map[string]bool)
+	for _, o := range optionRewrites {
+		if enabled := o.option(c); enabled != nil && !*enabled {
+			for _, name := range o.rewrites {
+				disabled[name] = true
+			}
+		}
+	}
+	for _, name := range c.DisableRewrites {
+		disabled[name] = true
+	}
+	return disabled
+}
+
+// Rewrites returns the names of the buildifier rewrites that
+// Config.DisableRewrites accepts, sorted.
+func Rewrites() []string {
+	names := make([]string, 0, len(rewriteScopes))
+	for name := range rewriteScopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
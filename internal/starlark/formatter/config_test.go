@@ -0,0 +1,133 @@
+package formatter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+	"github.com/albertocavalcante/sky/internal/starlark/formatter"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	config, err := formatter.LoadConfig(write("ok.json", `{"reorderLoads": false, "disableRewrites": ["editfloat"]}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.ReorderLoads == nil || *config.ReorderLoads || len(config.DisableRewrites) != 1 || config.IsZero() {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unsupported option", content: `{"indent": 2}`, wantErr: `unknown field "indent"`},
+		{name: "unknown rewrite", content: `{"disableRewrites": ["nosuch"]}`, wantErr: `unknown rewrite "nosuch"`},
+		{name: "invalid json", content: `{`, wantErr: "parse config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := formatter.LoadConfig(write(tt.name+".json", tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := formatter.LoadConfig(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadConfig() of a missing file succeeded")
+	}
+}
+
+func TestNewBuildtools(t *testing.T) {
+	enabled := true
+	for _, config := range []*formatter.Config{nil, {}, {ReorderLoads: &enabled}} {
+		engine, err := formatter.NewBuildtools(config)
+		if err != nil || engine != formatter.Buildtools {
+			t.Errorf("NewBuildtools(%+v) = %v, %v; want Buildtools", config, engine, err)
+		}
+	}
+	if _, err := formatter.NewBuildtools(&formatter.Config{DisableRewrites: []string{"nosuch"}}); err == nil {
+		t.Error("NewBuildtools() accepted an unknown rewrite")
+	}
+}
+
+func TestNewBuildtools_ReorderLoads(t *testing.T) {
+	src := `x = 1
+
+load("//b:defs.bzl", "z", "a")
+load("//a:defs.bzl", "y")
+`
+	disabled := false
+	engine, err := formatter.NewBuildtools(&formatter.Config{ReorderLoads: &disabled})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := engine.Format([]byte(src), "BUILD", filekind.KindBUILD)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if string(got) != src {
+		t.Errorf("reorderLoads=false changed the loads:\n%s", got)
+	}
+
+	got, err = formatter.Buildtools.Format([]byte(src), "BUILD", filekind.KindBUILD)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.HasPrefix(string(got), `load("//a:defs.bzl", "y")`+"\n"+`load("//b:defs.bzl", "a", "z")`) {
+		t.Errorf("default engine did not reorder the loads:\n%s", got)
+	}
+}
+
+// An option that disables nothing relevant must format like the default
+// engine, for every file type; this pins rewriteScopes to upstream.
+func TestNewBuildtools_MatchesDefaultRewrites(t *testing.T) {
+	engine, err := formatter.NewBuildtools(&formatter.Config{DisableRewrites: []string{"editfloat"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := `"""Module docstring."""
+load("//b:defs.bzl", "z", "a")
+load("//b:defs.bzl", "y")
+cc_library(srcs = ["b.cc", "a.cc"], name = "lib", deps = ["//:b", "//:a"], visibility = ("//visibility:public"))
+x = ["b", "a"]  # keep sorted
+y = 0777
+bazel_dep(name = "rules_go", version = "1.0")
+use_repo(ext, "b", "a")
+def f():
+    """Doc.
+
+        Indented.
+    """
+    pass
+`
+	for _, kind := range []filekind.Kind{filekind.KindBUILD, filekind.KindBzl, filekind.KindMODULE, filekind.KindWORKSPACE, filekind.KindStarlark} {
+		want, err := formatter.Buildtools.Format([]byte(src), "file", kind)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		got, err := engine.Format([]byte(src), "file", kind)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s: configured engine diverged from default:\n--- got\n%s\n--- want\n%s", kind, got, want)
+		}
+	}
+}
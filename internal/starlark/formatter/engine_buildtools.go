@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/tables"
 
 	"github.com/albertocavalcante/sky/internal/starlark/filekind"
 )
@@ -13,16 +14,86 @@ import (
 // compared against during migration.
 var Buildtools Engine = buildtoolsEngine{}
 
-type buildtoolsEngine struct{}
+type buildtoolsEngine struct {
+	// config turns off buildifier rewrites; nil applies them all. A
+	// pointer keeps the engine comparable.
+	config *Config
+}
+
+// NewBuildtools returns a buildtools engine that formats with config's
+// options. A nil or empty config returns Buildtools.
+func NewBuildtools(config *Config) (Engine, error) {
+	if config.IsZero() {
+		return Buildtools, nil
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return buildtoolsEngine{config: config}, nil
+}
 
 func (buildtoolsEngine) Name() string { return "buildtools" }
 
-func (buildtoolsEngine) Format(src []byte, path string, kind filekind.Kind) ([]byte, error) {
+func (e buildtoolsEngine) Format(src []byte, path string, kind filekind.Kind) ([]byte, error) {
 	f, err := parse(src, path, kind)
 	if err != nil {
 		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
-	return build.Format(f), nil
+	if e.config == nil {
+		return build.Format(f), nil
+	}
+	return build.FormatWithRewriter(e.rewriter(f.Type), f), nil
+}
+
+// rewriter returns a rewriter that applies the rewrites build.Format would
+// for a file of type t, minus those the config turns off. The set has to
+// be spelled out because a rewriter with a RewriteSet ignores the scopes
+// of the rewrites.
+func (e buildtoolsEngine) rewriter(t build.FileType) *build.Rewriter {
+	disabled := e.config.disabledRewrites()
+	rewriteSet := []string{}
+	for name, scope := range rewriteScopes {
+		if t&scope != 0 && !disabled[name] {
+			rewriteSet = append(rewriteSet, name)
+		}
+	}
+	return &build.Rewriter{
+		RewriteSet:                      rewriteSet,
+		IsLabelArg:                      tables.IsLabelArg,
+		LabelDenyList:                   tables.LabelDenylist,
+		IsSortableListArg:               tables.IsSortableListArg,
+		SortableDenylist:                tables.SortableDenylist,
+		SortableAllowlist:               tables.SortableAllowlist,
+		NamePriority:                    tables.NamePriority,
+		StripLabelLeadingSlashes:        tables.StripLabelLeadingSlashes,
+		ShortenAbsoluteLabelsToRelative: tables.ShortenAbsoluteLabelsToRelative,
+	}
+}
+
+// File type scopes of the buildifier rewrites, mirroring build/rewrite.go.
+const (
+	scopeBuild = build.TypeBuild | build.TypeWorkspace | build.TypeModule
+	scopeBoth  = build.TypeDefault | build.TypeBzl | scopeBuild
+)
+
+// rewriteScopes lists the rewrites build.Format applies and the file types
+// each applies to. It must be kept in sync with buildtools when upgrading.
+var rewriteScopes = map[string]build.FileType{
+	"removeParens":           scopeBuild,
+	"callsort":               scopeBuild,
+	"label":                  scopeBuild,
+	"listsort":               scopeBoth,
+	"multiplus":              scopeBuild,
+	"loadTop":                scopeBoth,
+	"sameOriginLoad":         scopeBoth,
+	"sortLoadStatements":     scopeBoth,
+	"loadsort":               scopeBoth,
+	"useRepoPositionalsSort": build.TypeModule,
+	"formatdocstrings":       scopeBoth,
+	"reorderarguments":       scopeBoth,
+	"editoctal":              scopeBoth,
+	"editfloat":              scopeBoth,
+	"collapseEmpty":          scopeBoth,
 }
 
 // parse parses source code using the appropriate buildtools parser based
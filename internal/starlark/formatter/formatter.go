@@ -33,12 +33,12 @@ import (
 // a fresh classifier per call was wasteful.
 var (
 	defaultClassifierOnce sync.Once
-	defaultClassifier     *classifier.DefaultClassifier
+	defaultClassifier     classifier.Classifier
 )
 
-func sharedClassifier() *classifier.DefaultClassifier {
+func sharedClassifier() classifier.Classifier {
 	defaultClassifierOnce.Do(func() {
-		defaultClassifier = classifier.NewDefaultClassifier()
+		defaultClassifier = classifier.New()
 	})
 	return defaultClassifier
 }
@@ -88,7 +88,7 @@ func FormatWith(engine Engine, src []byte, path string, kind filekind.Kind) ([]b
 }
 
 // FormatFile reads a file, formats it with Default, and returns the result.
-// The file kind is auto-detected from the filename using the default
+// The file kind is auto-detected from the filename using the shared
 // classifier.
 func FormatFile(path string) *Result {
 	return FormatFileWithKind(path, "")
@@ -126,8 +126,9 @@ func FormatFileWith(engine Engine, path string, kind filekind.Kind) *Result {
 	return result
 }
 
-// DetectKind uses the default classifier to detect the file kind from a
-// path. Returns KindUnknown on any classification error.
+// DetectKind uses the shared classifier (.skyfiletypes overrides, then
+// name-based detection) to detect the file kind from a path. Returns
+// KindUnknown on any classification error.
 //
 // Exported so CLIs and tools can do kind-aware dispatch without
 // duplicating the classifier wiring.
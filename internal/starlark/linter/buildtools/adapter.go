@@ -30,6 +30,9 @@ func AllRules() []*linter.Rule {
 		rules = append(rules, wrapRuleWarning(name))
 	}
 
+	// Native rules not provided by buildtools/warn
+	rules = append(rules, AttrOrderRule, BzlLibraryDepsRule, DuplicateDictKeyRule, UnsortedListRule)
+
 	return rules
 }
 
@@ -79,7 +82,14 @@ func wrapFileWarning(name string) *linter.Rule {
 		Run: func(pass *linter.Pass) (any, error) {
 			findings := fn(pass.File)
 			for _, f := range findings {
-				pass.Report(convertFinding(f, name, pass.FilePath))
+				finding := convertFinding(f, name, pass.FilePath)
+				// Prefer a minimal edit over reformatting the whole load statement
+				if name == "load" {
+					if repl := unusedLoadReplacement(pass.Content, f); repl != nil {
+						finding.Replacement = repl
+					}
+				}
+				pass.Report(finding)
 			}
 			return nil, nil
 		},
@@ -162,7 +172,8 @@ func convertFinding(f *warn.LinterFinding, ruleName, filePath string) linter.Fin
 		// Multiple replacements are rare and usually represent alternatives
 		repl := f.Replacement[0]
 
-		if repl.Old != nil {
+		// Old may point to a nil expression, e.g. for insertions
+		if repl.Old != nil && *repl.Old != nil {
 			// Get byte positions from the Old expression
 			start, end := (*repl.Old).Span()
 
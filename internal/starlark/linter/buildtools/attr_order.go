@@ -0,0 +1,173 @@
+package buildtools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+const (
+	attrOrderName     = "attr-order"
+	attrOrderCategory = "style"
+
+	// attrOrderOption names the rule option listing the attributes that
+	// come first, in order.
+	attrOrderOption = "order"
+)
+
+// defaultAttrOrder is used when the "order" option is unset.
+var defaultAttrOrder = []string{"name", "srcs", "deps"}
+
+// AttrOrderRule flags rule calls whose keyword arguments are not in
+// canonical order, and reorders them as a fix.
+//
+// The attributes listed in the "order" option (name, srcs, deps by default)
+// come first, in that order, and the rest follow alphabetically. Only
+// top-level calls are checked, so select() and glob() arguments are left
+// alone, as are calls preceded by a "do not sort" comment. Comments above an
+// argument and at the end of its line move with it.
+//
+// The rule is opt-in: buildifier sorts arguments by its own priority table,
+// which places deps after most attributes, so the two would disagree.
+var AttrOrderRule = &linter.Rule{
+	Name:      attrOrderName,
+	Doc:       "Checks that rule attributes are in canonical order: name, srcs, deps, then the rest alphabetically",
+	Category:  attrOrderCategory,
+	Severity:  linter.SeverityWarning,
+	AutoFix:   true,
+	OptIn:     true,
+	FileKinds: []filekind.Kind{filekind.KindBUILD, filekind.KindBUCK},
+	Run:       runAttrOrder,
+}
+
+func runAttrOrder(pass *linter.Pass) (any, error) {
+	leading, err := stringListOption(pass.Config, attrOrderOption, defaultAttrOrder)
+	if err != nil {
+		return nil, err
+	}
+	rank := make(map[string]int, len(leading))
+	for i, name := range leading {
+		if _, dup := rank[name]; !dup {
+			rank[name] = i
+		}
+	}
+
+	for _, stmt := range pass.File.Stmt {
+		if call, ok := stmt.(*build.CallExpr); ok && !doNotSort(call) {
+			checkAttrOrder(pass, call, rank)
+		}
+	}
+	return nil, nil
+}
+
+func checkAttrOrder(pass *linter.Pass, call *build.CallExpr, rank map[string]int) {
+	block, ok := keywordArgs(pass.Content, call)
+	if !ok || len(block) < 2 {
+		return
+	}
+
+	order := slices.Clone(block)
+	slices.SortStableFunc(order, func(a, b listItem) int {
+		return compareAttrs(argName(a.expr), argName(b.expr), rank)
+	})
+	if sameOrder(block, order) {
+		return
+	}
+
+	names := make([]string, len(order))
+	for i, item := range order {
+		names[i] = argName(item.expr)
+	}
+	startPos, _ := block[0].expr.Span()
+	_, endPos := block[len(block)-1].expr.Span()
+	pass.Report(linter.Finding{
+		Severity:    linter.SeverityWarning,
+		Message:     fmt.Sprintf("Attributes of %s are not in canonical order; expected %s", calleeName(call), strings.Join(names, ", ")),
+		Line:        startPos.Line,
+		Column:      startPos.LineRune,
+		EndLine:     endPos.Line,
+		EndColumn:   endPos.LineRune,
+		Rule:        attrOrderName,
+		Category:    attrOrderCategory,
+		Replacement: attrOrderReplacement(pass.Content, block, order),
+	})
+}
+
+// keywordArgs returns the keyword arguments of call that follow its
+// positional arguments, up to any *args or **kwargs. Each item starts at
+// the first comment line above its argument, so that the comment moves with
+// it.
+func keywordArgs(content []byte, call *build.CallExpr) ([]listItem, bool) {
+	var items []listItem
+	for i, arg := range call.List {
+		assign, isKeyword := arg.(*build.AssignExpr)
+		if !isKeyword {
+			if len(items) > 0 {
+				break
+			}
+			continue
+		}
+		if _, ok := assign.LHS.(*build.Ident); !ok {
+			break
+		}
+		item, ok := newListItem(content, call.List, call.End.Pos.Byte, i)
+		if !ok {
+			// Positions do not match the content; leave the call alone.
+			return nil, false
+		}
+		if before := arg.Comment().Before; len(before) > 0 {
+			item.start = before[0].Start.Byte
+		}
+		items = append(items, item)
+	}
+	return items, true
+}
+
+// attrOrderReplacement reorders block. Arguments with comments above them
+// only move between arguments on lines of their own; otherwise there is no
+// fix.
+func attrOrderReplacement(content []byte, block, order []listItem) *linter.Replacement {
+	commented := slices.ContainsFunc(block, func(item listItem) bool { return len(item.expr.Comment().Before) > 0 })
+	shared := slices.ContainsFunc(block, func(item listItem) bool { return !item.ownLine })
+	if commented && shared {
+		return nil
+	}
+	return sortReplacement(content, block, order)
+}
+
+// compareAttrs orders the attributes with a rank first, by rank, and the
+// rest alphabetically after them.
+func compareAttrs(a, b string, rank map[string]int) int {
+	ra, aRanked := rank[a]
+	rb, bRanked := rank[b]
+	switch {
+	case aRanked && bRanked:
+		return ra - rb
+	case aRanked:
+		return -1
+	case bRanked:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func argName(expr build.Expr) string {
+	return expr.(*build.AssignExpr).LHS.(*build.Ident).Name
+}
+
+// calleeName returns the function a call names, such as cc_library or
+// native.cc_library, for messages.
+func calleeName(call *build.CallExpr) string {
+	switch x := call.X.(type) {
+	case *build.Ident:
+		return x.Name
+	case *build.DotExpr:
+		return build.FormatString(x)
+	}
+	return "call"
+}
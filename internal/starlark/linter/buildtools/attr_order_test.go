@@ -0,0 +1,172 @@
+package buildtools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+func lintAttrOrder(t *testing.T, name, content string, options map[string]any) []linter.Finding {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	registry := linter.NewRegistry()
+	if err := registry.Register(AttrOrderRule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	registry.Enable(AttrOrderRule.Name)
+	if options != nil {
+		if err := registry.SetConfig(AttrOrderRule.Name, linter.RuleConfig{Options: options}); err != nil {
+			t.Fatalf("SetConfig() error = %v", err)
+		}
+	}
+	findings, err := linter.NewDriver(registry).RunFile(path)
+	if err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+	return findings
+}
+
+func TestAttrOrderRule_Fix(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]any
+		content string
+		want    string
+	}{
+		{
+			name: "single line",
+			content: `cc_library(deps = [":b"], name = "lib", srcs = ["a.cc"])
+`,
+			want: `cc_library(name = "lib", srcs = ["a.cc"], deps = [":b"])
+`,
+		},
+		{
+			name: "rest alphabetically",
+			content: `cc_library(
+    visibility = ["//visibility:public"],
+    name = "lib",
+    copts = ["-O2"],
+    deps = [":b"],
+)
+`,
+			want: `cc_library(
+    name = "lib",
+    deps = [":b"],
+    copts = ["-O2"],
+    visibility = ["//visibility:public"],
+)
+`,
+		},
+		{
+			name: "comments and multi-line values move with their argument",
+			content: `cc_library(
+    # Exported to everyone.
+    visibility = ["//visibility:public"],  # see README
+    srcs = [
+        "a.cc",
+        "b.cc",
+    ],
+    name = "lib",
+)
+`,
+			want: `cc_library(
+    name = "lib",
+    srcs = [
+        "a.cc",
+        "b.cc",
+    ],
+    # Exported to everyone.
+    visibility = ["//visibility:public"],  # see README
+)
+`,
+		},
+		{
+			name: "positional arguments and kwargs stay in place",
+			content: `my_macro("x", deps = [], name = "lib", **kwargs)
+`,
+			want: `my_macro("x", name = "lib", deps = [], **kwargs)
+`,
+		},
+		{
+			name:    "order option",
+			options: map[string]any{"order": []any{"name", "visibility"}},
+			content: `cc_library(deps = [], visibility = [], name = "lib")
+`,
+			want: `cc_library(name = "lib", visibility = [], deps = [])
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := lintAttrOrder(t, "BUILD", tt.content, tt.options)
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %+v", findings)
+			}
+			if findings[0].Replacement == nil {
+				t.Fatal("finding has no fix")
+			}
+			fixed, applied, _ := linter.ApplyFixes([]byte(tt.content), []*linter.Replacement{findings[0].Replacement})
+			if applied != 1 {
+				t.Fatalf("fix was not applied")
+			}
+			if string(fixed) != tt.want {
+				t.Errorf("fixed content mismatch\ngot:\n%s\nwant:\n%s", fixed, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttrOrderRule_NoFindings(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "canonical", file: "BUILD", content: `cc_library(name = "lib", srcs = [], deps = [], copts = [])
+`},
+		{name: "nested calls", file: "BUILD", content: `cc_library(name = "lib", srcs = glob(include = ["*.cc"], exclude = ["x.cc"]))
+`},
+		{name: "do not sort", file: "BUILD", content: `# do not sort
+cc_library(deps = [], name = "lib")
+`},
+		{name: "bzl file", file: "defs.bzl", content: `cc_library(deps = [], name = "lib")
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if findings := lintAttrOrder(t, tt.file, tt.content, nil); len(findings) != 0 {
+				t.Errorf("expected no findings, got %+v", findings)
+			}
+		})
+	}
+}
+
+func TestAttrOrderRule_Finding(t *testing.T) {
+	findings := lintAttrOrder(t, "BUILD", `cc_library(deps = [], name = "lib")
+`, nil)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Rule != attrOrderName || f.Line != 1 || f.Column != 12 ||
+		!strings.Contains(f.Message, "Attributes of cc_library are not in canonical order; expected name, deps") {
+		t.Errorf("unexpected finding %+v", f)
+	}
+}
+
+func TestAttrOrderRule_OptIn(t *testing.T) {
+	registry := linter.NewRegistry()
+	if err := registry.Register(AttrOrderRule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if registry.IsEnabled(AttrOrderRule.Name) {
+		t.Error("attr-order should be disabled until enabled")
+	}
+}
@@ -0,0 +1,290 @@
+package buildtools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/labels"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+const (
+	bzlLibraryDepsName     = "bzl-library-deps"
+	bzlLibraryDepsCategory = "correctness"
+)
+
+// bazelRootMarkers are the files that mark the root of a Bazel workspace,
+// which labels starting with // are relative to.
+var bazelRootMarkers = []string{"MODULE.bazel", "REPO.bazel", "WORKSPACE.bazel", "WORKSPACE"}
+
+// BzlLibraryDepsRule flags bzl_library targets whose .bzl sources load
+// files that none of their deps provide.
+//
+// For each bzl_library in a BUILD file, the rule reads the .bzl files in
+// srcs and resolves their load() labels. A loaded file must be in the srcs
+// of the same target or of a bzl_library listed directly in deps; the
+// providing target is looked up in the BUILD file of the loaded file's
+// package. Loads from external repositories and targets whose srcs or deps
+// are not string lists (such as glob()) are skipped. A loaded file counts
+// as provided if its package has no BUILD file, or if a bzl_library there
+// has such srcs, since the files that target provides are unknown.
+//
+// The rule is opt-in: it reads the BUILD files of other packages, and does
+// not see bzl_library targets that macros declare.
+var BzlLibraryDepsRule = &linter.Rule{
+	Name:      bzlLibraryDepsName,
+	Doc:       "Checks that bzl_library deps cover the load() statements of its srcs",
+	Category:  bzlLibraryDepsCategory,
+	Severity:  linter.SeverityWarning,
+	OptIn:     true,
+	FileKinds: []filekind.Kind{filekind.KindBUILD},
+	Run:       runBzlLibraryDeps,
+}
+
+// bzlLibrary is a bzl_library target of a BUILD file.
+type bzlLibrary struct {
+	call  *build.CallExpr
+	label labels.Label
+	srcs  []labels.Label
+	deps  []labels.Label
+	// depsKnown is false if deps is not a list of string literals, in
+	// which case the target is not checked.
+	depsKnown bool
+}
+
+// bzlPackage is the bzl_library targets of one BUILD file.
+type bzlPackage struct {
+	libs []bzlLibrary
+	// partial is set if a bzl_library has srcs that are not a list of
+	// string literals, such as glob(), so which files the package's
+	// targets provide is unknown.
+	partial bool
+}
+
+func runBzlLibraryDeps(pass *linter.Pass) (any, error) {
+	dir, err := filepath.Abs(filepath.Dir(pass.FilePath))
+	if err != nil {
+		return nil, nil
+	}
+	root := bazelRoot(dir)
+	pkg, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, nil
+	}
+	pkg = filepath.ToSlash(pkg)
+	if pkg == "." {
+		pkg = ""
+	}
+
+	bp := bzlLibraries(pass.File, pkg)
+	index := &bzlLibraryIndex{root: root, packages: map[string]*bzlPackage{pkg: bp}}
+	for _, lib := range bp.libs {
+		if lib.depsKnown {
+			checkBzlLibrary(pass, index, lib)
+		}
+	}
+	return nil, nil
+}
+
+func checkBzlLibrary(pass *linter.Pass, index *bzlLibraryIndex, lib bzlLibrary) {
+	var missing []string
+	for _, src := range lib.srcs {
+		for _, loaded := range bzlLoads(index.root, src) {
+			if slices.Contains(lib.srcs, loaded) {
+				continue
+			}
+			providers, ok := index.providers(loaded)
+			if !ok || slices.ContainsFunc(providers, func(p labels.Label) bool { return slices.Contains(lib.deps, p) }) {
+				continue
+			}
+
+			var msg string
+			if len(providers) == 0 {
+				msg = fmt.Sprintf("%s loads %s, which is not in the srcs of any bzl_library",
+					src.FormatRelative(lib.label.Package), loaded.FormatRelative(lib.label.Package))
+			} else {
+				msg = fmt.Sprintf("%s loads %s; add %s to deps",
+					src.FormatRelative(lib.label.Package), loaded.FormatRelative(lib.label.Package), providers[0].FormatRelative(lib.label.Package))
+			}
+			if !slices.Contains(missing, msg) {
+				missing = append(missing, msg)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	var at build.Expr = lib.call
+	if deps := (&build.Rule{Call: lib.call}).Attr("deps"); deps != nil {
+		at = deps
+	}
+	start, end := at.Span()
+	for _, msg := range missing {
+		pass.Report(linter.Finding{
+			Severity:  linter.SeverityWarning,
+			Message:   fmt.Sprintf("bzl_library %q: %s", lib.label.Target, msg),
+			Line:      start.Line,
+			Column:    start.LineRune,
+			EndLine:   end.Line,
+			EndColumn: end.LineRune,
+			Rule:      bzlLibraryDepsName,
+			Category:  bzlLibraryDepsCategory,
+		})
+	}
+}
+
+// bzlLibraries returns the bzl_library targets declared at the top level
+// of f, the BUILD file of pkg.
+func bzlLibraries(f *build.File, pkg string) *bzlPackage {
+	bp := &bzlPackage{}
+	for _, stmt := range f.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok {
+			continue
+		}
+		rule := &build.Rule{Call: call}
+		if rule.Kind() != "bzl_library" && rule.Kind() != "native.bzl_library" || rule.Name() == "" {
+			continue
+		}
+		srcs, ok := labelList(rule.Attr("srcs"), pkg)
+		if !ok {
+			bp.partial = true
+			continue
+		}
+		deps, depsKnown := labelList(rule.Attr("deps"), pkg)
+		bp.libs = append(bp.libs, bzlLibrary{
+			call:      call,
+			label:     labels.Label{Package: pkg, Target: rule.Name()},
+			srcs:      srcs,
+			deps:      deps,
+			depsKnown: depsKnown,
+		})
+	}
+	return bp
+}
+
+// labelList resolves a list of string labels relative to pkg, reporting
+// false if expr is not a list of string literals. A missing attribute is
+// an empty list.
+func labelList(expr build.Expr, pkg string) ([]labels.Label, bool) {
+	if expr == nil {
+		return nil, true
+	}
+	list, ok := expr.(*build.ListExpr)
+	if !ok {
+		return nil, false
+	}
+	result := make([]labels.Label, 0, len(list.List))
+	for _, item := range list.List {
+		str, ok := item.(*build.StringExpr)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, labels.ParseRelative(str.Value, pkg))
+	}
+	return result, true
+}
+
+// bzlLoads returns the files loaded by the .bzl file src, resolved to
+// labels. Loads from other repositories are left out, as is everything
+// if src cannot be read or parsed.
+func bzlLoads(root string, src labels.Label) []labels.Label {
+	if src.Repository != "" || !strings.HasSuffix(src.Target, ".bzl") {
+		return nil
+	}
+	path := filepath.Join(root, filepath.FromSlash(src.Package), filepath.FromSlash(src.Target))
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	f, err := build.ParseBzl(path, content)
+	if err != nil {
+		return nil
+	}
+
+	var loads []labels.Label
+	for _, stmt := range f.Stmt {
+		load, ok := stmt.(*build.LoadStmt)
+		if !ok {
+			continue
+		}
+		module := load.Module.Value
+		if strings.HasPrefix(module, "@") && !strings.HasPrefix(module, "@//") && !strings.HasPrefix(module, "@@//") {
+			continue
+		}
+		module = strings.TrimLeft(module, "@")
+		loads = append(loads, labels.ParseRelative(module, src.Package))
+	}
+	return loads
+}
+
+// bzlLibraryIndex looks up the bzl_library targets of packages, reading
+// each BUILD file once.
+type bzlLibraryIndex struct {
+	root     string
+	packages map[string]*bzlPackage // nil entry: no BUILD file
+}
+
+// providers returns the bzl_library targets whose srcs contain file,
+// reporting false if they are unknown: file's package has no BUILD file,
+// or one of its bzl_library targets has srcs that are not a string list.
+func (x *bzlLibraryIndex) providers(file labels.Label) ([]labels.Label, bool) {
+	bp, ok := x.packages[file.Package]
+	if !ok {
+		bp = x.readPackage(file.Package)
+		x.packages[file.Package] = bp
+	}
+	if bp == nil || bp.partial {
+		return nil, false
+	}
+
+	var providers []labels.Label
+	for _, lib := range bp.libs {
+		if slices.Contains(lib.srcs, file) {
+			providers = append(providers, lib.label)
+		}
+	}
+	return providers, true
+}
+
+// readPackage parses the BUILD file of pkg, returning nil if there is none
+// or it cannot be parsed.
+func (x *bzlLibraryIndex) readPackage(pkg string) *bzlPackage {
+	dir := filepath.Join(x.root, filepath.FromSlash(pkg))
+	for _, name := range []string{"BUILD.bazel", "BUILD"} {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		f, err := build.ParseBuild(path, content)
+		if err != nil {
+			return nil
+		}
+		return bzlLibraries(f, pkg)
+	}
+	return nil
+}
+
+// bazelRoot returns the nearest directory at or above dir that contains a
+// Bazel workspace marker, or dir itself if there is none. dir must be
+// absolute.
+func bazelRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, marker := range bazelRootMarkers {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				return d
+			}
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package buildtools
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+// writeWorkspace creates files under a new workspace root and returns it.
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	files["MODULE.bazel"] = ""
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func lintBzlLibraryDeps(t *testing.T, path string) []string {
+	t.Helper()
+	registry := linter.NewRegistry()
+	if err := registry.Register(BzlLibraryDepsRule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if registry.IsEnabled(BzlLibraryDepsRule.Name) {
+		t.Errorf("%s should be opt-in", BzlLibraryDepsRule.Name)
+	}
+	registry.Enable(BzlLibraryDepsRule.Name)
+	findings, err := linter.NewDriver(registry).RunFile(path)
+	if err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+	var messages []string
+	for _, f := range findings {
+		messages = append(messages, f.Message)
+	}
+	return messages
+}
+
+func TestBzlLibraryDepsRule(t *testing.T) {
+	root := writeWorkspace(t, map[string]string{
+		"rules/BUILD.bazel": `load("@bazel_skylib//:bzl_library.bzl", "bzl_library")
+
+bzl_library(
+    name = "defs",
+    srcs = ["defs.bzl", "private.bzl"],
+    deps = [":utils"],
+)
+
+bzl_library(
+    name = "utils",
+    srcs = ["utils.bzl"],
+)
+
+bzl_library(
+    name = "missing",
+    srcs = ["missing.bzl"],
+)
+`,
+		"rules/defs.bzl": `load(":private.bzl", "p")
+load(":utils.bzl", "u")
+load("//common:paths.bzl", "paths")
+load("@bazel_skylib//lib:dicts.bzl", "dicts")
+`,
+		"rules/private.bzl": "p = 1\n",
+		"rules/utils.bzl":   "u = 1\n",
+		"rules/missing.bzl": `load(":utils.bzl", "u")
+load(":orphan.bzl", "o")
+load("//nobuild:x.bzl", "x")
+`,
+		"common/BUILD": `bzl_library(
+    name = "paths",
+    srcs = ["paths.bzl"],
+)
+`,
+		"common/paths.bzl": "paths = 1\n",
+		"nobuild/x.bzl":    "x = 1\n",
+	})
+
+	got := lintBzlLibraryDeps(t, filepath.Join(root, "rules", "BUILD.bazel"))
+	want := []string{
+		`bzl_library "defs": :defs.bzl loads //common:paths.bzl; add //common:paths to deps`,
+		`bzl_library "missing": :missing.bzl loads :utils.bzl; add :utils to deps`,
+		`bzl_library "missing": :missing.bzl loads :orphan.bzl, which is not in the srcs of any bzl_library`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findings:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestBzlLibraryDepsRule_NoFindings(t *testing.T) {
+	root := writeWorkspace(t, map[string]string{
+		"BUILD": `bzl_library(
+    name = "defs",
+    srcs = ["defs.bzl"],
+    deps = ["//lib"],
+)
+
+bzl_library(
+    name = "globbed",
+    srcs = glob(["*.bzl"]),
+)
+`,
+		"defs.bzl":      `load("//lib:lib.bzl", "x")` + "\n",
+		"lib/BUILD":     `bzl_library(name = "lib", srcs = ["lib.bzl"])` + "\n",
+		"lib/lib.bzl":   "x = 1\n",
+		"other/BUILD":   "",
+		"other/any.bzl": "",
+	})
+
+	if got := lintBzlLibraryDeps(t, filepath.Join(root, "BUILD")); len(got) != 0 {
+		t.Errorf("expected no findings, got %q", got)
+	}
+}
+
+func TestBzlLibraryDepsRule_UnknownProviders(t *testing.T) {
+	root := writeWorkspace(t, map[string]string{
+		"rules/BUILD": `bzl_library(
+    name = "defs",
+    srcs = ["defs.bzl"],
+)
+
+bzl_library(
+    name = "local",
+    srcs = glob(["local/*.bzl"]),
+)
+`,
+		"rules/defs.bzl": `load("//globbed:lib.bzl", "x")
+load(":local/helpers.bzl", "h")
+load("//mixed:known.bzl", "k")
+load("//computed:computed.bzl", "c")
+`,
+		"rules/local/helpers.bzl": "h = 1\n",
+		"computed/BUILD": `bzl_library(
+    name = "computed",
+    srcs = ["computed.bzl"],
+    deps = DEPS,
+)
+`,
+		"computed/computed.bzl": `load("//nowhere:x.bzl", "x")` + "\n",
+		"globbed/BUILD":         `bzl_library(name = "lib", srcs = glob(["*.bzl"]))` + "\n",
+		"globbed/lib.bzl":       "x = 1\n",
+		"mixed/BUILD": `bzl_library(name = "known", srcs = ["known.bzl"])
+
+bzl_library(name = "rest", srcs = glob(["*.bzl"], exclude = ["known.bzl"]))
+`,
+		"mixed/known.bzl": "k = 1\n",
+	})
+
+	// Packages with a glob()-ed bzl_library may provide any file, so only
+	// the load from a package whose srcs are all known is reported. A
+	// target whose deps are not a list still provides its srcs.
+	got := lintBzlLibraryDeps(t, filepath.Join(root, "rules", "BUILD"))
+	want := []string{
+		`bzl_library "defs": :defs.bzl loads //computed:computed.bzl; add //computed to deps`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findings:\n%q\nwant:\n%q", got, want)
+	}
+
+	// ...but is not checked itself.
+	if got := lintBzlLibraryDeps(t, filepath.Join(root, "computed", "BUILD")); len(got) != 0 {
+		t.Errorf("expected no findings for a target with computed deps, got %q", got)
+	}
+}
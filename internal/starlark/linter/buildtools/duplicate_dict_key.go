@@ -0,0 +1,81 @@
+package buildtools
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+const (
+	duplicateDictKeyName     = "duplicate-dict-key"
+	duplicateDictKeyCategory = "correctness"
+)
+
+// DuplicateDictKeyRule flags dict literals that repeat a constant key.
+// Starlark keeps only the last value for a repeated key, so earlier entries
+// (e.g. a select() branch or an attribute default) are silently dropped.
+var DuplicateDictKeyRule = &linter.Rule{
+	Name:     duplicateDictKeyName,
+	Doc:      "Checks for dict literals with repeated keys",
+	Category: duplicateDictKeyCategory,
+	Severity: linter.SeverityError,
+	Run:      runDuplicateDictKey,
+}
+
+func runDuplicateDictKey(pass *linter.Pass) (any, error) {
+	build.Walk(pass.File, func(expr build.Expr, _ []build.Expr) {
+		dict, ok := expr.(*build.DictExpr)
+		if !ok {
+			return
+		}
+
+		first := make(map[string]build.Position, len(dict.List))
+		for _, kv := range dict.List {
+			key, display, ok := constantKey(kv.Key)
+			if !ok {
+				continue
+			}
+			start, end := kv.Key.Span()
+			prev, seen := first[key]
+			if !seen {
+				first[key] = start
+				continue
+			}
+			pass.Report(linter.Finding{
+				Severity:  linter.SeverityError,
+				Message:   fmt.Sprintf("Duplicate key %s in dict literal (first defined on line %d)", display, prev.Line),
+				Line:      start.Line,
+				Column:    start.LineRune,
+				EndLine:   end.Line,
+				EndColumn: end.LineRune,
+				Rule:      duplicateDictKeyName,
+				Category:  duplicateDictKeyCategory,
+			})
+		}
+	})
+	return nil, nil
+}
+
+// constantKey returns a canonical identity and display form for keys whose
+// value is known statically: strings, numbers, and True/False/None.
+func constantKey(expr build.Expr) (key, display string, ok bool) {
+	switch e := expr.(type) {
+	case *build.StringExpr:
+		return "str:" + e.Value, strconv.Quote(e.Value), true
+	case *build.LiteralExpr:
+		// Canonicalize integer spellings such as 0x10 and 16.
+		if n, err := strconv.ParseInt(e.Token, 0, 64); err == nil {
+			return "int:" + strconv.FormatInt(n, 10), e.Token, true
+		}
+		return "lit:" + e.Token, e.Token, true
+	case *build.Ident:
+		switch e.Name {
+		case "True", "False", "None":
+			return "ident:" + e.Name, e.Name, true
+		}
+	}
+	return "", "", false
+}
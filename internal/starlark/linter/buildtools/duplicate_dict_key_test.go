@@ -0,0 +1,82 @@
+package buildtools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+func lintDuplicateDictKeys(t *testing.T, name, content string) []linter.Finding {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	registry := linter.NewRegistry()
+	if err := registry.Register(DuplicateDictKeyRule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	findings, err := linter.NewDriver(registry).RunFile(path)
+	if err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+	return findings
+}
+
+func TestDuplicateDictKeyRule(t *testing.T) {
+	content := `cc_library(
+    name = "lib",
+    srcs = select({
+        "//conditions:linux": ["linux.c"],
+        "//conditions:default": ["generic.c"],
+        "//conditions:linux": ["other.c"],
+    }),
+)
+
+FLAGS = {1: "a", 0x1: "b", True: "c", True: "d", name: "e", name: "f"}
+`
+	findings := lintDuplicateDictKeys(t, "BUILD.bazel", content)
+
+	type want struct {
+		line, column int
+	}
+	wants := []want{{6, 9}, {10, 18}, {10, 39}}
+	if len(findings) != len(wants) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(wants), findings)
+	}
+	for i, w := range wants {
+		f := findings[i]
+		if f.Line != w.line || f.Column != w.column {
+			t.Errorf("finding %d at %d:%d, want %d:%d (%s)", i, f.Line, f.Column, w.line, w.column, f.Message)
+		}
+		if f.Severity != linter.SeverityError {
+			t.Errorf("finding %d severity = %v, want error", i, f.Severity)
+		}
+		if f.Rule != "duplicate-dict-key" {
+			t.Errorf("finding %d rule = %q", i, f.Rule)
+		}
+	}
+	if got, want := findings[0].Message, `Duplicate key "//conditions:linux" in dict literal (first defined on line 4)`; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestDuplicateDictKeyRule_NoDuplicates(t *testing.T) {
+	content := `X = {"a": 1, "b": 2, k: 3, k2: 4}
+`
+	if findings := lintDuplicateDictKeys(t, "defs.bzl", content); len(findings) != 0 {
+		t.Errorf("expected no findings, got %+v", findings)
+	}
+}
+
+func TestAllRules_IncludesDuplicateDictKey(t *testing.T) {
+	for _, rule := range AllRules() {
+		if rule.Name == "duplicate-dict-key" {
+			return
+		}
+	}
+	t.Fatal("AllRules() does not include duplicate-dict-key")
+}
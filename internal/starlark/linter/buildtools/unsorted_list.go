@@ -0,0 +1,350 @@
+package buildtools
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+const (
+	unsortedListName     = "unsorted-list"
+	unsortedListCategory = "style"
+
+	// unsortedListAttributesOption names the rule option listing the
+	// attributes whose string lists must be sorted.
+	unsortedListAttributesOption = "attributes"
+)
+
+// defaultSortedAttributes are checked when the "attributes" option is unset.
+var defaultSortedAttributes = []string{"data", "deps", "srcs", "visibility"}
+
+// itemTail matches what may follow a list item on its own line: an optional
+// comma and an optional comment.
+var itemTail = regexp.MustCompile(`^([ \t]*,)?([ \t]*#[^\n]*)?[ \t]*$`)
+
+// UnsortedListRule flags string lists in attributes such as deps and srcs
+// that are not sorted, and sorts them as a fix.
+//
+// Items are ordered as buildifier orders them: local labels (":x") first,
+// then absolute labels ("//x"), then external labels ("@x"), with everything
+// else before all three. A comment line or blank line splits the list into
+// blocks that are sorted separately, and a "do not sort" comment above the
+// list or its first item skips the list. Comments at the end of an item's
+// line move with the item.
+var UnsortedListRule = &linter.Rule{
+	Name:      unsortedListName,
+	Doc:       "Checks that string lists in attributes such as deps and srcs are sorted",
+	Category:  unsortedListCategory,
+	Severity:  linter.SeverityWarning,
+	AutoFix:   true,
+	FileKinds: []filekind.Kind{filekind.KindBUILD, filekind.KindBUCK},
+	Run:       runUnsortedList,
+}
+
+func runUnsortedList(pass *linter.Pass) (any, error) {
+	attrs, err := sortedAttributes(pass.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	build.Walk(pass.File, func(expr build.Expr, _ []build.Expr) {
+		call, ok := expr.(*build.CallExpr)
+		if !ok {
+			return
+		}
+		for _, arg := range call.List {
+			assign, ok := arg.(*build.AssignExpr)
+			if !ok {
+				continue
+			}
+			key, ok := assign.LHS.(*build.Ident)
+			if !ok || !attrs[key.Name] || doNotSort(assign) {
+				continue
+			}
+			for _, list := range stringLists(assign.RHS) {
+				checkSortedList(pass, key.Name, list)
+			}
+		}
+	})
+	return nil, nil
+}
+
+// sortedAttributes returns the attribute names from the "attributes" option,
+// or the defaults when it is unset.
+func sortedAttributes(config linter.RuleConfig) (map[string]bool, error) {
+	names, err := stringListOption(config, unsortedListAttributesOption, defaultSortedAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]bool, len(names))
+	for _, name := range names {
+		attrs[name] = true
+	}
+	return attrs, nil
+}
+
+// stringListOption returns the list of strings in the named rule option, or
+// defaults when it is unset. Options decoded from JSON arrive as []any.
+func stringListOption(config linter.RuleConfig, option string, defaults []string) ([]string, error) {
+	raw, ok := config.Options[option]
+	if !ok {
+		return defaults, nil
+	}
+	switch v := raw.(type) {
+	case []string:
+		return v, nil
+	case []any:
+		names := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("option %q must be a list of strings", option)
+			}
+			names = append(names, s)
+		}
+		return names, nil
+	default:
+		return nil, fmt.Errorf("option %q must be a list of strings", option)
+	}
+}
+
+// stringLists returns the list literals that make up an attribute value,
+// looking through "+" concatenations and select() branches.
+func stringLists(expr build.Expr) []*build.ListExpr {
+	switch e := expr.(type) {
+	case *build.ListExpr:
+		return []*build.ListExpr{e}
+	case *build.BinaryExpr:
+		if e.Op != "+" {
+			return nil
+		}
+		return append(stringLists(e.X), stringLists(e.Y)...)
+	case *build.CallExpr:
+		ident, ok := e.X.(*build.Ident)
+		if !ok || ident.Name != "select" || len(e.List) == 0 {
+			return nil
+		}
+		dict, ok := e.List[0].(*build.DictExpr)
+		if !ok {
+			return nil
+		}
+		var lists []*build.ListExpr
+		for _, kv := range dict.List {
+			lists = append(lists, stringLists(kv.Value)...)
+		}
+		return lists
+	}
+	return nil
+}
+
+// listItem is an item of a list, or an argument of a call, together with
+// the source text around it that a fix must keep or move.
+type listItem struct {
+	expr build.Expr
+	// start and end delimit the item itself.
+	start, end int
+	// slotEnd is where the item's line ends when the item is the last thing
+	// on its line, and end otherwise.
+	slotEnd int
+	// ownLine reports whether the item ends its line, so that a trailing
+	// comment may follow it.
+	ownLine bool
+	// comma reports whether a comma follows the item on its own line.
+	comma bool
+	// comment is the trailing comment, with its leading whitespace.
+	comment string
+}
+
+func checkSortedList(pass *linter.Pass, attr string, list *build.ListExpr) {
+	if len(list.List) < 2 || doNotSort(list) || doNotSort(list.List[0]) {
+		return
+	}
+
+	for _, block := range sortBlocks(pass.Content, list) {
+		order := slices.Clone(block)
+		slices.SortStableFunc(order, func(a, b listItem) int {
+			return compareListValues(a.expr.(*build.StringExpr).Value, b.expr.(*build.StringExpr).Value)
+		})
+		if sameOrder(block, order) {
+			continue
+		}
+
+		startPos, _ := block[0].expr.Span()
+		_, endPos := block[len(block)-1].expr.Span()
+		pass.Report(linter.Finding{
+			Severity:    linter.SeverityWarning,
+			Message:     fmt.Sprintf("Elements of %q are not sorted", attr),
+			Line:        startPos.Line,
+			Column:      startPos.LineRune,
+			EndLine:     endPos.Line,
+			EndColumn:   endPos.LineRune,
+			Rule:        unsortedListName,
+			Category:    unsortedListCategory,
+			Replacement: sortReplacement(pass.Content, block, order),
+		})
+	}
+}
+
+// sortBlocks splits a list into runs of string items that are sorted
+// independently. A run ends at a non-string item, at an item preceded by a
+// comment line, and at a blank line.
+func sortBlocks(content []byte, list *build.ListExpr) [][]listItem {
+	var blocks [][]listItem
+	var current []listItem
+	flush := func() {
+		if len(current) > 1 {
+			blocks = append(blocks, current)
+		}
+		current = nil
+	}
+
+	for i, expr := range list.List {
+		str, ok := expr.(*build.StringExpr)
+		if !ok {
+			flush()
+			continue
+		}
+		item, ok := newListItem(content, list.List, list.End.Pos.Byte, i)
+		if !ok {
+			// Positions do not match the content; leave the list alone.
+			return nil
+		}
+		if len(str.Comments.Before) > 0 {
+			flush()
+		} else if n := len(current); n > 0 && hasBlankLine(content[current[n-1].end:item.start]) {
+			flush()
+		}
+		current = append(current, item)
+	}
+	flush()
+	return blocks
+}
+
+// newListItem returns items[i] of a list or call whose closing bracket is
+// at byte offset closing.
+func newListItem(content []byte, items []build.Expr, closing int, i int) (listItem, bool) {
+	expr := items[i]
+	startPos, endPos := expr.Span()
+	item := listItem{expr: expr, start: startPos.Byte, end: endPos.Byte, slotEnd: endPos.Byte}
+	if item.start < 0 || item.end > len(content) || item.start >= item.end {
+		return listItem{}, false
+	}
+
+	lineEnd := len(content)
+	if j := bytes.IndexByte(content[item.end:], '\n'); j >= 0 {
+		lineEnd = item.end + j
+	}
+	nextOnLine := false
+	if i+1 < len(items) {
+		nextStart, _ := items[i+1].Span()
+		nextOnLine = nextStart.Byte < lineEnd
+	}
+	if !nextOnLine && closing > lineEnd {
+		if m := itemTail.FindSubmatch(content[item.end:lineEnd]); m != nil {
+			item.ownLine = true
+			item.slotEnd = lineEnd
+			item.comma = len(m[1]) > 0
+			item.comment = string(m[2])
+		}
+	}
+	return item, true
+}
+
+// sameOrder reports whether block and order hold the same items in the
+// same order.
+func sameOrder(block, order []listItem) bool {
+	return slices.EqualFunc(block, order, func(a, b listItem) bool { return a.expr == b.expr })
+}
+
+// sortReplacement rewrites block so that its items appear in order. Each
+// item keeps its trailing comment, while commas and the whitespace between
+// items stay in place. Returns nil if a commented item would have to move
+// onto a line shared with other items.
+func sortReplacement(content []byte, block, order []listItem) *linter.Replacement {
+	var out strings.Builder
+	for i, slot := range block {
+		item := order[i]
+		out.Write(content[item.start:item.end])
+		if slot.ownLine {
+			if slot.comma {
+				out.WriteByte(',')
+			}
+			out.WriteString(item.comment)
+		} else if item.comment != "" {
+			return nil
+		}
+		if i+1 < len(block) {
+			out.Write(content[slot.slotEnd:block[i+1].start])
+		}
+	}
+	return &linter.Replacement{
+		Content: out.String(),
+		Start:   block[0].start,
+		End:     block[len(block)-1].slotEnd,
+	}
+}
+
+// compareListValues orders list items as buildifier does: by phase (plain
+// strings, then ":local", "//absolute", and "@external" labels), then by
+// the parts of the value split at '.' and ':', then by the value itself.
+func compareListValues(a, b string) int {
+	if pa, pb := listValuePhase(a), listValuePhase(b); pa != pb {
+		return pa - pb
+	}
+	sa := strings.Split(strings.ReplaceAll(a, ":", "."), ".")
+	sb := strings.Split(strings.ReplaceAll(b, ":", "."), ".")
+	for k := 0; k < len(sa) && k < len(sb); k++ {
+		if c := strings.Compare(sa[k], sb[k]); c != 0 {
+			return c
+		}
+	}
+	if len(sa) != len(sb) {
+		return len(sa) - len(sb)
+	}
+	return strings.Compare(a, b)
+}
+
+func listValuePhase(value string) int {
+	switch {
+	case strings.HasPrefix(value, ":"):
+		return 1
+	case strings.HasPrefix(value, "//"):
+		return 2
+	case strings.HasPrefix(value, "@"):
+		return 3
+	}
+	return 0
+}
+
+// doNotSort reports whether expr is preceded by a "do not sort" comment.
+func doNotSort(expr build.Expr) bool {
+	for _, c := range expr.Comment().Before {
+		if strings.Contains(strings.ToLower(c.Token), "do not sort") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBlankLine reports whether the text between two items contains an empty
+// line.
+func hasBlankLine(between []byte) bool {
+	lines := bytes.Split(between, []byte("\n"))
+	if len(lines) < 3 {
+		return false
+	}
+	for _, line := range lines[1 : len(lines)-1] {
+		if len(bytes.TrimSpace(line)) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package buildtools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+func lintUnsortedLists(t *testing.T, name, content string, options map[string]any) []linter.Finding {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	registry := linter.NewRegistry()
+	if err := registry.Register(UnsortedListRule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if options != nil {
+		if err := registry.SetConfig(UnsortedListRule.Name, linter.RuleConfig{Options: options}); err != nil {
+			t.Fatalf("SetConfig() error = %v", err)
+		}
+	}
+	findings, err := linter.NewDriver(registry).RunFile(path)
+	if err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+	return findings
+}
+
+func fixUnsortedLists(t *testing.T, content string, findings []linter.Finding) string {
+	t.Helper()
+	var fixes []*linter.Replacement
+	for _, f := range findings {
+		if f.Replacement == nil {
+			t.Fatalf("finding at %d:%d has no fix", f.Line, f.Column)
+		}
+		fixes = append(fixes, f.Replacement)
+	}
+	fixed, applied, _ := linter.ApplyFixes([]byte(content), fixes)
+	if applied != len(fixes) {
+		t.Fatalf("applied %d of %d fixes", applied, len(fixes))
+	}
+	return string(fixed)
+}
+
+func TestUnsortedListRule_Fix(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "single line",
+			content: `cc_library(name = "lib", srcs = ["b.cc", "a.cc"])
+`,
+			want: `cc_library(name = "lib", srcs = ["a.cc", "b.cc"])
+`,
+		},
+		{
+			name: "labels in buildifier order",
+			content: `cc_library(
+    name = "lib",
+    deps = [
+        "@zlib//:z",
+        "//base",
+        ":util",
+        "//base:strings",
+    ],
+)
+`,
+			want: `cc_library(
+    name = "lib",
+    deps = [
+        ":util",
+        "//base",
+        "//base:strings",
+        "@zlib//:z",
+    ],
+)
+`,
+		},
+		{
+			name: "trailing comments move with items",
+			content: `cc_library(
+    name = "lib",
+    srcs = [
+        "c.cc",  # generated
+        "a.cc",
+        "b.cc"  # keep
+    ],
+)
+`,
+			want: `cc_library(
+    name = "lib",
+    srcs = [
+        "a.cc",
+        "b.cc",  # keep
+        "c.cc"  # generated
+    ],
+)
+`,
+		},
+		{
+			name: "comment lines and blank lines split blocks",
+			content: `cc_library(
+    name = "lib",
+    srcs = [
+        "b.cc",
+        "a.cc",
+
+        "d.cc",
+        "c.cc",
+        # Platform sources.
+        "f.cc",
+        "e.cc",
+    ],
+)
+`,
+			want: `cc_library(
+    name = "lib",
+    srcs = [
+        "a.cc",
+        "b.cc",
+
+        "c.cc",
+        "d.cc",
+        # Platform sources.
+        "e.cc",
+        "f.cc",
+    ],
+)
+`,
+		},
+		{
+			name: "concatenation and select",
+			content: `cc_library(
+    name = "lib",
+    deps = ["b", "a"] + select({
+        "//conditions:default": ["d", "c"],
+    }),
+)
+`,
+			want: `cc_library(
+    name = "lib",
+    deps = ["a", "b"] + select({
+        "//conditions:default": ["c", "d"],
+    }),
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := lintUnsortedLists(t, "BUILD.bazel", tt.content, nil)
+			if len(findings) == 0 {
+				t.Fatal("expected findings")
+			}
+			for _, f := range findings {
+				if f.Rule != "unsorted-list" || f.Severity != linter.SeverityWarning {
+					t.Errorf("unexpected finding %+v", f)
+				}
+			}
+			if got := fixUnsortedLists(t, tt.content, findings); got != tt.want {
+				t.Errorf("fixed content:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsortedListRule_NoFindings(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "sorted",
+			file:    "BUILD.bazel",
+			content: `cc_library(name = "lib", srcs = ["a.cc", "b.cc"], deps = [":a", "//b", "@c"])` + "\n",
+		},
+		{
+			name:    "other attribute",
+			file:    "BUILD.bazel",
+			content: `cc_library(name = "lib", copts = ["-b", "-a"])` + "\n",
+		},
+		{
+			name: "do not sort",
+			file: "BUILD.bazel",
+			content: `cc_test(
+    name = "test",
+    deps = [
+        # do not sort: link order matters
+        "b",
+        "a",
+    ],
+)
+`,
+		},
+		{
+			name:    "bzl file",
+			file:    "defs.bzl",
+			content: `def m():` + "\n" + `    native.cc_library(name = "lib", srcs = ["b.cc", "a.cc"])` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if findings := lintUnsortedLists(t, tt.file, tt.content, nil); len(findings) != 0 {
+				t.Errorf("expected no findings, got %+v", findings)
+			}
+		})
+	}
+}
+
+func TestUnsortedListRule_Finding(t *testing.T) {
+	content := `cc_library(
+    name = "lib",
+    deps = [
+        "b",
+        "a",
+    ],
+)
+`
+	findings := lintUnsortedLists(t, "BUILD", content, nil)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Line != 4 || f.Column != 9 || f.EndLine != 5 || f.EndColumn != 12 {
+		t.Errorf("finding at %d:%d-%d:%d, want 4:9-5:12", f.Line, f.Column, f.EndLine, f.EndColumn)
+	}
+	if want := `Elements of "deps" are not sorted`; f.Message != want {
+		t.Errorf("message = %q, want %q", f.Message, want)
+	}
+}
+
+func TestUnsortedListRule_AttributesOption(t *testing.T) {
+	content := `cc_library(name = "lib", srcs = ["b.cc", "a.cc"], copts = ["-b", "-a"])
+`
+	findings := lintUnsortedLists(t, "BUILD.bazel", content, map[string]any{
+		"attributes": []any{"copts"},
+	})
+	if len(findings) != 1 || findings[0].Message != `Elements of "copts" are not sorted` {
+		t.Fatalf("expected one copts finding, got %+v", findings)
+	}
+
+	registry := linter.NewRegistry()
+	if err := registry.Register(UnsortedListRule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.SetConfig(UnsortedListRule.Name, linter.RuleConfig{Options: map[string]any{"attributes": "copts"}}); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "BUILD")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := linter.NewDriver(registry).RunFile(path); err == nil {
+		t.Fatal("expected an error for a non-list attributes option")
+	}
+}
@@ -0,0 +1,181 @@
+package buildtools
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/warn"
+
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+// trailingArgRest matches what may follow a load argument on its own line:
+// an optional comma and an optional comment.
+var trailingArgRest = regexp.MustCompile(`^[ \t]*,?[ \t]*(#[^\n]*)?$`)
+
+// unusedLoadReplacement turns the fix attached by the buildtools "load"
+// warning into a minimal edit of the original source.
+//
+// buildtools replaces the whole load statement with a reformatted copy,
+// which rewrites quoting, ordering and line breaks. Instead, this removes
+// only the dropped symbols (plain or aliased) and keeps everything else
+// byte-for-byte. When no symbols remain, the statement and its line are
+// deleted. Returns nil if the finding carries no load fix or the edit
+// cannot be computed safely, in which case the buildtools fix is kept.
+func unusedLoadReplacement(content []byte, f *warn.LinterFinding) *linter.Replacement {
+	if len(f.Replacement) == 0 || f.Replacement[0].Old == nil {
+		return nil
+	}
+	old, ok := (*f.Replacement[0].Old).(*build.LoadStmt)
+	if !ok {
+		return nil
+	}
+	start, end := old.Span()
+	if start.Byte < 0 || end.Byte > len(content) || start.Byte >= end.Byte {
+		return nil
+	}
+
+	updated, _ := f.Replacement[0].New.(*build.LoadStmt)
+	if updated == nil || len(updated.To) == 0 {
+		return deleteLineReplacement(content, start.Byte, end.Byte)
+	}
+
+	// buildtools copies the Ident pointers of the kept symbols, so identity
+	// distinguishes a duplicate load of the same name from the original.
+	kept := make(map[*build.Ident]bool, len(updated.To))
+	for _, to := range updated.To {
+		kept[to] = true
+	}
+
+	// Segment 0 is the module label; segment i+1 is load argument i.
+	modStart, modEnd := old.Module.Span()
+	segments := [][2]int{{modStart.Byte, modEnd.Byte}}
+	for i := range old.To {
+		s, e, ok := loadArgSpan(content, old.From[i], old.To[i])
+		if !ok {
+			return nil
+		}
+		segments = append(segments, [2]int{s, e})
+	}
+
+	var cuts [][2]int
+	for i, to := range old.To {
+		if kept[to] {
+			continue
+		}
+		seg := segments[i+1]
+		lineStart := bytes.LastIndexByte(content[:seg[0]], '\n') + 1
+		lineEnd := len(content)
+		if j := bytes.IndexByte(content[seg[1]:], '\n'); j >= 0 {
+			lineEnd = seg[1] + j
+		}
+		ownLine := len(bytes.TrimSpace(content[lineStart:seg[0]])) == 0 &&
+			trailingArgRest.Match(content[seg[1]:lineEnd]) &&
+			lineStart > start.Byte && lineEnd < end.Byte
+		if ownLine {
+			// Multi-line load: drop the argument's whole line, including
+			// its trailing comma and comment.
+			cuts = append(cuts, [2]int{lineStart, lineEnd + 1})
+		} else {
+			// Compact load: drop the argument and the separator before it.
+			cuts = append(cuts, [2]int{segments[i][1], seg[1]})
+		}
+	}
+
+	var out []byte
+	pos := start.Byte
+	for _, c := range mergeSpans(cuts) {
+		out = append(out, content[pos:c[0]]...)
+		pos = c[1]
+	}
+	out = append(out, content[pos:end.Byte]...)
+
+	if !keepsSymbols(out, updated) {
+		return nil
+	}
+	return &linter.Replacement{
+		Content: string(out),
+		Start:   start.Byte,
+		End:     end.Byte,
+	}
+}
+
+// loadArgSpan returns the byte range of a load argument, covering the alias
+// (if any) and the quoted symbol name.
+func loadArgSpan(content []byte, from, to *build.Ident) (int, int, bool) {
+	// from.NamePos points just past the opening quote(s).
+	quotes := 1
+	nameStart := from.NamePos.Byte
+	if nameStart >= 3 && isTripleQuote(content[nameStart-3:nameStart]) {
+		quotes = 3
+	}
+	start := nameStart - quotes
+	end := nameStart + len(from.Name) + quotes
+	if start < 0 || end > len(content) {
+		return 0, 0, false
+	}
+	if to.NamePos.Byte < start {
+		start = to.NamePos.Byte
+	}
+	return start, end, true
+}
+
+func isTripleQuote(b []byte) bool {
+	return string(b) == `"""` || string(b) == `'''`
+}
+
+// deleteLineReplacement removes [start, end) and, when the statement is the
+// only thing on its line, the surrounding indentation and line break.
+func deleteLineReplacement(content []byte, start, end int) *linter.Replacement {
+	lineStart := bytes.LastIndexByte(content[:start], '\n') + 1
+	if len(bytes.TrimSpace(content[lineStart:start])) == 0 {
+		start = lineStart
+	}
+	rest := end
+	for rest < len(content) && (content[rest] == ' ' || content[rest] == '\t') {
+		rest++
+	}
+	if rest == len(content) || content[rest] == '\n' {
+		end = min(rest+1, len(content))
+	}
+	return &linter.Replacement{Start: start, End: end}
+}
+
+// mergeSpans sorts [start, end) ranges and unions overlapping or adjacent ones.
+func mergeSpans(spans [][2]int) [][2]int {
+	slices.SortFunc(spans, func(a, b [2]int) int { return a[0] - b[0] })
+
+	var merged [][2]int
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], s[1])
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// keepsSymbols reports whether stmt parses as a single load statement that
+// binds exactly the symbols buildtools decided to keep.
+func keepsSymbols(stmt []byte, want *build.LoadStmt) bool {
+	f, err := build.ParseBzl("", stmt)
+	if err != nil || len(f.Stmt) != 1 {
+		return false
+	}
+	got, ok := f.Stmt[0].(*build.LoadStmt)
+	if !ok || len(got.To) != len(want.To) || got.Module.Value != want.Module.Value {
+		return false
+	}
+	names := func(l *build.LoadStmt) []string {
+		var out []string
+		for i := range l.To {
+			out = append(out, l.To[i].Name+"="+l.From[i].Name)
+		}
+		slices.Sort(out)
+		return out
+	}
+	return slices.Equal(names(got), names(want))
+}
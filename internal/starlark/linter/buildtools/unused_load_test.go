@@ -0,0 +1,118 @@
+package buildtools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/linter"
+)
+
+func TestUnusedLoadFix(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "compact",
+			input: "load(\"//a.bzl\", \"used\", \"unused\")\n\nused()\n",
+			want:  "load(\"//a.bzl\", \"used\")\n\nused()\n",
+		},
+		{
+			name:  "first symbol unused",
+			input: "load('//a.bzl', 'unused', 'used')\n\nused()\n",
+			want:  "load('//a.bzl', 'used')\n\nused()\n",
+		},
+		{
+			name:  "aliased",
+			input: "load(\"//a.bzl\", local = \"orig\", \"used\")\n\nused()\n",
+			want:  "load(\"//a.bzl\", \"used\")\n\nused()\n",
+		},
+		{
+			name: "multi-line keeps order and comments",
+			input: `load(
+    "//a.bzl",
+    "zeta",  # keep me
+    "unused",  # goes away
+    alias = "orig",
+    "alpha",
+)
+
+zeta()
+alpha()
+`,
+			want: `load(
+    "//a.bzl",
+    "zeta",  # keep me
+    "alpha",
+)
+
+zeta()
+alpha()
+`,
+		},
+		{
+			name:  "empty load is deleted",
+			input: "load(\"//a.bzl\", \"used\")\nload(\"//b.bzl\", \"x\", y = \"z\")\n\nused()\n",
+			want:  "load(\"//a.bzl\", \"used\")\n\nused()\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "BUILD.bazel")
+			if err := os.WriteFile(path, []byte(tc.input), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			registry := linter.NewRegistry()
+			if err := registry.Register(wrapFileWarning("load")); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			findings, err := linter.NewDriver(registry).RunFile(path)
+			if err != nil {
+				t.Fatalf("RunFile() error = %v", err)
+			}
+			if linter.FixableCount(findings) == 0 {
+				t.Fatalf("expected a fixable finding, got %+v", findings)
+			}
+
+			results, err := linter.FixFiles(findings)
+			if err != nil {
+				t.Fatalf("FixFiles() error = %v", err)
+			}
+			if err := linter.WriteFixResults(results); err != nil {
+				t.Fatalf("WriteFixResults() error = %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read fixed file: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("fixed content mismatch\ngot:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnusedLoadFix_UnusedComment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "defs.bzl")
+	content := "load(\"//a.bzl\", \"kept\")  # @unused\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	registry := linter.NewRegistry()
+	if err := registry.Register(wrapFileWarning("load")); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	findings, err := linter.NewDriver(registry).RunFile(path)
+	if err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected @unused to suppress the warning, got %+v", findings)
+	}
+}
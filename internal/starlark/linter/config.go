@@ -10,6 +10,10 @@ import (
 
 // Config represents the skylint configuration file structure.
 type Config struct {
+	// Comment is free text for humans; JSON has no comments, so the "//" key
+	// is used by convention. It is ignored when the config is applied.
+	Comment string `json:"//,omitempty"`
+
 	// Enable is a list of rules or categories to enable (e.g., ["all"], ["correctness"])
 	Enable []string `json:"enable,omitempty"`
 
@@ -25,6 +29,13 @@ type Config struct {
 
 // RuleConfigOverride allows overriding rule-specific settings.
 type RuleConfigOverride struct {
+	// Comment is free text for humans, ignored when the config is applied.
+	Comment string `json:"//,omitempty"`
+
+	// Enabled turns the rule on or off. It is applied after Enable and
+	// Disable. If nil, the rule keeps the state they give it.
+	Enabled *bool `json:"enabled,omitempty"`
+
 	// Severity overrides the default severity for this rule
 	Severity string `json:"severity,omitempty"`
 
@@ -113,6 +124,14 @@ func (c *Config) ApplyToRegistry(registry *Registry) error {
 			return fmt.Errorf("unknown rule in config: %s", ruleName)
 		}
 
+		if override.Enabled != nil {
+			if *override.Enabled {
+				registry.Enable(ruleName)
+			} else {
+				registry.Disable(ruleName)
+			}
+		}
+
 		// Parse severity override
 		var severity Severity
 		if override.Severity != "" {
@@ -135,6 +154,29 @@ func (c *Config) ApplyToRegistry(registry *Registry) error {
 	return nil
 }
 
+// GenerateConfig returns a config that lists every rule in registry with
+// its current enabled state and default severity, with the rule's category
+// and description as a comment, as a complete starting point for users.
+func GenerateConfig(registry *Registry) *Config {
+	config := &Config{
+		Comment: "skylint configuration. Set \"enabled\" to turn a rule on or off and \"severity\" to one of error, warning, info, hint. Run skylint --explain=RULE for details.",
+		Rules:   make(map[string]RuleConfigOverride),
+	}
+	for _, rule := range registry.AllRules() {
+		enabled := registry.IsEnabled(rule.Name)
+		comment := rule.Doc
+		if rule.Category != "" {
+			comment = rule.Category + ": " + rule.Doc
+		}
+		config.Rules[rule.Name] = RuleConfigOverride{
+			Comment:  comment,
+			Enabled:  &enabled,
+			Severity: rule.Severity.String(),
+		}
+	}
+	return config
+}
+
 // parseSeverity converts a string to a Severity value.
 func parseSeverity(s string) (Severity, error) {
 	switch s {
@@ -1,6 +1,7 @@
 package linter
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -305,3 +306,58 @@ func TestConfig_ApplyToRegistry(t *testing.T) {
 	// For now, we test the logic through the main binary's integration tests.
 	t.Skip("Requires Registry implementation - tested via integration tests")
 }
+
+// TestGenerateConfig verifies the generated config lists every rule and
+// round-trips through LoadConfig and ApplyToRegistry.
+func TestGenerateConfig(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(
+		&Rule{Name: "style-a", Category: "style", Doc: "Checks style.", Severity: SeverityWarning},
+		&Rule{Name: "docs-a", Doc: "Checks docs.", Severity: SeverityInfo},
+	); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	registry.Disable("docs-a")
+
+	config := GenerateConfig(registry)
+	if len(config.Rules) != 2 {
+		t.Fatalf("Rules: got %d, want 2", len(config.Rules))
+	}
+	styleA := config.Rules["style-a"]
+	if styleA.Enabled == nil || !*styleA.Enabled || styleA.Severity != "warning" || styleA.Comment != "style: Checks style." {
+		t.Errorf("style-a: got %+v", styleA)
+	}
+	docsA := config.Rules["docs-a"]
+	if docsA.Enabled == nil || *docsA.Enabled || docsA.Comment != "Checks docs." {
+		t.Errorf("docs-a: got %+v", docsA)
+	}
+
+	// Toggle a rule in the generated file and load it back
+	*styleA.Enabled = false
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), ".skylint.json")
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	loaded, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	fresh := NewRegistry()
+	if err := fresh.Register(
+		&Rule{Name: "style-a", Category: "style", Severity: SeverityWarning},
+		&Rule{Name: "docs-a", Severity: SeverityInfo},
+	); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := loaded.ApplyToRegistry(fresh); err != nil {
+		t.Fatalf("ApplyToRegistry failed: %v", err)
+	}
+	if fresh.IsEnabled("style-a") || fresh.IsEnabled("docs-a") {
+		t.Errorf("expected both rules disabled, got style-a=%v docs-a=%v", fresh.IsEnabled("style-a"), fresh.IsEnabled("docs-a"))
+	}
+}
@@ -11,22 +11,30 @@ import (
 
 	"github.com/albertocavalcante/sky/internal/starlark/classifier"
 	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+	"github.com/albertocavalcante/sky/internal/starlark/pathmatch"
 )
 
 // Driver executes lint rules on files.
 type Driver struct {
 	registry   *Registry
 	classifier classifier.Classifier
+	excludes   pathmatch.Excludes
 }
 
 // NewDriver creates a new driver with the given registry.
 func NewDriver(registry *Registry) *Driver {
 	return &Driver{
 		registry:   registry,
-		classifier: classifier.NewDefaultClassifier(),
+		classifier: classifier.New(),
 	}
 }
 
+// SetExcludes sets patterns for paths to skip when walking directories.
+// Files named explicitly are always linted.
+func (d *Driver) SetExcludes(excludes pathmatch.Excludes) {
+	d.excludes = excludes
+}
+
 // Run executes all enabled rules on the specified files and returns the results.
 // The files parameter can include individual files or directories (which will be walked).
 func (d *Driver) Run(ctx context.Context, paths []string) (*Result, error) {
@@ -37,9 +45,10 @@ func (d *Driver) Run(ctx context.Context, paths []string) (*Result, error) {
 	}
 
 	result := &Result{
-		Files:    len(files),
-		Findings: []Finding{},
-		Errors:   []FileError{},
+		Files:            len(files),
+		Findings:         []Finding{},
+		Errors:           []FileError{},
+		InvalidOverrides: classifier.InvalidOverrides(files),
 	}
 
 	// Process each file
@@ -73,7 +82,13 @@ func (d *Driver) RunFile(path string) ([]Finding, error) {
 	if err != nil {
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
+	return d.RunContent(path, content)
+}
 
+// RunContent executes all enabled rules on content as the file at path,
+// without reading the file. Path determines the file kind and is reported
+// in findings. This lets editors lint unsaved buffers.
+func (d *Driver) RunContent(path string, content []byte) ([]Finding, error) {
 	// Classify the file to determine its kind
 	classification, err := d.classifier.Classify(path)
 	if err != nil {
@@ -82,9 +97,15 @@ func (d *Driver) RunFile(path string) ([]Finding, error) {
 			FileKind: filekind.KindStarlark,
 		}
 	}
+	return d.RunContentKind(path, content, classification.FileKind)
+}
 
+// RunContentKind is like RunContent but lints the content as a file of the
+// given kind rather than classifying path, for callers that have already
+// classified it. The kind selects the parser and the applicable rules.
+func (d *Driver) RunContentKind(path string, content []byte, kind filekind.Kind) ([]Finding, error) {
 	// Parse the file
-	file, err := parseFile(content, path, classification.FileKind)
+	file, err := parseFile(content, path, kind)
 	if err != nil {
 		return nil, fmt.Errorf("parsing file: %w", err)
 	}
@@ -101,7 +122,7 @@ func (d *Driver) RunFile(path string) ([]Finding, error) {
 	// Filter rules by file kind
 	var applicableRules []*Rule
 	for _, rule := range rules {
-		if d.isApplicable(rule, classification.FileKind) {
+		if d.isApplicable(rule, kind) {
 			applicableRules = append(applicableRules, rule)
 		}
 	}
@@ -118,7 +139,7 @@ func (d *Driver) RunFile(path string) ([]Finding, error) {
 		pass := &Pass{
 			File:     file,
 			FilePath: path,
-			FileKind: classification.FileKind,
+			FileKind: kind,
 			Content:  content,
 			Config:   config,
 			Report: func(f Finding) {
@@ -217,6 +238,14 @@ func (d *Driver) expandPath(path string) ([]string, error) {
 			return err
 		}
 
+		// Skip excluded paths
+		if d.excludes.MatchIn(path, p) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Skip hidden directories
 		if entry.IsDir() && strings.HasPrefix(entry.Name(), ".") && entry.Name() != "." {
 			return filepath.SkipDir
@@ -228,7 +257,7 @@ func (d *Driver) expandPath(path string) ([]string, error) {
 		}
 
 		// Check if it's a Starlark file
-		if filekind.IsStarlarkFile(entry.Name()) {
+		if classifier.IsStarlarkPath(p) {
 			files = append(files, p)
 		}
 
@@ -0,0 +1,98 @@
+package linter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+// TestDriver_RunContent verifies that content is linted as the file at path
+// without the file existing on disk.
+func TestDriver_RunContent(t *testing.T) {
+	var kind filekind.Kind
+	rule := &Rule{
+		Name:     "no-assignments",
+		Severity: SeverityWarning,
+		Run: func(pass *Pass) (any, error) {
+			kind = pass.FileKind
+			for _, stmt := range pass.File.Stmt {
+				if assign, ok := stmt.(*build.AssignExpr); ok {
+					start, _ := assign.Span()
+					pass.Report(Finding{Rule: "no-assignments", Message: "assignment", Line: start.Line})
+				}
+			}
+			return nil, nil
+		},
+	}
+	registry := NewRegistry()
+	if err := registry.Register(rule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pkg", "BUILD.bazel")
+	content := "a = 1\nb = 2  # skylint: disable=no-assignments\nc = 3\n"
+	findings, err := NewDriver(registry).RunContent(path, []byte(content))
+	if err != nil {
+		t.Fatalf("RunContent() error = %v", err)
+	}
+
+	if kind != filekind.KindBUILD {
+		t.Errorf("file kind = %v, want %v", kind, filekind.KindBUILD)
+	}
+	var lines []int
+	for _, f := range findings {
+		if f.FilePath != path {
+			t.Errorf("finding path = %q, want %q", f.FilePath, path)
+		}
+		lines = append(lines, f.Line)
+	}
+	if len(lines) != 2 || lines[0] != 1 || lines[1] != 3 {
+		t.Errorf("finding lines = %v, want [1 3]", lines)
+	}
+
+	if _, err := NewDriver(registry).RunFile(path); err == nil {
+		t.Error("RunFile() expected error for missing file")
+	}
+}
+
+// TestDriver_RunContentKind verifies that the given kind, rather than the
+// path, selects the parser and the rules that apply.
+func TestDriver_RunContentKind(t *testing.T) {
+	rule := &Rule{
+		Name:      "build-only",
+		Severity:  SeverityWarning,
+		FileKinds: []filekind.Kind{filekind.KindBUILD},
+		Run: func(pass *Pass) (any, error) {
+			pass.Report(Finding{Rule: "build-only", Message: "BUILD file", Line: 1})
+			return nil, nil
+		},
+	}
+	registry := NewRegistry()
+	if err := registry.Register(rule); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	driver := NewDriver(registry)
+
+	tests := []struct {
+		kind filekind.Kind
+		want int
+	}{
+		{filekind.KindBUILD, 1},
+		{filekind.KindBzl, 0},
+		{filekind.KindStarlark, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			findings, err := driver.RunContentKind("rules.txt", []byte("x = 1\n"), tt.kind)
+			if err != nil {
+				t.Fatalf("RunContentKind() error = %v", err)
+			}
+			if len(findings) != tt.want {
+				t.Errorf("got %d findings, want %d", len(findings), tt.want)
+			}
+		})
+	}
+}
@@ -26,6 +26,15 @@ type FixResult struct {
 
 	// SkippedFixes is the number of fixes skipped due to conflicts.
 	SkippedFixes int
+
+	// RuleCounts breaks AppliedFixes and SkippedFixes down by rule name.
+	RuleCounts map[string]FixCounts
+}
+
+// FixCounts is the number of applied and skipped fixes for one rule.
+type FixCounts struct {
+	Applied int
+	Skipped int
 }
 
 // HasChanges returns true if fixes were applied.
@@ -50,8 +59,14 @@ func (r *FixResult) Diff() string {
 // Fixes are applied in reverse order (from end to start) to preserve byte offsets.
 // When fixes overlap, the one with the earlier start position wins.
 func ApplyFixes(content []byte, fixes []*Replacement) ([]byte, int, int) {
+	result, applied, skipped := applyFixes(content, fixes)
+	return result, len(applied), len(skipped)
+}
+
+// applyFixes is ApplyFixes, returning which fixes were applied and skipped.
+func applyFixes(content []byte, fixes []*Replacement) ([]byte, []*Replacement, []*Replacement) {
 	if len(fixes) == 0 {
-		return content, 0, 0
+		return content, nil, nil
 	}
 
 	// Filter out nil fixes and validate
@@ -63,15 +78,14 @@ func ApplyFixes(content []byte, fixes []*Replacement) ([]byte, int, int) {
 	}
 
 	if len(validFixes) == 0 {
-		return content, 0, 0
+		return content, nil, nil
 	}
 
 	// Sort fixes by start position (ascending) to detect overlaps correctly
 	sortutil.Asc(validFixes, func(f *Replacement) int { return f.Start })
 
 	// Detect and skip overlapping fixes (prefer earlier fixes)
-	var nonOverlapping []*Replacement
-	skipped := 0
+	var nonOverlapping, skipped []*Replacement
 	lastEnd := 0
 
 	for _, fix := range validFixes {
@@ -81,7 +95,7 @@ func ApplyFixes(content []byte, fixes []*Replacement) ([]byte, int, int) {
 			lastEnd = fix.End
 		} else {
 			// Overlaps with a previously accepted fix, skip it
-			skipped++
+			skipped = append(skipped, fix)
 		}
 	}
 
@@ -94,7 +108,7 @@ func ApplyFixes(content []byte, fixes []*Replacement) ([]byte, int, int) {
 		result = applyFix(result, fix)
 	}
 
-	return result, len(nonOverlapping), skipped
+	return result, nonOverlapping, skipped
 }
 
 // applyFix applies a single fix to content.
@@ -141,25 +155,54 @@ func fixFile(path string, findings []Finding) (FixResult, error) {
 		return FixResult{}, fmt.Errorf("reading file: %w", err)
 	}
 
-	// Extract replacements from findings
+	// Extract replacements from findings, remembering which rule made each
 	var fixes []*Replacement
+	ruleOf := make(map[*Replacement]string)
 	for _, f := range findings {
 		if f.Replacement != nil {
 			fixes = append(fixes, f.Replacement)
+			ruleOf[f.Replacement] = f.Rule
 		}
 	}
 
-	fixed, applied, skipped := ApplyFixes(content, fixes)
+	fixed, applied, skipped := applyFixes(content, fixes)
+
+	counts := make(map[string]FixCounts)
+	for _, fix := range applied {
+		c := counts[ruleOf[fix]]
+		c.Applied++
+		counts[ruleOf[fix]] = c
+	}
+	for _, fix := range skipped {
+		c := counts[ruleOf[fix]]
+		c.Skipped++
+		counts[ruleOf[fix]] = c
+	}
 
 	return FixResult{
 		Path:            path,
 		OriginalContent: content,
 		FixedContent:    fixed,
-		AppliedFixes:    applied,
-		SkippedFixes:    skipped,
+		AppliedFixes:    len(applied),
+		SkippedFixes:    len(skipped),
+		RuleCounts:      counts,
 	}, nil
 }
 
+// FixCountsByRule sums the per-rule fix counts of several results.
+func FixCountsByRule(results []FixResult) map[string]FixCounts {
+	total := make(map[string]FixCounts)
+	for _, r := range results {
+		for rule, c := range r.RuleCounts {
+			t := total[rule]
+			t.Applied += c.Applied
+			t.Skipped += c.Skipped
+			total[rule] = t
+		}
+	}
+	return total
+}
+
 // WriteFixResults writes the fixed content back to files.
 // Only writes files that have changes.
 func WriteFixResults(results []FixResult) error {
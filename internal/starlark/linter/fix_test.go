@@ -1,6 +1,8 @@
 package linter
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -195,3 +197,42 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestFixFiles_RuleCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "BUILD")
+	if err := os.WriteFile(path, []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	findings := []Finding{
+		{FilePath: path, Rule: "load", Replacement: &Replacement{Content: "HELLO", Start: 0, End: 5}},
+		{FilePath: path, Rule: "native-py", Replacement: &Replacement{Content: "lo wo", Start: 3, End: 8}},
+		{FilePath: path, Rule: "load", Replacement: &Replacement{Content: "WORLD", Start: 6, End: 11}},
+		{FilePath: path, Rule: "no-fix"},
+	}
+
+	results, err := FixFiles(findings)
+	if err != nil {
+		t.Fatalf("FixFiles: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	want := map[string]FixCounts{
+		"load":      {Applied: 2},
+		"native-py": {Skipped: 1},
+	}
+	got := FixCountsByRule(results)
+	if len(got) != len(want) {
+		t.Fatalf("expected counts %v, got %v", want, got)
+	}
+	for rule, c := range want {
+		if got[rule] != c {
+			t.Fatalf("expected %s counts %+v, got %+v", rule, c, got[rule])
+		}
+	}
+	if results[0].AppliedFixes != 2 || results[0].SkippedFixes != 1 {
+		t.Fatalf("expected 2 applied and 1 skipped, got %d and %d", results[0].AppliedFixes, results[0].SkippedFixes)
+	}
+}
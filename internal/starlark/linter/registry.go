@@ -55,8 +55,8 @@ func (r *Registry) Register(rules ...*Rule) error {
 		// Register the rule
 		r.rules[rule.Name] = rule
 
-		// Enable by default
-		r.enabled[rule.Name] = true
+		// Enable by default, unless the rule is opt-in
+		r.enabled[rule.Name] = !rule.OptIn
 
 		// Add to category index
 		if rule.Category != "" {
@@ -74,6 +74,11 @@ func (r *Registry) Rule(name string) (*Rule, bool) {
 	return rule, ok
 }
 
+// IsEnabled reports whether the named rule is registered and enabled.
+func (r *Registry) IsEnabled(name string) bool {
+	return r.enabled[name]
+}
+
 // Enable enables the specified rules by name or category.
 // Names can be exact rule names, category names, or "all".
 // If a name matches both a rule and a category, the rule takes precedence.
@@ -142,6 +147,32 @@ func (r *Registry) Disable(names ...string) {
 	}
 }
 
+// OnlyCategories enables exactly the rules in the given categories and
+// disables every other rule. Unknown category names are rejected without
+// modifying the registry.
+func (r *Registry) OnlyCategories(categories ...string) error {
+	var unknown []string
+	for _, cat := range categories {
+		if _, exists := r.categories[cat]; !exists {
+			unknown = append(unknown, cat)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown categories: %s (available: %s)",
+			strings.Join(unknown, ", "), strings.Join(r.Categories(), ", "))
+	}
+
+	for ruleName := range r.rules {
+		r.enabled[ruleName] = false
+	}
+	for _, cat := range categories {
+		for _, ruleName := range r.categories[cat] {
+			r.enabled[ruleName] = true
+		}
+	}
+	return nil
+}
+
 // enablePattern enables all rules matching a glob pattern.
 func (r *Registry) enablePattern(pattern string) {
 	for ruleName := range r.rules {
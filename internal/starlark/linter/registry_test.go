@@ -0,0 +1,60 @@
+package linter
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	registry := NewRegistry()
+	err := registry.Register(
+		&Rule{Name: "style-a", Category: "style"},
+		&Rule{Name: "style-b", Category: "style"},
+		&Rule{Name: "docs-a", Category: "documentation"},
+		&Rule{Name: "misc-a", Category: "correctness"},
+	)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return registry
+}
+
+func TestRegistry_OnlyCategories(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	if err := registry.OnlyCategories("style", "documentation"); err != nil {
+		t.Fatalf("OnlyCategories() error = %v", err)
+	}
+
+	want := map[string]bool{
+		"style-a": true,
+		"style-b": true,
+		"docs-a":  true,
+		"misc-a":  false,
+	}
+	for name, enabled := range want {
+		if registry.enabled[name] != enabled {
+			t.Errorf("rule %s enabled = %v, want %v", name, registry.enabled[name], enabled)
+		}
+	}
+}
+
+func TestRegistry_OnlyCategories_Unknown(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	err := registry.OnlyCategories("style", "bogus")
+	if err == nil {
+		t.Fatal("OnlyCategories() expected error for unknown category")
+	}
+	if !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("error %q does not name the unknown category", err)
+	}
+
+	// Registry must be left untouched on error.
+	for name := range registry.rules {
+		if !registry.enabled[name] {
+			t.Errorf("rule %s was disabled despite error", name)
+		}
+	}
+}
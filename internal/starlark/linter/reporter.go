@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/albertocavalcante/sky/internal/cli"
 	"github.com/albertocavalcante/sky/internal/starlark/sortutil"
 )
 
@@ -126,25 +127,13 @@ func (r *TextReporter) reportFinding(w io.Writer, f Finding) error {
 func (r *TextReporter) formatSeverity(s Severity) string {
 	switch s {
 	case SeverityError:
-		if r.ColorOutput {
-			return "\033[31merror:\033[0m" // Red
-		}
-		return "error:"
+		return cli.Paint(r.ColorOutput, cli.Red, "error:")
 	case SeverityWarning:
-		if r.ColorOutput {
-			return "\033[33mwarning:\033[0m" // Yellow
-		}
-		return "warning:"
+		return cli.Paint(r.ColorOutput, cli.Yellow, "warning:")
 	case SeverityInfo:
-		if r.ColorOutput {
-			return "\033[36minfo:\033[0m" // Cyan
-		}
-		return "info:"
+		return cli.Paint(r.ColorOutput, cli.Cyan, "info:")
 	case SeverityHint:
-		if r.ColorOutput {
-			return "\033[90mhint:\033[0m" // Gray
-		}
-		return "hint:"
+		return cli.Paint(r.ColorOutput, cli.Gray, "hint:")
 	default:
 		return "unknown:"
 	}
@@ -253,16 +242,5 @@ func (r *CompactReporter) Report(w io.Writer, result *Result) error {
 
 // formatSeverity formats the severity for display.
 func (r *CompactReporter) formatSeverity(s Severity) string {
-	switch s {
-	case SeverityError:
-		return "error:"
-	case SeverityWarning:
-		return "warning:"
-	case SeverityInfo:
-		return "info:"
-	case SeverityHint:
-		return "hint:"
-	default:
-		return "unknown:"
-	}
+	return (&TextReporter{ColorOutput: r.ColorOutput}).formatSeverity(s)
 }
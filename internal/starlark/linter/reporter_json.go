@@ -38,6 +38,7 @@ type jsonFinding struct {
 	Column    int    `json:"column"`
 	EndLine   int    `json:"end_line,omitempty"`
 	EndColumn int    `json:"end_column,omitempty"`
+	Fixable   bool   `json:"fixable"`
 }
 
 // jsonSummary represents summary statistics.
@@ -48,6 +49,7 @@ type jsonSummary struct {
 	Warnings      int             `json:"warnings"`
 	Infos         int             `json:"infos"`
 	Hints         int             `json:"hints"`
+	Fixable       int             `json:"fixable"`
 	FileErrors    []jsonFileError `json:"file_errors,omitempty"`
 	BySeverity    map[string]int  `json:"by_severity"`
 	ByRule        map[string]int  `json:"by_rule"`
@@ -108,6 +110,7 @@ func (r *JSONReporter) buildOutput(result *Result) jsonOutput {
 				Column:    f.Column,
 				EndLine:   f.EndLine,
 				EndColumn: f.EndColumn,
+				Fixable:   f.Replacement != nil,
 			})
 		}
 		files = append(files, jf)
@@ -142,6 +145,10 @@ func (r *JSONReporter) buildOutput(result *Result) jsonOutput {
 			summary.Hints++
 		}
 
+		if finding.Replacement != nil {
+			summary.Fixable++
+		}
+
 		// Count by rule and category
 		if finding.Rule != "" {
 			summary.ByRule[finding.Rule]++
@@ -329,6 +329,58 @@ func TestJSONReporter_EndLineColumn(t *testing.T) {
 	}
 }
 
+// TestJSONReporter_Fixable verifies that findings carrying a fix are marked
+// fixable and counted in the summary.
+func TestJSONReporter_Fixable(t *testing.T) {
+	reporter := NewJSONReporter()
+	result := &Result{
+		Files: 1,
+		Findings: []Finding{
+			{
+				FilePath:    "BUILD",
+				Line:        1,
+				Column:      1,
+				Rule:        "unused-load",
+				Severity:    SeverityWarning,
+				Message:     "fixable",
+				Replacement: &Replacement{Start: 0, End: 4},
+			},
+			{
+				FilePath: "BUILD",
+				Line:     2,
+				Column:   1,
+				Rule:     "print",
+				Severity: SeverityWarning,
+				Message:  "not fixable",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := reporter.Report(&buf, result); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	var output jsonOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	findings := output.Files[0].Findings
+	if !findings[0].Fixable {
+		t.Error("expected first finding to be fixable")
+	}
+	if findings[1].Fixable {
+		t.Error("expected second finding not to be fixable")
+	}
+	if output.Summary.Fixable != 1 {
+		t.Errorf("Summary.Fixable: got %d, want 1", output.Summary.Fixable)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"fixable": false`)) {
+		t.Errorf("expected fixable to be written even when false:\n%s", buf.String())
+	}
+}
+
 // TestJSONReporter_UnicodeInMessages verifies Unicode characters are handled correctly.
 func TestJSONReporter_UnicodeInMessages(t *testing.T) {
 	reporter := NewJSONReporter()
@@ -0,0 +1,73 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ReporterFactory creates a new Reporter instance.
+type ReporterFactory func() Reporter
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = map[string]ReporterFactory{
+		"text":    func() Reporter { return NewTextReporter() },
+		"compact": func() Reporter { return NewCompactReporter() },
+		"json":    func() Reporter { return NewJSONReporter() },
+		"github":  func() Reporter { return NewGitHubReporter() },
+	}
+)
+
+// RegisterReporter makes a report format available under name, alongside
+// the built-in text, compact, json and github formats. Registering an
+// existing name replaces it.
+//
+// Embedders register custom formats before invoking the skylint run
+// function, typically from an init function:
+//
+//	func init() {
+//		linter.RegisterReporter("dashboard", func() linter.Reporter {
+//			return &DashboardReporter{}
+//		})
+//	}
+//
+// after which "skylint --format=dashboard" selects it.
+//
+// RegisterReporter panics if name is empty or factory is nil.
+func RegisterReporter(name string, factory ReporterFactory) {
+	if name == "" {
+		panic("linter: RegisterReporter with empty name")
+	}
+	if factory == nil {
+		panic("linter: RegisterReporter with nil factory for " + name)
+	}
+
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	reporters[name] = factory
+}
+
+// NewReporter creates the reporter registered under name.
+func NewReporter(name string) (Reporter, error) {
+	reportersMu.RLock()
+	factory, ok := reporters[name]
+	reportersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown format: %s", name)
+	}
+	return factory(), nil
+}
+
+// ReporterNames returns the names of all registered report formats, sorted.
+func ReporterNames() []string {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
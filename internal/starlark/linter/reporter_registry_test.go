@@ -0,0 +1,75 @@
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+)
+
+type countingReporter struct{}
+
+func (countingReporter) Report(w io.Writer, result *Result) error {
+	_, err := fmt.Fprintf(w, "%d findings\n", len(result.Findings))
+	return err
+}
+
+func TestNewReporter_BuiltIns(t *testing.T) {
+	for _, name := range []string{"text", "compact", "json", "github"} {
+		reporter, err := NewReporter(name)
+		if err != nil {
+			t.Fatalf("NewReporter(%q) error = %v", name, err)
+		}
+		if reporter == nil {
+			t.Fatalf("NewReporter(%q) returned nil", name)
+		}
+	}
+
+	if _, err := NewReporter("no-such-format"); err == nil {
+		t.Fatal("NewReporter(unknown) expected error")
+	}
+}
+
+func TestRegisterReporter(t *testing.T) {
+	RegisterReporter("test-counting", func() Reporter { return countingReporter{} })
+
+	if !slices.Contains(ReporterNames(), "test-counting") {
+		t.Fatalf("ReporterNames() = %v, missing test-counting", ReporterNames())
+	}
+
+	reporter, err := NewReporter("test-counting")
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	result := &Result{Findings: []Finding{{Rule: "a"}, {Rule: "b"}}}
+	if err := reporter.Report(&buf, result); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if got := buf.String(); got != "2 findings\n" {
+		t.Errorf("Report() = %q, want %q", got, "2 findings\n")
+	}
+}
+
+func TestRegisterReporter_PanicsOnInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory ReporterFactory
+	}{
+		{name: "", factory: func() Reporter { return countingReporter{} }},
+		{name: "nil-factory", factory: nil},
+	}
+
+	for _, tc := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterReporter(%q) did not panic", tc.name)
+				}
+			}()
+			RegisterReporter(tc.name, tc.factory)
+		}()
+	}
+}
@@ -41,6 +41,10 @@ type Rule struct {
 	// AutoFix indicates whether this rule can automatically fix issues.
 	AutoFix bool
 
+	// OptIn marks a rule that is registered disabled, for opinionated
+	// rules that teams turn on explicitly.
+	OptIn bool
+
 	// FileKinds specifies which file kinds this rule applies to.
 	// An empty slice means the rule applies to all file kinds.
 	FileKinds []filekind.Kind
@@ -160,6 +164,11 @@ type Result struct {
 
 	// Errors is the list of files that could not be linted.
 	Errors []FileError
+
+	// InvalidOverrides are the errors of invalid .skyfiletypes files that
+	// apply to the linted files. Those files are ignored, so the linted files
+	// were classified by name.
+	InvalidOverrides []error
 }
 
 // FileError represents an error that occurred while linting a file.
@@ -0,0 +1,34 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/albertocavalcante/sky/internal/starlark/validator"
+)
+
+func TestFindingToDiagnostic(t *testing.T) {
+	finding := Finding{
+		FilePath:  "BUILD",
+		Severity:  SeverityHint,
+		Message:   "sort the list",
+		Line:      2,
+		Column:    4,
+		EndLine:   3,
+		EndColumn: 9,
+		Rule:      "unsorted-dict-items",
+	}
+	want := validator.Diagnostic{
+		Severity:  validator.SeverityHint,
+		Message:   "sort the list",
+		File:      "BUILD",
+		Line:      2,
+		Column:    4,
+		EndLine:   3,
+		EndColumn: 9,
+		Code:      "unsorted-dict-items",
+		Source:    "skylint",
+	}
+	if got := finding.ToDiagnostic("BUILD"); got != want {
+		t.Errorf("ToDiagnostic() = %+v, want %+v", got, want)
+	}
+}
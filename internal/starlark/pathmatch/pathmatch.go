@@ -0,0 +1,112 @@
+// Package pathmatch matches file paths against glob patterns, for pruning
+// paths that the command-line tools find while walking directories.
+package pathmatch
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Excludes is a list of glob patterns for paths to skip when walking
+// directories. It implements flag.Value, so that it can back a repeatable
+// --exclude flag.
+//
+// A pattern without a slash matches any single path element, such as
+// "third_party" or "*_test.bzl". A pattern with a slash matches leading
+// path elements, where "**" matches zero or more elements, such as
+// "src/gen/**" or "**/testdata". A trailing slash is ignored.
+type Excludes []string
+
+// String returns the patterns, comma-separated.
+func (e *Excludes) String() string {
+	return strings.Join(*e, ",")
+}
+
+// Set adds a pattern, returning an error if it is malformed.
+func (e *Excludes) Set(pattern string) error {
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+	if pattern == "" {
+		return fmt.Errorf("empty exclude pattern")
+	}
+	for _, elem := range strings.Split(pattern, "/") {
+		if _, err := path.Match(elem, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	*e = append(*e, pattern)
+	return nil
+}
+
+// MatchIn reports whether p, found while walking the directory root,
+// matches one of the patterns. Patterns are matched against the path
+// relative to root, so that the directories above root never match.
+func (e Excludes) MatchIn(root, p string) bool {
+	if len(e) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == "." {
+		return false
+	}
+	return e.Match(rel)
+}
+
+// Match reports whether the relative path p, or any directory containing
+// it, matches one of the patterns. Walkers prune a directory that matches,
+// so files below it are never visited.
+func (e Excludes) Match(p string) bool {
+	if len(e) == 0 {
+		return false
+	}
+	elems := strings.Split(path.Clean(filepath.ToSlash(p)), "/")
+	for _, pattern := range e {
+		if matchPattern(pattern, elems) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern reports whether pattern matches a leading run of elems.
+func matchPattern(pattern string, elems []string) bool {
+	if !strings.Contains(pattern, "/") {
+		for _, elem := range elems {
+			if ok, _ := path.Match(pattern, elem); ok {
+				return true
+			}
+		}
+		return false
+	}
+	patterns := strings.Split(pattern, "/")
+	for n := 1; n <= len(elems); n++ {
+		if matchElems(patterns, elems[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchElems reports whether patterns match all of elems, where "**"
+// matches zero or more elements.
+func matchElems(patterns, elems []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchElems(patterns[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(patterns[0], elems[0]); !ok {
+			return false
+		}
+		patterns, elems = patterns[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
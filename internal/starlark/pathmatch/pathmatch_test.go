@@ -0,0 +1,68 @@
+package pathmatch
+
+import "testing"
+
+func TestExcludesMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"third_party", "third_party", true},
+		{"third_party", "src/third_party/lib.bzl", true},
+		{"third_party/", "third_party/lib.bzl", true},
+		{"third_party", "third_party_lib.bzl", false},
+		{"*_test.bzl", "pkg/defs_test.bzl", true},
+		{"*_test.bzl", "pkg/defs.bzl", false},
+		{"src/gen", "src/gen/out.bzl", true},
+		{"src/gen", "lib/src/gen/out.bzl", false},
+		{"src/gen/**", "src/gen/a/b.bzl", true},
+		{"**/testdata", "a/b/testdata/x.star", true},
+		{"**/testdata", "testdata/x.star", true},
+		{"pkg/*.star", "pkg/a.star", true},
+		{"pkg/*.star", "pkg/sub/a.star", false},
+		{"vendor", "./vendor/x.bzl", true},
+	}
+
+	for _, tt := range tests {
+		var e Excludes
+		if err := e.Set(tt.pattern); err != nil {
+			t.Fatalf("Set(%q) error = %v", tt.pattern, err)
+		}
+		if got := e.Match(tt.path); got != tt.want {
+			t.Errorf("Excludes{%q}.Match(%q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExcludesSetInvalid(t *testing.T) {
+	for _, pattern := range []string{"", "/", "a/[b"} {
+		var e Excludes
+		if err := e.Set(pattern); err == nil {
+			t.Errorf("Set(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestExcludesEmpty(t *testing.T) {
+	var e Excludes
+	if e.Match("anything.star") {
+		t.Error("empty Excludes matched a path")
+	}
+}
+
+func TestExcludesMatchIn(t *testing.T) {
+	var e Excludes
+	if err := e.Set("src"); err != nil {
+		t.Fatalf("Set error = %v", err)
+	}
+	if e.MatchIn("src", "src") {
+		t.Error("MatchIn matched the walk root")
+	}
+	if e.MatchIn("src", "src/lib/defs.bzl") {
+		t.Error("MatchIn matched a directory above the walk root")
+	}
+	if !e.MatchIn(".", "src/defs.bzl") {
+		t.Error("MatchIn did not match a directory below the walk root")
+	}
+}
@@ -62,6 +62,12 @@ func assertEq(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
 		return nil, err
 	}
 	if !eq {
+		// For containers, show which elements differ instead of only the
+		// (possibly very long) full values.
+		if diff := valueDiff(a, expected); diff != "" {
+			err := assertionError(msg, "expected %s == %s", truncateRepr(a), truncateRepr(expected))
+			return nil, fmt.Errorf("%w\n%s", err, diff)
+		}
 		return nil, assertionError(msg, "expected %s == %s", a, expected)
 	}
 	return starlark.None, nil
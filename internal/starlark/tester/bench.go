@@ -0,0 +1,129 @@
+package tester
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// BenchPrefix is the prefix for benchmark function names.
+const BenchPrefix = "bench_"
+
+// DefaultBenchTime is the default target run time for each benchmark.
+const DefaultBenchTime = time.Second
+
+// maxBenchIterations caps the number of iterations of a single benchmark.
+const maxBenchIterations = 1_000_000_000
+
+// BenchResult represents the result of running a single benchmark.
+type BenchResult struct {
+	// Name is the benchmark function name.
+	Name string
+
+	// File is the source file containing the benchmark.
+	File string
+
+	// Iterations is the number of calls in the final timed run.
+	Iterations int
+
+	// Duration is the total time of the final timed run.
+	Duration time.Duration
+
+	// Error contains the error if the benchmark failed.
+	Error error
+}
+
+// NsPerOp returns the average time per call in nanoseconds.
+func (b BenchResult) NsPerOp() float64 {
+	if b.Iterations == 0 {
+		return 0
+	}
+	return float64(b.Duration.Nanoseconds()) / float64(b.Iterations)
+}
+
+// findBenchFunctions returns the sorted names of benchmark functions.
+func findBenchFunctions(globals starlark.StringDict) []string {
+	var names []string
+	for name, val := range globals {
+		if _, ok := val.(*starlark.Function); ok && strings.HasPrefix(name, BenchPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runBenchmarks runs the benchmarks in globals that match the filters.
+func (r *Runner) runBenchmarks(filename string, globals starlark.StringDict, testMeta map[string]TestMeta) []BenchResult {
+	var results []BenchResult
+	for _, name := range findBenchFunctions(globals) {
+		meta := testMeta[name]
+		if meta.Skip || !r.matchesMarkerFilter(meta) || !r.matchesFilter(name) {
+			continue
+		}
+		if r.DeadlineExceeded() {
+			break
+		}
+		result := r.runBenchmark(name, globals[name].(*starlark.Function))
+		result.File = filename
+		results = append(results, result)
+	}
+	return results
+}
+
+// runBenchmark calls fn repeatedly, scaling the number of iterations until a
+// run takes at least the configured bench time, like "go test -bench".
+func (r *Runner) runBenchmark(name string, fn *starlark.Function) BenchResult {
+	result := BenchResult{Name: name}
+	if fn.NumParams() > 0 {
+		result.Error = fmt.Errorf("benchmark functions take no parameters")
+		return result
+	}
+
+	benchTime := r.opts.BenchTime
+	if benchTime <= 0 {
+		benchTime = DefaultBenchTime
+	}
+
+	n := 1
+	for {
+		d, err := r.timeBenchmark(name, fn, n)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		result.Iterations = n
+		result.Duration = d
+		if d >= benchTime || n >= maxBenchIterations {
+			return result
+		}
+
+		// Predict the iterations needed to reach benchTime, overshooting by
+		// 20%, but grow by at most 100x per round.
+		prev := n
+		if d <= 0 {
+			n = prev * 100
+		} else {
+			n = int(int64(benchTime) * int64(prev) / int64(d))
+			n += n / 5
+		}
+		n = min(n, 100*prev, maxBenchIterations)
+		n = max(n, prev+1)
+	}
+}
+
+// timeBenchmark calls fn n times on a fresh thread and returns the elapsed time.
+func (r *Runner) timeBenchmark(name string, fn *starlark.Function, n int) (time.Duration, error) {
+	thread := &starlark.Thread{Name: name}
+	defer r.watchdog(thread, "benchmark")()
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if _, err := starlark.Call(thread, fn, nil, nil); err != nil {
+			return 0, err
+		}
+	}
+	return time.Since(start), nil
+}
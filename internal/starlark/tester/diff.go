@@ -0,0 +1,196 @@
+package tester
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+const (
+	// maxDiffLines caps the number of differences shown for one assertion.
+	maxDiffLines = 20
+
+	// maxReprLen caps the length of each full value in a failure message
+	// when a structural diff is shown alongside it.
+	maxReprLen = 120
+)
+
+// valueDiff describes how two container values differ, one difference per
+// line, with the path to each differing element ([0], ["key"], .field).
+// Unchanged elements are omitted, and dict keys and set elements are visited
+// in sorted order so the output is deterministic. It returns "" if either
+// value is not a list, tuple, dict, set, or struct, or if no element-level
+// difference is found.
+func valueDiff(a, b starlark.Value) string {
+	if !isContainer(a) || !isContainer(b) {
+		return ""
+	}
+
+	var lines []string
+	collectDiff(&lines, "", a, b)
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("differences:")
+	for i, line := range lines {
+		if i == maxDiffLines {
+			fmt.Fprintf(&sb, "\n  ... and %d more", len(lines)-maxDiffLines)
+			break
+		}
+		sb.WriteString("\n  ")
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+func isContainer(v starlark.Value) bool {
+	switch v.(type) {
+	case *starlark.List, starlark.Tuple, *starlark.Dict, *starlark.Set, *starlarkstruct.Struct:
+		return true
+	}
+	return false
+}
+
+// collectDiff appends the differences between a and b at path to lines.
+func collectDiff(lines *[]string, path string, a, b starlark.Value) {
+	if eq, err := starlark.Equal(a, b); err == nil && eq {
+		return
+	}
+
+	switch a := a.(type) {
+	case *starlark.Dict:
+		if b, ok := b.(*starlark.Dict); ok {
+			diffDicts(lines, path, a, b)
+			return
+		}
+	case *starlark.Set:
+		if b, ok := b.(*starlark.Set); ok {
+			diffSets(lines, path, a, b)
+			return
+		}
+	case *starlarkstruct.Struct:
+		if b, ok := b.(*starlarkstruct.Struct); ok {
+			diffStructs(lines, path, a, b)
+			return
+		}
+	case *starlark.List:
+		if b, ok := b.(*starlark.List); ok {
+			diffSequences(lines, path, a, b)
+			return
+		}
+	case starlark.Tuple:
+		if b, ok := b.(starlark.Tuple); ok {
+			diffSequences(lines, path, a, b)
+			return
+		}
+	}
+
+	*lines = append(*lines, fmt.Sprintf("%s: %s != %s", displayPath(path), truncateRepr(a), truncateRepr(b)))
+}
+
+func diffSequences(lines *[]string, path string, a, b starlark.Indexable) {
+	n := min(a.Len(), b.Len())
+	for i := 0; i < n; i++ {
+		collectDiff(lines, fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i))
+	}
+	for i := n; i < a.Len(); i++ {
+		*lines = append(*lines, fmt.Sprintf("%s[%d]: only in first: %s", path, i, truncateRepr(a.Index(i))))
+	}
+	for i := n; i < b.Len(); i++ {
+		*lines = append(*lines, fmt.Sprintf("%s[%d]: only in second: %s", path, i, truncateRepr(b.Index(i))))
+	}
+}
+
+// diffDicts compares dicts key by key in sorted key order, so the output does
+// not depend on insertion order.
+func diffDicts(lines *[]string, path string, a, b *starlark.Dict) {
+	for _, key := range sortedKeys(a) {
+		av, _, _ := a.Get(key)
+		keyPath := fmt.Sprintf("%s[%s]", path, key)
+		bv, found, _ := b.Get(key)
+		if !found {
+			*lines = append(*lines, fmt.Sprintf("%s: only in first: %s", keyPath, truncateRepr(av)))
+			continue
+		}
+		collectDiff(lines, keyPath, av, bv)
+	}
+	for _, key := range sortedKeys(b) {
+		if _, found, _ := a.Get(key); !found {
+			bv, _, _ := b.Get(key)
+			*lines = append(*lines, fmt.Sprintf("%s[%s]: only in second: %s", path, key, truncateRepr(bv)))
+		}
+	}
+}
+
+// diffSets reports the elements found in only one of the sets, in sorted
+// order.
+func diffSets(lines *[]string, path string, a, b *starlark.Set) {
+	for _, item := range setItems(a) {
+		if found, _ := b.Has(item); !found {
+			*lines = append(*lines, fmt.Sprintf("%s: only in first: %s", displayPath(path), truncateRepr(item)))
+		}
+	}
+	for _, item := range setItems(b) {
+		if found, _ := a.Has(item); !found {
+			*lines = append(*lines, fmt.Sprintf("%s: only in second: %s", displayPath(path), truncateRepr(item)))
+		}
+	}
+}
+
+func sortedKeys(d *starlark.Dict) []starlark.Value {
+	keys := d.Keys()
+	sortStarlarkValues(keys)
+	return keys
+}
+
+func setItems(s *starlark.Set) []starlark.Value {
+	var items []starlark.Value
+	iter := s.Iterate()
+	defer iter.Done()
+	var item starlark.Value
+	for iter.Next(&item) {
+		items = append(items, item)
+	}
+	sortStarlarkValues(items)
+	return items
+}
+
+func diffStructs(lines *[]string, path string, a, b *starlarkstruct.Struct) {
+	for _, name := range a.AttrNames() {
+		av, _ := a.Attr(name)
+		bv, err := b.Attr(name)
+		if err != nil || bv == nil {
+			*lines = append(*lines, fmt.Sprintf("%s.%s: only in first: %s", path, name, truncateRepr(av)))
+			continue
+		}
+		collectDiff(lines, path+"."+name, av, bv)
+	}
+	for _, name := range b.AttrNames() {
+		if av, err := a.Attr(name); err != nil || av == nil {
+			bv, _ := b.Attr(name)
+			*lines = append(*lines, fmt.Sprintf("%s.%s: only in second: %s", path, name, truncateRepr(bv)))
+		}
+	}
+}
+
+// displayPath returns path, or "value" for the top level.
+func displayPath(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
+
+// truncateRepr returns the Starlark representation of v, shortened to
+// maxReprLen characters.
+func truncateRepr(v starlark.Value) string {
+	s := v.String()
+	if len(s) <= maxReprLen {
+		return s
+	}
+	return s[:maxReprLen-3] + "..."
+}
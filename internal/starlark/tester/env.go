@@ -0,0 +1,87 @@
+package tester
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// DefaultEnvAllowlist lists the environment variables the env module can
+// read when Options.AllowEnv is set, in addition to Options.EnvAllowlist.
+// They identify CI systems and carry no secrets.
+var DefaultEnvAllowlist = []string{
+	"CI",
+	"BUILDKITE",
+	"CIRCLECI",
+	"GITHUB_ACTIONS",
+	"GITLAB_CI",
+	"JENKINS_URL",
+	"TF_BUILD",
+}
+
+// NewEnvModule creates the read-only env module. When enabled is false, as
+// it is by default to keep tests hermetic, every function fails. Otherwise
+// only the variables in allowlist can be read.
+//
+// Available functions:
+//   - env.get(name, default=None) - The variable's value, or default if unset
+//   - env.has(name) - Whether the variable is set
+func NewEnvModule(enabled bool, allowlist []string) *starlarkstruct.Module {
+	e := &envModule{enabled: enabled, allowlist: allowlist}
+	return &starlarkstruct.Module{
+		Name: "env",
+		Members: starlark.StringDict{
+			"get": starlark.NewBuiltin("env.get", e.get),
+			"has": starlark.NewBuiltin("env.has", e.has),
+		},
+	}
+}
+
+type envModule struct {
+	enabled   bool
+	allowlist []string
+}
+
+// lookup reads name from the environment if the module may.
+func (e *envModule) lookup(fn, name string) (string, bool, error) {
+	if !e.enabled {
+		return "", false, fmt.Errorf("%s: environment access is disabled (run skytest with --allow-env)", fn)
+	}
+	if !slices.Contains(e.allowlist, name) {
+		return "", false, fmt.Errorf("%s: %q is not in the allowlist (%s); add it with --allow-env-var", fn, name, strings.Join(e.allowlist, ", "))
+	}
+	value, ok := os.LookupEnv(name)
+	return value, ok, nil
+}
+
+func (e *envModule) get(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var dflt starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "default?", &dflt); err != nil {
+		return nil, err
+	}
+	value, ok, err := e.lookup(b.Name(), name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return dflt, nil
+	}
+	return starlark.String(value), nil
+}
+
+func (e *envModule) has(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	_, ok, err := e.lookup(b.Name(), name)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(ok), nil
+}
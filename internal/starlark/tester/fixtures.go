@@ -3,6 +3,7 @@ package tester
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"go.starlark.net/starlark"
@@ -29,6 +30,40 @@ type Fixture struct {
 	Fn *starlark.Function
 	// Scope determines when the fixture is instantiated.
 	Scope FixtureScope
+	// Mutable lets tests modify a file-scoped value. Otherwise the value is
+	// frozen when first computed, so that tests sharing it cannot affect
+	// each other, whatever order or in parallel they run.
+	Mutable bool
+	// Params are the values of a parametrized fixture, from
+	// __fixture_params__. Each test that uses the fixture runs once per
+	// value. Fn may be nil, in which case the value itself is the fixture.
+	Params []FixtureParam
+}
+
+// FixtureParam is one value of a parametrized fixture.
+type FixtureParam struct {
+	// ID identifies the value in test names (test_x[db=ID]).
+	ID string
+	// Value is passed to the fixture function as its "param" argument.
+	Value starlark.Value
+}
+
+// fixtureVariant is one combination of parametrized fixture values.
+type fixtureVariant struct {
+	id     string // e.g. "backend=sqlite-region=0"; empty if no fixture is parametrized
+	values map[string]starlark.Value
+}
+
+// name returns the test name for this variant: test_x[db=a], or
+// test_x[case-db=a] when base is already a parametrized name.
+func (v fixtureVariant) name(base string) string {
+	if v.id == "" {
+		return base
+	}
+	if strings.HasSuffix(base, "]") {
+		return base[:len(base)-1] + "-" + v.id + "]"
+	}
+	return base + "[" + v.id + "]"
 }
 
 // FixtureRegistry holds all available fixtures for a test file.
@@ -38,6 +73,8 @@ type FixtureRegistry struct {
 	cache map[string]starlark.Value
 	// builtins holds pre-computed builtin fixture values (e.g., mock)
 	builtins map[string]starlark.Value
+	// variant holds the parametrized fixture values for the current test
+	variant fixtureVariant
 }
 
 // NewFixtureRegistry creates a new fixture registry.
@@ -76,6 +113,67 @@ func (r *FixtureRegistry) ClearTestCache() {
 	}
 }
 
+// setVariant selects the parametrized fixture values for the next test.
+// Cached file-scoped values are dropped when the variant changes, since they
+// may depend on a parametrized fixture.
+func (r *FixtureRegistry) setVariant(v fixtureVariant) {
+	if v.id != r.variant.id {
+		clear(r.cache)
+	}
+	r.variant = v
+}
+
+// variants returns every combination of parametrized fixture values used,
+// directly or through other fixtures, by fn's parameters from index skip on.
+// It returns a single empty variant if fn uses no parametrized fixture.
+func (r *FixtureRegistry) variants(fn *starlark.Function, skip int) []fixtureVariant {
+	seen := make(map[string]bool)
+	var parametrized []string
+	var visit func(fn *starlark.Function, skip int)
+	visit = func(fn *starlark.Function, skip int) {
+		for i := skip; i < fn.NumParams(); i++ {
+			name, _ := fn.Param(i)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			fixture, ok := r.fixtures[name]
+			if !ok {
+				continue
+			}
+			if len(fixture.Params) > 0 {
+				parametrized = append(parametrized, name)
+			}
+			if fixture.Fn != nil {
+				visit(fixture.Fn, 0)
+			}
+		}
+	}
+	visit(fn, skip)
+	sort.Strings(parametrized)
+
+	variants := []fixtureVariant{{}}
+	for _, name := range parametrized {
+		var next []fixtureVariant
+		for _, v := range variants {
+			for _, p := range r.fixtures[name].Params {
+				values := make(map[string]starlark.Value, len(v.values)+1)
+				for k, val := range v.values {
+					values[k] = val
+				}
+				values[name] = p.Value
+				id := name + "=" + p.ID
+				if v.id != "" {
+					id = v.id + "-" + id
+				}
+				next = append(next, fixtureVariant{id: id, values: values})
+			}
+		}
+		variants = next
+	}
+	return variants
+}
+
 // GetOrCompute returns the fixture value, computing it if necessary.
 func (r *FixtureRegistry) GetOrCompute(thread *starlark.Thread, name string, registry *FixtureRegistry) (starlark.Value, error) {
 	// Check builtins first (e.g., mock)
@@ -88,6 +186,18 @@ func (r *FixtureRegistry) GetOrCompute(thread *starlark.Thread, name string, reg
 		return nil, fmt.Errorf("fixture %q not found", name)
 	}
 
+	// Parametrized fixtures take their value from the current variant
+	var param starlark.Value
+	if len(fixture.Params) > 0 {
+		param, ok = r.variant.values[name]
+		if !ok {
+			return nil, fmt.Errorf("parametrized fixture %q has no current value", name)
+		}
+		if fixture.Fn == nil {
+			return param, nil
+		}
+	}
+
 	// Check cache for file-scoped fixtures
 	if fixture.Scope == ScopeFile {
 		if val, ok := r.cache[name]; ok {
@@ -97,7 +207,7 @@ func (r *FixtureRegistry) GetOrCompute(thread *starlark.Thread, name string, reg
 
 	// Compute the fixture value
 	// First resolve any dependencies the fixture might have
-	args, err := r.resolveFixtureArgs(thread, fixture.Fn, registry)
+	args, err := r.resolveFixtureArgs(thread, fixture.Fn, registry, param)
 	if err != nil {
 		return nil, fmt.Errorf("resolving fixture %q dependencies: %w", name, err)
 	}
@@ -107,16 +217,20 @@ func (r *FixtureRegistry) GetOrCompute(thread *starlark.Thread, name string, reg
 		return nil, fmt.Errorf("calling fixture %q: %w", name, err)
 	}
 
-	// Cache file-scoped fixtures
+	// Cache file-scoped fixtures, frozen unless declared mutable
 	if fixture.Scope == ScopeFile {
+		if !fixture.Mutable {
+			val.Freeze()
+		}
 		r.cache[name] = val
 	}
 
 	return val, nil
 }
 
-// resolveFixtureArgs resolves dependencies for a fixture function.
-func (r *FixtureRegistry) resolveFixtureArgs(thread *starlark.Thread, fn *starlark.Function, registry *FixtureRegistry) (starlark.Tuple, error) {
+// resolveFixtureArgs resolves dependencies for a fixture function. For a
+// parametrized fixture, a parameter named "param" receives the current value.
+func (r *FixtureRegistry) resolveFixtureArgs(thread *starlark.Thread, fn *starlark.Function, registry *FixtureRegistry, param starlark.Value) (starlark.Tuple, error) {
 	numParams := fn.NumParams()
 	if numParams == 0 {
 		return nil, nil
@@ -125,6 +239,10 @@ func (r *FixtureRegistry) resolveFixtureArgs(thread *starlark.Thread, fn *starla
 	args := make(starlark.Tuple, numParams)
 	for i := 0; i < numParams; i++ {
 		paramName, _ := fn.Param(i)
+		if paramName == "param" && param != nil {
+			args[i] = param
+			continue
+		}
 		val, err := registry.GetOrCompute(thread, paramName, registry)
 		if err != nil {
 			return nil, err
@@ -149,34 +267,101 @@ func FindFixtures(globals starlark.StringDict) *FixtureRegistry {
 		}
 
 		fixtureName := strings.TrimPrefix(name, FixturePrefix)
-		scope := ScopeTest // default scope
+		fixture := &Fixture{
+			Name:  fixtureName,
+			Fn:    fn,
+			Scope: ScopeTest, // default scope
+		}
 
 		// Check for scope configuration via __fixture_config__ dict
 		if configVal, ok := globals["__fixture_config__"]; ok {
 			if configDict, ok := configVal.(*starlark.Dict); ok {
-				if scopeVal, found, _ := configDict.Get(starlark.String(fixtureName)); found {
-					if scopeStr, ok := scopeVal.(starlark.String); ok {
-						switch string(scopeStr) {
-						case "file":
-							scope = ScopeFile
-						case "test":
-							scope = ScopeTest
-						}
-					}
+				if config, found, _ := configDict.Get(starlark.String(fixtureName)); found {
+					applyFixtureConfig(fixture, config)
 				}
 			}
 		}
 
-		registry.Register(&Fixture{
-			Name:  fixtureName,
-			Fn:    fn,
-			Scope: scope,
-		})
+		registry.Register(fixture)
+	}
+
+	// Attach values from __fixture_params__. A name without a fixture_
+	// function becomes a fixture whose value is the param itself.
+	if paramsVal, ok := globals["__fixture_params__"]; ok {
+		if paramsDict, ok := paramsVal.(*starlark.Dict); ok {
+			for _, item := range paramsDict.Items() {
+				fixtureName, ok := starlark.AsString(item[0])
+				if !ok {
+					continue
+				}
+				params := fixtureParams(item[1])
+				if len(params) == 0 {
+					continue
+				}
+				if fixture, ok := registry.fixtures[fixtureName]; ok {
+					fixture.Params = params
+				} else {
+					registry.Register(&Fixture{
+						Name:   fixtureName,
+						Scope:  ScopeTest,
+						Params: params,
+					})
+				}
+			}
+		}
 	}
 
 	return registry
 }
 
+// applyFixtureConfig applies a __fixture_config__ entry to f. The entry is
+// either a scope name ("test" or "file") or a dict with "scope" and
+// "mutable" keys. Unknown values are ignored.
+func applyFixtureConfig(f *Fixture, config starlark.Value) {
+	scopeVal := config
+	if dict, ok := config.(*starlark.Dict); ok {
+		scopeVal, _, _ = dict.Get(starlark.String("scope"))
+		if mutable, found, _ := dict.Get(starlark.String("mutable")); found {
+			f.Mutable = bool(mutable.Truth())
+		}
+	}
+	if scopeStr, ok := scopeVal.(starlark.String); ok {
+		switch string(scopeStr) {
+		case "file":
+			f.Scope = ScopeFile
+		case "test":
+			f.Scope = ScopeTest
+		}
+	}
+}
+
+// fixtureParams converts a __fixture_params__ list into fixture params.
+// A value's ID is its "name" key if it is a dict, the value itself if it is
+// a string, and its index otherwise.
+func fixtureParams(v starlark.Value) []FixtureParam {
+	list, ok := v.(*starlark.List)
+	if !ok {
+		return nil
+	}
+	params := make([]FixtureParam, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		val := list.Index(i)
+		id := fmt.Sprintf("%d", i)
+		switch val := val.(type) {
+		case starlark.String:
+			id = string(val)
+		case *starlark.Dict:
+			if nameVal, found, _ := val.Get(starlark.String("name")); found {
+				if name, ok := starlark.AsString(nameVal); ok {
+					id = name
+				}
+			}
+		}
+		params = append(params, FixtureParam{ID: id, Value: val})
+	}
+	return params
+}
+
 // ResolveTestArgs resolves fixture arguments for a test function.
 func ResolveTestArgs(thread *starlark.Thread, testFn *starlark.Function, registry *FixtureRegistry) (starlark.Tuple, error) {
 	numParams := testFn.NumParams()
@@ -0,0 +1,86 @@
+package tester
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/albertocavalcante/sky/internal/plugins"
+	"go.starlark.net/starlark"
+)
+
+// loadEntry is a cached result of loading a module.
+type loadEntry struct {
+	globals starlark.StringDict
+	err     error
+}
+
+// MakeLoad returns a load function for threads that run the Starlark files
+// of the workspace at root. Modules are resolved with ResolveLoad and run
+// with predeclared on the loading thread, so step limits and cancellation
+// apply to them too. Each module runs once per load function, and load
+// cycles are reported as errors.
+func MakeLoad(root string, predeclared starlark.StringDict) func(*starlark.Thread, string) (starlark.StringDict, error) {
+	cache := make(map[string]*loadEntry)
+
+	return func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		path, err := ResolveLoad(root, thread.CallFrame(0).Pos.Filename(), module)
+		if err != nil {
+			return nil, err
+		}
+		e, ok := cache[path]
+		if e == nil {
+			if ok {
+				// Request for a module that is still being loaded.
+				return nil, fmt.Errorf("cycle in load graph")
+			}
+
+			// Add a placeholder to indicate "load in progress".
+			cache[path] = nil
+
+			globals, err := starlark.ExecFile(thread, path, nil, predeclared)
+			e = &loadEntry{globals, err}
+			cache[path] = e
+		}
+		return e.globals, e.err
+	}
+}
+
+// ResolveLoad returns the file that module refers to when it is loaded from
+// fromFile. Labels starting with "//" ("//pkg:file.bzl" or "//pkg/file.bzl")
+// resolve against root; ":file.bzl" and other relative names resolve against
+// the directory of fromFile. Labels of other repositories ("@repo//...")
+// are not supported.
+func ResolveLoad(root, fromFile, module string) (string, error) {
+	if strings.HasPrefix(module, "@") {
+		return "", fmt.Errorf("cannot load %s: external repositories are not supported", module)
+	}
+	if label, ok := strings.CutPrefix(module, "//"); ok {
+		if root == "" {
+			return "", fmt.Errorf("cannot load %s: no workspace root", module)
+		}
+		return filepath.Join(root, filepath.FromSlash(strings.Replace(label, ":", "/", 1))), nil
+	}
+	if filepath.IsAbs(module) {
+		return module, nil
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(module, ":"))
+	return filepath.Join(filepath.Dir(fromFile), rel), nil
+}
+
+// LoadRoot returns the directory that "//" labels in filename resolve
+// against: dir if it is set, otherwise the nearest ancestor of filename
+// containing a workspace marker. It returns "" if there is neither.
+func LoadRoot(dir, filename string) string {
+	if dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return abs
+		}
+		return dir
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return ""
+	}
+	return plugins.NearestWorkspaceRoot(filepath.Dir(abs))
+}
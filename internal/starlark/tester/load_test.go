@@ -0,0 +1,79 @@
+package tester
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveLoad(t *testing.T) {
+	root := filepath.FromSlash("/ws")
+	from := filepath.FromSlash("/ws/pkg/test.star")
+	tests := []struct {
+		module  string
+		want    string
+		wantErr string
+	}{
+		{module: "//lib:defs.bzl", want: "/ws/lib/defs.bzl"},
+		{module: "//lib/defs.bzl", want: "/ws/lib/defs.bzl"},
+		{module: ":helpers.star", want: "/ws/pkg/helpers.star"},
+		{module: "helpers.star", want: "/ws/pkg/helpers.star"},
+		{module: "../lib/defs.bzl", want: "/ws/lib/defs.bzl"},
+		{module: "@rules_cc//cc:defs.bzl", wantErr: "external repositories are not supported"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveLoad(root, from, tt.module)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveLoad(%q) error = %v, want %q", tt.module, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != filepath.FromSlash(tt.want) {
+			t.Errorf("ResolveLoad(%q) = %q, %v; want %q", tt.module, got, err, tt.want)
+		}
+	}
+
+	if _, err := ResolveLoad("", from, "//lib:defs.bzl"); err == nil {
+		t.Error("ResolveLoad with no root accepted a // label")
+	}
+}
+
+func TestRunnerLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/math.star":      "def double(x):\n    return 2 * x\n",
+		"lib/helpers.star":   "load(\":math.star\", \"double\")\n\ndef quadruple(x):\n    return double(double(x))\n",
+		"tests/a_test.star":  "load(\"//lib:helpers.star\", \"quadruple\")\nload(\"../lib/math.star\", \"double\")\n\ndef test_load():\n    assert.eq(quadruple(1), 4)\n    assert.eq(double(1), 2)\n",
+		"tests/cycle_a.star": "load(\":cycle_b.star\", \"b\")\na = 1\n",
+		"tests/cycle_b.star": "load(\":cycle_a.star\", \"a\")\nb = 1\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := DefaultOptions()
+	opts.ConftestRoot = dir
+	runner := New(opts)
+
+	testFile := filepath.Join(dir, "tests", "a_test.star")
+	result, err := runner.RunFile(testFile, []byte(files["tests/a_test.star"]))
+	if err != nil {
+		t.Fatalf("RunFile() error = %v", err)
+	}
+	if passed, failed := result.Summary(); passed != 1 || failed != 0 {
+		t.Errorf("Summary() = %d passed, %d failed; want 1 passed", passed, failed)
+	}
+
+	cycle := filepath.Join(dir, "tests", "cycle_a.star")
+	if _, err := runner.RunFile(cycle, []byte(files["tests/cycle_a.star"])); err == nil || !strings.Contains(err.Error(), "cycle in load graph") {
+		t.Errorf("RunFile(cycle) error = %v, want a load cycle", err)
+	}
+}
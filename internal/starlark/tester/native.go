@@ -0,0 +1,165 @@
+package tester
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/albertocavalcante/sky/internal/starlark/builtins"
+	"github.com/albertocavalcante/sky/internal/starlark/builtins/loader"
+	"github.com/albertocavalcante/sky/internal/starlark/filekind"
+)
+
+// NativeRulesKey is the thread-local key for the rules declared by rule stubs.
+const NativeRulesKey = "skytest.native_rules"
+
+// nativeRules records the rules declared on one thread, in declaration order.
+type nativeRules struct {
+	names []string
+	rules map[string]*starlark.Dict
+}
+
+// rulesFor returns the rules declared on thread, creating the record on first
+// use. Every test runs on a fresh thread, so each test starts with no rules.
+func rulesFor(thread *starlark.Thread) *nativeRules {
+	if r, ok := thread.Local(NativeRulesKey).(*nativeRules); ok {
+		return r
+	}
+	r := &nativeRules{rules: make(map[string]*starlark.Dict)}
+	thread.SetLocal(NativeRulesKey, r)
+	return r
+}
+
+// DialectBuiltins returns stubs of the native rules that the builtins data
+// shared with the language server knows for dialect's BUILD files (see
+// NativeBuiltins).
+func DialectBuiltins(dialect string) (starlark.StringDict, error) {
+	provider := builtins.NewChainProvider(loader.NewProtoProvider(), loader.NewJSONProvider())
+	b, err := provider.Builtins(dialect, filekind.KindBUILD)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.Functions) == 0 {
+		return nil, fmt.Errorf("no builtins available for this dialect")
+	}
+	return NativeBuiltins(b), nil
+}
+
+// NativeBuiltins returns stub implementations of the rule functions in b,
+// such as the native rules a builtins provider knows for a dialect's BUILD
+// files. Each rule is available both as a global and on a "native" struct,
+// so macros that call native.cc_library(...) or cc_library(...) can be
+// tested. A rule call checks its attributes against the signature and
+// records the rule; native.existing_rules() and native.existing_rule(name)
+// return what was recorded, with the rule's kind under "kind".
+func NativeBuiltins(b builtins.Builtins) starlark.StringDict {
+	globals := make(starlark.StringDict)
+	members := starlark.StringDict{
+		"existing_rules":  starlark.NewBuiltin("native.existing_rules", nativeExistingRules),
+		"existing_rule":   starlark.NewBuiltin("native.existing_rule", nativeExistingRule),
+		"package_name":    starlark.NewBuiltin("native.package_name", nativePackageName),
+		"repository_name": starlark.NewBuiltin("native.repository_name", nativeRepositoryName),
+	}
+	for _, sig := range b.Functions {
+		rule := newRuleStub(sig)
+		globals[sig.Name] = rule
+		members[sig.Name] = rule
+	}
+	globals["native"] = starlarkstruct.FromStringDict(starlark.String("native"), members)
+	return globals
+}
+
+// newRuleStub returns a builtin that declares a rule of kind sig.Name.
+func newRuleStub(sig builtins.Signature) *starlark.Builtin {
+	attrs := make(map[string]bool)
+	for _, p := range sig.Params {
+		// Attributes starting with "$" or ":" are private to the rule.
+		if !strings.HasPrefix(p.Name, "$") && !strings.HasPrefix(p.Name, ":") {
+			attrs[p.Name] = true
+		}
+	}
+
+	kind := sig.Name
+	return starlark.NewBuiltin(kind, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("%s: rules accept keyword arguments only", kind)
+		}
+
+		rule := starlark.NewDict(len(kwargs) + 1)
+		var name string
+		for _, kv := range kwargs {
+			key := string(kv[0].(starlark.String))
+			if len(attrs) > 0 && !attrs[key] {
+				return nil, fmt.Errorf("%s: no such attribute %q", kind, key)
+			}
+			if key == "name" {
+				s, ok := kv[1].(starlark.String)
+				if !ok {
+					return nil, fmt.Errorf("%s: name must be a string, got %s", kind, kv[1].Type())
+				}
+				name = string(s)
+			}
+			if err := rule.SetKey(kv[0], kv[1]); err != nil {
+				return nil, err
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("%s: missing mandatory attribute \"name\"", kind)
+		}
+		if err := rule.SetKey(starlark.String("kind"), starlark.String(kind)); err != nil {
+			return nil, err
+		}
+
+		declared := rulesFor(thread)
+		if _, exists := declared.rules[name]; exists {
+			return nil, fmt.Errorf("%s: rule %q already declared", kind, name)
+		}
+		declared.names = append(declared.names, name)
+		declared.rules[name] = rule
+		return starlark.None, nil
+	})
+}
+
+func nativeExistingRules(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	declared := rulesFor(thread)
+	result := starlark.NewDict(len(declared.names))
+	for _, name := range declared.names {
+		if err := result.SetKey(starlark.String(name), declared.rules[name]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func nativeExistingRule(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	if rule, ok := rulesFor(thread).rules[name]; ok {
+		return rule, nil
+	}
+	return starlark.None, nil
+}
+
+// nativePackageName returns "", the root package, as tests are not run in a
+// package.
+func nativePackageName(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	return starlark.String(""), nil
+}
+
+// nativeRepositoryName returns "@", the main repository.
+func nativeRepositoryName(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	return starlark.String("@"), nil
+}
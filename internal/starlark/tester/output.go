@@ -0,0 +1,127 @@
+package tester
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// OutputDirKey is the thread-local key for the current test's output
+// directory.
+const OutputDirKey = "skytest.output_dir"
+
+// OutputBuiltins returns the builtins that give tests a directory for
+// artifacts, under Options.OutputDir:
+//
+//   - test_output_dir() - the current test's output directory
+//   - write_test_output(name, content) - write a file there, returning its path
+func OutputBuiltins() starlark.StringDict {
+	return starlark.StringDict{
+		"test_output_dir":   starlark.NewBuiltin("test_output_dir", testOutputDir),
+		"write_test_output": starlark.NewBuiltin("write_test_output", writeTestOutput),
+	}
+}
+
+// testOutputPath returns the output directory for a test: the test file's
+// path (relative to the working directory when inside it) and the test name
+// below base, with characters unsafe in file names replaced.
+func testOutputPath(base, filename, testName string) string {
+	rel := filename
+	if filepath.IsAbs(rel) {
+		if cwd, err := os.Getwd(); err == nil {
+			if r, err := filepath.Rel(cwd, rel); err == nil && filepath.IsLocal(r) {
+				rel = r
+			}
+		}
+	}
+	var parts []string
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Clean(rel)), "/") {
+		if part != "" && part != "." && part != ".." {
+			parts = append(parts, sanitizePathPart(part))
+		}
+	}
+	parts = append(parts, sanitizePathPart(testName))
+	return filepath.Join(append([]string{base}, parts...)...)
+}
+
+// sanitizePathPart replaces characters other than letters, digits, '.', '-',
+// and '_' with '_', so parametrized names such as test_x[a b] are usable as
+// directory names.
+func sanitizePathPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// prepareOutputDir gives the test running on thread a fresh output
+// directory, removing what an earlier run left there. The returned function
+// removes the directory again if the test wrote nothing to it.
+func (r *Runner) prepareOutputDir(thread *starlark.Thread, filename, testName string) (func(), error) {
+	if r.opts.OutputDir == "" {
+		return func() {}, nil
+	}
+	dir := testOutputPath(r.opts.OutputDir, filename, testName)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("cleaning output directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+	thread.SetLocal(OutputDirKey, dir)
+	return func() {
+		// Remove fails on a non-empty directory, which is what we want.
+		_ = os.Remove(dir)
+	}, nil
+}
+
+// outputDir returns the output directory of the test running on thread.
+func outputDir(thread *starlark.Thread, fn string) (string, error) {
+	dir, ok := thread.Local(OutputDirKey).(string)
+	if !ok {
+		return "", fmt.Errorf("%s: no output directory (run skytest with --output-dir)", fn)
+	}
+	return dir, nil
+}
+
+func testOutputDir(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	dir, err := outputDir(thread, b.Name())
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(dir), nil
+}
+
+// writeTestOutput writes content to name in the test's output directory.
+// name may contain subdirectories but must stay inside the directory.
+func writeTestOutput(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, content string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "content", &content); err != nil {
+		return nil, err
+	}
+	dir, err := outputDir(thread, b.Name())
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("%s: %q is not a relative path inside the output directory", b.Name(), name)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.String(path), nil
+}
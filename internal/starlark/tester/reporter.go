@@ -4,8 +4,11 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/albertocavalcante/sky/internal/cli"
 )
 
 // Reporter formats test results for output.
@@ -24,12 +27,15 @@ type TextReporter struct {
 
 	// ShowDuration shows timing information.
 	ShowDuration bool
+
+	// Color colors test statuses and the summary (for terminals).
+	Color bool
 }
 
 // Report implements Reporter.
 func (r *TextReporter) Report(w io.Writer, result *FileResult) error {
 	if result.SetupError != nil {
-		if _, err := fmt.Fprintf(w, "SETUP FAILED: %s\n  %v\n", result.File, result.SetupError); err != nil {
+		if _, err := fmt.Fprintf(w, "%s %s\n  %v\n", cli.Paint(r.Color, cli.Red, "SETUP FAILED:"), result.File, result.SetupError); err != nil {
 			return err
 		}
 		return nil
@@ -40,15 +46,15 @@ func (r *TextReporter) Report(w io.Writer, result *FileResult) error {
 		var status string
 		switch {
 		case t.Skipped:
-			status = "SKIP"
+			status = cli.Paint(r.Color, cli.Yellow, "SKIP")
 		case t.XPass:
-			status = "XPASS"
+			status = cli.Paint(r.Color, cli.Red, "XPASS")
 		case t.XFail && t.Passed:
-			status = "XFAIL"
+			status = cli.Paint(r.Color, cli.Yellow, "XFAIL")
 		case t.Passed:
-			status = "PASS"
+			status = cli.Paint(r.Color, cli.Green, "PASS")
 		default:
-			status = "FAIL"
+			status = cli.Paint(r.Color, cli.Red, "FAIL")
 		}
 
 		if r.ShowDuration {
@@ -98,7 +104,7 @@ func (r *TextReporter) Report(w io.Writer, result *FileResult) error {
 	}
 
 	if result.TeardownError != nil {
-		if _, err := fmt.Fprintf(w, "TEARDOWN FAILED: %s\n  %v\n", result.File, result.TeardownError); err != nil {
+		if _, err := fmt.Fprintf(w, "%s %s\n  %v\n", cli.Paint(r.Color, cli.Red, "TEARDOWN FAILED:"), result.File, result.TeardownError); err != nil {
 			return err
 		}
 	}
@@ -111,13 +117,49 @@ func (r *TextReporter) ReportSummary(w io.Writer, result *RunResult) {
 	passed, failed, files := result.Summary()
 	total := passed + failed
 
+	style := cli.Green
+	if failed > 0 {
+		style = cli.Red
+	}
 	_, _ = fmt.Fprintln(w)
-	_, _ = fmt.Fprintf(w, "Results: %d passed, %d failed, %d total in %d file(s)\n",
-		passed, failed, total, files)
+	_, _ = fmt.Fprintln(w, cli.Paint(r.Color, style, fmt.Sprintf("Results: %d passed, %d failed, %d total in %d file(s)",
+		passed, failed, total, files)))
 
 	if r.ShowDuration {
 		_, _ = fmt.Fprintf(w, "Duration: %s\n", result.Duration.Round(time.Millisecond))
 	}
+
+	writeBenchmarks(w, result)
+}
+
+// writeBenchmarks writes the benchmark section of the text summary, if any
+// benchmarks ran.
+func writeBenchmarks(w io.Writer, result *RunResult) {
+	var benches []BenchResult
+	width := 0
+	for _, fr := range result.Files {
+		for _, b := range fr.Benchmarks {
+			b.Name = filepath.Base(b.File) + "::" + b.Name
+			width = max(width, len(b.Name))
+			benches = append(benches, b)
+		}
+	}
+	if len(benches) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Benchmarks:")
+	for _, b := range benches {
+		if b.Error != nil {
+			_, _ = fmt.Fprintf(w, "  %-*s  FAIL\n", width, b.Name)
+			for _, line := range strings.Split(b.Error.Error(), "\n") {
+				_, _ = fmt.Fprintf(w, "      %s\n", line)
+			}
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  %-*s  %10d  %12.1f ns/op\n", width, b.Name, b.Iterations, b.NsPerOp())
+	}
 }
 
 // JUnitReporter outputs results in JUnit XML format.
@@ -285,6 +327,17 @@ func (r *MarkdownReporter) ReportSummary(w io.Writer, result *RunResult) {
 		_, _ = fmt.Fprintln(w)
 
 		for _, fr := range result.Files {
+			if fr.SetupError != nil {
+				_, _ = fmt.Fprintf(w, "<details>\n")
+				_, _ = fmt.Fprintf(w, "<summary><code>%s</code> (setup failed)</summary>\n", fr.File)
+				_, _ = fmt.Fprintln(w)
+				_, _ = fmt.Fprintln(w, "```")
+				_, _ = fmt.Fprintln(w, fr.SetupError.Error())
+				_, _ = fmt.Fprintln(w, "```")
+				_, _ = fmt.Fprintln(w)
+				_, _ = fmt.Fprintln(w, "</details>")
+				_, _ = fmt.Fprintln(w)
+			}
 			for _, t := range fr.Tests {
 				// Skip passed, skipped, and expected failures
 				if t.Passed || t.Skipped || (t.XFail && !t.XPass) {
@@ -356,6 +409,7 @@ func (r *JSONReporter) ReportSummary(w io.Writer, result *RunResult) {
 		File     string     `json:"file"`
 		Tests    []jsonTest `json:"tests"`
 		Duration float64    `json:"duration_ms"`
+		Error    string     `json:"error,omitempty"`
 	}
 
 	type jsonOutput struct {
@@ -380,6 +434,9 @@ func (r *JSONReporter) ReportSummary(w io.Writer, result *RunResult) {
 			File:     fr.File,
 			Duration: float64(fr.Duration.Milliseconds()),
 		}
+		if fr.SetupError != nil {
+			jf.Error = fr.SetupError.Error()
+		}
 		for _, t := range fr.Tests {
 			jt := jsonTest{
 				Name:     t.Name,
@@ -407,6 +464,9 @@ func (r *JSONReporter) ReportSummary(w io.Writer, result *RunResult) {
 		_, _ = fmt.Fprintf(w, "    {\n")
 		_, _ = fmt.Fprintf(w, "      \"file\": %q,\n", jf.File)
 		_, _ = fmt.Fprintf(w, "      \"duration_ms\": %.0f,\n", jf.Duration)
+		if jf.Error != "" {
+			_, _ = fmt.Fprintf(w, "      \"error\": %q,\n", jf.Error)
+		}
 		_, _ = fmt.Fprintf(w, "      \"tests\": [\n")
 
 		for j, jt := range jf.Tests {
@@ -426,6 +486,26 @@ func (r *JSONReporter) ReportSummary(w io.Writer, result *RunResult) {
 			}
 		}
 
+		if benches := result.Files[i].Benchmarks; len(benches) > 0 {
+			_, _ = fmt.Fprintf(w, "      ],\n")
+			_, _ = fmt.Fprintf(w, "      \"benchmarks\": [\n")
+			for j, b := range benches {
+				_, _ = fmt.Fprintf(w, "        {\n")
+				_, _ = fmt.Fprintf(w, "          \"name\": %q,\n", b.Name)
+				_, _ = fmt.Fprintf(w, "          \"iterations\": %d,\n", b.Iterations)
+				_, _ = fmt.Fprintf(w, "          \"ns_per_op\": %.1f", b.NsPerOp())
+				if b.Error != nil {
+					_, _ = fmt.Fprintf(w, ",\n          \"error\": %q\n", b.Error.Error())
+				} else {
+					_, _ = fmt.Fprintf(w, "\n")
+				}
+				if j < len(benches)-1 {
+					_, _ = fmt.Fprintf(w, "        },\n")
+				} else {
+					_, _ = fmt.Fprintf(w, "        }\n")
+				}
+			}
+		}
 		_, _ = fmt.Fprintf(w, "      ]\n")
 		if i < len(out.Results)-1 {
 			_, _ = fmt.Fprintf(w, "    },\n")
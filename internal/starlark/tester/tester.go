@@ -7,12 +7,16 @@ package tester
 
 import (
 	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/albertocavalcante/sky/internal/plugins"
 	"github.com/albertocavalcante/sky/internal/starlark/coverage"
 
 	"go.starlark.net/lib/json"
@@ -65,18 +69,28 @@ type FileResult struct {
 	// Tests contains results for each test function.
 	Tests []TestResult
 
-	// SetupError contains any error from setup().
+	// SetupError contains any error that kept the file's tests from running:
+	// reading or executing the file, its preludes, or its conftest.star
+	// files. It counts as one failure.
 	SetupError error
 
 	// TeardownError contains any error from teardown().
 	TeardownError error
 
+	// Benchmarks contains results for each benchmark function, when
+	// Options.Bench is set.
+	Benchmarks []BenchResult
+
 	// Duration is total time for all tests in this file.
 	Duration time.Duration
 }
 
-// Summary returns counts of passed, failed, and skipped tests.
+// Summary returns counts of passed and failed tests. A file that failed to
+// load counts as one failure.
 func (fr *FileResult) Summary() (passed, failed int) {
+	if fr.SetupError != nil {
+		failed++
+	}
 	for _, t := range fr.Tests {
 		if t.Skipped {
 			// Skipped tests don't count as pass or fail
@@ -108,10 +122,18 @@ func (fr *FileResult) SkippedCount() int {
 	return count
 }
 
-// HasFailures returns true if any test in this file failed.
+// HasFailures returns true if any test or benchmark in this file failed.
 func (fr *FileResult) HasFailures() bool {
 	_, failed := fr.Summary()
-	return failed > 0
+	if failed > 0 {
+		return true
+	}
+	for _, b := range fr.Benchmarks {
+		if b.Error != nil {
+			return true
+		}
+	}
+	return false
 }
 
 // RunResult contains all results from a test run.
@@ -134,10 +156,14 @@ func (rr *RunResult) Summary() (passed, failed, files int) {
 	return
 }
 
-// HasFailures returns true if any test failed.
+// HasFailures returns true if any test or benchmark failed.
 func (rr *RunResult) HasFailures() bool {
-	_, failed, _ := rr.Summary()
-	return failed > 0
+	for i := range rr.Files {
+		if rr.Files[i].HasFailures() {
+			return true
+		}
+	}
+	return false
 }
 
 // Options configures the test runner.
@@ -184,12 +210,61 @@ type Options struct {
 	// If zero, no timeout is applied.
 	Timeout time.Duration
 
+	// MaxSteps caps the Starlark execution steps of each test and of each
+	// test file's top-level code. If zero, the number of steps is unbounded.
+	MaxSteps uint64
+
+	// Deadline is the wall-clock limit for the whole run. Once it passes,
+	// the in-flight test is cancelled and no further tests are started.
+	// If zero, there is no deadline.
+	Deadline time.Time
+
 	// FailFast stops running tests after the first failure.
 	FailFast bool
 
+	// Bench enables running bench_* functions after the tests in each file.
+	Bench bool
+
+	// BenchTime is the target run time for each benchmark
+	// (default: DefaultBenchTime).
+	BenchTime time.Duration
+
+	// RunFirst lists test ids ("file::test_name", with file as passed to
+	// RunFile) to run before the other tests in their file. Parametrized
+	// ids (test_name[case]) move the whole test function.
+	RunFirst []string
+
+	// Shuffle runs the tests of each file in a pseudo-random order derived
+	// from Seed and the file name, to expose tests that depend on the order
+	// they run in. The same seed always gives the same order.
+	Shuffle bool
+
+	// Seed seeds the test order when Shuffle is set.
+	Seed int64
+
+	// ConftestRoot is the directory where the upward conftest.star search
+	// stops (inclusive). If empty, the nearest ancestor of each test file
+	// containing a workspace marker (MODULE.bazel, WORKSPACE, .git, .sky.yaml,
+	// ...) is used.
+	ConftestRoot string
+
 	// UpdateSnapshots when true, updates snapshots instead of comparing.
 	// Use with -u or --update-snapshots flag.
 	UpdateSnapshots bool
+
+	// OutputDir is the base directory for test artifacts. Each test gets
+	// its own subdirectory, named after its file and test name, which is
+	// emptied before the test runs and which test_output_dir() returns.
+	// If empty, test_output_dir() and write_test_output() fail.
+	OutputDir string
+
+	// AllowEnv lets tests read environment variables through the env
+	// module. It is off by default so that tests stay hermetic.
+	AllowEnv bool
+
+	// EnvAllowlist lists the environment variables the env module can read
+	// when AllowEnv is set, in addition to DefaultEnvAllowlist.
+	EnvAllowlist []string
 }
 
 // DefaultOptions returns sensible defaults.
@@ -267,7 +342,8 @@ func (r *Runner) RunFile(filename string, src []byte) (*FileResult, error) {
 	}
 
 	// Parse and execute the file
-	thread := &starlark.Thread{Name: filename}
+	thread := &starlark.Thread{Name: filename, Load: r.makeLoad(filename, predeclared)}
+	r.limitSteps(thread)
 
 	// EXPERIMENTAL: Enable coverage collection via OnExec hook.
 	// This only works when starlark-go-x replace directive is enabled in go.mod.
@@ -280,7 +356,7 @@ func (r *Runner) RunFile(filename string, src []byte) (*FileResult, error) {
 	}
 
 	// Find test functions
-	testFuncs := r.findTestFunctions(globals)
+	testFuncs := r.prioritize(filename, r.shuffle(filename, r.findTestFunctions(globals)))
 
 	// Find fixtures in this file
 	fileFixtures := FindFixtures(globals)
@@ -315,110 +391,216 @@ func (r *Runner) RunFile(filename string, src []byte) (*FileResult, error) {
 
 		// Check if this test has parameters
 		if params, ok := testParams[name]; ok {
-			// Run parametrized test for each case
+			// Run parametrized test for each case and fixture variant
 			for _, pc := range params {
-				virtualName := pc.virtualName(name)
-				if !r.matchesFilter(virtualName) {
+				for _, variant := range fixtureRegistry.variants(fn, 1) {
+					if r.DeadlineExceeded() {
+						break
+					}
+					virtualName := variant.name(pc.virtualName(name))
+					if !r.matchesFilter(virtualName) {
+						continue // Skip tests that don't match filter
+					}
+
+					// Check for skip
+					if meta.Skip {
+						result.Tests = append(result.Tests, skippedResult(virtualName, filename, meta))
+						continue
+					}
+
+					fixtureRegistry.setVariant(variant)
+					testResult := r.runParametrizedTest(thread, virtualName, filename, fn, setupFn, teardownFn, predeclared, fixtureRegistry, pc.caseDict)
+					testResult.File = filename
+					applyXFail(&testResult, meta)
+
+					result.Tests = append(result.Tests, testResult)
+
+					// Clear test-scoped fixture cache and mock state between tests
+					fixtureRegistry.ClearTestCache()
+					r.mock.Reset()
+
+					// Fail-fast: stop after first failure
+					if r.opts.FailFast && !testResult.Passed {
+						break
+					}
+				}
+				if (r.opts.FailFast && result.HasFailures()) || r.DeadlineExceeded() {
+					break
+				}
+			}
+		} else {
+			// Regular non-parametrized test, once per fixture variant
+			for _, variant := range fixtureRegistry.variants(fn, 0) {
+				if r.DeadlineExceeded() {
+					break
+				}
+				testName := variant.name(name)
+				if !r.matchesFilter(testName) {
 					continue // Skip tests that don't match filter
 				}
 
 				// Check for skip
 				if meta.Skip {
-					testResult := TestResult{
-						Name:       virtualName,
-						File:       filename,
-						Skipped:    true,
-						SkipReason: meta.SkipReason,
-						Passed:     true, // Skipped counts as passed for exit code
-					}
-					result.Tests = append(result.Tests, testResult)
+					result.Tests = append(result.Tests, skippedResult(testName, filename, meta))
 					continue
 				}
 
-				testResult := r.runParametrizedTest(thread, virtualName, fn, setupFn, teardownFn, predeclared, fixtureRegistry, pc.caseDict)
+				fixtureRegistry.setVariant(variant)
+				testResult := r.runSingleTest(thread, testName, filename, fn, setupFn, teardownFn, predeclared, fixtureRegistry)
 				testResult.File = filename
-
-				// Handle xfail
-				if meta.XFail {
-					testResult.XFail = true
-					testResult.XFailReason = meta.XFailReason
-					if testResult.Passed {
-						// Test passed but was expected to fail - this is XPASS (failure)
-						testResult.XPass = true
-						testResult.Passed = false
-					} else {
-						// Test failed as expected - this is success
-						testResult.Passed = true
-						testResult.Error = nil
-					}
-				}
+				applyXFail(&testResult, meta)
 
 				result.Tests = append(result.Tests, testResult)
 
-				// Clear test-scoped fixture cache and mock state between tests
+				// Clear test-scoped fixture cache between tests
 				fixtureRegistry.ClearTestCache()
-				r.mock.Reset()
 
 				// Fail-fast: stop after first failure
 				if r.opts.FailFast && !testResult.Passed {
 					break
 				}
 			}
-			// Check fail-fast at file level too
-			if r.opts.FailFast && result.HasFailures() {
-				break
-			}
-		} else {
-			// Regular non-parametrized test
-			if !r.matchesFilter(name) {
-				continue // Skip tests that don't match filter
-			}
+		}
+		// Check fail-fast and the run deadline at file level too
+		if (r.opts.FailFast && result.HasFailures()) || r.DeadlineExceeded() {
+			break
+		}
+	}
 
-			// Check for skip
-			if meta.Skip {
-				testResult := TestResult{
-					Name:       name,
-					File:       filename,
-					Skipped:    true,
-					SkipReason: meta.SkipReason,
-					Passed:     true, // Skipped counts as passed for exit code
-				}
-				result.Tests = append(result.Tests, testResult)
-				continue
-			}
+	// Run benchmarks, unless fail-fast already stopped the file
+	if r.opts.Bench && !(r.opts.FailFast && result.HasFailures()) && !r.DeadlineExceeded() {
+		result.Benchmarks = r.runBenchmarks(filename, globals, testMeta)
+	}
 
-			testResult := r.runSingleTest(thread, name, filename, fn, setupFn, teardownFn, predeclared, fixtureRegistry)
-			testResult.File = filename
-
-			// Handle xfail
-			if meta.XFail {
-				testResult.XFail = true
-				testResult.XFailReason = meta.XFailReason
-				if testResult.Passed {
-					// Test passed but was expected to fail - this is XPASS (failure)
-					testResult.XPass = true
-					testResult.Passed = false
-				} else {
-					// Test failed as expected - this is success
-					testResult.Passed = true
-					testResult.Error = nil
-				}
-			}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// DeadlineExceeded reports whether Options.Deadline has passed.
+func (r *Runner) DeadlineExceeded() bool {
+	return !r.opts.Deadline.IsZero() && !time.Now().Before(r.opts.Deadline)
+}
+
+// watchdog cancels thread when the per-test timeout or the run deadline is
+// reached, whichever comes first. what names the work in the timeout
+// message ("test", "benchmark"). The returned function stops the watchdog.
+func (r *Runner) watchdog(thread *starlark.Thread, what string) func() {
+	d := r.opts.Timeout
+	reason := fmt.Sprintf("%s timeout after %s", what, r.opts.Timeout)
+	if !r.opts.Deadline.IsZero() {
+		if untilDeadline := time.Until(r.opts.Deadline); d <= 0 || untilDeadline < d {
+			d = max(untilDeadline, 0)
+			reason = "run deadline exceeded"
+		}
+	} else if d <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(d, func() {
+		thread.Cancel(reason)
+	})
+	return func() { timer.Stop() }
+}
+
+// limitSteps applies Options.MaxSteps to thread.
+func (r *Runner) limitSteps(thread *starlark.Thread) {
+	if r.opts.MaxSteps == 0 {
+		return
+	}
+	thread.SetMaxExecutionSteps(r.opts.MaxSteps)
+	thread.OnMaxSteps = func(thread *starlark.Thread) {
+		thread.Cancel(fmt.Sprintf("exceeded limit of %d execution steps", r.opts.MaxSteps))
+	}
+}
+
+// skippedResult returns the result for a test skipped via __test_meta__.
+func skippedResult(name, filename string, meta TestMeta) TestResult {
+	return TestResult{
+		Name:       name,
+		File:       filename,
+		Skipped:    true,
+		SkipReason: meta.SkipReason,
+		Passed:     true, // Skipped counts as passed for exit code
+	}
+}
+
+// applyXFail inverts the outcome of a test expected to fail via __test_meta__.
+func applyXFail(testResult *TestResult, meta TestMeta) {
+	if !meta.XFail {
+		return
+	}
+	testResult.XFail = true
+	testResult.XFailReason = meta.XFailReason
+	if testResult.Passed {
+		// Test passed but was expected to fail - this is XPASS (failure)
+		testResult.XPass = true
+		testResult.Passed = false
+	} else {
+		// Test failed as expected - this is success
+		testResult.Passed = true
+		testResult.Error = nil
+	}
+}
 
-			result.Tests = append(result.Tests, testResult)
+// CollectFile returns the test names RunFile would run for a file, without
+// executing any tests. The module body and conftest.star files are still
+// executed so that __test_params__, __test_meta__, and __fixture_params__
+// can be read. Parametrized tests are expanded to their virtual names
+// (test_name[case]), and the name, marker, and :: filters are applied.
+// Skipped tests are included, as are benchmarks when Options.Bench is set.
+func (r *Runner) CollectFile(filename string, src []byte) ([]string, error) {
+	predeclared, err := r.loadPreludes(r.buildPredeclared())
+	if err != nil {
+		return nil, err
+	}
 
-			// Clear test-scoped fixture cache between tests
-			fixtureRegistry.ClearTestCache()
+	conftestFixtures, err := r.loadConftestFixtures(filename, predeclared)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &starlark.Thread{Name: filename, Load: r.makeLoad(filename, predeclared)}
+	r.limitSteps(thread)
+	globals, err := starlark.ExecFile(thread, filename, src, predeclared)
+	if err != nil {
+		return nil, fmt.Errorf("executing %s: %w", filename, err)
+	}
+
+	fixtureRegistry := r.mergeFixtureRegistries(conftestFixtures, FindFixtures(globals))
+	testParams := r.extractTestParams(globals)
+	testMeta := r.extractTestMeta(globals)
 
-			// Fail-fast: stop after first failure
-			if r.opts.FailFast && !testResult.Passed {
-				break
+	var names []string
+	for _, name := range r.shuffle(filename, r.findTestFunctions(globals)) {
+		if !r.matchesMarkerFilter(testMeta[name]) {
+			continue
+		}
+		fn := globals[name].(*starlark.Function)
+		params, ok := testParams[name]
+		if !ok {
+			for _, variant := range fixtureRegistry.variants(fn, 0) {
+				if testName := variant.name(name); r.matchesFilter(testName) {
+					names = append(names, testName)
+				}
+			}
+			continue
+		}
+		for _, pc := range params {
+			for _, variant := range fixtureRegistry.variants(fn, 1) {
+				if virtualName := variant.name(pc.virtualName(name)); r.matchesFilter(virtualName) {
+					names = append(names, virtualName)
+				}
 			}
 		}
 	}
-
-	result.Duration = time.Since(start)
-	return result, nil
+	if r.opts.Bench {
+		for _, name := range findBenchFunctions(globals) {
+			if r.matchesMarkerFilter(testMeta[name]) && r.matchesFilter(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
 }
 
 // loadConftestFixtures searches for conftest.star files up the directory tree
@@ -429,7 +611,8 @@ func (r *Runner) loadConftestFixtures(filename string, predeclared starlark.Stri
 	// Find conftest.star files from the test file's directory up to root
 	conftestPaths := r.findConftestFiles(filename)
 
-	// Load conftest files in order from root to leaf (so closer ones override)
+	// Load conftest files in order from root to leaf, so fixtures in nearer
+	// conftest files override same-named fixtures from farther ones
 	for i := len(conftestPaths) - 1; i >= 0; i-- {
 		conftestPath := conftestPaths[i]
 		src, err := os.ReadFile(conftestPath)
@@ -437,7 +620,7 @@ func (r *Runner) loadConftestFixtures(filename string, predeclared starlark.Stri
 			return nil, fmt.Errorf("reading conftest %s: %w", conftestPath, err)
 		}
 
-		thread := &starlark.Thread{Name: conftestPath}
+		thread := &starlark.Thread{Name: conftestPath, Load: r.makeLoad(conftestPath, predeclared)}
 		globals, err := starlark.ExecFile(thread, conftestPath, src, predeclared)
 		if err != nil {
 			return nil, fmt.Errorf("executing conftest %s: %w", conftestPath, err)
@@ -447,9 +630,11 @@ func (r *Runner) loadConftestFixtures(filename string, predeclared starlark.Stri
 		conftestFixtures := FindFixtures(globals)
 		for name, fixture := range conftestFixtures.fixtures {
 			registry.Register(&Fixture{
-				Name:  name,
-				Fn:    fixture.Fn,
-				Scope: fixture.Scope,
+				Name:    name,
+				Fn:      fixture.Fn,
+				Scope:   fixture.Scope,
+				Mutable: fixture.Mutable,
+				Params:  fixture.Params,
 			})
 		}
 	}
@@ -457,7 +642,17 @@ func (r *Runner) loadConftestFixtures(filename string, predeclared starlark.Stri
 	return registry, nil
 }
 
-// findConftestFiles finds conftest.star files from the test file's directory up to root.
+// ConftestFiles returns the conftest.star files that apply to a test file,
+// nearest first.
+func (r *Runner) ConftestFiles(filename string) []string {
+	return r.findConftestFiles(filename)
+}
+
+// findConftestFiles finds conftest.star files from the test file's directory
+// up to the conftest root, nearest first. The root is Options.ConftestRoot if
+// set, otherwise the nearest ancestor containing a workspace marker (see
+// plugins.WorkspaceMarkers). If there
+// is no such ancestor, the search continues to the filesystem root.
 func (r *Runner) findConftestFiles(filename string) []string {
 	var conftestPaths []string
 
@@ -469,6 +664,15 @@ func (r *Runner) findConftestFiles(filename string) []string {
 		}
 	}
 
+	root := r.opts.ConftestRoot
+	if root != "" {
+		if absRoot, err := filepath.Abs(root); err == nil {
+			root = absRoot
+		}
+	} else {
+		root = plugins.NearestWorkspaceRoot(dir)
+	}
+
 	// Walk up the directory tree looking for conftest.star files
 	for {
 		conftestPath := filepath.Join(dir, "conftest.star")
@@ -476,9 +680,12 @@ func (r *Runner) findConftestFiles(filename string) []string {
 			conftestPaths = append(conftestPaths, conftestPath)
 		}
 
+		if dir == root {
+			break // reached conftest root
+		}
 		parent := filepath.Dir(dir)
 		if parent == dir {
-			break // reached root
+			break // reached filesystem root
 		}
 		dir = parent
 	}
@@ -486,6 +693,12 @@ func (r *Runner) findConftestFiles(filename string) []string {
 	return conftestPaths
 }
 
+// makeLoad returns the load function for the top-level code of filename,
+// which runs with predeclared. "//" labels resolve against the conftest root.
+func (r *Runner) makeLoad(filename string, predeclared starlark.StringDict) func(*starlark.Thread, string) (starlark.StringDict, error) {
+	return MakeLoad(LoadRoot(r.opts.ConftestRoot, filename), predeclared)
+}
+
 // mergeFixtureRegistries merges multiple fixture registries.
 // Later registries override earlier ones.
 func (r *Runner) mergeFixtureRegistries(registries ...*FixtureRegistry) *FixtureRegistry {
@@ -496,9 +709,11 @@ func (r *Runner) mergeFixtureRegistries(registries ...*FixtureRegistry) *Fixture
 		}
 		for name, fixture := range reg.fixtures {
 			merged.Register(&Fixture{
-				Name:  name,
-				Fn:    fixture.Fn,
-				Scope: fixture.Scope,
+				Name:    name,
+				Fn:      fixture.Fn,
+				Scope:   fixture.Scope,
+				Mutable: fixture.Mutable,
+				Params:  fixture.Params,
 			})
 		}
 	}
@@ -525,6 +740,15 @@ func (r *Runner) buildPredeclared() starlark.StringDict {
 	// Add json module for JSON parsing/serialization in tests
 	predeclared["json"] = json.Module
 
+	// Add the read-only env module (fails unless AllowEnv is set)
+	allowlist := append(slices.Clone(DefaultEnvAllowlist), r.opts.EnvAllowlist...)
+	predeclared["env"] = NewEnvModule(r.opts.AllowEnv, allowlist)
+
+	// Add test_output_dir() and write_test_output() for test artifacts
+	for name, fn := range OutputBuiltins() {
+		predeclared[name] = fn
+	}
+
 	return predeclared
 }
 
@@ -548,7 +772,7 @@ func (r *Runner) loadPreludes(basePredeclared starlark.StringDict) (starlark.Str
 			return nil, fmt.Errorf("reading prelude %s: %w", preludePath, err)
 		}
 
-		thread := &starlark.Thread{Name: preludePath}
+		thread := &starlark.Thread{Name: preludePath, Load: r.makeLoad(preludePath, combined)}
 		globals, err := starlark.ExecFile(thread, preludePath, src, combined)
 		if err != nil {
 			return nil, fmt.Errorf("executing prelude %s: %w", preludePath, err)
@@ -577,6 +801,55 @@ func (r *Runner) findTestFunctions(globals starlark.StringDict) []string {
 	return names
 }
 
+// shuffle reorders names pseudo-randomly if Options.Shuffle is set. The order
+// depends only on the seed and filename, not on which other files run.
+func (r *Runner) shuffle(filename string, names []string) []string {
+	if !r.opts.Shuffle {
+		return names
+	}
+	h := fnv.New64a()
+	h.Write([]byte(filename))
+	rng := rand.New(rand.NewPCG(uint64(r.opts.Seed), h.Sum64()))
+	rng.Shuffle(len(names), func(i, j int) {
+		names[i], names[j] = names[j], names[i]
+	})
+	return names
+}
+
+// prioritize moves the tests listed in Options.RunFirst for filename to the
+// front, keeping the relative order of both groups.
+func (r *Runner) prioritize(filename string, names []string) []string {
+	if len(r.opts.RunFirst) == 0 {
+		return names
+	}
+	first := make(map[string]bool)
+	for _, id := range r.opts.RunFirst {
+		file, name, ok := strings.Cut(id, "::")
+		if !ok || file != filename {
+			continue
+		}
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		first[name] = true
+	}
+	if len(first) == 0 {
+		return names
+	}
+	sorted := make([]string, 0, len(names))
+	for _, name := range names {
+		if first[name] {
+			sorted = append(sorted, name)
+		}
+	}
+	for _, name := range names {
+		if !first[name] {
+			sorted = append(sorted, name)
+		}
+	}
+	return sorted
+}
+
 // TestMeta holds metadata for a test function from __test_meta__.
 type TestMeta struct {
 	// Skip indicates the test should be skipped.
@@ -741,6 +1014,7 @@ func (r *Runner) runSingleTest(
 
 	// Create a fresh thread for this test
 	testThread := &starlark.Thread{Name: name}
+	r.limitSteps(testThread)
 
 	// EXPERIMENTAL: Enable coverage collection for this test thread
 	r.setupCoverageHook(testThread)
@@ -751,14 +1025,17 @@ func (r *Runner) runSingleTest(
 		testThread.SetLocal(SnapshotManagerKey, r.snapshot)
 	}
 
-	// Set up timeout cancellation if configured
-	var timer *time.Timer
-	if r.opts.Timeout > 0 {
-		timer = time.AfterFunc(r.opts.Timeout, func() {
-			testThread.Cancel(fmt.Sprintf("test timeout after %s", r.opts.Timeout))
-		})
-		defer timer.Stop()
+	// Give the test a clean output directory if configured
+	cleanup, err := r.prepareOutputDir(testThread, filename, name)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(start)
+		return result
 	}
+	defer cleanup()
+
+	// Set up timeout and deadline cancellation if configured
+	defer r.watchdog(testThread, "test")()
 
 	// Run setup if present
 	if setupFn != nil {
@@ -783,7 +1060,7 @@ func (r *Runner) runSingleTest(
 	}
 
 	// Run the test with fixture arguments
-	_, err := starlark.Call(testThread, testFn, args, nil)
+	_, err = starlark.Call(testThread, testFn, args, nil)
 	if err != nil {
 		result.Error = err
 	} else {
@@ -882,6 +1159,7 @@ func (r *Runner) extractTestParams(globals starlark.StringDict) map[string][]par
 func (r *Runner) runParametrizedTest(
 	_ *starlark.Thread,
 	name string,
+	filename string,
 	testFn *starlark.Function,
 	setupFn *starlark.Function,
 	teardownFn *starlark.Function,
@@ -894,18 +1172,22 @@ func (r *Runner) runParametrizedTest(
 
 	// Create a fresh thread for this test
 	testThread := &starlark.Thread{Name: name}
+	r.limitSteps(testThread)
 
 	// EXPERIMENTAL: Enable coverage collection for this test thread
 	r.setupCoverageHook(testThread)
 
-	// Set up timeout cancellation if configured
-	var timer *time.Timer
-	if r.opts.Timeout > 0 {
-		timer = time.AfterFunc(r.opts.Timeout, func() {
-			testThread.Cancel(fmt.Sprintf("test timeout after %s", r.opts.Timeout))
-		})
-		defer timer.Stop()
+	// Give the test a clean output directory if configured
+	cleanup, err := r.prepareOutputDir(testThread, filename, name)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(start)
+		return result
 	}
+	defer cleanup()
+
+	// Set up timeout and deadline cancellation if configured
+	defer r.watchdog(testThread, "test")()
 
 	// Run setup if present
 	if setupFn != nil {
@@ -934,7 +1216,7 @@ func (r *Runner) runParametrizedTest(
 	}
 
 	// Run the test with the case dict as argument
-	_, err := starlark.Call(testThread, testFn, args, nil)
+	_, err = starlark.Call(testThread, testFn, args, nil)
 	if err != nil {
 		result.Error = err
 	} else {
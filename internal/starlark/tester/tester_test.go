@@ -1,8 +1,17 @@
 package tester
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/albertocavalcante/sky/internal/starlark/builtins"
 )
 
 func TestRunnerBasic(t *testing.T) {
@@ -180,6 +189,142 @@ func TestAssertEq(t *testing.T) {
 	}
 }
 
+func TestAssertEqDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "dict value and keys",
+			src:  `assert.eq({"a": 1, "b": 2, "same": 0}, {"a": 1, "b": 3, "c": 4, "same": 0})`,
+			want: []string{
+				`differences:`,
+				`["b"]: 2 != 3`,
+				`["c"]: only in second: 4`,
+			},
+			notWant: []string{`["a"]:`, `["same"]:`},
+		},
+		{
+			name: "nested list index",
+			src:  `assert.eq({"users": [{"name": "bob"}, {"name": "al"}]}, {"users": [{"name": "bob"}, {"name": "alice"}]})`,
+			want: []string{`["users"][1]["name"]: "al" != "alice"`},
+		},
+		{
+			name: "list length",
+			src:  `assert.eq([1, 2, 3], [1, 2])`,
+			want: []string{`[2]: only in first: 3`},
+		},
+		{
+			name: "struct field",
+			src:  `assert.eq(struct(x = 1, y = 2), struct(x = 1, y = 5))`,
+			want: []string{`.y: 2 != 5`},
+		},
+		{
+			name: "custom message keeps diff",
+			src:  `assert.eq([1], [2], "lists differ")`,
+			want: []string{"assertion failed: lists differ", `[0]: 1 != 2`},
+		},
+		{
+			name:    "many differences truncated",
+			src:     `assert.eq(list(range(50)), [x + 1 for x in range(50)])`,
+			want:    []string{`[0]: 0 != 1`, "... and 30 more", "..."},
+			notWant: []string{`[20]:`},
+		},
+		{
+			name: "set elements",
+			src:  `assert.eq(set([1, 2]), set([2, 3]))`,
+			want: []string{`value: only in first: 1`, `value: only in second: 3`},
+		},
+		{
+			name:    "scalars have no diff",
+			src:     `assert.eq(1, 2)`,
+			want:    []string{"expected 1 == 2"},
+			notWant: []string{"differences:"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fullSrc := []byte("def test_it():\n    " + tc.src)
+			result, err := New(DefaultOptions()).RunFile("test.star", fullSrc)
+			if err != nil {
+				t.Fatalf("RunFile failed: %v", err)
+			}
+			if result.Tests[0].Passed {
+				t.Fatal("expected test to fail")
+			}
+			msg := result.Tests[0].Error.Error()
+			for _, want := range tc.want {
+				if !strings.Contains(msg, want) {
+					t.Errorf("expected error to contain %q, got:\n%s", want, msg)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(msg, notWant) {
+					t.Errorf("expected error not to contain %q, got:\n%s", notWant, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestValueDiffSortedKeys(t *testing.T) {
+	a := starlark.NewDict(3)
+	b := starlark.NewDict(3)
+	for _, k := range []string{"z", "m", "a"} {
+		_ = a.SetKey(starlark.String(k), starlark.MakeInt(1))
+	}
+	for _, k := range []string{"a", "m", "z"} {
+		_ = b.SetKey(starlark.String(k), starlark.MakeInt(2))
+	}
+
+	want := "differences:\n  [\"a\"]: 1 != 2\n  [\"m\"]: 1 != 2\n  [\"z\"]: 1 != 2"
+	if got := valueDiff(a, b); got != want {
+		t.Errorf("expected keys in sorted order:\n%s\ngot:\n%s", want, got)
+	}
+	if got := valueDiff(b, a); !strings.HasPrefix(got, "differences:\n  [\"a\"]") {
+		t.Errorf("expected diff independent of insertion order, got:\n%s", got)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	var src strings.Builder
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		src.WriteString("def test_" + name + "():\n    pass\n")
+	}
+	order := func(opts Options) []string {
+		result, err := New(opts).RunFile("test.star", []byte(src.String()))
+		if err != nil {
+			t.Fatalf("RunFile failed: %v", err)
+		}
+		var names []string
+		for _, tr := range result.Tests {
+			names = append(names, tr.Name)
+		}
+		return names
+	}
+
+	opts := DefaultOptions()
+	sorted := order(opts)
+
+	opts.Shuffle = true
+	opts.Seed = 42
+	first := order(opts)
+	if strings.Join(first, ",") != strings.Join(order(opts), ",") {
+		t.Fatalf("expected the same seed to give the same order, got %v", first)
+	}
+	if strings.Join(first, ",") == strings.Join(sorted, ",") {
+		t.Errorf("expected a shuffled order, got %v", first)
+	}
+
+	opts.Seed = 7
+	if strings.Join(first, ",") == strings.Join(order(opts), ",") {
+		t.Errorf("expected a different seed to give a different order, got %v twice", first)
+	}
+}
+
 func TestAssertContains(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -340,6 +485,18 @@ func TestTextReporter(t *testing.T) {
 	if !strings.Contains(output, "FAIL") {
 		t.Error("expected FAIL in output")
 	}
+	if strings.Contains(output, "\033[") {
+		t.Errorf("expected no color codes without Color:\n%s", output)
+	}
+
+	buf.Reset()
+	reporter.Color = true
+	if err := reporter.Report(&buf, &result.Files[0]); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "\033[32mPASS\033[0m") || !strings.Contains(buf.String(), "\033[31mFAIL\033[0m") {
+		t.Errorf("expected colored statuses:\n%q", buf.String())
+	}
 }
 
 func TestMarkdownReporter(t *testing.T) {
@@ -658,3 +815,538 @@ func TestEscapeGitHubValue(t *testing.T) {
 		}
 	}
 }
+
+func TestConftestHierarchy(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "MODULE.bazel"), "")
+	// Above the workspace root: must not be loaded.
+	writeTestFile(t, filepath.Join(filepath.Dir(root), "conftest.star"), "")
+	writeTestFile(t, filepath.Join(root, "conftest.star"), `
+def fixture_env():
+    return "root"
+
+def fixture_region():
+    return "us"
+`)
+	writeTestFile(t, filepath.Join(root, "pkg", "conftest.star"), `
+def fixture_env():
+    return "pkg"
+`)
+	writeTestFile(t, filepath.Join(root, "pkg", "sub", "conftest.star"), `
+def fixture_user():
+    return "alice"
+`)
+	testFile := filepath.Join(root, "pkg", "sub", "deep", "test_conftest.star")
+	writeTestFile(t, testFile, "")
+
+	runner := New(DefaultOptions())
+	got := runner.findConftestFiles(testFile)
+	want := []string{
+		filepath.Join(root, "pkg", "sub", "conftest.star"),
+		filepath.Join(root, "pkg", "conftest.star"),
+		filepath.Join(root, "conftest.star"),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected conftest files:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	src := []byte(`
+def test_merged(env, region, user):
+    assert.eq(env, "pkg")
+    assert.eq(region, "us")
+    assert.eq(user, "alice")
+`)
+	result, err := runner.RunFile(testFile, src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+	if len(result.Tests) != 1 || !result.Tests[0].Passed {
+		t.Fatalf("expected merged fixtures test to pass, got %+v", result.Tests)
+	}
+}
+
+func TestConftestRootOption(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "conftest.star"), "")
+	writeTestFile(t, filepath.Join(root, "pkg", "conftest.star"), "")
+	testFile := filepath.Join(root, "pkg", "test_x.star")
+
+	opts := DefaultOptions()
+	opts.ConftestRoot = filepath.Join(root, "pkg")
+	got := New(opts).findConftestFiles(testFile)
+	if len(got) != 1 || got[0] != filepath.Join(root, "pkg", "conftest.star") {
+		t.Fatalf("expected only the pkg conftest, got %v", got)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParametrizedFixtures(t *testing.T) {
+	src := []byte(`
+def fixture_db(param, region):
+    return {"backend": param["name"], "region": region}
+
+def fixture_uses_db(db):
+    return db["backend"]
+
+__fixture_params__ = {
+    "db": [{"name": "sqlite"}, {"name": "postgres"}],
+    "region": ["us", "eu"],
+}
+
+__test_params__ = {
+    "test_case": [{"name": "a"}],
+}
+
+def test_direct(db):
+    assert.true(db["backend"] in ("sqlite", "postgres"))
+    assert.true(db["region"] in ("us", "eu"))
+
+def test_indirect(uses_db):
+    assert.true(uses_db in ("sqlite", "postgres"))
+
+def test_case(case, region):
+    assert.eq(case["name"], "a")
+
+def test_plain():
+    pass
+`)
+
+	runner := New(DefaultOptions())
+	result, err := runner.RunFile("test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+
+	var got []string
+	for _, tr := range result.Tests {
+		if !tr.Passed {
+			t.Errorf("expected %s to pass, got %v", tr.Name, tr.Error)
+		}
+		got = append(got, tr.Name)
+	}
+	want := []string{
+		"test_case[a-region=us]",
+		"test_case[a-region=eu]",
+		"test_direct[db=sqlite-region=us]",
+		"test_direct[db=sqlite-region=eu]",
+		"test_direct[db=postgres-region=us]",
+		"test_direct[db=postgres-region=eu]",
+		"test_indirect[db=sqlite-region=us]",
+		"test_indirect[db=sqlite-region=eu]",
+		"test_indirect[db=postgres-region=us]",
+		"test_indirect[db=postgres-region=eu]",
+		"test_plain",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected tests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	collected, err := runner.CollectFile("test.star", src)
+	if err != nil {
+		t.Fatalf("CollectFile failed: %v", err)
+	}
+	if strings.Join(collected, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected collected tests to match run tests, got:\n%s", strings.Join(collected, "\n"))
+	}
+}
+
+func TestParametrizedFixtureFileScope(t *testing.T) {
+	src := []byte(`
+def fixture_conn(param):
+    return param
+
+__fixture_config__ = {"conn": "file"}
+__fixture_params__ = {"conn": ["a", "b"]}
+
+def test_only_b(conn):
+    assert.eq(conn, "b")
+`)
+
+	result, err := New(DefaultOptions()).RunFile("test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+	if len(result.Tests) != 2 {
+		t.Fatalf("expected 2 tests, got %d", len(result.Tests))
+	}
+	// The file-scoped value cached for "a" must not leak into the "b" variant.
+	if result.Tests[0].Name != "test_only_b[conn=a]" || result.Tests[0].Passed {
+		t.Errorf("expected test_only_b[conn=a] to fail, got %+v", result.Tests[0])
+	}
+	if result.Tests[1].Name != "test_only_b[conn=b]" || !result.Tests[1].Passed {
+		t.Errorf("expected test_only_b[conn=b] to pass, got %+v", result.Tests[1])
+	}
+}
+
+func TestBenchmarks(t *testing.T) {
+	src := []byte(`
+def test_ok():
+    pass
+
+def bench_concat():
+    "a" + "b"
+
+def bench_other():
+    pass
+
+def bench_fails():
+    fail("boom")
+
+def bench_params(x):
+    pass
+
+__test_meta__ = {"bench_other": {"skip": True}}
+`)
+
+	opts := DefaultOptions()
+	opts.Bench = true
+	opts.BenchTime = 10 * time.Millisecond
+	result, err := New(opts).RunFile("test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+
+	if len(result.Tests) != 1 {
+		t.Fatalf("expected bench functions not to run as tests, got %d tests", len(result.Tests))
+	}
+	var names []string
+	for _, b := range result.Benchmarks {
+		names = append(names, b.Name)
+	}
+	if got := strings.Join(names, ","); got != "bench_concat,bench_fails,bench_params" {
+		t.Fatalf("expected benchmarks bench_concat,bench_fails,bench_params, got %s", got)
+	}
+
+	concat := result.Benchmarks[0]
+	if concat.Error != nil || concat.Iterations < 2 || concat.Duration < opts.BenchTime || concat.NsPerOp() <= 0 {
+		t.Errorf("expected bench_concat to scale iterations to the bench time, got %+v", concat)
+	}
+	if result.Benchmarks[1].Error == nil || result.Benchmarks[2].Error == nil {
+		t.Errorf("expected bench_fails and bench_params to fail")
+	}
+	if !result.HasFailures() {
+		t.Error("expected failing benchmark to fail the file")
+	}
+
+	var text, js bytes.Buffer
+	run := &RunResult{Files: []FileResult{*result}}
+	(&TextReporter{}).ReportSummary(&text, run)
+	if !strings.Contains(text.String(), "Benchmarks:") || !strings.Contains(text.String(), "test.star::bench_concat") || !strings.Contains(text.String(), "ns/op") {
+		t.Errorf("expected benchmark section in text summary, got:\n%s", text.String())
+	}
+	(&JSONReporter{}).ReportSummary(&js, run)
+	var decoded struct {
+		Results []struct {
+			Benchmarks []struct {
+				Name       string  `json:"name"`
+				Iterations int     `json:"iterations"`
+				NsPerOp    float64 `json:"ns_per_op"`
+				Error      string  `json:"error"`
+			} `json:"benchmarks"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatalf("expected valid JSON, got %v:\n%s", err, js.String())
+	}
+	if len(decoded.Results) != 1 || len(decoded.Results[0].Benchmarks) != 3 || decoded.Results[0].Benchmarks[0].Iterations != concat.Iterations {
+		t.Errorf("expected benchmarks in JSON output, got:\n%s", js.String())
+	}
+}
+
+func TestBenchmarksDisabled(t *testing.T) {
+	src := []byte(`
+def bench_concat():
+    pass
+`)
+	result, err := New(DefaultOptions()).RunFile("test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+	if len(result.Benchmarks) != 0 {
+		t.Fatalf("expected no benchmarks without Bench, got %d", len(result.Benchmarks))
+	}
+}
+
+func TestNativeBuiltins(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Predeclared = NativeBuiltins(builtins.Builtins{
+		Functions: []builtins.Signature{
+			{Name: "cc_library", Params: []builtins.Param{{Name: "name"}, {Name: "srcs"}, {Name: "$private"}}},
+			{Name: "cc_test", Params: []builtins.Param{{Name: "name"}, {Name: "deps"}}},
+		},
+	})
+	src := []byte(`
+def cc_lib_with_test(name, srcs):
+    native.cc_library(name = name, srcs = srcs)
+    cc_test(name = name + "_test", deps = [":" + name])
+
+def test_macro_declares_rules():
+    cc_lib_with_test("foo", ["foo.cc"])
+    rules = native.existing_rules()
+    assert.eq(list(rules.keys()), ["foo", "foo_test"])
+    assert.eq(rules["foo"], {"name": "foo", "srcs": ["foo.cc"], "kind": "cc_library"})
+    assert.eq(native.existing_rule("foo_test")["deps"], [":foo"])
+    assert.eq(native.existing_rule("bar"), None)
+
+def test_rules_are_per_test():
+    assert.eq(native.existing_rules(), {})
+
+def test_unknown_attribute():
+    assert.fails(lambda: native.cc_library(name = "x", hdrs = []), "no such attribute")
+
+def test_private_attribute():
+    assert.fails(lambda: cc_library(name = "x", **{"$private": 1}), "no such attribute")
+
+def test_missing_name():
+    assert.fails(lambda: cc_library(srcs = []), "missing mandatory attribute")
+
+def test_duplicate_name():
+    cc_library(name = "x")
+    assert.fails(lambda: cc_library(name = "x"), "already declared")
+
+def test_package():
+    assert.eq(native.package_name(), "")
+    assert.eq(native.repository_name(), "@")
+`)
+	result, err := New(opts).RunFile("test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+	for _, tr := range result.Tests {
+		if !tr.Passed {
+			t.Errorf("expected %s to pass, got: %v", tr.Name, tr.Error)
+		}
+	}
+	if len(result.Tests) != 7 {
+		t.Fatalf("expected 7 tests, got %d", len(result.Tests))
+	}
+}
+
+func TestSerializeStarlarkValueSortsKeys(t *testing.T) {
+	a := starlark.NewDict(2)
+	_ = a.SetKey(starlark.String("b"), starlark.MakeInt(2))
+	_ = a.SetKey(starlark.String("a"), starlark.MakeInt(1))
+	b := starlark.NewDict(2)
+	_ = b.SetKey(starlark.String("a"), starlark.MakeInt(1))
+	_ = b.SetKey(starlark.String("b"), starlark.MakeInt(2))
+
+	if SerializeStarlarkValue(a) != SerializeStarlarkValue(b) {
+		t.Errorf("expected insertion order not to affect the snapshot:\n%s\n%s", SerializeStarlarkValue(a), SerializeStarlarkValue(b))
+	}
+}
+
+func TestMaxSteps(t *testing.T) {
+	src := []byte(`
+def test_small():
+    assert.eq(len([x for x in range(10)]), 10)
+
+def test_large():
+    [x for x in range(100000)]
+`)
+	opts := DefaultOptions()
+	opts.MaxSteps = 10000
+	result, err := New(opts).RunFile("test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+	for _, tr := range result.Tests {
+		switch tr.Name {
+		case "test_small":
+			if !tr.Passed {
+				t.Errorf("expected test_small to pass, got: %v", tr.Error)
+			}
+		case "test_large":
+			if tr.Passed || !strings.Contains(tr.Error.Error(), "exceeded limit of 10000 execution steps") {
+				t.Errorf("expected test_large to exceed the step limit, got: %v", tr.Error)
+			}
+		}
+	}
+
+	// Top-level code is limited too
+	_, err = New(opts).RunFile("test.star", []byte("X = [x for x in range(100000)]\n"))
+	if err == nil || !strings.Contains(err.Error(), "execution steps") {
+		t.Errorf("expected top-level code to exceed the step limit, got: %v", err)
+	}
+}
+
+func TestRunnerOutputDir(t *testing.T) {
+	base := t.TempDir()
+	stale := filepath.Join(base, "pkg", "out_test.star", "test_write", "stale.txt")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := []byte(`
+def test_write():
+    path = write_test_output("sub/report.txt", "hello")
+    assert.true(path.startswith(test_output_dir()))
+
+def test_nothing():
+    assert.true(test_output_dir())
+
+def test_escape():
+    write_test_output("../x.txt", "nope")
+
+__test_params__ = {"test_param": [{"name": "a b"}]}
+
+def test_param(case):
+    write_test_output("case.txt", case["name"])
+`)
+	opts := DefaultOptions()
+	opts.OutputDir = base
+	result, err := New(opts).RunFile("pkg/out_test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+
+	for _, test := range result.Tests {
+		if wantPass := test.Name != "test_escape"; test.Passed != wantPass {
+			t.Errorf("%s: passed = %v, error = %v", test.Name, test.Passed, test.Error)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(base, "pkg", "out_test.star", "test_write", "sub", "report.txt")); err != nil || string(data) != "hello" {
+		t.Errorf("report.txt = %q, %v", data, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale artifact was not cleaned: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "pkg", "out_test.star", "test_nothing")); !os.IsNotExist(err) {
+		t.Errorf("empty output directory was not removed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(base, "pkg", "out_test.star", "test_param_a_b_", "case.txt")); err != nil || string(data) != "a b" {
+		t.Errorf("case.txt = %q, %v", data, err)
+	}
+}
+
+func TestRunnerOutputDir_Unset(t *testing.T) {
+	result, err := New(DefaultOptions()).RunFile("test.star", []byte(`
+def test_dir():
+    test_output_dir()
+`))
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+	if len(result.Tests) != 1 || result.Tests[0].Passed || !strings.Contains(result.Tests[0].Error.Error(), "--output-dir") {
+		t.Errorf("expected a failure mentioning --output-dir, got %+v", result.Tests)
+	}
+}
+
+func TestRunnerEnv(t *testing.T) {
+	t.Setenv("CI", "true")
+	t.Setenv("SKYTEST_TOKEN", "secret")
+	t.Setenv("HOME", "/home/test")
+	src := []byte(`
+def test_ci():
+    assert.eq(env.get("CI"), "true")
+
+def test_allowlisted():
+    assert.true(env.has("SKYTEST_TOKEN"))
+
+def test_unset_default():
+    assert.eq(env.get("GITLAB_CI", "no"), "no")
+    assert.false(env.has("GITLAB_CI"))
+
+def test_not_allowlisted():
+    env.get("HOME")
+`)
+
+	tests := []struct {
+		name     string
+		allow    bool
+		wantFail map[string]string
+	}{
+		{
+			name: "disabled",
+			wantFail: map[string]string{
+				"test_ci":              "--allow-env",
+				"test_allowlisted":     "--allow-env",
+				"test_unset_default":   "--allow-env",
+				"test_not_allowlisted": "--allow-env",
+			},
+		},
+		{
+			name:     "enabled",
+			allow:    true,
+			wantFail: map[string]string{"test_not_allowlisted": `"HOME" is not in the allowlist`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITLAB_CI", "")
+			_ = os.Unsetenv("GITLAB_CI")
+
+			opts := DefaultOptions()
+			opts.AllowEnv = tt.allow
+			opts.EnvAllowlist = []string{"SKYTEST_TOKEN"}
+			result, err := New(opts).RunFile("env_test.star", src)
+			if err != nil {
+				t.Fatalf("RunFile failed: %v", err)
+			}
+			for _, test := range result.Tests {
+				want, shouldFail := tt.wantFail[test.Name]
+				switch {
+				case shouldFail && (test.Passed || !strings.Contains(test.Error.Error(), want)):
+					t.Errorf("%s: expected failure containing %q, got %v", test.Name, want, test.Error)
+				case !shouldFail && !test.Passed:
+					t.Errorf("%s: unexpected failure: %v", test.Name, test.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestFileScopedFixtureFrozen(t *testing.T) {
+	src := []byte(`
+def fixture_shared():
+    return {"items": []}
+
+def fixture_scratch():
+    return []
+
+def fixture_local():
+    return []
+
+__fixture_config__ = {
+    "shared": "file",
+    "scratch": {"scope": "file", "mutable": True},
+}
+
+def test_mutate_shared(shared):
+    shared["items"].append(1)
+
+def test_read_shared(shared):
+    assert.eq(shared["items"], [])
+
+def test_mutate_scratch(scratch):
+    scratch.append(1)
+
+def test_mutate_local(local):
+    local.append(1)
+`)
+
+	result, err := New(DefaultOptions()).RunFile("test.star", src)
+	if err != nil {
+		t.Fatalf("RunFile failed: %v", err)
+	}
+	for _, test := range result.Tests {
+		if test.Name == "test_mutate_shared" {
+			if test.Passed || !strings.Contains(test.Error.Error(), "frozen") {
+				t.Errorf("expected mutating a shared fixture to fail, got %v", test.Error)
+			}
+		} else if !test.Passed {
+			t.Errorf("%s: unexpected failure: %v", test.Name, test.Error)
+		}
+	}
+}
@@ -137,6 +137,36 @@ func (w *Watcher) Add(testFile string) error {
 	return nil
 }
 
+// AddDependency watches a file that the given test files depend on without
+// loading it, such as a prelude or conftest.star. A change to dep reports
+// the test files as affected.
+func (w *Watcher) AddDependency(dep string, testFiles ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	depPath, err := filepath.Abs(dep)
+	if err != nil {
+		return fmt.Errorf("getting absolute path: %w", err)
+	}
+
+	if err := w.fsWatcher.Add(depPath); err != nil {
+		return fmt.Errorf("watching %s: %w", depPath, err)
+	}
+
+	if w.dependencies[depPath] == nil {
+		w.dependencies[depPath] = make(map[string]bool)
+	}
+	for _, testFile := range testFiles {
+		absTest, err := filepath.Abs(testFile)
+		if err != nil {
+			return fmt.Errorf("getting absolute path: %w", err)
+		}
+		w.dependencies[depPath][absTest] = true
+	}
+
+	return nil
+}
+
 // trackTransitiveDeps recursively tracks dependencies.
 func (w *Watcher) trackTransitiveDeps(file, testFile string) {
 	deps, err := w.extractLoads(file)
@@ -333,3 +333,46 @@ def test_value():
 		t.Errorf("expected 1 affected test for helper2.star after refresh, got %d", len(affected))
 	}
 }
+
+func TestWatcher_AddDependency(t *testing.T) {
+	dir := t.TempDir()
+
+	conftest := filepath.Join(dir, "conftest.star")
+	prelude := filepath.Join(dir, "prelude.star")
+	testA := filepath.Join(dir, "test_a.star")
+	testB := filepath.Join(dir, "test_b.star")
+	for _, f := range []string{conftest, prelude, testA, testB} {
+		if err := os.WriteFile(f, []byte("x = 1\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", f, err)
+		}
+	}
+
+	watcher, err := NewWatcher(dir)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	for _, f := range []string{testA, testB} {
+		if err := watcher.Add(f); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := watcher.AddDependency(prelude, testA, testB); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+	if err := watcher.AddDependency(conftest, testA); err != nil {
+		t.Fatalf("AddDependency failed: %v", err)
+	}
+
+	if got := watcher.AffectedTestFiles(prelude); len(got) != 2 {
+		t.Errorf("expected prelude to affect 2 tests, got %v", got)
+	}
+	if got := watcher.AffectedTestFiles(conftest); len(got) != 1 || got[0] != testA {
+		t.Errorf("expected conftest to affect %s, got %v", testA, got)
+	}
+
+	if err := watcher.AddDependency(filepath.Join(dir, "missing.star"), testA); err == nil {
+		t.Error("expected error watching a missing file")
+	}
+}
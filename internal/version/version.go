@@ -2,9 +2,14 @@
 package version
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"runtime"
 	"runtime/debug"
 	"strings"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
 )
 
 var (
@@ -16,17 +21,19 @@ var (
 
 // Info is the immutable identity of a built binary.
 type Info struct {
-	Version string
-	Commit  string
-	Date    string
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
 }
 
 // Current returns the build identity embedded in this binary.
 func Current() Info {
 	info := Info{
-		Version: clean(version, "dev"),
-		Commit:  clean(commit, "unknown"),
-		Date:    clean(date, "unknown"),
+		Version:   clean(version, "dev"),
+		Commit:    clean(commit, "unknown"),
+		Date:      clean(date, "unknown"),
+		GoVersion: runtime.Version(),
 	}
 
 	if bi, ok := debug.ReadBuildInfo(); ok {
@@ -57,6 +64,18 @@ func String() string {
 	return fmt.Sprintf("%s (commit %s, built %s)", info.Version, info.Commit, info.Date)
 }
 
+// Write prints the build identity of the named tool to w: a line such as
+// "skylint v1.2.0 (commit ..., built ...)", or the Info as a JSON object
+// when asJSON is set or SKY_OUTPUT_FORMAT is "json" (as with "sky --json").
+func Write(w io.Writer, name string, asJSON bool) {
+	if asJSON || skyplugin.IsJSONOutput() {
+		data, _ := json.Marshal(Current())
+		_, _ = fmt.Fprintf(w, "%s\n", data)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%s %s\n", name, String())
+}
+
 func clean(value, fallback string) string {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -1,6 +1,14 @@
 package version
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
 
 func TestClean(t *testing.T) {
 	tests := []struct {
@@ -30,3 +38,38 @@ func TestShortCommit(t *testing.T) {
 		t.Fatalf("shortCommit() = %q, want %q", got, "abc")
 	}
 }
+
+func TestWrite(t *testing.T) {
+	t.Setenv(skyplugin.EnvOutputFormat, "")
+
+	var text bytes.Buffer
+	Write(&text, "skylint", false)
+	if !strings.HasPrefix(text.String(), "skylint ") || !strings.HasSuffix(text.String(), "\n") {
+		t.Fatalf("unexpected text output: %q", text.String())
+	}
+
+	var out bytes.Buffer
+	Write(&out, "skylint", true)
+	var got map[string]string
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("expected JSON output: %v (%q)", err, out.String())
+	}
+	for _, key := range []string{"version", "commit", "buildDate", "goVersion"} {
+		if got[key] == "" {
+			t.Fatalf("expected %q in %v", key, got)
+		}
+	}
+	if got["goVersion"] != runtime.Version() {
+		t.Fatalf("goVersion = %q, want %q", got["goVersion"], runtime.Version())
+	}
+}
+
+func TestWrite_OutputFormatEnv(t *testing.T) {
+	t.Setenv(skyplugin.EnvOutputFormat, "json")
+
+	var out bytes.Buffer
+	Write(&out, "skyfmt", false)
+	if !json.Valid(out.Bytes()) {
+		t.Fatalf("expected JSON when SKY_OUTPUT_FORMAT=json, got %q", out.String())
+	}
+}
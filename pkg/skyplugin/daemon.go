@@ -0,0 +1,205 @@
+package skyplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// MaxFrameSize bounds a single daemon protocol message.
+const MaxFrameSize = 64 << 20
+
+// DaemonRequest is one command sent by the host in daemon mode. It carries
+// what a one-shot invocation gets from its process: the args, the SKY_*
+// environment, the working directory, and the contents of stdin.
+type DaemonRequest struct {
+	Args  []string          `json:"args"`
+	Env   map[string]string `json:"env,omitempty"`
+	Dir   string            `json:"dir,omitempty"`
+	Stdin []byte            `json:"stdin,omitempty"`
+}
+
+// DaemonResponse is the outcome of a DaemonRequest: what a one-shot
+// invocation would have written to stdout and stderr, and its exit code.
+type DaemonResponse struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// serveDaemon answers requests read from in until it ends, writing one
+// response per request to out. Each request runs p.Run as a one-shot
+// invocation would: with the request's args, SKY_* environment, working
+// directory, and stdin. What Run writes to os.Stdout and os.Stderr is captured and
+// returned in the response, so it must look them up when it writes (as
+// fmt.Println and DefaultOutput do) rather than keep them from before the
+// request.
+func serveDaemon(ctx context.Context, p Plugin, in io.Reader, out io.Writer) int {
+	var env map[string]string
+	for {
+		var req DaemonRequest
+		if err := ReadFrame(in, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "%s: daemon: %v\n", p.Metadata.Name, err)
+			return ExitFailure
+		}
+
+		applyEnv(env, req.Env)
+		env = req.Env
+
+		resp := handleRequest(ctx, p, req)
+		if err := WriteFrame(out, resp); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: daemon: %v\n", p.Metadata.Name, err)
+			return ExitFailure
+		}
+	}
+}
+
+// handleRequest runs one request with os.Stdin reading the request's stdin
+// and os.Stdout and os.Stderr redirected into the response.
+func handleRequest(ctx context.Context, p Plugin, req DaemonRequest) DaemonResponse {
+	if req.Dir != "" {
+		if err := os.Chdir(req.Dir); err != nil {
+			return DaemonResponse{ExitCode: ExitFailure, Stderr: fmt.Sprintf("%s: %v\n", p.Metadata.Name, err)}
+		}
+	}
+
+	restoreStdin, err := replaceStdin(req.Stdin)
+	if err != nil {
+		return DaemonResponse{ExitCode: ExitFailure, Stderr: fmt.Sprintf("%s: %v\n", p.Metadata.Name, err)}
+	}
+	defer restoreStdin()
+
+	var resp DaemonResponse
+	stdout, stderr, err := captureOutput(func(stdout, stderr io.Writer) {
+		resp.ExitCode = run(ctx, p, req.Args, stdout, stderr)
+	})
+	if err != nil {
+		return DaemonResponse{ExitCode: ExitFailure, Stderr: fmt.Sprintf("%s: %v\n", p.Metadata.Name, err)}
+	}
+	resp.Stdout, resp.Stderr = stdout, stderr
+	return resp
+}
+
+// captureOutput calls fn with os.Stdout and os.Stderr replaced by pipes and
+// returns what was written to them.
+func captureOutput(fn func(stdout, stderr io.Writer)) (string, string, error) {
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return "", "", err
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		_ = outR.Close()
+		_ = outW.Close()
+		return "", "", err
+	}
+
+	var outBuf, errBuf bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(&outBuf, outR)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(&errBuf, errR)
+	}()
+
+	savedOut, savedErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+	func() {
+		defer func() { os.Stdout, os.Stderr = savedOut, savedErr }()
+		fn(outW, errW)
+	}()
+
+	_ = outW.Close()
+	_ = errW.Close()
+	wg.Wait()
+	_ = outR.Close()
+	_ = errR.Close()
+	return outBuf.String(), errBuf.String(), nil
+}
+
+// replaceStdin points os.Stdin at a pipe that yields data and then EOF, so
+// that a request never reads the protocol stream. The returned function
+// restores os.Stdin.
+func replaceStdin(data []byte) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_, _ = w.Write(data)
+		_ = w.Close()
+	}()
+	saved := os.Stdin
+	os.Stdin = r
+	return func() {
+		os.Stdin = saved
+		_ = r.Close()
+	}, nil
+}
+
+// applyEnv sets the variables in next and unsets those that were set for
+// the previous request but are absent from this one.
+func applyEnv(prev, next map[string]string) {
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			_ = os.Unsetenv(key)
+		}
+	}
+	for key, value := range next {
+		_ = os.Setenv(key, value)
+	}
+}
+
+// WriteFrame writes v as one daemon protocol message: a 4-byte big-endian
+// length followed by that many bytes of JSON. Hosts and plugins share it so
+// that both sides of the protocol use the same framing.
+func WriteFrame(w io.Writer, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(payload) > MaxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds limit of %d", len(payload), MaxFrameSize)
+	}
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(payload)
+	return err
+}
+
+// ReadFrame reads one daemon protocol message written by WriteFrame into v.
+// It returns io.EOF if r ends cleanly before a new message.
+func ReadFrame(r io.Reader, v any) error {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds limit of %d", size, MaxFrameSize)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
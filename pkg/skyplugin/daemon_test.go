@@ -0,0 +1,137 @@
+package skyplugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestServeDaemon(t *testing.T) {
+	t.Setenv(EnvOutputFormat, "")
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+
+	p := Plugin{
+		Metadata: Metadata{Name: "echo"},
+		Run: func(_ context.Context, args []string) error {
+			if len(args) > 0 && args[0] == "fail" {
+				return Exitf(3, "failed on request")
+			}
+			wd, _ := os.Getwd()
+			fmt.Printf("args=%s json=%v dir=%v\n", strings.Join(args, ","), IsJSONOutput(), wd == dir)
+			fmt.Fprintln(os.Stderr, "note")
+			return nil
+		},
+	}
+
+	var in bytes.Buffer
+	requests := []DaemonRequest{
+		{Args: []string{"a", "b"}, Env: map[string]string{EnvOutputFormat: "json"}, Dir: dir},
+		{Args: []string{"fail"}},
+		{Args: []string{}},
+	}
+	for _, req := range requests {
+		if err := WriteFrame(&in, req); err != nil {
+			t.Fatalf("WriteFrame: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if code := serveDaemon(context.Background(), p, &in, &out); code != 0 {
+		t.Fatalf("serveDaemon returned %d, want 0", code)
+	}
+
+	want := []DaemonResponse{
+		{ExitCode: 0, Stdout: "args=a,b json=true dir=true\n", Stderr: "note\n"},
+		{ExitCode: 3, Stderr: "failed on request\n"},
+		// The previous request's SKY_OUTPUT_FORMAT is unset; the directory stays.
+		{ExitCode: 0, Stdout: "args= json=false dir=true\n", Stderr: "note\n"},
+	}
+	for i, w := range want {
+		var got DaemonResponse
+		if err := ReadFrame(&out, &got); err != nil {
+			t.Fatalf("response %d: ReadFrame: %v", i, err)
+		}
+		if got != w {
+			t.Errorf("response %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if err := ReadFrame(&out, &DaemonResponse{}); !errors.Is(err, io.EOF) {
+		t.Errorf("expected no more responses, got %v", err)
+	}
+}
+
+func TestServeDaemon_TruncatedRequest(t *testing.T) {
+	in := bytes.NewReader([]byte{0, 0, 0, 10, '{'})
+	var out bytes.Buffer
+	if code := serveDaemon(context.Background(), Plugin{Metadata: Metadata{Name: "x"}}, in, &out); code != ExitFailure {
+		t.Fatalf("serveDaemon returned %d, want %d", code, ExitFailure)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no response, got %q", out.String())
+	}
+}
+
+func TestServeDaemon_Stdin(t *testing.T) {
+	p := Plugin{
+		Metadata: Metadata{Name: "cat"},
+		Run: func(_ context.Context, _ []string) error {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("stdin=%q\n", data)
+			return nil
+		},
+	}
+
+	var in bytes.Buffer
+	for _, req := range []DaemonRequest{{Stdin: []byte("line 1\nline 2\n")}, {}} {
+		if err := WriteFrame(&in, req); err != nil {
+			t.Fatalf("WriteFrame: %v", err)
+		}
+	}
+	var out bytes.Buffer
+	if code := serveDaemon(context.Background(), p, &in, &out); code != 0 {
+		t.Fatalf("serveDaemon returned %d, want 0", code)
+	}
+
+	// A request without stdin reads EOF, not the protocol stream.
+	for i, want := range []string{"stdin=\"line 1\\nline 2\\n\"\n", "stdin=\"\"\n"} {
+		var got DaemonResponse
+		if err := ReadFrame(&out, &got); err != nil {
+			t.Fatalf("response %d: ReadFrame: %v", i, err)
+		}
+		if got.ExitCode != 0 || got.Stdout != want {
+			t.Errorf("response %d = %+v, want stdout %q", i, got, want)
+		}
+	}
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	want := DaemonRequest{Args: []string{"a"}, Env: map[string]string{"K": "V"}, Dir: "/tmp", Stdin: []byte{0, 0xff}}
+	if err := WriteFrame(&buf, want); err != nil {
+		t.Fatalf("WriteFrame: %v", err)
+	}
+	var got DaemonRequest
+	if err := ReadFrame(&buf, &got); err != nil {
+		t.Fatalf("ReadFrame: %v", err)
+	}
+	if got.Dir != want.Dir || got.Env["K"] != "V" || len(got.Args) != 1 || got.Args[0] != "a" || !bytes.Equal(got.Stdin, want.Stdin) {
+		t.Fatalf("round trip = %+v, want %+v", got, want)
+	}
+	if err := ReadFrame(&buf, &got); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF at end of stream, got %v", err)
+	}
+
+	truncated := bytes.NewReader([]byte{0, 0, 0, 5, '{'})
+	if err := ReadFrame(truncated, &got); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF for a truncated frame, got %v", err)
+	}
+}
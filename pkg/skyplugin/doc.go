@@ -23,10 +23,11 @@
 //	func main() {
 //		skyplugin.Serve(skyplugin.Plugin{
 //			Metadata: skyplugin.Metadata{
-//				APIVersion: 1,
-//				Name:       "hello",
-//				Version:    "0.1.0",
-//				Summary:    "A hello world plugin",
+//				APIVersion:   1,
+//				Name:         "hello",
+//				Version:      "0.1.0",
+//				Summary:      "A hello world plugin",
+//				Capabilities: []string{skyplugin.CapabilityWorkspaceRead},
 //			},
 //			Run: func(ctx context.Context, args []string) error {
 //				fmt.Println("Hello from Sky!")
@@ -35,6 +36,17 @@
 //		})
 //	}
 //
+// # Exit Codes
+//
+// Serve exits 0 when Run returns nil and 1 for a plain error. Return an
+// *ExitError to choose another code:
+//
+//	if len(args) == 0 {
+//		return skyplugin.Exitf(skyplugin.ExitUsage, "usage: hello <name>")
+//	}
+//
+// A panic in Run exits with ExitPanic.
+//
 // # Environment Variables
 //
 // The SDK provides helper functions to read plugin environment variables:
@@ -58,20 +70,27 @@
 //
 // # Testing
 //
-// The skyplugin/testing package provides utilities for testing plugins:
+// The skyplugin/testing package provides utilities for testing plugins.
+// RunPlugin drives a Plugin end-to-end in-process:
 //
-//	import "github.com/albertocavalcante/sky/pkg/skyplugin/testing"
+//	import skytesting "github.com/albertocavalcante/sky/pkg/skyplugin/testing"
 //
 //	func TestPlugin(t *testing.T) {
-//		cleanup := testing.MockEnv("exec", "my-plugin")
-//		defer cleanup()
-//
-//		result := testing.CaptureOutput(func() {
-//			myPluginMain()
-//		})
-//
+//		result := skytesting.RunPlugin(myPlugin, []string{"BUILD"}, "")
 //		if result.ExitCode != 0 {
 //			t.Errorf("unexpected exit code: %d", result.ExitCode)
 //		}
+//
+//		m, err := skytesting.RunMetadata(myPlugin)
+//		if err != nil || m.Name != "my-plugin" {
+//			t.Errorf("bad metadata: %+v, %v", m, err)
+//		}
 //	}
+//
+// AssertGolden compares output against a golden file and prints a diff on
+// mismatch; run the tests with SKY_UPDATE_GOLDEN=1 to rewrite golden files:
+//
+//	skytesting.AssertGolden(t, "testdata/lint.golden", []byte(result.Stdout))
+//
+// MockEnv and CaptureOutput remain available for lower-level tests.
 package skyplugin
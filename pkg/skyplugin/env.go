@@ -29,6 +29,18 @@ func IsMetadataMode() bool {
 	return os.Getenv(EnvPluginMode) == "metadata"
 }
 
+// IsDaemonMode returns true if the plugin should serve requests from stdin
+// until it is closed, instead of running once.
+func IsDaemonMode() bool {
+	return os.Getenv(EnvPluginMode) == "daemon"
+}
+
+// IsHealthMode returns true if the plugin should report whether it is ready
+// to run and exit.
+func IsHealthMode() bool {
+	return os.Getenv(EnvPluginMode) == "health"
+}
+
 // PluginName returns the name of the current plugin.
 func PluginName() string {
 	return os.Getenv(EnvPluginName)
@@ -72,17 +84,11 @@ func IsJSONOutput() bool {
 	return OutputFormat() == "json"
 }
 
-// NoColor returns true if color output should be disabled.
-// This respects both SKY_NO_COLOR and the NO_COLOR standard (https://no-color.org).
+// NoColor returns true if color output should be disabled: SKY_NO_COLOR or
+// NO_COLOR (https://no-color.org) is set to a non-empty value. Sky and its
+// tools use the same rule.
 func NoColor() bool {
-	if os.Getenv(EnvNoColor) == "1" {
-		return true
-	}
-	// Also respect the NO_COLOR standard
-	if _, ok := os.LookupEnv("NO_COLOR"); ok {
-		return true
-	}
-	return false
+	return os.Getenv(EnvNoColor) != "" || os.Getenv("NO_COLOR") != ""
 }
 
 // Verbose returns the verbosity level (0-3).
@@ -0,0 +1,47 @@
+package skyplugin
+
+import "fmt"
+
+// Exit codes used by Serve. Plugins may return any other code via ExitError.
+const (
+	// ExitFailure is the exit code for a plain error returned from Run.
+	ExitFailure = 1
+	// ExitUsage is the conventional exit code for invalid arguments,
+	// matching the core sky commands.
+	ExitUsage = 2
+	// ExitPanic is the exit code used when Run panics. It matches EX_SOFTWARE
+	// from sysexits.h so hosts can tell a crash apart from an ordinary failure.
+	ExitPanic = 70
+)
+
+// ExitError is an error that sets the plugin's exit code when returned from
+// Run. Serve prints Err (if non-nil) to stderr and exits with Code. Plain
+// errors exit with ExitFailure.
+//
+//	if len(args) == 0 {
+//		return skyplugin.Exitf(skyplugin.ExitUsage, "usage: my-plugin <file>")
+//	}
+type ExitError struct {
+	// Code is the process exit code. Values <= 0 are treated as ExitFailure.
+	Code int
+	// Err is the underlying error. It may be nil to exit silently.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Exitf returns an ExitError with the given code and a formatted message.
+func Exitf(code int, format string, args ...any) error {
+	return &ExitError{Code: code, Err: fmt.Errorf(format, args...)}
+}
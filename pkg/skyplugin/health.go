@@ -0,0 +1,61 @@
+package skyplugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MissingDependencyError is returned from Plugin.HealthCheck when the plugin
+// needs something that is not installed, such as a tool that must be in
+// PATH. The host lists Dependencies so the user knows what to install.
+//
+//	if _, err := exec.LookPath("buildozer"); err != nil {
+//		return &skyplugin.MissingDependencyError{Dependencies: []string{"buildozer"}}
+//	}
+type MissingDependencyError struct {
+	Dependencies []string
+}
+
+// Error implements the error interface.
+func (e *MissingDependencyError) Error() string {
+	return "missing dependencies: " + strings.Join(e.Dependencies, ", ")
+}
+
+// healthReport is the JSON object written in health mode.
+type healthReport struct {
+	Ready   bool     `json:"ready"`
+	Message string   `json:"message,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// serveHealth runs p.HealthCheck and writes its outcome to stdout, exiting
+// with ExitFailure when the plugin is not ready. A plugin without a
+// HealthCheck is always ready.
+func serveHealth(ctx context.Context, p Plugin, stdout io.Writer) (code int) {
+	report := healthReport{Ready: true}
+	if p.HealthCheck != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				report = healthReport{Message: fmt.Sprintf("internal error: %v", r)}
+				_ = json.NewEncoder(stdout).Encode(report)
+				code = ExitPanic
+			}
+		}()
+		if err := p.HealthCheck(ctx); err != nil {
+			report = healthReport{Message: err.Error()}
+			var missing *MissingDependencyError
+			if errors.As(err, &missing) {
+				report.Missing = missing.Dependencies
+			}
+		}
+	}
+
+	if err := json.NewEncoder(stdout).Encode(report); err != nil || !report.Ready {
+		return ExitFailure
+	}
+	return 0
+}
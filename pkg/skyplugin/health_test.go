@@ -0,0 +1,90 @@
+package skyplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExecute_Health(t *testing.T) {
+	missing := &MissingDependencyError{Dependencies: []string{"buildozer", "jq"}}
+	tests := []struct {
+		name     string
+		check    func(context.Context) error
+		wantCode int
+		want     healthReport
+	}{
+		{name: "no check", wantCode: 0, want: healthReport{Ready: true}},
+		{name: "ready", check: func(context.Context) error { return nil }, wantCode: 0, want: healthReport{Ready: true}},
+		{
+			name:     "error",
+			check:    func(context.Context) error { return errors.New("no credentials") },
+			wantCode: ExitFailure,
+			want:     healthReport{Message: "no credentials"},
+		},
+		{
+			name:     "missing dependencies",
+			check:    func(context.Context) error { return fmt.Errorf("setup: %w", missing) },
+			wantCode: ExitFailure,
+			want:     healthReport{Message: "setup: missing dependencies: buildozer, jq", Missing: []string{"buildozer", "jq"}},
+		},
+		{
+			name:     "panic",
+			check:    func(context.Context) error { panic("boom") },
+			wantCode: ExitPanic,
+			want:     healthReport{Message: "internal error: boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvPlugin, "1")
+			t.Setenv(EnvPluginMode, "health")
+
+			ran := false
+			p := Plugin{
+				Metadata:    Metadata{Name: "demo"},
+				Run:         func(context.Context, []string) error { ran = true; return nil },
+				HealthCheck: tt.check,
+			}
+			var stdout, stderr bytes.Buffer
+			if code := Execute(context.Background(), p, nil, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d", tt.wantCode, code)
+			}
+			if ran {
+				t.Error("Run was called in health mode")
+			}
+			var got healthReport
+			if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+				t.Fatalf("invalid health JSON %q: %v", stdout.String(), err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_MetadataDeclaresHealth(t *testing.T) {
+	t.Setenv(EnvPlugin, "1")
+	t.Setenv(EnvPluginMode, "metadata")
+
+	for _, p := range []Plugin{
+		{Metadata: Metadata{Name: "demo", Capabilities: []string{CapabilityNet}}, HealthCheck: func(context.Context) error { return nil }},
+		{Metadata: Metadata{Name: "demo", Capabilities: []string{CapabilityNet, CapabilityHealth}}, HealthCheck: func(context.Context) error { return nil }},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Execute(context.Background(), p, nil, &stdout, &stderr); code != 0 {
+			t.Fatalf("expected exit code 0, got %d", code)
+		}
+		var m Metadata
+		if err := json.Unmarshal(stdout.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(m.Capabilities) != "[net health]" {
+			t.Errorf("unexpected capabilities %v", m.Capabilities)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package skyplugin
 
 import (
 	"encoding/json"
+	"flag"
+	"io"
 	"os"
 )
 
@@ -12,23 +14,95 @@ type Metadata struct {
 	Version    string            `json:"version,omitempty"`
 	Summary    string            `json:"summary,omitempty"`
 	Commands   []CommandMetadata `json:"commands,omitempty"`
+
+	// Capabilities declares what the plugin needs from the host, such as
+	// CapabilityFSRead or CapabilityNet. Sky records and displays them so
+	// users can audit a plugin; they are not enforced yet.
+	Capabilities []string `json:"capabilities,omitempty"`
 }
 
+// Well-known capability names for Metadata.Capabilities.
+const (
+	// CapabilityFSRead reads files outside the workspace.
+	CapabilityFSRead = "fs:read"
+	// CapabilityFSWrite writes files outside the workspace.
+	CapabilityFSWrite = "fs:write"
+	// CapabilityWorkspaceRead reads files in the workspace.
+	CapabilityWorkspaceRead = "workspace:read"
+	// CapabilityWorkspaceWrite modifies files in the workspace.
+	CapabilityWorkspaceWrite = "workspace:write"
+	// CapabilityNet makes network requests.
+	CapabilityNet = "net"
+	// CapabilityExec runs other processes.
+	CapabilityExec = "exec"
+	// CapabilityDaemon asks the host to keep the plugin running and send it
+	// requests over stdin instead of starting a process per call. Serve
+	// handles the protocol; Run must write through os.Stdout and os.Stderr
+	// as they are at call time, and should not keep state it expects to be
+	// reset between calls.
+	CapabilityDaemon = "daemon"
+	// CapabilityHealth tells the host the plugin answers health checks.
+	// Serve adds it when Plugin.HealthCheck is set.
+	CapabilityHealth = "health"
+)
+
 // CommandMetadata describes a single plugin command.
 type CommandMetadata struct {
 	Name    string `json:"name"`
 	Summary string `json:"summary,omitempty"`
+
+	// Flags lists the flags the command accepts, so that "sky plugin
+	// inspect" can show how to use it. See FlagsFromFlagSet.
+	Flags []FlagMetadata `json:"flags,omitempty"`
+}
+
+// FlagMetadata describes a flag accepted by a plugin command.
+type FlagMetadata struct {
+	// Name is the flag name without leading dashes.
+	Name string `json:"name"`
+	// Type is a short name for the value, such as "string", "int", or
+	// "bool".
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// FlagsFromFlagSet describes the flags defined in fs, in lexical order, for
+// use in CommandMetadata.Flags:
+//
+//	fs := flag.NewFlagSet("hello", flag.ContinueOnError)
+//	fs.String("name", "world", "who to greet")
+//	cmd := skyplugin.CommandMetadata{Name: "hello", Flags: skyplugin.FlagsFromFlagSet(fs)}
+func FlagsFromFlagSet(fs *flag.FlagSet) []FlagMetadata {
+	var flags []FlagMetadata
+	fs.VisitAll(func(f *flag.Flag) {
+		typ, usage := flag.UnquoteUsage(f)
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			typ = "bool"
+		}
+		flags = append(flags, FlagMetadata{
+			Name:        f.Name,
+			Type:        typ,
+			Description: usage,
+			Default:     f.DefValue,
+		})
+	})
+	return flags
 }
 
 // HandleMetadata writes the metadata as JSON to stdout and exits.
 // This should be called when IsMetadataMode() returns true.
 func HandleMetadata(m Metadata) {
-	if m.APIVersion == 0 {
-		m.APIVersion = 1
-	}
-	enc := json.NewEncoder(os.Stdout)
-	if err := enc.Encode(m); err != nil {
+	if err := writeMetadata(os.Stdout, m); err != nil {
 		os.Exit(1)
 	}
 	os.Exit(0)
 }
+
+// writeMetadata writes m as JSON, defaulting the API version to 1.
+func writeMetadata(w io.Writer, m Metadata) error {
+	if m.APIVersion == 0 {
+		m.APIVersion = 1
+	}
+	return json.NewEncoder(w).Encode(m)
+}
@@ -2,9 +2,14 @@ package skyplugin
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"slices"
 )
 
 // Plugin defines a Sky plugin.
@@ -14,14 +19,27 @@ type Plugin struct {
 
 	// Run is the main entry point for the plugin.
 	// It receives the CLI arguments (excluding the program name).
+	// Return an *ExitError to choose the exit code; other errors exit 1.
 	Run func(ctx context.Context, args []string) error
+
+	// HealthCheck, if set, reports whether the plugin is ready to run, for
+	// "sky plugin doctor". Return nil when ready, or an error explaining
+	// what is wrong; a *MissingDependencyError also lists what to install.
+	HealthCheck func(ctx context.Context) error
 }
 
 // Serve is the main entrypoint for plugins.
 // It handles the plugin protocol:
 //   - If running in metadata mode, outputs metadata and exits
+//   - If running in health mode, outputs the result of HealthCheck and exits
+//   - If running in daemon mode, serves requests from stdin until it closes
+//     (see CapabilityDaemon)
 //   - Otherwise, calls the Run function with a cancellable context
 //
+// If Run panics, Serve prints a one-line error to stderr instead of a raw
+// stack trace (the stack is included when SKY_VERBOSE >= 2), writes a JSON
+// error object to stdout in JSON output mode, and exits with ExitPanic.
+//
 // Usage:
 //
 //	func main() {
@@ -39,28 +57,95 @@ type Plugin struct {
 //		})
 //	}
 func Serve(p Plugin) {
+	// Set up context with cancellation on interrupt
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := Execute(ctx, p, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// Execute runs the plugin protocol in-process and returns the exit code
+// instead of exiting. In metadata mode it writes the metadata JSON to
+// stdout; in health mode it writes the HealthCheck report; in daemon mode it reads requests from os.Stdin and writes
+// responses to stdout; otherwise it calls p.Run with args. Serve is Execute plus signal
+// handling and os.Exit; tests can call Execute directly (see the
+// skyplugin/testing package).
+func Execute(ctx context.Context, p Plugin, args []string, stdout, stderr io.Writer) int {
 	// Check if we're running as a plugin
 	if !IsPlugin() {
-		fmt.Fprintf(os.Stderr, "This is a Sky plugin. Run it with: sky %s\n", p.Metadata.Name)
-		os.Exit(1)
+		_, _ = fmt.Fprintf(stderr, "This is a Sky plugin. Run it with: sky %s\n", p.Metadata.Name)
+		return ExitFailure
 	}
 
 	// Handle metadata request
 	if IsMetadataMode() {
-		HandleMetadata(p.Metadata)
-		return // HandleMetadata calls os.Exit
+		m := p.Metadata
+		if p.HealthCheck != nil && !slices.Contains(m.Capabilities, CapabilityHealth) {
+			m.Capabilities = append(slices.Clone(m.Capabilities), CapabilityHealth)
+		}
+		if err := writeMetadata(stdout, m); err != nil {
+			return ExitFailure
+		}
+		return 0
 	}
 
-	// Set up context with cancellation on interrupt
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer cancel()
+	if IsHealthMode() {
+		return serveHealth(ctx, p, stdout)
+	}
 
-	// Run the plugin
-	args := os.Args[1:]
-	if err := p.Run(ctx, args); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	if IsDaemonMode() {
+		return serveDaemon(ctx, p, os.Stdin, stdout)
 	}
+
+	return run(ctx, p, args, stdout, stderr)
+}
+
+// errorPayload is the JSON object written to stdout when a plugin fails in
+// JSON output mode.
+type errorPayload struct {
+	Error string `json:"error"`
+	Panic bool   `json:"panic,omitempty"`
+}
+
+// run calls p.Run, converting a returned error or a panic into an exit code.
+func run(ctx context.Context, p Plugin, args []string, stdout, stderr io.Writer) (code int) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		msg := fmt.Sprintf("%s: internal error: %v", p.Metadata.Name, r)
+		_, _ = fmt.Fprintln(stderr, msg)
+		if Verbose() >= 2 {
+			_, _ = stderr.Write(debug.Stack())
+		}
+		if IsJSONOutput() {
+			_ = json.NewEncoder(stdout).Encode(errorPayload{Error: msg, Panic: true})
+		}
+		code = ExitPanic
+	}()
+
+	err := p.Run(ctx, args)
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.Err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+		}
+		if exitErr.Code <= 0 {
+			return ExitFailure
+		}
+		return exitErr.Code
+	}
+
+	_, _ = fmt.Fprintln(stderr, err)
+	return ExitFailure
 }
 
 // ServeFunc is a convenience wrapper around Serve for simple plugins.
@@ -0,0 +1,151 @@
+package skyplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func panicking(context.Context, []string) error {
+	panic("boom")
+}
+
+func TestRun_Panic(t *testing.T) {
+	t.Setenv(EnvOutputFormat, "text")
+	t.Setenv(EnvVerbose, "")
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), Plugin{Metadata: Metadata{Name: "demo"}, Run: panicking}, nil, &stdout, &stderr)
+
+	if code != ExitPanic {
+		t.Fatalf("expected exit code %d, got %d", ExitPanic, code)
+	}
+	if got := stderr.String(); got != "demo: internal error: boom\n" {
+		t.Fatalf("unexpected stderr: %q", got)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected empty stdout, got %q", stdout.String())
+	}
+}
+
+func TestRun_PanicVerboseStack(t *testing.T) {
+	t.Setenv(EnvOutputFormat, "text")
+	t.Setenv(EnvVerbose, "2")
+
+	var stdout, stderr bytes.Buffer
+	run(context.Background(), Plugin{Metadata: Metadata{Name: "demo"}, Run: panicking}, nil, &stdout, &stderr)
+
+	if !strings.Contains(stderr.String(), "goroutine") {
+		t.Fatalf("expected stack trace at verbosity 2, got %q", stderr.String())
+	}
+}
+
+func TestRun_PanicJSON(t *testing.T) {
+	t.Setenv(EnvOutputFormat, "json")
+	t.Setenv(EnvVerbose, "")
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), Plugin{Metadata: Metadata{Name: "demo"}, Run: panicking}, nil, &stdout, &stderr)
+
+	if code != ExitPanic {
+		t.Fatalf("expected exit code %d, got %d", ExitPanic, code)
+	}
+	var payload errorPayload
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("expected JSON error on stdout: %v (%q)", err, stdout.String())
+	}
+	if !payload.Panic || payload.Error != "demo: internal error: boom" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestRun_Error(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), Plugin{Run: func(context.Context, []string) error {
+		return errors.New("bad input")
+	}}, nil, &stdout, &stderr)
+
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if stderr.String() != "bad input\n" {
+		t.Fatalf("unexpected stderr: %q", stderr.String())
+	}
+}
+
+func TestRun_ExitError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		stderr string
+	}{
+		{name: "code", err: &ExitError{Code: 3, Err: errors.New("no match")}, code: 3, stderr: "no match\n"},
+		{name: "exitf", err: Exitf(ExitUsage, "usage: %s <file>", "demo"), code: ExitUsage, stderr: "usage: demo <file>\n"},
+		{name: "silent", err: &ExitError{Code: 4}, code: 4, stderr: ""},
+		{name: "wrapped", err: fmt.Errorf("lint: %w", &ExitError{Code: 5, Err: errors.New("failed")}), code: 5, stderr: "lint: failed\n"},
+		{name: "zero code", err: &ExitError{Err: errors.New("oops")}, code: ExitFailure, stderr: "oops\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), Plugin{Run: func(context.Context, []string) error {
+				return tt.err
+			}}, nil, &stdout, &stderr)
+
+			if code != tt.code {
+				t.Fatalf("expected exit code %d, got %d", tt.code, code)
+			}
+			if stderr.String() != tt.stderr {
+				t.Fatalf("expected stderr %q, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestFlagsFromFlagSet(t *testing.T) {
+	fs := flag.NewFlagSet("greet", flag.ContinueOnError)
+	fs.String("name", "world", "`who` to greet")
+	fs.Bool("loud", false, "shout the greeting")
+	fs.Int("count", 1, "number of greetings")
+
+	got := FlagsFromFlagSet(fs)
+	want := []FlagMetadata{
+		{Name: "count", Type: "int", Description: "number of greetings", Default: "1"},
+		{Name: "loud", Type: "bool", Description: "shout the greeting", Default: "false"},
+		{Name: "name", Type: "who", Description: "who to greet", Default: "world"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d flags, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("flag %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	tests := []struct {
+		sky, std string
+		want     bool
+	}{
+		{"", "", false},
+		{"1", "", true},
+		{"true", "", true},
+		{"", "1", true},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvNoColor, tt.sky)
+		t.Setenv("NO_COLOR", tt.std)
+		if got := NoColor(); got != tt.want {
+			t.Errorf("NoColor() with SKY_NO_COLOR=%q NO_COLOR=%q = %v, want %v", tt.sky, tt.std, got, tt.want)
+		}
+	}
+}
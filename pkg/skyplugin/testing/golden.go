@@ -0,0 +1,82 @@
+package testing
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	stdtesting "testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden rewrite
+// golden files when set to a true value such as "1".
+const UpdateEnv = "SKY_UPDATE_GOLDEN"
+
+// Update reports whether golden files should be rewritten: UpdateEnv is
+// true, or the test binary defines its own boolean -update flag and it is
+// set. This package does not register -update itself, so it cannot clash
+// with a flag of the same name.
+func Update() bool {
+	if on, err := strconv.ParseBool(os.Getenv(UpdateEnv)); err == nil && on {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			on, _ := getter.Get().(bool)
+			return on
+		}
+	}
+	return false
+}
+
+// AssertGolden compares got against the contents of the golden file at
+// path and fails the test with a unified diff if they differ.
+//
+// When Update reports true, AssertGolden writes got to path instead
+// (creating parent directories as needed):
+//
+//	SKY_UPDATE_GOLDEN=1 go test ./...
+//
+// Usage:
+//
+//	result := testing.RunPlugin(myPlugin, []string{"BUILD"}, "")
+//	testing.AssertGolden(t, "testdata/build.golden", []byte(result.Stdout))
+func AssertGolden(t stdtesting.TB, path string, got []byte) {
+	t.Helper()
+
+	if Update() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("update golden file %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("update golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			t.Fatalf("golden file %s does not exist (set "+UpdateEnv+"=1 to create it)", path)
+		}
+		t.Fatalf("read golden file %s: %v", path, err)
+	}
+	if bytes.Equal(want, got) {
+		return
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(want)),
+		B:        difflib.SplitLines(string(got)),
+		FromFile: path,
+		ToFile:   "got",
+		Context:  3,
+	})
+	if err != nil || diff == "" {
+		diff = "--- " + path + "\n" + string(want) + "\n+++ got\n" + string(got)
+	}
+	t.Errorf("output does not match golden file %s (set "+UpdateEnv+"=1 to accept):\n%s", path, diff)
+}
@@ -0,0 +1,88 @@
+package testing_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	skytesting "github.com/albertocavalcante/sky/pkg/skyplugin/testing"
+)
+
+// recorder captures failures from AssertGolden without failing the test.
+type recorder struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+	r.msg = fmt.Sprintf(format, args...)
+}
+
+// Fatalf records only the first failure, since it cannot stop the caller.
+func (r *recorder) Fatalf(format string, args ...any) {
+	if !r.failed {
+		r.Errorf(format, args...)
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	if skytesting.Update() {
+		t.Skip("golden comparison is not meaningful with -update")
+	}
+
+	path := filepath.Join(t.TempDir(), "out.golden")
+	if err := os.WriteFile(path, []byte("line 1\nline 2\n"), 0o644); err != nil {
+		t.Fatalf("write golden: %v", err)
+	}
+
+	match := &recorder{TB: t}
+	skytesting.AssertGolden(match, path, []byte("line 1\nline 2\n"))
+	if match.failed {
+		t.Fatalf("expected match, got failure: %s", match.msg)
+	}
+
+	mismatch := &recorder{TB: t}
+	skytesting.AssertGolden(mismatch, path, []byte("line 1\nline two\n"))
+	if !mismatch.failed {
+		t.Fatalf("expected mismatch to fail")
+	}
+	if !strings.Contains(mismatch.msg, "-line 2") || !strings.Contains(mismatch.msg, "+line two") {
+		t.Fatalf("expected unified diff, got %q", mismatch.msg)
+	}
+
+	missing := &recorder{TB: t}
+	skytesting.AssertGolden(missing, filepath.Join(t.TempDir(), "missing.golden"), nil)
+	if !missing.failed || !strings.Contains(missing.msg, skytesting.UpdateEnv) {
+		t.Fatalf("expected missing golden failure mentioning %s, got %q", skytesting.UpdateEnv, missing.msg)
+	}
+}
+
+// update is defined here as a plugin's own tests would; importing the
+// package must not register a clashing -update flag.
+var update = flag.Bool("update", false, "update golden files")
+
+func TestAssertGolden_Update(t *testing.T) {
+	t.Setenv(skytesting.UpdateEnv, "1")
+	if !skytesting.Update() {
+		t.Fatalf("expected Update() with %s=1", skytesting.UpdateEnv)
+	}
+
+	path := filepath.Join(t.TempDir(), "sub", "out.golden")
+	skytesting.AssertGolden(t, path, []byte("new output\n"))
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "new output\n" {
+		t.Fatalf("expected golden file to be written, got %q, %v", got, err)
+	}
+
+	t.Setenv(skytesting.UpdateEnv, "")
+	if skytesting.Update() != *update {
+		t.Errorf("Update() should follow the -update flag when %s is unset", skytesting.UpdateEnv)
+	}
+}
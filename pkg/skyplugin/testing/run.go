@@ -0,0 +1,83 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+)
+
+// RunPlugin runs a plugin end-to-end in exec mode, as if invoked by
+// "sky <name> args...". It sets up the plugin environment, feeds stdin to
+// os.Stdin, and returns everything the plugin wrote together with the exit
+// code Serve would have used.
+//
+// Like CaptureOutput, this swaps process-wide state and must not be used
+// from parallel tests.
+//
+// Usage:
+//
+//	result := testing.RunPlugin(myPlugin, []string{"--check", "BUILD"}, "")
+//	if result.ExitCode != 0 {
+//		t.Fatalf("exit %d: %s", result.ExitCode, result.Stderr)
+//	}
+func RunPlugin(p skyplugin.Plugin, args []string, stdin string) CaptureResult {
+	return runMode(p, "exec", args, stdin)
+}
+
+// RunMetadata runs the plugin's metadata handshake and decodes the JSON it
+// prints, failing if the plugin exits non-zero or prints anything else.
+func RunMetadata(p skyplugin.Plugin) (skyplugin.Metadata, error) {
+	result := runMode(p, "metadata", nil, "")
+	if result.ExitCode != 0 {
+		return skyplugin.Metadata{}, fmt.Errorf("metadata exited with code %d: %s", result.ExitCode, result.Stderr)
+	}
+
+	var m skyplugin.Metadata
+	dec := json.NewDecoder(bytes.NewReader([]byte(result.Stdout)))
+	if err := dec.Decode(&m); err != nil {
+		return skyplugin.Metadata{}, fmt.Errorf("invalid metadata JSON: %w", err)
+	}
+	if dec.More() {
+		return skyplugin.Metadata{}, fmt.Errorf("unexpected output after metadata JSON")
+	}
+	return m, nil
+}
+
+// runMode runs p via skyplugin.Execute with a mocked environment and stdin.
+func runMode(p skyplugin.Plugin, mode string, args []string, stdin string) CaptureResult {
+	cleanup := MockEnv(mode, p.Metadata.Name)
+	defer cleanup()
+
+	restoreStdin := mockStdin(stdin)
+	defer restoreStdin()
+
+	result := CaptureResult{ExitCode: -1}
+	result.Stdout, result.Stderr = CaptureOutputSimple(func() {
+		result.ExitCode = skyplugin.Execute(context.Background(), p, args, os.Stdout, os.Stderr)
+	})
+	return result
+}
+
+// mockStdin replaces os.Stdin with a pipe that yields input, returning a
+// function that restores the original.
+func mockStdin(input string) func() {
+	orig := os.Stdin
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	go func() {
+		_, _ = io.WriteString(w, input)
+		_ = w.Close()
+	}()
+	os.Stdin = r
+	return func() {
+		os.Stdin = orig
+		_ = r.Close()
+	}
+}
@@ -0,0 +1,79 @@
+package testing_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/albertocavalcante/sky/pkg/skyplugin"
+	skytesting "github.com/albertocavalcante/sky/pkg/skyplugin/testing"
+)
+
+var upper = skyplugin.Plugin{
+	Metadata: skyplugin.Metadata{
+		Name:    "upper",
+		Version: "0.1.0",
+		Summary: "Uppercase stdin",
+	},
+	Run: func(ctx context.Context, args []string) error {
+		if len(args) > 0 {
+			return skyplugin.Exitf(skyplugin.ExitUsage, "upper: unexpected argument %q", args[0])
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		fmt.Print(strings.ToUpper(string(data)))
+		fmt.Fprintln(os.Stderr, "plugin:", skyplugin.PluginName())
+		return nil
+	},
+}
+
+func TestRunPlugin(t *testing.T) {
+	result := skytesting.RunPlugin(upper, nil, "hello\n")
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr %q)", result.ExitCode, result.Stderr)
+	}
+	if result.Stdout != "HELLO\n" {
+		t.Fatalf("expected stdout %q, got %q", "HELLO\n", result.Stdout)
+	}
+	if result.Stderr != "plugin: upper\n" {
+		t.Fatalf("expected plugin name in env, got stderr %q", result.Stderr)
+	}
+}
+
+func TestRunPlugin_ExitCode(t *testing.T) {
+	result := skytesting.RunPlugin(upper, []string{"extra"}, "")
+
+	if result.ExitCode != skyplugin.ExitUsage {
+		t.Fatalf("expected exit code %d, got %d", skyplugin.ExitUsage, result.ExitCode)
+	}
+	if !strings.Contains(result.Stderr, `unexpected argument "extra"`) {
+		t.Fatalf("unexpected stderr: %q", result.Stderr)
+	}
+}
+
+func TestRunMetadata(t *testing.T) {
+	m, err := skytesting.RunMetadata(upper)
+	if err != nil {
+		t.Fatalf("RunMetadata: %v", err)
+	}
+	if m.Name != "upper" || m.Version != "0.1.0" || m.APIVersion != 1 {
+		t.Fatalf("unexpected metadata: %+v", m)
+	}
+}
+
+func TestRunMetadata_NoRun(t *testing.T) {
+	p := upper
+	p.Run = func(context.Context, []string) error {
+		return errors.New("Run must not be called in metadata mode")
+	}
+	if _, err := skytesting.RunMetadata(p); err != nil {
+		t.Fatalf("RunMetadata: %v", err)
+	}
+}